@@ -3,6 +3,7 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -12,6 +13,7 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/muhammadmuzzammil1998/jsonc"
@@ -58,14 +60,18 @@ func LoadClashAPIConfig(configPath string) (baseURL, token string, err error) {
 	host, _ := api["external_controller"].(string)
 	secret, _ := api["secret"].(string)
 
-	if host == "" || secret == "" {
-		return "", "", fmt.Errorf("'external_controller' or 'secret' is empty in Clash API config")
+	// The secret is optional: without it sing-box accepts requests without authorization
+	if host == "" {
+		return "", "", fmt.Errorf("'external_controller' is empty in Clash API config")
 	}
 
 	baseURL = "http://" + host
 	token = secret
 
-	log.Printf("Clash API loaded from config: %s / %s", baseURL, token)
+	if token == "" {
+		log.Printf("LoadClashAPIConfig: Warning: experimental.clash_api.secret is empty, any local program can control sing-box through %s", baseURL)
+	}
+	log.Printf("Clash API loaded from config: %s (secret set: %t)", baseURL, token != "")
 	return baseURL, token, nil
 }
 
@@ -84,6 +90,19 @@ var httpClient = &http.Client{
 	},
 }
 
+// newAuthenticatedRequest creates a Clash API request with "Authorization: Bearer <token>"
+// (experimental.clash_api.secret); without a token the header is not sent
+func newAuthenticatedRequest(ctx context.Context, method, url, token string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return req, nil
+}
+
 // TestAPIConnection attempts to connect to the Clash API.
 func TestAPIConnection(baseURL, token string, logFile *os.File) error {
 	logMessage := fmt.Sprintf("[%s] GET /version request started for API test.\n", time.Now().Format("2006-01-02 15:04:05"))
@@ -94,14 +113,13 @@ func TestAPIConnection(baseURL, token string, logFile *os.File) error {
 	url := fmt.Sprintf("%s/version", baseURL)
 	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(httpRequestTimeoutSeconds)*time.Second)
 	defer cancel()
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	req, err := newAuthenticatedRequest(ctx, "GET", url, token, nil)
 	if err != nil {
 		if logFile != nil {
 			fmt.Fprint(logFile, fmt.Sprintf("[%s] Error creating API test request: %v\n", time.Now().Format("2006-01-02 15:04:05"), err))
 		}
 		return fmt.Errorf("failed to create API test request: %w", err)
 	}
-	req.Header.Set("Authorization", "Bearer "+token)
 
 	resp, err := httpClient.Do(req)
 	if err != nil {
@@ -136,6 +154,45 @@ func TestAPIConnection(baseURL, token string, logFile *os.File) error {
 	return nil
 }
 
+// CheckVersion does GET /version and returns a descriptive error if the Clash API can't be reached
+// before ctx is done, refuses the connection or answers with another status than 200
+func CheckVersion(ctx context.Context, baseURL, token string) error {
+	url := fmt.Sprintf("%s/version", baseURL)
+	req, err := newAuthenticatedRequest(ctx, "GET", url, token, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		var netErr net.Error
+		switch {
+		case isConnectionRefused(err):
+			return fmt.Errorf("connection refused by %s", req.URL.Host)
+		case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+			return fmt.Errorf("no answer from %s: connection timed out", req.URL.Host)
+		}
+		return fmt.Errorf("cannot connect to %s: %w", req.URL.Host, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusUnauthorized:
+		return fmt.Errorf("unauthorized (401): the secret in experimental.clash_api does not match")
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+}
+
+// isConnectionRefused reports whether nothing listens on the port. On Windows the error is
+// WSAECONNREFUSED, which is not syscall.ECONNREFUSED, so the message is checked as well.
+func isConnectionRefused(err error) bool {
+	var opErr *net.OpError
+	return errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.As(err, &opErr) && opErr.Op == "dial" && strings.Contains(opErr.Err.Error(), "refused")
+}
+
 // ProxyInfo holds the proxy name and traffic usage.
 type ProxyInfo struct {
 	Name    string
@@ -160,12 +217,11 @@ func GetProxiesInGroup(baseURL, token, groupName string, logFile *os.File) ([]Pr
 
 	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(httpRequestTimeoutSeconds)*time.Second)
 	defer cancel()
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	req, err := newAuthenticatedRequest(ctx, "GET", url, token, nil)
 	if err != nil {
 		logMsg("GetProxiesInGroup: ERROR: Failed to create request: %v", err)
 		return nil, "", fmt.Errorf("failed to create /proxies request: %w", err)
 	}
-	req.Header.Set("Authorization", "Bearer "+token)
 
 	resp, err := httpClient.Do(req)
 	if err != nil {
@@ -273,15 +329,13 @@ func SwitchProxy(baseURL, token, group, proxy string, logFile *os.File) error {
 
 	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(httpRequestTimeoutSeconds)*time.Second)
 	defer cancel()
-	req, err := http.NewRequestWithContext(ctx, "PUT", url, payload)
+	req, err := newAuthenticatedRequest(ctx, "PUT", url, token, payload)
 	if err != nil {
 		if logFile != nil {
 			fmt.Fprint(logFile, fmt.Sprintf("[%s] Error creating switch request for %s/%s: %v\n", time.Now().Format("2006-01-02 15:04:05"), group, proxy, err))
 		}
 		return fmt.Errorf("failed to create switch request: %w", err)
 	}
-
-	req.Header.Set("Authorization", "Bearer "+token)
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := httpClient.Do(req)
@@ -317,6 +371,124 @@ func SwitchProxy(baseURL, token, group, proxy string, logFile *os.File) error {
 	return nil
 }
 
+// GroupInfo describes a selector group reported by the Clash API
+type GroupInfo struct {
+	Name    string
+	Now     string   // Currently selected member
+	Members []string // Member tags in config order
+	Delay   int64    // Last known delay of the selected member in ms
+}
+
+// GetSelectorGroups returns all selector groups from GET /proxies, sorted by name.
+func GetSelectorGroups(baseURL, token string, logFile *os.File) ([]GroupInfo, error) {
+	url := fmt.Sprintf("%s/proxies", baseURL)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(httpRequestTimeoutSeconds)*time.Second)
+	defer cancel()
+	req, err := newAuthenticatedRequest(ctx, "GET", url, token, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create /proxies request: %w", err)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		if logFile != nil {
+			fmt.Fprint(logFile, fmt.Sprintf("[%s] GetSelectorGroups: Failed to execute request: %v\n", time.Now().Format("2006-01-02 15:04:05"), err))
+		}
+		if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+			return nil, fmt.Errorf("network timeout: connection timed out")
+		}
+		if opErr, ok := err.(*net.OpError); ok && opErr.Op == "dial" {
+			return nil, fmt.Errorf("network error: cannot connect to server")
+		}
+		return nil, fmt.Errorf("failed to execute /proxies request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read /proxies response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code for /proxies: %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var data struct {
+		Proxies map[string]struct {
+			Type    string   `json:"type"`
+			Now     string   `json:"now"`
+			All     []string `json:"all"`
+			History []struct {
+				Delay int64 `json:"delay"`
+			} `json:"history"`
+		} `json:"proxies"`
+	}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal /proxies response: %w", err)
+	}
+
+	var groups []GroupInfo
+	for name, p := range data.Proxies {
+		if !strings.EqualFold(p.Type, "Selector") {
+			continue
+		}
+		g := GroupInfo{Name: name, Now: p.Now, Members: p.All}
+		if now, ok := data.Proxies[p.Now]; ok && len(now.History) > 0 {
+			g.Delay = now.History[0].Delay
+		}
+		groups = append(groups, g)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].Name < groups[j].Name
+	})
+	if logFile != nil {
+		fmt.Fprint(logFile, fmt.Sprintf("[%s] GetSelectorGroups: Found %d selector groups.\n", time.Now().Format("2006-01-02 15:04:05"), len(groups)))
+	}
+	return groups, nil
+}
+
+// GetGroupNow returns the currently selected proxy of a selector group (GET /proxies/{group}).
+// Only errors are written to the log file, as it is called periodically.
+func GetGroupNow(baseURL, token, group string, logFile *os.File) (string, error) {
+	url := fmt.Sprintf("%s/proxies/%s", baseURL, group)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(httpRequestTimeoutSeconds)*time.Second)
+	defer cancel()
+	req, err := newAuthenticatedRequest(ctx, "GET", url, token, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create group request: %w", err)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+			return "", fmt.Errorf("network timeout: connection timed out")
+		}
+		if opErr, ok := err.(*net.OpError); ok && opErr.Op == "dial" {
+			return "", fmt.Errorf("network error: cannot connect to server")
+		}
+		return "", fmt.Errorf("failed to execute group request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read group response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		if logFile != nil {
+			fmt.Fprint(logFile, fmt.Sprintf("[%s] Unexpected status code for GET /proxies/%s: %d, body: %s\n", time.Now().Format("2006-01-02 15:04:05"), group, resp.StatusCode, string(body)))
+		}
+		return "", fmt.Errorf("unexpected status code for group %s: %d", group, resp.StatusCode)
+	}
+
+	var data struct {
+		Now string `json:"now"`
+	}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return "", fmt.Errorf("failed to unmarshal group response: %w", err)
+	}
+	return data.Now, nil
+}
+
 // GetDelay gets the delay for the specified proxy node.
 func GetDelay(baseURL, token, proxyName string, logFile *os.File) (int64, error) {
 	logMessage := fmt.Sprintf("[%s] GET /proxies/%s/delay request started.\n", time.Now().Format("2006-01-02 15:04:05"), proxyName)
@@ -327,7 +499,7 @@ func GetDelay(baseURL, token, proxyName string, logFile *os.File) (int64, error)
 	url := fmt.Sprintf("%s/proxies/%s/delay?timeout=5000&url=http://www.gstatic.com/generate_204", baseURL, proxyName)
 	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(httpRequestTimeoutSeconds)*time.Second)
 	defer cancel()
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	req, err := newAuthenticatedRequest(ctx, "GET", url, token, nil)
 	if err != nil {
 		if logFile != nil {
 			fmt.Fprint(logFile, fmt.Sprintf("[%s] Error creating delay request for %s: %v\n", time.Now().Format("2006-01-02 15:04:05"), proxyName, err))
@@ -335,8 +507,6 @@ func GetDelay(baseURL, token, proxyName string, logFile *os.File) (int64, error)
 		return 0, fmt.Errorf("failed to create delay request: %w", err)
 	}
 
-	req.Header.Set("Authorization", "Bearer "+token)
-
 	resp, err := httpClient.Do(req)
 	if err != nil {
 		if logFile != nil {
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAuthorizationHeader(t *testing.T) {
+	tests := []struct {
+		name  string
+		token string
+		want  string
+	}{
+		{"secret", "s3cret", "Bearer s3cret"},
+		{"no secret", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			var hasHeader bool
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.Header.Get("Authorization")
+				_, hasHeader = r.Header["Authorization"]
+				w.Write([]byte(`{"version":"1.12.0"}`))
+			}))
+			defer server.Close()
+
+			if err := TestAPIConnection(server.URL, tt.token, nil); err != nil {
+				t.Fatalf("TestAPIConnection() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Authorization = %q, want %q", got, tt.want)
+			}
+			if tt.want == "" && hasHeader {
+				t.Error("Authorization header sent without a secret")
+			}
+		})
+	}
+}
+
+func TestLoadClashAPIConfigEmptySecret(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	config := `{
+  // JSONC
+  "experimental": {"clash_api": {"external_controller": "127.0.0.1:9090", "secret": ""}},
+}`
+	if err := os.WriteFile(configPath, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	baseURL, token, err := LoadClashAPIConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadClashAPIConfig() error = %v", err)
+	}
+	if baseURL != "http://127.0.0.1:9090" || token != "" {
+		t.Errorf("LoadClashAPIConfig() = %q, %q, want %q, %q", baseURL, token, "http://127.0.0.1:9090", "")
+	}
+}
@@ -24,6 +24,8 @@ set "PATH=C:\Program Files\Go\bin;%PATH%"
 set "PATH=%USERPROFILE%\go\bin;%PATH%"
 
 set CGO_ENABLED=1
+:: Версия лаунчера для User-Agent, можно передать через set VERSION=1.2.3
+if "%VERSION%"=="" set "VERSION=dev"
 set GOOS=windows
 set GOARCH=amd64
 
@@ -72,7 +74,7 @@ echo Using output file: "%OUTPUT_FILENAME%"
 :: Собираем проект
 echo.
 echo === Starting Build ===
-go build -buildvcs=false -ldflags="-H windowsgui -s -w" -o "%OUTPUT_FILENAME%"
+go build -buildvcs=false -ldflags="-H windowsgui -s -w -X singbox-launcher/core.AppVersion=%VERSION%" -o "%OUTPUT_FILENAME%"
 
 if %ERRORLEVEL% NEQ 0 (
     echo.
@@ -0,0 +1,144 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Events of the audit log entries
+const (
+	AuditEventConfigGenerated = "config_generated" // see LogConfigGeneration
+	AuditEventSingBoxStopped  = "singbox_stopped"  // see LogSingBoxStop
+)
+
+// Stop methods recorded by LogSingBoxStop
+const (
+	StopMethodGraceful     = "graceful"             // sing-box exited after the stop signal
+	StopMethodSignalFailed = "forced:signal_failed" // the stop signal could not be sent, killed
+	StopMethodTimeout      = "forced:timeout"       // still running after StopTimeout, killed
+)
+
+// AuditLog appends AuditEntry records to a file, one JSON object per line, oldest first.
+// Unlike the launcher logs it is not rotated. The file is only readable by the user.
+type AuditLog struct {
+	mutex sync.Mutex
+	path  string
+}
+
+// AuditEntry is one line of the audit log
+type AuditEntry struct {
+	Timestamp time.Time `json:"timestamp"`
+	Event     string    `json:"event"` // AuditEventConfigGenerated, AuditEventSingBoxStopped
+	// Sources are the subscription URLs, not their content, see auditSourceURL
+	Sources []string `json:"sources,omitempty"`
+	// RuleAssignments maps the label of each enabled selectable rule to its outbound tag
+	// ("reject"/"drop" for rejecting rules)
+	RuleAssignments map[string]string `json:"rule_assignments,omitempty"`
+	OutputPath      string            `json:"output_path,omitempty"`
+	SHA256          string            `json:"sha256,omitempty"` // of the file at OutputPath
+
+	PID        int    `json:"pid,omitempty"`
+	StopMethod string `json:"stop_method,omitempty"` // StopMethodGraceful, StopMethodSignalFailed or StopMethodTimeout
+}
+
+// NewAuditLog creates an audit log writing to path; the file is created on the first entry
+func NewAuditLog(path string) *AuditLog {
+	return &AuditLog{path: path}
+}
+
+// Path returns the file of the audit log
+func (l *AuditLog) Path() string {
+	return l.path
+}
+
+// LogConfigGeneration records that the config at outputPath was generated from the subscription
+// sources with the given rule assignments (label → outbound tag). With the SHA-256 of the written
+// file a config can be matched to the inputs it was generated from. Failures are only logged.
+func (l *AuditLog) LogConfigGeneration(sources []string, ruleAssignments map[string]string, outputPath string) {
+	entry := AuditEntry{
+		Timestamp:       time.Now().UTC(),
+		Event:           AuditEventConfigGenerated,
+		RuleAssignments: ruleAssignments,
+		OutputPath:      outputPath,
+	}
+	for _, source := range sources {
+		entry.Sources = append(entry.Sources, auditSourceURL(source))
+	}
+	if data, err := os.ReadFile(outputPath); err != nil {
+		log.Printf("LogConfigGeneration: Failed to read %s: %v", outputPath, err)
+	} else {
+		sum := sha256.Sum256(data)
+		entry.SHA256 = hex.EncodeToString(sum[:])
+	}
+	l.append(entry)
+}
+
+// LogSingBoxStop records how the sing-box process pid was stopped (one of the StopMethod constants)
+func (l *AuditLog) LogSingBoxStop(pid int, method string) {
+	l.append(AuditEntry{
+		Timestamp:  time.Now().UTC(),
+		Event:      AuditEventSingBoxStopped,
+		PID:        pid,
+		StopMethod: method,
+	})
+}
+
+// append writes entry as a line at the end of the file
+func (l *AuditLog) append(entry AuditEntry) {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		log.Printf("AuditLog: Failed to encode entry: %v", err)
+		return
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		log.Printf("AuditLog: Failed to create directory: %v", err)
+		return
+	}
+	file, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		log.Printf("AuditLog: Failed to open %s: %v", l.path, err)
+		return
+	}
+	defer file.Close()
+	// A file created by an older version is readable by everyone
+	if err := file.Chmod(0600); err != nil {
+		log.Printf("AuditLog: Failed to restrict permissions of %s: %v", l.path, err)
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		log.Printf("AuditLog: Failed to write %s: %v", l.path, err)
+	}
+}
+
+// auditSourceURL returns source as it is written to the audit log: a data: URI holds the
+// subscription itself, so only its hash is kept. Credentials and tokens of a URL (user info,
+// query string) are replaced by the hash of the whole URL as well.
+func auditSourceURL(source string) string {
+	sum := sha256.Sum256([]byte(source))
+	hash := hex.EncodeToString(sum[:])
+	if IsDataURI(source) {
+		return "data:sha256=" + hash
+	}
+	u, err := url.Parse(source)
+	if err != nil {
+		return "sha256=" + hash
+	}
+	if u.User == nil && u.RawQuery == "" && !u.ForceQuery {
+		return source
+	}
+	u.User = nil
+	u.RawQuery = ""
+	u.ForceQuery = false
+	u.Fragment = ""
+	u.RawFragment = ""
+	return u.String() + "#sha256=" + hash
+}
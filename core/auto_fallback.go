@@ -0,0 +1,113 @@
+package core
+
+import (
+	"log"
+	"time"
+
+	"singbox-launcher/api"
+)
+
+const (
+	defaultFallbackHealthCheckInterval = 60 * time.Second // how often AutoFallback checks the selector groups
+	defaultFallbackThresholdMs         = 1000             // latency above which the selected proxy is replaced
+)
+
+// SetAutoFallback turns the automatic switch away from slow proxies on or off (not saved, see
+// Preferences.AutoFallback). Safe to call while StartAutoFallback runs.
+func (ac *AppController) SetAutoFallback(enabled bool) {
+	ac.autoFallback.Store(enabled)
+}
+
+// IsAutoFallbackEnabled reports whether StartAutoFallback switches slow selector groups
+func (ac *AppController) IsAutoFallbackEnabled() bool {
+	return ac.autoFallback.Load()
+}
+
+// StartAutoFallback checks every FallbackHealthCheckInterval (read once, when called) whether the
+// selected proxy of a selector group is slow or unreachable and switches the group to the
+// member with the lowest latency. Checks are skipped while AutoFallback is off, sing-box is not
+// running or the Clash API is disabled. Runs until the application exits.
+func (ac *AppController) StartAutoFallback() {
+	interval := ac.FallbackHealthCheckInterval
+	if interval <= 0 {
+		interval = defaultFallbackHealthCheckInterval
+	}
+	ac.GoBackground(func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ac.ShuttingDown():
+				return
+			case <-ticker.C:
+			}
+			if !ac.IsAutoFallbackEnabled() || !ac.RunningState.IsRunning() || !ac.ClashAPIEnabled {
+				continue
+			}
+			ac.checkFallback()
+		}
+	})
+}
+
+// checkFallback switches every selector group whose selected proxy is slower than
+// FallbackThresholdMs (or failed its test) and publishes ProxySwitched for each switch
+func (ac *AppController) checkFallback() {
+	groups, err := api.GetSelectorGroups(ac.ClashAPIBaseURL, ac.ClashAPIToken, ac.ApiLogFile)
+	if err != nil {
+		log.Printf("checkFallback: %v", err)
+		return
+	}
+	for _, group := range groups {
+		if group.Now == "" || len(group.Members) < 2 {
+			continue
+		}
+		latency, err := ac.GetURLTestLatency(group.Name)
+		if err != nil {
+			log.Printf("checkFallback: %v", err)
+			continue
+		}
+		// 0 means either "never tested" or "test failed": test the selected proxy now,
+		// so one that just was not measured yet is not switched away from
+		if latency[group.Now] == 0 {
+			delay, err := api.GetDelay(ac.ClashAPIBaseURL, ac.ClashAPIToken, group.Now, ac.ApiLogFile)
+			if err != nil {
+				log.Printf("checkFallback: %s: selected proxy %s failed its test: %v", group.Name, group.Now, err)
+			} else {
+				latency[group.Now] = int(delay)
+			}
+		}
+		to, ok := fallbackCandidate(group.Now, group.Members, latency, ac.FallbackThresholdMs)
+		if !ok {
+			continue
+		}
+		if err := ac.SwitchProxyGroup(group.Name, to); err != nil {
+			log.Printf("checkFallback: Failed to switch %s to %s: %v", group.Name, to, err)
+			continue
+		}
+		log.Printf("checkFallback: %s switched from %s (%d ms) to %s (%d ms)",
+			group.Name, group.Now, latency[group.Now], to, latency[to])
+		ac.onSelectorChanged(group.Name, to)
+		ac.Events.Publish(ProxySwitched{Group: group.Name, From: group.Now, To: to, LatencyMs: latency[group.Now]})
+	}
+}
+
+// fallbackCandidate returns the member with the lowest latency if current is slower than
+// thresholdMs, or has no latency while other members were measured: checkFallback tests
+// current itself when sing-box has no measurement, so 0 means its test failed.
+// Members without a measurement are never chosen.
+func fallbackCandidate(current string, members []string, latency map[string]int, thresholdMs int) (string, bool) {
+	best, bestLatency := "", 0
+	for _, member := range members {
+		if l := latency[member]; member != current && l > 0 && (best == "" || l < bestLatency) {
+			best, bestLatency = member, l
+		}
+	}
+	if best == "" {
+		return "", false
+	}
+	currentLatency := latency[current]
+	if currentLatency == 0 {
+		return best, true
+	}
+	return best, currentLatency > thresholdMs && bestLatency < currentLatency
+}
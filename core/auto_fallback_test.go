@@ -0,0 +1,44 @@
+package core
+
+import "testing"
+
+func TestFallbackCandidate(t *testing.T) {
+	members := []string{"a", "b", "c"}
+	tests := []struct {
+		name    string
+		current string
+		latency map[string]int
+		want    string
+		wantOK  bool
+	}{
+		{"fast enough", "a", map[string]int{"a": 300, "b": 100, "c": 200}, "", false},
+		{"slow", "a", map[string]int{"a": 1500, "b": 400, "c": 200}, "c", true},
+		{"slow, others slower", "a", map[string]int{"a": 1500, "b": 2000}, "", false},
+		{"current failed", "a", map[string]int{"b": 400, "c": 200}, "c", true},
+		{"nothing measured", "a", map[string]int{}, "", false},
+		{"unmeasured members are not chosen", "a", map[string]int{"a": 1500, "b": 0, "c": 900}, "c", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := fallbackCandidate(tt.current, members, tt.latency, defaultFallbackThresholdMs)
+			if ok != tt.wantOK || (ok && got != tt.want) {
+				t.Errorf("fallbackCandidate() = %q, %v, want %q, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestAutoFallbackRace(t *testing.T) {
+	ac := &AppController{}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			ac.SetAutoFallback(i%2 == 0)
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		ac.IsAutoFallbackEnabled()
+	}
+	<-done
+}
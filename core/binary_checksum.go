@@ -0,0 +1,111 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"singbox-launcher/internal/constants"
+)
+
+// coreChecksum is the expected sha256 of the installed sing-box binary, saved after DownloadCore
+type coreChecksum struct {
+	Version string `json:"version"`
+	SHA256  string `json:"sha256"`
+}
+
+// getCoreChecksumPath returns the path to data/core_checksum.json
+func (ac *AppController) getCoreChecksumPath() string {
+	return filepath.Join(ac.ExecDir(), constants.DataDirName, constants.CoreChecksumName)
+}
+
+// fileSHA256 returns the hex-encoded sha256 of a file
+func fileSHA256(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// StoreExpectedChecksum remembers the sha256 of the sing-box binary installed for version
+func (ac *AppController) StoreExpectedChecksum(version, hexSum string) error {
+	data, err := json.MarshalIndent(coreChecksum{Version: version, SHA256: strings.ToLower(hexSum)}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("StoreExpectedChecksum: %w", err)
+	}
+	path := ac.getCoreChecksumPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("StoreExpectedChecksum: failed to create data directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("StoreExpectedChecksum: %w", err)
+	}
+	log.Printf("StoreExpectedChecksum: Saved checksum for sing-box v%s", version)
+	return nil
+}
+
+// loadExpectedChecksum returns the stored checksum, nil if none was saved
+func (ac *AppController) loadExpectedChecksum() (*coreChecksum, error) {
+	data, err := os.ReadFile(ac.getCoreChecksumPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var stored coreChecksum
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", constants.CoreChecksumName, err)
+	}
+	if stored.SHA256 == "" {
+		return nil, nil
+	}
+	return &stored, nil
+}
+
+// ExpectedChecksumVersion returns the sing-box version the stored checksum belongs to
+func (ac *AppController) ExpectedChecksumVersion() string {
+	stored, err := ac.loadExpectedChecksum()
+	if err != nil || stored == nil {
+		return ""
+	}
+	return stored.Version
+}
+
+// VerifyBinaryChecksum compares the sha256 of the sing-box binary with the one stored by DownloadCore.
+// Returns true if they match, no checksum was stored (binary installed manually)
+// or sing-box from PATH is used instead (see ExternalSingboxPath).
+func (ac *AppController) VerifyBinaryChecksum() (bool, error) {
+	if _, external := ac.ExternalSingboxPath(); external {
+		return true, nil
+	}
+	stored, err := ac.loadExpectedChecksum()
+	if err != nil {
+		return false, fmt.Errorf("VerifyBinaryChecksum: %w", err)
+	}
+	if stored == nil {
+		return true, nil
+	}
+	actual, err := fileSHA256(ac.SingboxPath)
+	if err != nil {
+		return false, fmt.Errorf("VerifyBinaryChecksum: %w", err)
+	}
+	if actual != stored.SHA256 {
+		log.Printf("VerifyBinaryChecksum: Mismatch for sing-box v%s: expected %s, got %s", stored.Version, stored.SHA256, actual)
+		return false, nil
+	}
+	return true, nil
+}
@@ -0,0 +1,101 @@
+package core
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"singbox-launcher/internal/platform"
+)
+
+// WithBinaryDir sets the folder downloaded binaries (sing-box, wintun.dll, rule-sets) are stored in
+// instead of the one saved in preferences or <ExecDir>/bin, e.g. from the --binary-dir flag
+func WithBinaryDir(dir string) ControllerOption {
+	return func(ac *AppController) { ac.BinaryDir = dir }
+}
+
+// ValidateBinaryDir checks that dir is an absolute path to a writable folder, creating it if needed
+func ValidateBinaryDir(dir string) error {
+	if !filepath.IsAbs(dir) {
+		return fmt.Errorf("binary directory must be an absolute path: %s", dir)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("binary directory is not accessible: %w", err)
+	}
+	if err := checkDirWritable(dir); err != nil {
+		return fmt.Errorf("binary directory is not writable: %w", err)
+	}
+	return nil
+}
+
+// checkDirWritable creates and removes a temporary file in dir
+func checkDirWritable(dir string) error {
+	probe, err := os.CreateTemp(dir, ".write-test-")
+	if err != nil {
+		return err
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+	return nil
+}
+
+// SetBinaryDir validates dir and saves it as the folder for downloaded binaries.
+// An empty dir restores the default (<ExecDir>/bin). Applied after the launcher restarts.
+func (ac *AppController) SetBinaryDir(dir string) error {
+	dir = strings.TrimSpace(dir)
+	if dir != "" {
+		if err := ValidateBinaryDir(dir); err != nil {
+			return fmt.Errorf("SetBinaryDir: %w", err)
+		}
+	}
+	if err := ac.UpdatePreferences(func(p *Preferences) { p.BinaryDir = dir }); err != nil {
+		return fmt.Errorf("SetBinaryDir: %w", err)
+	}
+	log.Printf("SetBinaryDir: Binary directory set to %q, applies after restart", dir)
+	return nil
+}
+
+// initBinaryDir sets BinaryDir (option, then preferences, then <ExecDir>/bin) and the binary
+// paths inside it, and checks that it is writable: downloads fail otherwise
+func (ac *AppController) initBinaryDir(prefs Preferences) {
+	ac.binaryDirOption = ac.BinaryDir
+	if ac.BinaryDir == "" {
+		ac.BinaryDir = prefs.BinaryDir
+	}
+	if ac.BinaryDir == "" {
+		ac.BinaryDir = platform.GetBinDir(ac.ExecDir())
+	}
+	singboxName, _ := platform.GetExecutableNames()
+	ac.SingboxPath = filepath.Join(ac.BinaryDir, singboxName)
+	if wintunPath := platform.GetWintunPath(ac.ExecDir()); wintunPath != "" { // Windows only
+		ac.WintunPath = filepath.Join(ac.BinaryDir, filepath.Base(wintunPath))
+	}
+
+	ac.binaryDirErr = ValidateBinaryDir(ac.BinaryDir)
+	if ac.binaryDirErr != nil {
+		log.Printf("NewAppController: %v", ac.binaryDirErr)
+	}
+}
+
+// resetBinaryDir drops the folder saved in preferences: BinaryDir goes back to the WithBinaryDir
+// option or <ExecDir>/bin. Only called by ResetToDefaults, while sing-box is stopped.
+func (ac *AppController) resetBinaryDir() {
+	ac.BinaryDir = ac.binaryDirOption
+	ac.initBinaryDir(Preferences{})
+}
+
+// BinaryDirError returns why BinaryDir is not writable (checked at startup), nil if it is
+func (ac *AppController) BinaryDirError() error {
+	return ac.binaryDirErr
+}
+
+// downloadTempDir creates a temporary folder for a download inside BinaryDir,
+// so the result can be moved into place with os.Rename
+func (ac *AppController) downloadTempDir() (string, error) {
+	if err := os.MkdirAll(ac.BinaryDir, 0755); err != nil {
+		return "", err
+	}
+	return os.MkdirTemp(ac.BinaryDir, ".download-")
+}
@@ -0,0 +1,147 @@
+package core
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"singbox-launcher/internal/constants"
+)
+
+// CustomCertFile describes a PEM file found in the certs/ folder
+type CustomCertFile struct {
+	Name  string
+	Path  string
+	Certs int   // Number of certificates in the file
+	Err   error // Non-nil if the file could not be read or contains no certificates
+}
+
+// CertsDir returns the folder for user-added CA certificates (<ExecDir>/certs)
+func (ac *AppController) CertsDir() string {
+	return filepath.Join(ac.ExecDir(), constants.CertsDirName)
+}
+
+// The certificate pool is shared by every HTTP client, see createHTTPClient.
+// It is built once from certsDir and rebuilt after ReloadCustomCACerts.
+var (
+	caPoolMutex sync.Mutex
+	certsDir    string         // set by NewAppController, see useCertsDir
+	caPool      *x509.CertPool // nil until first use or after ReloadCustomCACerts
+)
+
+// useCertsDir sets the folder the shared certificate pool is loaded from
+func useCertsDir(dir string) {
+	caPoolMutex.Lock()
+	defer caPoolMutex.Unlock()
+	certsDir = dir
+	caPool = nil
+}
+
+// ReloadCustomCACerts drops the cached certificate pool, so HTTP clients created
+// afterwards pick up files added to or removed from the certs/ folder
+func ReloadCustomCACerts() {
+	caPoolMutex.Lock()
+	defer caPoolMutex.Unlock()
+	caPool = nil
+}
+
+// customCACertPool returns the cached pool, loading it on first use
+func customCACertPool() *x509.CertPool {
+	caPoolMutex.Lock()
+	defer caPoolMutex.Unlock()
+	if caPool == nil {
+		pool, err := LoadSystemCACerts(certsDir)
+		if err != nil {
+			log.Printf("customCACertPool: Failed to load custom CA certificates: %v", err)
+		}
+		caPool = pool
+	}
+	return caPool
+}
+
+// ListCustomCACerts returns PEM files (*.pem, *.crt, *.cer) from the certs folder dir
+func ListCustomCACerts(dir string) ([]CustomCertFile, error) {
+	if dir == "" {
+		return nil, nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+
+	var files []CustomCertFile
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if entry.IsDir() || (ext != ".pem" && ext != ".crt" && ext != ".cer") {
+			continue
+		}
+		file := CustomCertFile{Name: entry.Name(), Path: filepath.Join(dir, entry.Name())}
+		data, err := os.ReadFile(file.Path)
+		if err != nil {
+			file.Err = err
+		} else {
+			file.Certs = countPEMCertificates(data)
+			if file.Certs == 0 {
+				file.Err = fmt.Errorf("no PEM certificates found")
+			}
+		}
+		files = append(files, file)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Name < files[j].Name })
+	return files, nil
+}
+
+// countPEMCertificates returns the number of valid CERTIFICATE blocks in data
+func countPEMCertificates(data []byte) int {
+	count := 0
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return count
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		if _, err := x509.ParseCertificate(block.Bytes); err == nil {
+			count++
+		}
+	}
+}
+
+// LoadSystemCACerts returns the system certificate pool extended with
+// CA certificates from the certs folder dir (for corporate setups with a custom CA)
+func LoadSystemCACerts(dir string) (*x509.CertPool, error) {
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		log.Printf("LoadSystemCACerts: System certificate pool unavailable, using empty pool: %v", err)
+		pool = x509.NewCertPool()
+	}
+
+	files, err := ListCustomCACerts(dir)
+	if err != nil {
+		return pool, err
+	}
+	for _, file := range files {
+		if file.Err != nil {
+			log.Printf("LoadSystemCACerts: Skipping %s: %v", file.Name, file.Err)
+			continue
+		}
+		data, err := os.ReadFile(file.Path)
+		if err != nil {
+			log.Printf("LoadSystemCACerts: Skipping %s: %v", file.Name, err)
+			continue
+		}
+		pool.AppendCertsFromPEM(data)
+	}
+	return pool, nil
+}
@@ -0,0 +1,100 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"singbox-launcher/api"
+)
+
+// clashAPIReachabilityTimeout - how long TestClashAPIReachability waits for the Clash API
+const clashAPIReachabilityTimeout = 2 * time.Second
+
+// clashAPIConfigCache holds experimental.clash_api of config.json read by GetClashAPIBaseURL
+type clashAPIConfigCache struct {
+	mutex   sync.Mutex
+	valid   bool
+	modTime time.Time // of config.json when it was read
+	size    int64
+	baseURL string
+	token   string
+	err     error
+}
+
+// GetClashAPIBaseURL returns the Clash API address from experimental.clash_api.external_controller
+// of config.json (e.g. "http://127.0.0.1:9090"). The result is cached until config.json changes.
+func (ac *AppController) GetClashAPIBaseURL() (string, error) {
+	baseURL, _, err := ac.loadClashAPIConfig()
+	if err != nil {
+		return "", fmt.Errorf("GetClashAPIBaseURL: %w", err)
+	}
+	return baseURL, nil
+}
+
+// loadClashAPIConfig returns the Clash API address and secret of config.json, cached like GetOutboundTags
+func (ac *AppController) loadClashAPIConfig() (baseURL, token string, err error) {
+	configPath := ac.ConfigPath()
+	info, err := os.Stat(configPath)
+	if err != nil {
+		ac.invalidateClashAPIConfig()
+		return "", "", fmt.Errorf("failed to read config.json: %w", err)
+	}
+
+	cache := &ac.clashAPIConfig
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	if !cache.valid || !cache.modTime.Equal(info.ModTime()) || cache.size != info.Size() {
+		cache.baseURL, cache.token, cache.err = api.LoadClashAPIConfig(configPath)
+		cache.modTime = info.ModTime()
+		cache.size = info.Size()
+		cache.valid = true
+	}
+	return cache.baseURL, cache.token, cache.err
+}
+
+// invalidateClashAPIConfig makes the next loadClashAPIConfig re-read config.json
+func (ac *AppController) invalidateClashAPIConfig() {
+	ac.clashAPIConfig.mutex.Lock()
+	ac.clashAPIConfig.valid = false
+	ac.clashAPIConfig.mutex.Unlock()
+}
+
+// reloadClashAPISettings sets ClashAPIBaseURL, ClashAPIToken and ClashAPIEnabled from config.json
+func (ac *AppController) reloadClashAPISettings(caller string) {
+	baseURL, token, err := ac.loadClashAPIConfig()
+	if err != nil {
+		log.Printf("%s: Clash API config error: %v", caller, err)
+		ac.ClashAPIBaseURL = ""
+		ac.SetClashAPISecret("")
+		ac.ClashAPIEnabled = false
+		return
+	}
+	ac.ClashAPIBaseURL = baseURL
+	ac.SetClashAPISecret(token)
+	ac.ClashAPIEnabled = true
+}
+
+// SetClashAPISecret sets the secret (experimental.clash_api.secret) sent as
+// "Authorization: Bearer <secret>" with every Clash API request; "" sends no Authorization header
+func (ac *AppController) SetClashAPISecret(secret string) {
+	ac.ClashAPIToken = secret
+}
+
+// TestClashAPIReachability checks that the Clash API answers GET /version within
+// clashAPIReachabilityTimeout. The error says whether the connection was refused, timed out or
+// the API answered with an unexpected status (e.g. 401 for a wrong secret).
+func (ac *AppController) TestClashAPIReachability(ctx context.Context) error {
+	if !ac.ClashAPIEnabled || ac.ClashAPIBaseURL == "" {
+		return fmt.Errorf("TestClashAPIReachability: Clash API is disabled")
+	}
+	ctx, cancel := context.WithTimeout(ctx, clashAPIReachabilityTimeout)
+	defer cancel()
+	if err := api.CheckVersion(ctx, ac.ClashAPIBaseURL, ac.ClashAPIToken); err != nil {
+		return fmt.Errorf("TestClashAPIReachability: %w", err)
+	}
+	return nil
+}
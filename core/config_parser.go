@@ -9,8 +9,8 @@ import (
 	"github.com/muhammadmuzzammil1998/jsonc"
 )
 
-// GetSelectorGroupsFromConfig extracts selector group names from config.json
-func GetSelectorGroupsFromConfig(configPath string) ([]string, string, error) {
+// cleanJSONC converts JSONC (with comments/trailing commas) into clean JSON
+func cleanJSONC(data []byte) []byte {
 	// Internal function to strip comments
 	stripComments := func(data []byte) []byte {
 		commentRegex := regexp.MustCompile(`(?m)\s+//.*$|/\*[\s\S]*?\*/`)
@@ -23,27 +23,89 @@ func GetSelectorGroupsFromConfig(configPath string) ([]string, string, error) {
 		return re.ReplaceAll(data, []byte("$1"))
 	}
 
+	cleanData := jsonc.ToJSON(data)
+	return removeTrailingCommas(stripComments(cleanData))
+}
+
+// loadConfigJSON reads config.json (JSONC with comments/trailing commas) into a generic map
+func loadConfigJSON(configPath string) (map[string]interface{}, error) {
 	data, err := os.ReadFile(configPath)
 	if err != nil {
-		return nil, "", fmt.Errorf("failed to read config.json: %w", err)
+		return nil, fmt.Errorf("failed to read config.json: %w", err)
 	}
 
-	// Convert JSONC (with comments/trailing commas) into clean JSON
-	cleanData := jsonc.ToJSON(data)
-	cleanData = removeTrailingCommas(stripComments(cleanData))
-
 	var jsonData map[string]interface{}
-	if err := json.Unmarshal(cleanData, &jsonData); err != nil {
-		return nil, "", fmt.Errorf("failed to parse JSON: %w", err)
+	if err := json.Unmarshal(cleanJSONC(data), &jsonData); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+	return jsonData, nil
+}
+
+// configOutbound is the tag and type of an outbound in config.json
+type configOutbound struct {
+	tag          string
+	outboundType string
+}
+
+// configOutbounds returns the outbounds in config.json with a unique tag, in config order
+func configOutbounds(jsonData map[string]interface{}) []configOutbound {
+	outbounds, _ := jsonData["outbounds"].([]interface{})
+
+	var result []configOutbound
+	seen := make(map[string]bool)
+	for _, outbound := range outbounds {
+		outboundMap, ok := outbound.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if tag, ok := outboundMap["tag"].(string); ok && !seen[tag] {
+			seen[tag] = true
+			outboundType, _ := outboundMap["type"].(string)
+			result = append(result, configOutbound{tag: tag, outboundType: outboundType})
+		}
+	}
+	return result
+}
+
+// filterOutboundTags returns the tags of outbounds, in order.
+// outboundType, if not empty, keeps only outbounds of that type.
+func filterOutboundTags(outbounds []configOutbound, outboundType string) []string {
+	var tags []string
+	for _, outbound := range outbounds {
+		if outboundType == "" || outbound.outboundType == outboundType {
+			tags = append(tags, outbound.tag)
+		}
+	}
+	return tags
+}
+
+// configSetsSystemProxy reports whether an inbound in config.json has "set_system_proxy": true
+func configSetsSystemProxy(configPath string) bool {
+	jsonData, err := loadConfigJSON(configPath)
+	if err != nil {
+		return false
+	}
+	inbounds, _ := jsonData["inbounds"].([]interface{})
+	for _, inbound := range inbounds {
+		if inboundMap, ok := inbound.(map[string]interface{}); ok && inboundMap["set_system_proxy"] == true {
+			return true
+		}
+	}
+	return false
+}
+
+// GetSelectorGroupsFromConfig extracts selector group names from config.json
+func GetSelectorGroupsFromConfig(configPath string) ([]string, string, error) {
+	jsonData, err := loadConfigJSON(configPath)
+	if err != nil {
+		return nil, "", err
 	}
 
 	// Extract selector groups from outbounds
-	outbounds, ok := jsonData["outbounds"].([]interface{})
-	if !ok {
+	if _, ok := jsonData["outbounds"].([]interface{}); !ok {
 		return []string{"proxy-out"}, "", nil
 	}
 
-	var selectorGroups []string
 	var defaultSelector string
 
 	// Get default from route.final
@@ -54,29 +116,7 @@ func GetSelectorGroupsFromConfig(configPath string) ([]string, string, error) {
 	}
 
 	// Find all selector type outbounds
-	for _, outbound := range outbounds {
-		outboundMap, ok := outbound.(map[string]interface{})
-		if !ok {
-			continue
-		}
-
-		outboundType, _ := outboundMap["type"].(string)
-		if outboundType == "selector" {
-			if tag, ok := outboundMap["tag"].(string); ok {
-				// Skip if already in list
-				found := false
-				for _, existing := range selectorGroups {
-					if existing == tag {
-						found = true
-						break
-					}
-				}
-				if !found {
-					selectorGroups = append(selectorGroups, tag)
-				}
-			}
-		}
-	}
+	selectorGroups := filterOutboundTags(configOutbounds(jsonData), "selector")
 
 	// If no selectors found, return default
 	if len(selectorGroups) == 0 {
@@ -0,0 +1,102 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/muhammadmuzzammil1998/jsonc"
+)
+
+// ConfigAudit describes what a generated config was built from, see AuditLog.LogConfigGeneration
+type ConfigAudit struct {
+	Sources         []string          // subscription sources
+	RuleAssignments map[string]string // selectable rule label -> outbound
+}
+
+// SaveConfig validates text (JSONC is allowed) and writes it to config.json. An existing
+// config.json is kept as a snapshot and renamed to config-old.json (config-old-N.json).
+// Returns the path written to. Performs file I/O, do not call it on the UI goroutine for large files.
+func (ac *AppController) SaveConfig(text string, audit ConfigAudit) (string, error) {
+	if ac.ReadOnly {
+		return "", fmt.Errorf("SaveConfig: %w", ErrReadOnly)
+	}
+	var testJSON interface{}
+	if err := json.Unmarshal(jsonc.ToJSON([]byte(text)), &testJSON); err != nil {
+		return "", fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	configPath := ac.ConfigPath()
+	if err := os.MkdirAll(filepath.Dir(configPath), 0o755); err != nil {
+		return "", err
+	}
+	if info, err := os.Stat(configPath); err == nil && !info.IsDir() {
+		// Create a save point so the previous config can be restored from the Tools tab
+		if err := ac.SaveSnapshot("Before Config Wizard save"); err != nil {
+			log.Printf("SaveConfig: Failed to create snapshot before save: %v", err)
+		}
+		if err := os.Rename(configPath, nextConfigBackupPath(configPath)); err != nil {
+			return "", err
+		}
+	} else if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	if err := os.WriteFile(configPath, []byte(text), 0o644); err != nil {
+		return "", err
+	}
+	if ac.AuditLog != nil {
+		ac.AuditLog.LogConfigGeneration(audit.Sources, audit.RuleAssignments, configPath)
+	}
+	if ac.UpdateConfigStatusFunc != nil {
+		ac.UpdateConfigStatusFunc()
+	}
+	if ac.IsFirstRun() {
+		if err := ac.MarkFirstRunComplete(); err != nil {
+			log.Printf("SaveConfig: %v", err)
+		}
+	}
+	return configPath, nil
+}
+
+// AutoGenerateConfigIfMissing saves the config returned by build when config.json does not exist yet,
+// e.g. after the first successful subscription parse, so new users can start sing-box right away.
+// build is only called when the config is missing. Reports whether a config was written;
+// nothing is written in read-only mode. Must not be called on the UI goroutine.
+func (ac *AppController) AutoGenerateConfigIfMissing(build func() (string, ConfigAudit, error)) (bool, error) {
+	if ac.ReadOnly {
+		return false, nil
+	}
+	if _, err := os.Stat(ac.ConfigPath()); !os.IsNotExist(err) {
+		return false, nil
+	}
+	text, audit, err := build()
+	if err != nil {
+		return false, fmt.Errorf("failed to generate config: %w", err)
+	}
+	path, err := ac.SaveConfig(text, audit)
+	if err != nil {
+		return false, fmt.Errorf("failed to save generated config: %w", err)
+	}
+	log.Printf("AutoGenerateConfigIfMissing: config.json was missing, generated %s from subscription", path)
+	return true, nil
+}
+
+// nextConfigBackupPath returns the first free config-old.json, config-old-1.json, ... next to path
+func nextConfigBackupPath(path string) string {
+	dir := filepath.Dir(path)
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(filepath.Base(path), ext)
+	candidate := filepath.Join(dir, fmt.Sprintf("%s-old%s", base, ext))
+	if _, err := os.Stat(candidate); os.IsNotExist(err) {
+		return candidate
+	}
+	for i := 1; ; i++ {
+		candidate = filepath.Join(dir, fmt.Sprintf("%s-old-%d%s", base, i, ext))
+		if _, err := os.Stat(candidate); os.IsNotExist(err) {
+			return candidate
+		}
+	}
+}
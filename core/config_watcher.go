@@ -0,0 +1,102 @@
+package core
+
+import (
+	"crypto/sha256"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// configChangeDebounce merges the bursts of events editors produce when saving a file
+const configChangeDebounce = 300 * time.Millisecond
+
+// invalidateConfigCaches drops the data cached from config.json when it changes on disk.
+// Subscribed first in NewAppController, so it runs before the UI subscribers.
+func (ac *AppController) invalidateConfigCaches(event Event) {
+	if event == ConfigChanged {
+		ac.invalidateOutboundTags()
+		ac.invalidateClashAPIConfig()
+	}
+}
+
+// StartConfigWatcher watches config.json for changes and publishes ConfigChanged.
+// The folder is watched rather than the file, because many editors save by replacing the file.
+// The watcher is closed when the application exits.
+func (ac *AppController) StartConfigWatcher() {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		log.Printf("StartConfigWatcher: %v", err)
+		return
+	}
+	configPath := ac.ConfigPath()
+	if err := os.MkdirAll(filepath.Dir(configPath), 0755); err != nil {
+		log.Printf("StartConfigWatcher: %v", err)
+	}
+	if err := watcher.Add(filepath.Dir(configPath)); err != nil {
+		log.Printf("StartConfigWatcher: Failed to watch %s: %v", filepath.Dir(configPath), err)
+		watcher.Close()
+		return
+	}
+
+	ac.GoBackground(func() {
+		defer watcher.Close()
+		log.Printf("StartConfigWatcher: Watching %s", configPath)
+
+		lastHash := configFileHash(configPath)
+		var timer *time.Timer
+		var fired <-chan time.Time
+		for {
+			select {
+			case <-ac.ShuttingDown():
+				if timer != nil {
+					timer.Stop()
+				}
+				log.Println("StartConfigWatcher: Stopped")
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if filepath.Base(event.Name) != filepath.Base(configPath) {
+					continue
+				}
+				if !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) && !event.Has(fsnotify.Rename) && !event.Has(fsnotify.Remove) {
+					continue
+				}
+				if timer == nil {
+					timer = time.NewTimer(configChangeDebounce)
+				} else {
+					timer.Reset(configChangeDebounce)
+				}
+				fired = timer.C
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				log.Printf("StartConfigWatcher: %v", err)
+			case <-fired:
+				fired = nil
+				// Touching the file or rewriting the same contents is not a change
+				hash := configFileHash(configPath)
+				if hash == lastHash {
+					continue
+				}
+				lastHash = hash
+				log.Printf("StartConfigWatcher: %s changed on disk", filepath.Base(configPath))
+				ac.Events.Publish(ConfigChanged)
+			}
+		}
+	})
+}
+
+// configFileHash returns the SHA-256 of the file contents, zero if it can't be read
+func configFileHash(path string) [sha256.Size]byte {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return [sha256.Size]byte{}
+	}
+	return sha256.Sum256(data)
+}
@@ -0,0 +1,172 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"singbox-launcher/api"
+)
+
+// Endpoints used by TestConnectivity
+const (
+	connectivityDNSHost   = "github.com"
+	connectivityHTTPURL   = "http://www.gstatic.com/generate_204"
+	connectivityGitHubURL = "https://api.github.com"
+)
+
+// ConnectivityReport is the result of TestConnectivity
+type ConnectivityReport struct {
+	DNSWorks              bool
+	HTTPWorks             bool
+	GitHubReachable       bool
+	SubscriptionReachable map[string]bool // keyed by subscription name (see ProxySource.DisplayName)
+	SingboxAPIReachable   bool
+	SingboxAPIChecked     bool              // false if sing-box is not running or Clash API is disabled
+	Errors                map[string]string // why a check failed, keyed like the lines of String()
+}
+
+// String formats the report as a ✅/❌ checklist that can be pasted into a support request
+func (r ConnectivityReport) String() string {
+	var b strings.Builder
+	line := func(ok bool, name string) {
+		mark := "✅"
+		if !ok {
+			mark = "❌"
+		}
+		b.WriteString(mark + " " + name)
+		if err := r.Errors[name]; err != "" {
+			b.WriteString(" — " + err)
+		}
+		b.WriteString("\n")
+	}
+	line(r.DNSWorks, "DNS")
+	line(r.HTTPWorks, "HTTP")
+	line(r.GitHubReachable, "GitHub")
+	names := make([]string, 0, len(r.SubscriptionReachable))
+	for name := range r.SubscriptionReachable {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		line(r.SubscriptionReachable[name], "Subscription "+name)
+	}
+	if r.SingboxAPIChecked {
+		line(r.SingboxAPIReachable, "sing-box API")
+	} else {
+		b.WriteString("➖ sing-box API — not running or Clash API disabled\n")
+	}
+	return b.String()
+}
+
+// TestConnectivity runs the DNS, HTTP, GitHub, subscription and Clash API checks in parallel.
+// This is the first thing to run when something does not work: the report says which part of the
+// chain is broken.
+func (ac *AppController) TestConnectivity(ctx context.Context) ConnectivityReport {
+	ctx, cancel := context.WithTimeout(ctx, NetworkRequestTimeout)
+	defer cancel()
+
+	report := ConnectivityReport{
+		SubscriptionReachable: make(map[string]bool),
+		Errors:                make(map[string]string),
+	}
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	check := func(name string, fn func() error, result func(ok bool)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := fn()
+			mutex.Lock()
+			defer mutex.Unlock()
+			result(err == nil)
+			if err != nil {
+				report.Errors[name] = err.Error()
+				log.Printf("TestConnectivity: %s: %v", name, err)
+			}
+		}()
+	}
+
+	check("DNS", func() error {
+		_, err := net.DefaultResolver.LookupHost(ctx, connectivityDNSHost)
+		return err
+	}, func(ok bool) { report.DNSWorks = ok })
+	check("HTTP", func() error {
+		return checkHTTPReachable(ctx, connectivityHTTPURL, FetchOptions{})
+	}, func(ok bool) { report.HTTPWorks = ok })
+	check("GitHub", func() error {
+		return checkHTTPReachable(ctx, connectivityGitHubURL, FetchOptions{})
+	}, func(ok bool) { report.GitHubReachable = ok })
+
+	if parserConfig, err := ExtractParcerConfig(ac.ConfigPath()); err == nil {
+		seen := make(map[string]int)
+		for _, source := range parserConfig.ParserConfig.Proxies {
+			source := source
+			// Names are shown instead of URLs, which often contain access tokens
+			name := source.DisplayName()
+			seen[name]++
+			if seen[name] > 1 {
+				name = fmt.Sprintf("%s #%d", name, seen[name])
+			}
+			lineName := "Subscription " + name
+			check(lineName, func() error {
+				opts, err := source.FetchOptions()
+				if err != nil {
+					return err
+				}
+				if IsFileURL(source.Source) || IsDataURI(source.Source) {
+					opts.LocalDirs = ac.LocalSubscriptionDirs()
+					_, err := FetchSubscriptionWithOptions(source.Source, opts)
+					return err
+				}
+				return checkHTTPReachable(ctx, source.Source, opts)
+			}, func(ok bool) { report.SubscriptionReachable[name] = ok })
+		}
+	} else {
+		log.Printf("TestConnectivity: No subscriptions to check: %v", err)
+	}
+
+	if ac.RunningState.IsRunning() && ac.ClashAPIEnabled {
+		report.SingboxAPIChecked = true
+		check("sing-box API", func() error {
+			return api.TestAPIConnection(ac.ClashAPIBaseURL, ac.ClashAPIToken, ac.ApiLogFile)
+		}, func(ok bool) { report.SingboxAPIReachable = ok })
+	}
+
+	wg.Wait()
+	return report
+}
+
+// checkHTTPReachable sends a GET request and expects a successful (2xx) response.
+// The body is not read.
+func checkHTTPReachable(ctx context.Context, rawURL string, opts FetchOptions) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", rawURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", UserAgent())
+	if opts.Authorization != "" {
+		req.Header.Set("Authorization", opts.Authorization)
+	}
+	for name, value := range opts.Headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := createHTTPClient(NetworkRequestTimeout).Do(req)
+	if err != nil {
+		if IsNetworkError(err) {
+			return fmt.Errorf("network error: %s", GetNetworkErrorMessage(err))
+		}
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("server returned status %d", resp.StatusCode)
+	}
+	return nil
+}
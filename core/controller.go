@@ -1,6 +1,7 @@
 package core
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -10,7 +11,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
-	"syscall"
+	"sync/atomic"
 	"time"
 
 	"fyne.io/fyne/v2"
@@ -38,6 +39,9 @@ const (
 	restartDelay            = 2 * time.Second
 	stabilityThreshold      = 180 * time.Second
 	gracefulShutdownTimeout = 2 * time.Second
+	defaultStopTimeout      = 10 * time.Second // time sing-box is given to exit after the stop signal
+	defaultVersionCacheTTL  = 10 * time.Minute // how long GetCoreVersionInfo reuses its result
+	startupReadyTimeout     = 30 * time.Second // how long auto-start waits for the startup sequence
 	maxLogFileSize          = 10 * 1024 * 1024 // 10 MB - maximum log file size before rotation
 )
 
@@ -69,37 +73,107 @@ type AppController struct {
 	ParserRunning            bool
 	StoppedByUser            bool
 	ConsecutiveCrashAttempts int
-	APIStateMutex            sync.RWMutex // Mutex for API-related fields (ProxiesList, ActiveProxyName, SelectedIndex)
+	StartupFailed            bool          // sing-box exited right after the last start, see handleStartupCrash
+	singboxStartedAt         time.Time     // when the current sing-box process was started
+	singboxLogOffset         int64         // size of sing-box.log when it was started
+	APIStateMutex            sync.RWMutex  // Mutex for API-related fields (ProxiesList, ActiveProxyName, SelectedIndex)
+	StopTimeout              time.Duration // Grace period before sing-box is killed on stop
+	VersionCacheTTL          time.Duration // How long GetCoreVersionInfo results are cached
+	versionInfo              *CoreVersionInfo
+	versionInfoMutex         sync.Mutex
+	singboxExited            chan struct{} // Closed when the current sing-box process exits
+	externalPID              int           // sing-box running but not started by the launcher, see adoptExternalSingBox
+	uiUpdateRequests         chan UIUpdateRequest
+
+	// --- Auto Fallback ---
+	// autoFallback switches selector groups away from a proxy slower than FallbackThresholdMs,
+	// checked every FallbackHealthCheckInterval, see StartAutoFallback. Set from Preferences.AutoFallback,
+	// read and changed with IsAutoFallbackEnabled/SetAutoFallback.
+	autoFallback                atomic.Bool
+	FallbackHealthCheckInterval time.Duration
+	FallbackThresholdMs         int
+
+	// --- Shutdown ---
+	exitOnce        sync.Once
+	shutdown        chan struct{}  // Closed when GracefulExit starts
+	backgroundTasks sync.WaitGroup // Goroutines started with GoBackground
+	shutdownHooks   []func()
+	shutdownMutex   sync.Mutex
+
+	// --- config.json watcher (see StartConfigWatcher) ---
+	outboundTags   outboundTagsCache   // see GetOutboundTags
+	clashAPIConfig clashAPIConfigCache // see GetClashAPIBaseURL
+
+	// --- Startup ---
+	startupTasks sync.WaitGroup // Goroutines started with GoStartup
+	startupOnce  sync.Once
+	firstRun     firstRunState // see IsFirstRun
+	ready        chan struct{} // Closed once all startup tasks have finished, see Ready()
 
 	// --- File Paths ---
-	ExecDir     string
-	ConfigPath  string
+	execDir     string // see ExecDir()
+	configPath  string // see ConfigPath()
 	SingboxPath string
 	ParserPath  string
 	WintunPath  string
+	// BinaryDir holds downloaded binaries: sing-box, wintun.dll, rule-sets. Default <ExecDir>/bin,
+	// set by WithBinaryDir (--binary-dir) or in Settings (SetBinaryDir, applies after restart)
+	BinaryDir       string
+	binaryDirOption string // set by WithBinaryDir, see resetBinaryDir
+	binaryDirErr    error  // see BinaryDirError
+	// ReadOnly disables configuration changes (wizard Save, config updates, downloads) for shared
+	// workstations. Set by WithReadOnly (--read-only) or when config.json is not writable.
+	ReadOnly bool
 
 	// --- VPN Operation State ---
-	RunningState *RunningState
+	RunningState RunningStateIface
+	Events       EventBus      // CoreStarted/CoreStopped (published by RunningState), downloads, ...
+	downloads    downloadState // see IsDownloadInProgress
+	deviceID     deviceIDState // see GetDeviceID
+
+	// EnvironmentVars are added to the environment of sing-box (e.g. SING_BOX_LOG_LEVEL=debug).
+	// Loaded from preferences, change with SetEnvironmentVars.
+	EnvironmentVars map[string]string
+	envMutex        sync.Mutex
+	// SingboxWorkDir is the working directory of sing-box: relative paths in config.json
+	// (e.g. rule sets) resolve against it. Default BinaryDir, change with SetSingboxWorkDir.
+	// Guarded by workDirMutex, read it with GetSingboxWorkDir.
+	SingboxWorkDir string
+	workDirMutex   sync.Mutex
 
 	// --- Logging ---
 	MainLogFile  *os.File
 	ChildLogFile *os.File
 	ApiLogFile   *os.File
+	// AuditLog records how config.json was generated (data/audit.log), see LogConfigGeneration
+	AuditLog *AuditLog
 
 	// --- Clash API configuration ---
 	ClashAPIBaseURL    string
 	ClashAPIToken      string
 	ClashAPIEnabled    bool
 	SelectedClashGroup string
-	AutoLoadInProgress bool       // Flag to prevent multiple auto-load attempts
-	AutoLoadMutex      sync.Mutex // Mutex for AutoLoadInProgress
+	AutoLoadInProgress bool             // Flag to prevent multiple auto-load attempts
+	AutoLoadMutex      sync.Mutex       // Mutex for AutoLoadInProgress
+	embeddedUI         embeddedUIServer // see StartEmbeddedUI
 
 	// --- Callbacks for UI logic ---
 	RefreshAPIFunc         func()
 	ResetAPIStateFunc      func()
-	UpdateCoreStatusFunc   func() // Callback to update status in Core Dashboard
-	UpdateConfigStatusFunc func() // Callback to update config status in Core Dashboard
-	UpdateTrayMenuFunc     func() // Callback to update tray menu
+	UpdateCoreStatusFunc   func()               // Callback to update status in Core Dashboard
+	UpdateConfigStatusFunc func()               // Callback to update config status in Core Dashboard
+	UpdateTrayMenuFunc     func()               // Callback to update tray menu
+	UpdateSnapshotsFunc    func()               // Callback to refresh the snapshots list in Tools tab
+	SaveUIStateFunc        func()               // Callback to persist UI state (window geometry) before exit
+	RedownloadCoreFunc     func(version string) // Callback to start sing-box download in Core Dashboard
+	// ConfirmProxyChangesFunc asks the user to apply refreshed subscription proxies (blocks until answered)
+	ConfirmProxyChangesFunc func(diff ProxyListDiff) bool
+
+	// --- sing-box download hooks (run synchronously in the download goroutine) ---
+	// PreDownloadHook runs before a download starts; an error cancels the download
+	PreDownloadHook func(version string) error
+	// PostDownloadHook runs after the new binary is installed and its checksum verified
+	PostDownloadHook func(version, path string) error
 
 	// --- Parser progress UI ---
 	ParserProgressBar        *widget.ProgressBar
@@ -107,11 +181,27 @@ type AppController struct {
 	UpdateParserProgressFunc func(progress float64, status string) // Callback to update parser progress
 }
 
+// RunningStateIface is the VPN's running state as seen by the controller and the UI.
+// Tests can replace AppController.RunningState with an implementation that changes state on demand.
+type RunningStateIface interface {
+	IsRunning() bool
+	SetRunning(value bool)
+	Wait() // blocks until the next state transition
+}
+
 // RunningState - structure for tracking the VPN's running state.
 type RunningState struct {
 	running bool
 	sync.Mutex
+	changed    chan struct{} // closed and replaced on every transition, see Wait
 	controller *AppController
+	pending    []bool // transitions not yet published, see publishTransitions
+	publishing bool   // publishTransitions is running
+}
+
+// NewRunningState creates the running state of ac (not running)
+func NewRunningState(ac *AppController) *RunningState {
+	return &RunningState{controller: ac, changed: make(chan struct{})}
 }
 
 // checkAndRotateLogFile checks log file size and rotates if it exceeds maxLogFileSize
@@ -142,36 +232,66 @@ func openLogFileWithRotation(logPath string) (*os.File, error) {
 	return os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
 }
 
+// ControllerOption overrides a default of NewAppController
+type ControllerOption func(*AppController)
+
+// WithExecDir sets the launcher directory instead of the directory of the executable
+func WithExecDir(dir string) ControllerOption {
+	return func(ac *AppController) { ac.execDir = dir }
+}
+
+// WithConfigPath sets the sing-box config path instead of <ExecDir>/bin/config.json
+func WithConfigPath(path string) ControllerOption {
+	return func(ac *AppController) { ac.configPath = path }
+}
+
+// ExecDir returns the launcher directory (bin/, logs/, data/ ... are located in it)
+func (ac *AppController) ExecDir() string {
+	return ac.execDir
+}
+
+// ConfigPath returns the path to the sing-box config.json
+func (ac *AppController) ConfigPath() string {
+	return ac.configPath
+}
+
 // NewAppController creates and initializes a new AppController instance.
-func NewAppController(appIconData, greyIconData, greenIconData, redIconData []byte) (*AppController, error) {
+func NewAppController(appIconData, greyIconData, greenIconData, redIconData []byte, opts ...ControllerOption) (*AppController, error) {
 	ac := &AppController{}
+	for _, opt := range opts {
+		opt(ac)
+	}
 
-	ex, err := os.Executable()
-	if err != nil {
-		return nil, fmt.Errorf("NewAppController: cannot determine executable path: %w", err)
+	if ac.execDir == "" {
+		ex, err := os.Executable()
+		if err != nil {
+			return nil, fmt.Errorf("NewAppController: cannot determine executable path: %w", err)
+		}
+		ac.execDir = filepath.Dir(ex)
 	}
-	ac.ExecDir = filepath.Dir(ex)
 
 	// Use platform-specific functions
-	if err := platform.EnsureDirectories(ac.ExecDir); err != nil {
+	if err := platform.EnsureDirectories(ac.ExecDir()); err != nil {
 		return nil, fmt.Errorf("NewAppController: cannot create directories: %w", err)
 	}
 
-	ac.ConfigPath = platform.GetConfigPath(ac.ExecDir)
-	singboxName, parserName := platform.GetExecutableNames()
-	ac.SingboxPath = filepath.Join(ac.ExecDir, "bin", singboxName)
-	ac.ParserPath = filepath.Join(ac.ExecDir, "bin", parserName)
-	ac.WintunPath = platform.GetWintunPath(ac.ExecDir)
+	if ac.configPath == "" {
+		ac.configPath = platform.GetConfigPath(ac.ExecDir())
+	}
+	useCertsDir(ac.CertsDir())
+	ac.initReadOnly()
+	_, parserName := platform.GetExecutableNames()
+	ac.ParserPath = filepath.Join(ac.ExecDir(), "bin", parserName)
 
 	// Open log files with rotation support
-	logFile, err := openLogFileWithRotation(filepath.Join(ac.ExecDir, logFileName))
+	logFile, err := openLogFileWithRotation(filepath.Join(ac.ExecDir(), logFileName))
 	if err != nil {
 		return nil, fmt.Errorf("NewAppController: cannot open main log file: %w", err)
 	}
 	log.SetOutput(logFile)
 	ac.MainLogFile = logFile
 
-	childLogFile, err := openLogFileWithRotation(filepath.Join(ac.ExecDir, childLogFileName))
+	childLogFile, err := openLogFileWithRotation(filepath.Join(ac.ExecDir(), childLogFileName))
 	if err != nil {
 		log.Printf("NewAppController: failed to open sing-box child log file: %v", err)
 		ac.ChildLogFile = nil
@@ -179,13 +299,14 @@ func NewAppController(appIconData, greyIconData, greenIconData, redIconData []by
 		ac.ChildLogFile = childLogFile
 	}
 
-	apiLogFile, err := openLogFileWithRotation(filepath.Join(ac.ExecDir, apiLogFileName))
+	apiLogFile, err := openLogFileWithRotation(filepath.Join(ac.ExecDir(), apiLogFileName))
 	if err != nil {
 		log.Printf("NewAppController: failed to open API log file: %v", err)
 		ac.ApiLogFile = nil
 	} else {
 		ac.ApiLogFile = apiLogFile
 	}
+	ac.AuditLog = NewAuditLog(filepath.Join(ac.ExecDir(), constants.DataDirName, constants.AuditLogName))
 
 	ac.AppIconData = fyne.NewStaticResource("appIcon", appIconData)
 	ac.GreyIconData = fyne.NewStaticResource("trayIcon", greyIconData)
@@ -195,24 +316,37 @@ func NewAppController(appIconData, greyIconData, greenIconData, redIconData []by
 	log.Println("Application initializing...")
 	ac.Application = app.NewWithID("com.singbox.launcher")
 	ac.Application.SetIcon(ac.AppIconData)
-	ac.RunningState = &RunningState{controller: ac}
-	ac.RunningState.Set(false) // Use Set() method instead of direct assignment
+	ac.RunningState = NewRunningState(ac)
+	ac.Events.Subscribe(ac.invalidateConfigCaches)
+	ac.Events.Subscribe(ac.animateTrayDuringDownload)
+	ac.RunningState.SetRunning(false) // Use SetRunning() method instead of direct assignment
 	ac.ConsecutiveCrashAttempts = 0
-
-	if base, tok, err := api.LoadClashAPIConfig(ac.ConfigPath); err != nil {
-		log.Printf("NewAppController: Clash API config error: %v", err)
-		ac.ClashAPIBaseURL = ""
-		ac.ClashAPIToken = ""
-		ac.ClashAPIEnabled = false
-	} else {
-		ac.ClashAPIBaseURL = base
-		ac.ClashAPIToken = tok
-		ac.ClashAPIEnabled = true
+	ac.StopTimeout = defaultStopTimeout
+	ac.VersionCacheTTL = defaultVersionCacheTTL
+	ac.FallbackHealthCheckInterval = defaultFallbackHealthCheckInterval
+	ac.FallbackThresholdMs = defaultFallbackThresholdMs
+	// Check before anything (window geometry, settings) creates preferences.json
+	if ac.IsFirstRun() {
+		log.Println("NewAppController: First run")
+	}
+	prefs := ac.LoadPreferences()
+	ac.EnvironmentVars = prefs.EnvironmentVars
+	ac.SetAutoFallback(prefs.AutoFallback)
+	ac.initBinaryDir(prefs)
+	ac.SingboxWorkDir = ac.BinaryDir
+	if prefs.SingboxWorkDir != "" {
+		if err := ValidateSingboxWorkDir(prefs.SingboxWorkDir); err != nil {
+			log.Printf("NewAppController: Ignoring sing-box working directory from preferences: %v", err)
+		} else {
+			ac.SingboxWorkDir = prefs.SingboxWorkDir
+		}
 	}
 
+	ac.reloadClashAPISettings("NewAppController")
+
 	// Initialize SelectedClashGroup from config (needed for auto-loading proxies)
 	if ac.ClashAPIEnabled {
-		_, defaultSelector, err := GetSelectorGroupsFromConfig(ac.ConfigPath)
+		_, defaultSelector, err := GetSelectorGroupsFromConfig(ac.ConfigPath())
 		if err != nil {
 			log.Printf("NewAppController: Failed to get selector groups: %v", err)
 			ac.SelectedClashGroup = "proxy-out" // Default fallback
@@ -232,88 +366,217 @@ func NewAppController(appIconData, greyIconData, greenIconData, redIconData []by
 	ac.UpdateCoreStatusFunc = func() { log.Println("UpdateCoreStatusFunc handler is not set yet.") }
 	ac.UpdateConfigStatusFunc = func() { log.Println("UpdateConfigStatusFunc handler is not set yet.") }
 	ac.UpdateTrayMenuFunc = func() { log.Println("UpdateTrayMenuFunc handler is not set yet.") }
+	ac.UpdateSnapshotsFunc = func() { log.Println("UpdateSnapshotsFunc handler is not set yet.") }
 	ac.UpdateParserProgressFunc = func(progress float64, status string) {
 		log.Printf("UpdateParserProgressFunc handler is not set yet. Progress: %.0f%%, Status: %s", progress, status)
 	}
 
+	ac.uiUpdateRequests = make(chan UIUpdateRequest, 1)
+	go ac.runUIUpdateLoop()
+	ac.shutdown = make(chan struct{})
+	ac.ready = make(chan struct{})
+
 	return ac, nil
 }
 
-// UpdateUI updates all UI elements based on the current application state.
-func (ac *AppController) UpdateUI() {
-	fyne.Do(func() {
-		// Update tray icon (this is a system function, not a UI widget)
-		if desk, ok := ac.Application.(desktop.App); ok {
-			// Check that icons are initialized
-			if ac.GreenIconData == nil || ac.GreyIconData == nil || ac.RedIconData == nil {
-				log.Printf("UpdateUI: Icons not initialized, skipping icon update")
-				return
-			}
+// UIUpdateRequest asks the UI update loop to refresh the tray icon, tray menu and Core Dashboard status
+type UIUpdateRequest struct{}
 
-			var iconToSet fyne.Resource
+// UpdateUI requests an update of all UI elements based on the current application state.
+// Requests are coalesced: while one is pending, further calls are dropped, so callers that
+// fire many times per second (e.g. download progress) do not flood the Fyne event queue.
+func (ac *AppController) UpdateUI() {
+	select {
+	case ac.uiUpdateRequests <- UIUpdateRequest{}:
+	default:
+		// An update is already pending and will pick up the current state
+	}
+}
 
-			if ac.RunningState.IsRunning() {
-				// Green icon - if running
-				iconToSet = ac.GreenIconData
-			} else {
-				// Check for binary to determine error state (simple file check)
-				if _, err := os.Stat(ac.SingboxPath); os.IsNotExist(err) {
-					// Red icon - on error (binary not found)
-					iconToSet = ac.RedIconData
-				} else {
-					// Grey icon - on normal stop
-					iconToSet = ac.GreyIconData
-				}
-			}
+// runUIUpdateLoop drains uiUpdateRequests, running one update at a time on the Fyne main thread
+func (ac *AppController) runUIUpdateLoop() {
+	for range ac.uiUpdateRequests {
+		done := make(chan struct{})
+		fyne.Do(func() {
+			defer close(done)
+			ac.applyUIUpdate()
+		})
+		<-done
+	}
+}
 
-			desk.SetSystemTrayIcon(iconToSet)
+// applyUIUpdate updates the tray icon, tray menu and Core Dashboard status. Must run on the main thread.
+func (ac *AppController) applyUIUpdate() {
+	// Update tray icon (this is a system function, not a UI widget)
+	if desk, ok := ac.Application.(desktop.App); ok {
+		// Check that icons are initialized
+		if ac.GreenIconData == nil || ac.GreyIconData == nil || ac.RedIconData == nil {
+			log.Printf("UpdateUI: Icons not initialized, skipping icon update")
+			return
 		}
 
-		// Если состояние Down, сбрасываем API состояние
-		if !ac.RunningState.IsRunning() && ac.ResetAPIStateFunc != nil {
-			log.Println("UpdateUI: Triggering API state reset because state is 'Down'.")
-			ac.ResetAPIStateFunc()
-		}
+		desk.SetSystemTrayIcon(ac.trayStateIcon())
+	}
 
-		// Update tray menu when state changes (same as Core Dashboard)
-		if ac.UpdateTrayMenuFunc != nil {
-			ac.UpdateTrayMenuFunc()
-		}
+	// Если состояние Down, сбрасываем API состояние
+	if !ac.RunningState.IsRunning() && ac.ResetAPIStateFunc != nil {
+		log.Println("UpdateUI: Triggering API state reset because state is 'Down'.")
+		ac.ResetAPIStateFunc()
+	}
 
-		// Update Core Dashboard status when state changes (synchronize with tray)
-		if ac.UpdateCoreStatusFunc != nil {
-			ac.UpdateCoreStatusFunc()
-		}
+	// Update tray menu when state changes (same as Core Dashboard)
+	if ac.UpdateTrayMenuFunc != nil {
+		ac.UpdateTrayMenuFunc()
+	}
+
+	// Update Core Dashboard status when state changes (synchronize with tray)
+	if ac.UpdateCoreStatusFunc != nil {
+		ac.UpdateCoreStatusFunc()
+	}
+}
+
+// trayStateIcon returns the tray icon for the current state of sing-box
+func (ac *AppController) trayStateIcon() fyne.Resource {
+	// Check for binary to determine error state (simple file check)
+	_, err := ac.FindSingboxBinary(true)
+	return ac.trayStateIconFor(err == nil)
+}
+
+// trayStateIconFor is trayStateIcon with the result of the binary check already known
+func (ac *AppController) trayStateIconFor(binaryFound bool) fyne.Resource {
+	if ac.RunningState.IsRunning() {
+		// Green icon - if running
+		return ac.GreenIconData
+	}
+	if !binaryFound {
+		// Red icon - on error (binary not found)
+		return ac.RedIconData
+	}
+	// Grey icon - on normal stop
+	return ac.GreyIconData
+}
+
+// GoStartup runs f in a goroutine that is part of the startup sequence (binary check, subscription load).
+// Ready() is closed once all of them have finished. Must be called before FinishStartup.
+func (ac *AppController) GoStartup(f func()) {
+	ac.startupTasks.Add(1)
+	go func() {
+		defer ac.startupTasks.Done()
+		f()
+	}()
+}
+
+// FinishStartup marks the end of the startup sequence: Ready() is closed as soon as
+// the tasks started with GoStartup have finished. Only the first call has an effect.
+func (ac *AppController) FinishStartup() {
+	ac.startupOnce.Do(func() {
+		go func() {
+			ac.startupTasks.Wait()
+			log.Println("FinishStartup: Startup sequence completed")
+			close(ac.ready)
+		}()
 	})
 }
 
+// Ready returns a channel that is closed once the startup sequence has finished
+func (ac *AppController) Ready() <-chan struct{} {
+	return ac.ready
+}
+
+// ReadyWithTimeout waits up to d for the startup sequence to finish and reports whether it did
+func (ac *AppController) ReadyWithTimeout(d time.Duration) bool {
+	select {
+	case <-ac.ready:
+		return true
+	default:
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ac.ready:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
+
+// exitCleanupTimeout is the time GracefulExit gives the steps after stopping sing-box (system
+// proxy, DNS cache within dnsFlushTimeout, background tasks). The whole shutdown is bounded by
+// the stop timeout plus this, after that the process exits anyway.
+const exitCleanupTimeout = 5 * time.Second
+
+// ShuttingDown returns a channel that is closed when the application starts exiting
+func (ac *AppController) ShuttingDown() <-chan struct{} {
+	return ac.shutdown
+}
+
+// GoBackground runs f in a goroutine that GracefulExit waits for.
+// f must return soon after ShuttingDown() is closed.
+func (ac *AppController) GoBackground(f func()) {
+	select {
+	case <-ac.shutdown:
+		return // Exiting, do not start new work
+	default:
+	}
+	ac.backgroundTasks.Add(1)
+	go func() {
+		defer ac.backgroundTasks.Done()
+		f()
+	}()
+}
+
+// OnShutdown registers f to be called by GracefulExit before it waits for background goroutines
+func (ac *AppController) OnShutdown(f func()) {
+	ac.shutdownMutex.Lock()
+	defer ac.shutdownMutex.Unlock()
+	ac.shutdownHooks = append(ac.shutdownHooks, f)
+}
+
 // GracefulExit performs a graceful shutdown of the application.
+// Safe to call more than once: only the first call has an effect.
 func (ac *AppController) GracefulExit() {
-	StopSingBoxProcess(ac)
+	ac.exitOnce.Do(ac.gracefulExit)
+}
 
-	log.Println("GracefulExit: Waiting for sing-box to stop...")
-	timeout := time.After(gracefulShutdownTimeout)
-	for {
-		if !ac.RunningState.IsRunning() {
-			log.Println("GracefulExit: Sing-box confirmed stopped.")
-			break
+func (ac *AppController) gracefulExit() {
+	if ac.SaveUIStateFunc != nil {
+		ac.SaveUIStateFunc()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		// An adopted sing-box (system service, another launcher) keeps running, and so do the
+		// system proxy and DNS it set: only the Stop button stops it
+		if ac.IsExternallyManaged() {
+			log.Println("GracefulExit: Leaving the externally managed sing-box running.")
+		} else {
+			ac.stopSingBoxForExit()
 		}
-		select {
-		case <-timeout:
-			log.Println("GracefulExit: Timeout waiting for sing-box to stop. Forcing kill.")
-			ac.CmdMutex.Lock()
-			if ac.SingboxCmd != nil && ac.SingboxCmd.Process != nil {
-				_ = ac.SingboxCmd.Process.Kill()
-			}
-			ac.CmdMutex.Unlock()
-			goto end_loop
-		default:
-			time.Sleep(100 * time.Millisecond)
+
+		close(ac.shutdown)
+		ac.shutdownMutex.Lock()
+		hooks := ac.shutdownHooks
+		ac.shutdownMutex.Unlock()
+		for _, hook := range hooks {
+			hook()
 		}
+
+		log.Println("GracefulExit: Waiting for background tasks...")
+		ac.backgroundTasks.Wait()
+	}()
+
+	forced := false
+	select {
+	case <-done:
+		log.Println("GracefulExit: Graceful exit complete")
+	case <-time.After(ac.stopTimeout() + time.Second + exitCleanupTimeout):
+		log.Println("GracefulExit: Forced exit after timeout")
+		forced = true
 	}
-end_loop:
 
 	if ac.MainLogFile != nil {
+		ac.MainLogFile.Sync()
 		ac.MainLogFile.Close()
 	}
 	if ac.ChildLogFile != nil {
@@ -323,9 +586,76 @@ end_loop:
 		ac.ApiLogFile.Close()
 	}
 
+	if forced {
+		os.Exit(1)
+	}
 	ac.Application.Quit()
 }
 
+// stopSingBoxForExit stops sing-box like Stop does (killed after StopTimeout), waits for it
+// and undoes what it changed in the system (DNS cache, system proxy)
+func (ac *AppController) stopSingBoxForExit() {
+	wasRunning := ac.RunningState.IsRunning()
+	ac.stopOwnSingBoxForExit()
+
+	// The system proxy first: a proxy left pointing at the stopped sing-box breaks all traffic,
+	// a stale DNS cache does not
+	if configSetsSystemProxy(ac.ConfigPath()) {
+		if err := platform.ClearSystemProxy(); err != nil {
+			log.Printf("GracefulExit: Failed to clear system proxy: %v", err)
+		} else {
+			log.Println("GracefulExit: System proxy cleared.")
+		}
+	}
+
+	// sing-box may have changed the system DNS: drop what was cached while it ran.
+	// A failure is only logged, exit goes on.
+	if wasRunning {
+		if err := FlushDNSCache(); err != nil {
+			log.Printf("GracefulExit: Failed to flush DNS cache: %v", err)
+		} else {
+			log.Println("GracefulExit: DNS cache flushed.")
+		}
+	}
+}
+
+// stopOwnSingBoxForExit stops the sing-box started by the launcher and waits for it
+func (ac *AppController) stopOwnSingBoxForExit() {
+	stopSingBox(ac, false)
+
+	log.Println("GracefulExit: Waiting for sing-box to stop...")
+	if !waitForSingBoxStop(ac) {
+		log.Println("GracefulExit: Timeout waiting for sing-box to stop. Forcing kill.")
+		ac.CmdMutex.Lock()
+		if ac.SingboxCmd != nil && ac.SingboxCmd.Process != nil {
+			if ac.SingboxCmd.Process.Kill() == nil {
+				ac.auditSingBoxStop(ac.SingboxCmd.Process.Pid, StopMethodTimeout)
+			}
+		}
+		ac.CmdMutex.Unlock()
+		ac.RemovePIDFile()
+		return
+	}
+	log.Println("GracefulExit: Sing-box confirmed stopped.")
+	ac.RemovePIDFile()
+}
+
+// stopTimeout returns StopTimeout, or its default if it is not set
+func (ac *AppController) stopTimeout() time.Duration {
+	if ac.StopTimeout <= 0 {
+		return defaultStopTimeout
+	}
+	return ac.StopTimeout
+}
+
+// auditSingBoxStop records the stop method in the audit log and the launcher log
+func (ac *AppController) auditSingBoxStop(pid int, method string) {
+	log.Printf("stopSingBox: Process %d stopped (method: %s)", pid, method)
+	if ac.AuditLog != nil {
+		ac.AuditLog.LogSingBoxStop(pid, method)
+	}
+}
+
 // RunHidden launches an external command in a hidden window.
 func (ac *AppController) RunHidden(name string, args []string, logPath string, dir string) error {
 	cmd := exec.Command(name, args...)
@@ -335,7 +665,7 @@ func (ac *AppController) RunHidden(name string, args []string, logPath string, d
 	}
 
 	if logPath != "" {
-		if logPath == filepath.Join(ac.ExecDir, childLogFileName) && ac.ChildLogFile != nil {
+		if logPath == filepath.Join(ac.ExecDir(), childLogFileName) && ac.ChildLogFile != nil {
 			// For sing-box logs, check and rotate if needed before writing
 			checkAndRotateLogFile(logPath)
 			logFile := ac.ChildLogFile
@@ -359,30 +689,57 @@ func (ac *AppController) RunHidden(name string, args []string, logPath string, d
 
 // CheckLinuxCapabilities checks Linux capabilities and shows a suggestion if needed
 func CheckLinuxCapabilities(ac *AppController) {
-	if suggestion := platform.CheckAndSuggestCapabilities(ac.SingboxPath); suggestion != "" {
+	if suggestion := platform.CheckAndSuggestCapabilities(ac.singboxBinary()); suggestion != "" {
 		log.Printf("CheckLinuxCapabilities: %s", suggestion)
 		// Show info dialog (not error) - capabilities can be set later
 		dialogs.ShowInfo(ac.MainWindow, "Linux Capabilities", suggestion)
 	}
 }
 
-// Set sets the new value for the 'running' state and triggers a UI update.
-func (r *RunningState) Set(value bool) {
+// SetRunning sets the new value for the 'running' state and triggers a UI update.
+// It is often called with CmdMutex held, so CoreStarted/CoreStopped are published from
+// another goroutine: subscribers may call methods that take CmdMutex (IsExternallyManaged).
+func (r *RunningState) SetRunning(value bool) {
 	r.Lock()
 	if r.running == value {
 		r.Unlock()
 		return
 	}
 	r.running = value
+	close(r.changed)
+	r.changed = make(chan struct{})
+	r.pending = append(r.pending, value)
+	startPublishing := !r.publishing
+	r.publishing = true
 	r.Unlock()
 
 	r.controller.UpdateUI()
 
-	// Call callback to update status in Core Dashboard
-	if r.controller.UpdateCoreStatusFunc != nil {
-		r.controller.UpdateCoreStatusFunc()
+	if startPublishing {
+		go r.publishTransitions()
 	}
+}
+
+// publishTransitions publishes the pending transitions in order, without holding any lock.
+// Core Dashboard, Clash API tab etc. are subscribed to the events.
+func (r *RunningState) publishTransitions() {
+	for {
+		r.Lock()
+		if len(r.pending) == 0 {
+			r.publishing = false
+			r.Unlock()
+			return
+		}
+		value := r.pending[0]
+		r.pending = r.pending[1:]
+		r.Unlock()
 
+		if value {
+			r.controller.Events.Publish(CoreStarted)
+		} else {
+			r.controller.Events.Publish(CoreStopped)
+		}
+	}
 }
 
 // IsRunning checks if the VPN is running.
@@ -392,6 +749,14 @@ func (r *RunningState) IsRunning() bool {
 	return r.running
 }
 
+// Wait blocks until the running state changes
+func (r *RunningState) Wait() {
+	r.Lock()
+	changed := r.changed
+	r.Unlock()
+	<-changed
+}
+
 // SetProxiesList safely sets the proxies list with mutex protection.
 func (ac *AppController) SetProxiesList(proxies []api.ProxyInfo) {
 	ac.APIStateMutex.Lock()
@@ -423,6 +788,20 @@ func (ac *AppController) GetActiveProxyName() string {
 	return ac.ActiveProxyName
 }
 
+// SetSelectedClashGroup safely sets the selector group shown in the Clash API tab.
+func (ac *AppController) SetSelectedClashGroup(group string) {
+	ac.APIStateMutex.Lock()
+	defer ac.APIStateMutex.Unlock()
+	ac.SelectedClashGroup = group
+}
+
+// GetSelectedClashGroup safely gets the selector group shown in the Clash API tab.
+func (ac *AppController) GetSelectedClashGroup() string {
+	ac.APIStateMutex.RLock()
+	defer ac.APIStateMutex.RUnlock()
+	return ac.SelectedClashGroup
+}
+
 // SetSelectedIndex safely sets the selected index with mutex protection.
 func (ac *AppController) SetSelectedIndex(index int) {
 	ac.APIStateMutex.Lock()
@@ -571,6 +950,33 @@ func checkAndShowSingBoxRunningWarning(ac *AppController, context string) bool {
 	return false
 }
 
+// checkBinaryChecksum verifies the sing-box binary against the checksum stored by DownloadCore.
+// On mismatch it offers to re-download the same version and returns false.
+func checkBinaryChecksum(ac *AppController) bool {
+	ok, err := ac.VerifyBinaryChecksum()
+	if err != nil {
+		log.Printf("startSingBox: Checksum verification failed: %v", err)
+		dialogs.ShowError(ac.MainWindow, err)
+		return false
+	}
+	if ok {
+		return true
+	}
+
+	version := ac.ExpectedChecksumVersion()
+	fyne.Do(func() {
+		d := dialog.NewConfirm("Checksum Error", "Binary checksum mismatch. The file may be corrupted. Re-download?", func(download bool) {
+			if download && ac.RedownloadCoreFunc != nil {
+				ac.RedownloadCoreFunc(version)
+			}
+		}, ac.MainWindow)
+		d.SetConfirmText("Download")
+		d.SetDismissText("Cancel")
+		d.Show()
+	})
+	return false
+}
+
 // StartSingBoxProcess launches the sing-box process.
 // skipRunningCheck: если true, пропускает проверку на уже запущенный процесс (для автоперезапуска)
 func StartSingBoxProcess(ac *AppController, skipRunningCheck ...bool) {
@@ -587,11 +993,21 @@ func StartSingBoxProcess(ac *AppController, skipRunningCheck ...bool) {
 		}
 	}
 
+	if !checkBinaryChecksum(ac) {
+		return
+	}
+	if quarantineBlocksStart(ac) {
+		return
+	}
+	if !skipCheck && clashAPIPortBlocksStart(ac) {
+		return
+	}
+
 	ac.CmdMutex.Lock()
 	defer ac.CmdMutex.Unlock()
 
 	// Check capabilities on Linux before starting
-	if suggestion := platform.CheckAndSuggestCapabilities(ac.SingboxPath); suggestion != "" {
+	if suggestion := platform.CheckAndSuggestCapabilities(ac.singboxBinary()); suggestion != "" {
 		log.Printf("startSingBox: Capabilities check failed: %s", suggestion)
 		dialogs.ShowError(ac.MainWindow, fmt.Errorf("Linux capabilities required\n\n%s", suggestion))
 		return
@@ -599,26 +1015,19 @@ func StartSingBoxProcess(ac *AppController, skipRunningCheck ...bool) {
 
 	// Reload API config from config.json before starting (in case it was corrupted)
 	log.Println("startSingBox: Reloading API config from config.json...")
-	if base, tok, err := api.LoadClashAPIConfig(ac.ConfigPath); err != nil {
-		log.Printf("startSingBox: Clash API config error: %v", err)
-		ac.ClashAPIBaseURL = ""
-		ac.ClashAPIToken = ""
-		ac.ClashAPIEnabled = false
-	} else {
-		ac.ClashAPIBaseURL = base
-		ac.ClashAPIToken = tok
-		ac.ClashAPIEnabled = true
+	ac.reloadClashAPISettings("startSingBox")
+	if ac.ClashAPIEnabled {
 		log.Printf("startSingBox: API config reloaded successfully")
 	}
 
 	// Reload SelectedClashGroup from config
 	if ac.ClashAPIEnabled {
-		_, defaultSelector, err := GetSelectorGroupsFromConfig(ac.ConfigPath)
+		_, defaultSelector, err := GetSelectorGroupsFromConfig(ac.ConfigPath())
 		if err != nil {
 			log.Printf("startSingBox: Failed to get selector groups: %v", err)
-			ac.SelectedClashGroup = "proxy-out" // Default fallback
+			ac.SetSelectedClashGroup("proxy-out") // Default fallback
 		} else {
-			ac.SelectedClashGroup = defaultSelector
+			ac.SetSelectedClashGroup(defaultSelector)
 			log.Printf("startSingBox: SelectedClashGroup reloaded: %s", defaultSelector)
 		}
 	}
@@ -630,12 +1039,19 @@ func StartSingBoxProcess(ac *AppController, skipRunningCheck ...bool) {
 	}
 
 	log.Println("startSingBox: Starting Sing-Box...")
-	ac.SingboxCmd = exec.Command(ac.SingboxPath, "run", "-c", filepath.Base(ac.ConfigPath))
+	// The config path is absolute: the working directory may be set to another folder
+	singboxPath := ac.singboxBinary()
+	if singboxPath != ac.SingboxPath {
+		log.Printf("startSingBox: %s not found, using sing-box from PATH: %s", ac.SingboxPath, singboxPath)
+	}
+	ac.SingboxCmd = exec.Command(singboxPath, "run", "-c", ac.ConfigPath())
 	platform.PrepareCommand(ac.SingboxCmd)
-	ac.SingboxCmd.Dir = platform.GetBinDir(ac.ExecDir)
+	ac.SingboxCmd.Dir = ac.GetSingboxWorkDir()
+	log.Printf("startSingBox: Working directory: %s", ac.SingboxCmd.Dir)
+	ac.SingboxCmd.Env = ac.singboxEnvironment()
 	if ac.ChildLogFile != nil {
 		// Check and rotate log file before starting new process to prevent unbounded growth
-		checkAndRotateLogFile(filepath.Join(ac.ExecDir, childLogFileName))
+		checkAndRotateLogFile(filepath.Join(ac.ExecDir(), childLogFileName))
 
 		// Write directly to file - no buffering in memory
 		// This prevents memory leaks from accumulating log output
@@ -645,27 +1061,42 @@ func StartSingBoxProcess(ac *AppController, skipRunningCheck ...bool) {
 	} else {
 		log.Println("startSingBox: Warning: sing-box log file not available, output will not be logged.")
 	}
+	ac.singboxLogOffset = ac.childLogSize()
+	ac.StartupFailed = false
 	if err := ac.SingboxCmd.Start(); err != nil {
-		ac.ShowStartupError(fmt.Errorf("failed to start Sing-Box process: %w", err))
 		log.Printf("startSingBox: Failed to start Sing-Box: %v", err)
+		if isQuarantineStartError(err) {
+			showQuarantineDialog(ac)
+			return
+		}
+		ac.ShowStartupError(fmt.Errorf("failed to start Sing-Box process: %w", err))
 		return
 	}
-	ac.RunningState.Set(true)
+	ac.singboxStartedAt = time.Now()
+	ac.RunningState.SetRunning(true)
 	ac.StoppedByUser = false
 	// Add log with PID
 	log.Printf("startSingBox: Sing-Box started. PID=%d", ac.SingboxCmd.Process.Pid)
+	if err := ac.WritePIDFile(ac.SingboxCmd.Process.Pid); err != nil {
+		log.Printf("startSingBox: %v", err)
+	}
 
-	go MonitorSingBoxProcess(ac, ac.SingboxCmd)
+	ac.singboxExited = make(chan struct{})
+	go MonitorSingBoxProcess(ac, ac.SingboxCmd, ac.singboxExited)
+	pid := ac.SingboxCmd.Process.Pid
+	ac.GoBackground(func() { ac.SyncSelectorChoices(pid) })
 }
 
 // MonitorSingBoxProcess monitors the sing-box process.
-func MonitorSingBoxProcess(ac *AppController, cmdToMonitor *exec.Cmd) {
+// exited is closed as soon as the process exits.
+func MonitorSingBoxProcess(ac *AppController, cmdToMonitor *exec.Cmd, exited chan struct{}) {
 	// Store the PID we're monitoring to avoid conflicts with restarted processes
 	monitoredPID := cmdToMonitor.Process.Pid
 
 	// Wait for process completion - no timeout for long-running processes
 	// The process should run until it exits or is stopped by user
 	err := cmdToMonitor.Wait()
+	close(exited)
 
 	ac.CmdMutex.Lock()
 	defer ac.CmdMutex.Unlock()
@@ -676,27 +1107,34 @@ func MonitorSingBoxProcess(ac *AppController, cmdToMonitor *exec.Cmd) {
 		log.Printf("monitorSingBox: Process was restarted (PID changed from %d). This monitor is obsolete. Exiting.", monitoredPID)
 		return
 	}
+	ac.RemovePIDFile()
 
 	// 2. Then StoppedByUser (did user stop it?)
 	if ac.StoppedByUser {
 		log.Println("monitorSingBox: Sing-Box exited as requested by user.")
 		ac.ConsecutiveCrashAttempts = 0
-		ac.RunningState.Set(false)
+		ac.RunningState.SetRunning(false)
 		ac.StoppedByUser = false // Reset flag for next start
 		return
 	}
 
-	// 3. Then err == nil (exited normally?)
+	// 3. Then the time since start (failed to start?) - restarting would fail the same way
+	if ac.isStartupCrash() {
+		ac.handleStartupCrash(err)
+		return
+	}
+
+	// 4. Then err == nil (exited normally?)
 	if err == nil {
 		log.Println("monitorSingBox: Sing-Box exited gracefully (exit code 0).")
 		ac.ConsecutiveCrashAttempts = 0
-		ac.RunningState.Set(false)
+		ac.RunningState.SetRunning(false)
 		return
 	}
 
-	// 4. Only then — crash → restart
+	// 5. Only then — crash → restart
 	// Процесс завершился с ошибкой - проверяем лимит попыток
-	ac.RunningState.Set(false)
+	ac.RunningState.SetRunning(false)
 	ac.ConsecutiveCrashAttempts++
 
 	if ac.ConsecutiveCrashAttempts > restartAttempts {
@@ -740,8 +1178,44 @@ func MonitorSingBoxProcess(ac *AppController, cmdToMonitor *exec.Cmd) {
 	}
 }
 
+// waitForSingBoxStop waits until sing-box has stopped after StopSingBoxProcess.
+// Returns false if it is still running after the stop timeout.
+func waitForSingBoxStop(ac *AppController) bool {
+	timeout := time.After(ac.stopTimeout() + time.Second)
+	for ac.RunningState.IsRunning() {
+		select {
+		case <-timeout:
+			return false
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+	return true
+}
+
+// RestartSingBoxProcess stops sing-box if it is running and starts it again, e.g. to apply a new config.
+// Blocks while waiting for the old process to stop, so it must not be called from the UI goroutine.
+func RestartSingBoxProcess(ac *AppController) {
+	if ac.RunningState.IsRunning() {
+		log.Println("RestartSingBoxProcess: Stopping sing-box...")
+		StopSingBoxProcess(ac)
+		if !waitForSingBoxStop(ac) {
+			log.Println("RestartSingBoxProcess: Timeout waiting for sing-box to stop, not starting a second instance")
+			return
+		}
+	}
+	log.Println("RestartSingBoxProcess: Starting sing-box...")
+	StartSingBoxProcess(ac, true)
+}
+
 // StopSingBoxProcess is the unified function to stop the sing-box process.
+// It is the Stop button: an adopted externally managed sing-box is killed as well.
 func StopSingBoxProcess(ac *AppController) {
+	stopSingBox(ac, true)
+}
+
+// stopSingBox stops the sing-box started by the launcher. An adopted externally managed one is
+// only killed if stopExternal; exit and Reset to Defaults leave it running.
+func stopSingBox(ac *AppController, stopExternal bool) {
 	ac.CmdMutex.Lock()
 
 	// CRITICAL: Set flag BEFORE sending signal
@@ -755,9 +1229,18 @@ func StopSingBoxProcess(ac *AppController) {
 		return
 	}
 
+	if ac.externalPID != 0 {
+		ac.StoppedByUser = false
+		ac.CmdMutex.Unlock()
+		if stopExternal {
+			stopExternalSingBox(ac)
+		}
+		return
+	}
+
 	if ac.SingboxCmd == nil || ac.SingboxCmd.Process == nil {
 		log.Println("StopSingBoxProcess: Inconsistent state detected. Correcting state.")
-		ac.RunningState.Set(false)
+		ac.RunningState.SetRunning(false)
 		ac.StoppedByUser = false
 		ac.CmdMutex.Unlock()
 		return
@@ -765,44 +1248,59 @@ func StopSingBoxProcess(ac *AppController) {
 
 	log.Println("stopSingBox: Attempting graceful shutdown...")
 	processToStop := ac.SingboxCmd.Process
+	exited := ac.singboxExited
+	stopTimeout := ac.stopTimeout()
 
 	// Разблокируем мьютекс перед отправкой сигнала, чтобы не блокировать
 	ac.CmdMutex.Unlock()
 
-	var err error
-	if runtime.GOOS == "windows" {
-		// sing-box ловит именно CTRL_BREAK_EVENT на Windows
-		dll := syscall.NewLazyDLL("kernel32.dll")
-		proc := dll.NewProc("GenerateConsoleCtrlEvent")
-		if r, _, e := proc.Call(uintptr(syscall.CTRL_BREAK_EVENT), uintptr(processToStop.Pid)); r == 0 {
-			err = e
-		}
-	} else {
-		err = processToStop.Signal(os.Interrupt)
-	}
+	// sing-box ловит CTRL_BREAK_EVENT на Windows и SIGTERM на остальных системах
+	err := platform.SendStopSignal(processToStop)
 
 	if err != nil {
 		log.Printf("stopSingBox: Graceful signal failed: %v. Forcing kill.", err)
 		if killErr := processToStop.Kill(); killErr != nil {
 			log.Printf("stopSingBox: Failed to kill Sing-Box process: %v", killErr)
 		}
-	} else {
-		// Start watchdog timer that will kill the process if it doesn't close itself
-		log.Println("stopSingBox: Signal sent, starting watchdog timer...")
-		go func(pid int) {
-			time.Sleep(gracefulShutdownTimeout)
-			p, _ := ps.FindProcess(pid)
-			if p != nil {
-				log.Printf("stopSingBox watchdog: Process %d still running after timeout. Forcing kill.", pid)
-				// Reliably kill the process and its child processes
-				_ = platform.KillProcessByPID(pid)
+		ac.auditSingBoxStop(processToStop.Pid, StopMethodSignalFailed)
+		finishStop(ac)
+		return
+	}
+
+	// Race process exit against the grace period; kill the process if it doesn't close itself
+	log.Printf("stopSingBox: Signal sent, waiting up to %v for exit...", stopTimeout)
+	go func(pid int) {
+		select {
+		case <-exited:
+			ac.auditSingBoxStop(pid, StopMethodGraceful)
+		case <-time.After(stopTimeout):
+			log.Printf("stopSingBox: Process %d still running after %v. Forcing kill.", pid, stopTimeout)
+			// Reliably kill the process and its child processes
+			if killErr := platform.KillProcessByPID(pid); killErr != nil {
+				log.Printf("stopSingBox: Failed to kill Sing-Box process: %v", killErr)
 			}
-		}(processToStop.Pid)
+			ac.auditSingBoxStop(pid, StopMethodTimeout)
+		}
+		finishStop(ac)
+	}(processToStop.Pid)
+}
+
+// finishStop cleans up after sing-box was stopped
+func finishStop(ac *AppController) {
+	ac.RemovePIDFile()
+	if ac.UpdateCoreStatusFunc != nil {
+		fyne.Do(ac.UpdateCoreStatusFunc)
 	}
 }
 
 // RunParserProcess starts the internal configuration update process.
-func RunParserProcess(ac *AppController) {
+// reviewChanges: если true, перед записью показывает изменения в списке прокси (ручное обновление)
+func RunParserProcess(ac *AppController, reviewChanges ...bool) {
+	if ac.ReadOnly {
+		log.Println("RunParser: Skipped, read-only mode")
+		dialogs.ShowAutoHideInfo(ac.Application, ac.MainWindow, "Parser Info", ErrReadOnly.Error())
+		return
+	}
 	// Проверяем, не запущен ли уже парсинг
 	ac.ParserMutex.Lock()
 	if ac.ParserRunning {
@@ -822,10 +1320,17 @@ func RunParserProcess(ac *AppController) {
 	}()
 
 	// Call internal parser to update configuration
-	err := UpdateConfigFromSubscriptions(ac)
+	review := len(reviewChanges) > 0 && reviewChanges[0]
+	err := UpdateConfigFromSubscriptions(ac, review)
 
 	// Обрабатываем результат
-	if err != nil {
+	if errors.Is(err, ErrProxyChangesCancelled) {
+		log.Println("RunParser: Config update cancelled by user.")
+	} else if errors.Is(err, ErrCredentialsUnreadable) {
+		log.Printf("RunParser: Failed to update config: %v", err)
+		dialogs.ShowError(ac.MainWindow, fmt.Errorf("%v\n\nconfig.json was not changed. Open the Config Wizard, "+
+			"click Auth... and enter the Authorization and headers of this subscription again.", err))
+	} else if err != nil {
 		log.Printf("RunParser: Failed to update config: %v", err)
 		// Progress already updated in UpdateConfigFromSubscriptions with error status
 		ac.ShowParserError(fmt.Errorf("failed to update config: %w", err))
@@ -839,12 +1344,23 @@ func RunParserProcess(ac *AppController) {
 // StartAutoReloadScheduler starts a background goroutine that periodically checks
 // if the configuration needs to be automatically reloaded based on the reload interval
 func StartAutoReloadScheduler(ac *AppController) {
-	go func() {
+	if ac.ReadOnly {
+		log.Println("AutoReload: Disabled in read-only mode")
+		return
+	}
+	ac.GoBackground(func() {
 		log.Println("AutoReload: Starting scheduler")
 		ticker := time.NewTicker(1 * time.Minute) // Check every minute
 		defer ticker.Stop()
 
-		for range ticker.C {
+		for {
+			select {
+			case <-ac.ShuttingDown():
+				log.Println("AutoReload: Stopping scheduler")
+				return
+			case <-ticker.C:
+			}
+
 			// Check if parser is already running
 			ac.ParserMutex.Lock()
 			if ac.ParserRunning {
@@ -854,68 +1370,96 @@ func StartAutoReloadScheduler(ac *AppController) {
 			}
 			ac.ParserMutex.Unlock()
 
-			// Extract config to check reload settings
-			config, err := ExtractParcerConfig(ac.ConfigPath)
-			if err != nil {
-				log.Printf("AutoReload: Failed to extract config: %v", err)
-				continue
+			if configReloadDue(ac) {
+				go RunParserProcess(ac)
 			}
+		}
+	})
+}
 
-			// Check if parser object exists and has reload setting
-			if config.ParserConfig.Parser.Reload == "" {
-				// No reload interval specified, skip
-				continue
-			}
+// LoadSubscriptionsAtStartup is the subscription load of the startup sequence: if the reload
+// interval of @ParcerConfig passed while the launcher was closed, the config is updated
+// before sing-box is started (see Ready).
+func LoadSubscriptionsAtStartup(ac *AppController) {
+	if ac.ReadOnly {
+		return
+	}
+	if _, err := os.Stat(ac.ConfigPath()); err != nil {
+		return
+	}
+	if configReloadDue(ac) {
+		RunParserProcess(ac)
+	}
+}
 
-			// Parse reload interval
-			reloadDuration, err := time.ParseDuration(config.ParserConfig.Parser.Reload)
-			if err != nil {
-				log.Printf("AutoReload: Error parsing reload interval '%s': %v", config.ParserConfig.Parser.Reload, err)
-				continue
-			}
+// configReloadDue reports whether the reload interval of @ParcerConfig has passed since last_updated
+func configReloadDue(ac *AppController) bool {
+	// Extract config to check reload settings
+	config, err := ExtractParcerConfig(ac.ConfigPath())
+	if err != nil {
+		log.Printf("AutoReload: Failed to extract config: %v", err)
+		return false
+	}
 
-			// Check if last_updated exists
-			if config.ParserConfig.Parser.LastUpdated == "" {
-				// No last_updated, trigger update
-				log.Printf("AutoReload: No last_updated found, triggering automatic config update (interval: %s)", config.ParserConfig.Parser.Reload)
-				go RunParserProcess(ac)
-				continue
-			}
+	// Check if parser object exists and has reload setting
+	if config.ParserConfig.Parser.Reload == "" {
+		// No reload interval specified, skip
+		return false
+	}
 
-			// Parse last_updated timestamp
-			lastUpdated, err := time.Parse(time.RFC3339, config.ParserConfig.Parser.LastUpdated)
-			if err != nil {
-				log.Printf("AutoReload: Error parsing last_updated '%s': %v", config.ParserConfig.Parser.LastUpdated, err)
-				// Treat as if update is needed
-				log.Printf("AutoReload: Triggering automatic config update due to invalid last_updated (interval: %s)", config.ParserConfig.Parser.Reload)
-				go RunParserProcess(ac)
-				continue
-			}
+	// Parse reload interval
+	reloadDuration, err := time.ParseDuration(config.ParserConfig.Parser.Reload)
+	if err != nil {
+		log.Printf("AutoReload: Error parsing reload interval '%s': %v", config.ParserConfig.Parser.Reload, err)
+		return false
+	}
+
+	// Check if last_updated exists
+	if config.ParserConfig.Parser.LastUpdated == "" {
+		// No last_updated, trigger update
+		log.Printf("AutoReload: No last_updated found, triggering automatic config update (interval: %s)", config.ParserConfig.Parser.Reload)
+		return true
+	}
 
-			// Check if enough time has passed
-			nextUpdateTime := lastUpdated.Add(reloadDuration)
-			now := time.Now().UTC()
+	// Parse last_updated timestamp
+	lastUpdated, err := time.Parse(time.RFC3339, config.ParserConfig.Parser.LastUpdated)
+	if err != nil {
+		log.Printf("AutoReload: Error parsing last_updated '%s': %v", config.ParserConfig.Parser.LastUpdated, err)
+		// Treat as if update is needed
+		log.Printf("AutoReload: Triggering automatic config update due to invalid last_updated (interval: %s)", config.ParserConfig.Parser.Reload)
+		return true
+	}
 
-			if now.After(nextUpdateTime) || now.Equal(nextUpdateTime) {
-				log.Printf("AutoReload: Triggering automatic config update (interval: %s, last_updated: %s)", config.ParserConfig.Parser.Reload, config.ParserConfig.Parser.LastUpdated)
-				go RunParserProcess(ac)
-			} else {
-				timeUntilUpdate := nextUpdateTime.Sub(now)
-				log.Printf("AutoReload: Next update in %v (last_updated: %s, interval: %s)", timeUntilUpdate, config.ParserConfig.Parser.LastUpdated, config.ParserConfig.Parser.Reload)
-			}
-		}
-	}()
+	// Check if enough time has passed
+	nextUpdateTime := lastUpdated.Add(reloadDuration)
+	now := time.Now().UTC()
+
+	if now.After(nextUpdateTime) || now.Equal(nextUpdateTime) {
+		log.Printf("AutoReload: Triggering automatic config update (interval: %s, last_updated: %s)", config.ParserConfig.Parser.Reload, config.ParserConfig.Parser.LastUpdated)
+		return true
+	}
+	timeUntilUpdate := nextUpdateTime.Sub(now)
+	log.Printf("AutoReload: Next update in %v (last_updated: %s, interval: %s)", timeUntilUpdate, config.ParserConfig.Parser.LastUpdated, config.ParserConfig.Parser.Reload)
+	return false
 }
 
+// CheckIfSingBoxRunningAtStartUtil restarts sing-box left by a previous launcher session or adopts
+// one started by someone else, then starts watching for externally started sing-box processes
 func CheckIfSingBoxRunningAtStartUtil(ac *AppController) {
-	checkAndShowSingBoxRunningWarning(ac, "CheckIfSingBoxRunningAtStart")
+	defer watchExternalSingBox(ac)
+	if restartOrphanedSingBox(ac) {
+		return
+	}
+	if pid := findExternalSingBoxPID(ac); pid != 0 {
+		adoptExternalSingBox(ac, pid)
+	}
 }
 
 // CheckConfigFileExists checks if config.json exists and shows a warning if it doesn't
 func CheckConfigFileExists(ac *AppController) {
-	if _, err := os.Stat(ac.ConfigPath); os.IsNotExist(err) {
-		log.Printf("CheckConfigFileExists: config.json not found at %s", ac.ConfigPath)
-		examplePath := filepath.Join(platform.GetBinDir(ac.ExecDir), constants.ConfigExampleName)
+	if _, err := os.Stat(ac.ConfigPath()); os.IsNotExist(err) {
+		log.Printf("CheckConfigFileExists: config.json not found at %s", ac.ConfigPath())
+		examplePath := filepath.Join(platform.GetBinDir(ac.ExecDir()), constants.ConfigExampleName)
 
 		message := fmt.Sprintf(
 			"⚠️ Configuration file not found!\n\n"+
@@ -963,7 +1507,7 @@ func CheckIfLauncherAlreadyRunningUtil(ac *AppController) {
 }
 
 func CheckFilesUtil(ac *AppController) {
-	files := platform.GetRequiredFiles(ac.ExecDir)
+	files := platform.GetRequiredFiles(ac.ExecDir())
 	msg := "File check:\n\n"
 	allOk := true
 	for _, f := range files {
@@ -1008,7 +1552,7 @@ func ShowSingBoxAlreadyRunningWarningUtil(ac *AppController) {
 		go func() {
 			processName := platform.GetProcessNameForCheck()
 			_ = platform.KillProcess(processName)
-			ac.RunningState.Set(false)
+			ac.RunningState.SetRunning(false)
 		}()
 		fyne.Do(func() { d.Hide() })
 	}
@@ -1132,19 +1676,19 @@ func (ac *AppController) GetVPNButtonState() VPNButtonState {
 
 	// Check if config.json exists
 	configExists := false
-	if _, err := os.Stat(ac.ConfigPath); err == nil {
+	if _, err := os.Stat(ac.ConfigPath()); err == nil {
 		configExists = true
 	}
 
 	// Check if wintun.dll exists (only on Windows)
 	wintunExists := true // Default to true for non-Windows
 	if runtime.GOOS == "windows" {
-		exists, err := ac.CheckWintunDLL()
+		wintun, err := ac.CheckWintunDLL()
 		if err != nil {
 			// Error checking - assume not available
 			wintunExists = false
 		} else {
-			wintunExists = exists
+			wintunExists = wintun.Found
 		}
 	}
 
@@ -1220,7 +1764,7 @@ func (ac *AppController) CreateTrayMenu() *fyne.Menu {
 			menuItem := fyne.NewMenuItem(proxyName, func() {
 				// Switch to selected proxy
 				go func() {
-					err := api.SwitchProxy(ac.ClashAPIBaseURL, ac.ClashAPIToken, selectedGroup, pName, ac.ApiLogFile)
+					err := ac.SwitchProxyGroup(selectedGroup, pName)
 					fyne.Do(func() {
 						if err != nil {
 							log.Printf("CreateTrayMenu: Failed to switch proxy: %v", err)
@@ -6,6 +6,7 @@ import (
 	"compress/gzip"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -40,10 +41,36 @@ type DownloadProgress struct {
 	Error    error
 }
 
+var errAnotherDownload = errors.New("another download is in progress")
+
 // DownloadCore downloads and installs sing-box
 func (ac *AppController) DownloadCore(ctx context.Context, version string, progressChan chan DownloadProgress) {
 	defer close(progressChan)
 
+	tracked, finish, err := ac.trackDownload(DownloadResourceCore, version, progressChan)
+	if err != nil {
+		progressChan <- DownloadProgress{Progress: 0, Message: err.Error(), Status: "error", Error: err}
+		return
+	}
+	defer finish()
+	progressChan = tracked
+
+	if ac.IsCoreVersionBlocked(version) {
+		err := fmt.Errorf("sing-box v%s is blocked (blocked versions: %s). Unblock it in the Core tab to download it",
+			normalizeBlockedVersion(version), strings.Join(ac.LoadPreferences().BlockedVersions, ", "))
+		progressChan <- DownloadProgress{Progress: 0, Message: err.Error(), Status: "error", Error: err}
+		return
+	}
+
+	if ac.PreDownloadHook != nil {
+		if err := ac.PreDownloadHook(version); err != nil {
+			err = fmt.Errorf("download cancelled by pre-download hook: %w", err)
+			log.Printf("DownloadCore: %v", err)
+			progressChan <- DownloadProgress{Progress: 0, Message: err.Error(), Status: "error", Error: err}
+			return
+		}
+	}
+
 	// 1. Get release information
 	progressChan <- DownloadProgress{Progress: 5, Message: "Getting release information...", Status: "downloading"}
 	release, err := ac.getReleaseInfo(ctx, version)
@@ -54,15 +81,15 @@ func (ac *AppController) DownloadCore(ctx context.Context, version string, progr
 
 	// 2. Находим правильный asset для платформы
 	progressChan <- DownloadProgress{Progress: 10, Message: "Finding platform asset...", Status: "downloading"}
-	asset, err := ac.findPlatformAsset(release.Assets)
+	asset, err := ac.findPlatformAsset(release)
 	if err != nil {
 		progressChan <- DownloadProgress{Progress: 0, Message: fmt.Sprintf("Failed to find platform asset: %v", err), Status: "error", Error: err}
 		return
 	}
 
 	// 3. Создаем временную директорию
-	tempDir := filepath.Join(ac.ExecDir, "temp")
-	if err := os.MkdirAll(tempDir, 0755); err != nil {
+	tempDir, err := ac.downloadTempDir()
+	if err != nil {
 		progressChan <- DownloadProgress{Progress: 0, Message: fmt.Sprintf("Failed to create temp dir: %v", err), Status: "error", Error: err}
 		return
 	}
@@ -75,6 +102,7 @@ func (ac *AppController) DownloadCore(ctx context.Context, version string, progr
 		progressChan <- DownloadProgress{Progress: 0, Message: fmt.Sprintf("Download failed: %v", err), Status: "error", Error: err}
 		return
 	}
+	ac.setDownloadedBytes(DownloadResourceCore, archivePath)
 
 	// 5. Распаковываем архив
 	progressChan <- DownloadProgress{Progress: 80, Message: "Extracting archive...", Status: "extracting"}
@@ -90,6 +118,31 @@ func (ac *AppController) DownloadCore(ctx context.Context, version string, progr
 		progressChan <- DownloadProgress{Progress: 0, Message: fmt.Sprintf("Installation failed: %v", err), Status: "error", Error: err}
 		return
 	}
+	// Установленная версия изменилась
+	ac.InvalidateCoreVersionInfo()
+
+	// Запоминаем контрольную сумму для проверки при каждом запуске
+	if sum, err := fileSHA256(ac.SingboxPath); err != nil {
+		log.Printf("DownloadCore: Failed to compute checksum: %v", err)
+	} else if err := ac.StoreExpectedChecksum(version, sum); err != nil {
+		log.Printf("DownloadCore: %v", err)
+	}
+
+	if ac.PostDownloadHook != nil {
+		ok, err := ac.VerifyBinaryChecksum()
+		if err == nil && !ok {
+			err = fmt.Errorf("checksum mismatch after install")
+		}
+		if err == nil {
+			err = ac.PostDownloadHook(version, ac.SingboxPath)
+		}
+		if err != nil {
+			err = fmt.Errorf("sing-box v%s installed, but post-download hook failed: %w", version, err)
+			log.Printf("DownloadCore: %v", err)
+			progressChan <- DownloadProgress{Progress: 0, Message: err.Error(), Status: "error", Error: err}
+			return
+		}
+	}
 
 	// 7. Готово!
 	progressChan <- DownloadProgress{Progress: 100, Message: fmt.Sprintf("sing-box v%s installed successfully!", version), Status: "done"}
@@ -125,7 +178,7 @@ func (ac *AppController) getReleaseInfoFromGitHub(ctx context.Context, version s
 	}
 
 	req.Header.Set("Accept", "application/vnd.github.v3+json")
-	req.Header.Set("User-Agent", "singbox-launcher/1.0")
+	req.Header.Set("User-Agent", ac.launcherUserAgent())
 
 	resp, err := client.Do(req)
 	if err != nil {
@@ -179,91 +232,125 @@ func (ac *AppController) getReleaseInfoFromSourceForge(ctx context.Context, vers
 
 // buildSourceForgeAssets строит список assets для SourceForge
 func (ac *AppController) buildSourceForgeAssets(version string) []Asset {
-	var assets []Asset
-
-	// Определяем нужный файл для текущей платформы
-	var fileName string
-	switch runtime.GOOS {
-	case "windows":
-		if runtime.GOARCH == "amd64" {
-			fileName = fmt.Sprintf("sing-box-%s-windows-amd64.zip", version)
-		} else if runtime.GOARCH == "arm64" {
-			fileName = fmt.Sprintf("sing-box-%s-windows-arm64.zip", version)
-		}
-	case "linux":
-		if runtime.GOARCH == "amd64" {
-			fileName = fmt.Sprintf("sing-box-%s-linux-amd64.tar.gz", version)
-		} else if runtime.GOARCH == "arm64" {
-			fileName = fmt.Sprintf("sing-box-%s-linux-arm64.tar.gz", version)
-		} else if runtime.GOARCH == "arm" {
-			fileName = fmt.Sprintf("sing-box-%s-linux-armv7.tar.gz", version)
-		}
-	case "darwin":
-		if runtime.GOARCH == "amd64" {
-			fileName = fmt.Sprintf("sing-box-%s-darwin-amd64.tar.gz", version)
-		} else if runtime.GOARCH == "arm64" {
-			fileName = fmt.Sprintf("sing-box-%s-darwin-arm64.tar.gz", version)
-		}
-	}
-
-	if fileName == "" {
-		return assets
+	if _, ok := assetArchToken(runtime.GOOS, runtime.GOARCH); !ok {
+		return nil
 	}
+	fileName := BuildAssetName(version, runtime.GOOS, runtime.GOARCH)
 
 	// Строим прямую ссылку на SourceForge
 	downloadURL := fmt.Sprintf("https://sourceforge.net/projects/sing-box.mirror/files/v%s/%s/download", version, fileName)
 
-	assets = append(assets, Asset{
+	return []Asset{{
 		Name:               fileName,
 		BrowserDownloadURL: downloadURL,
 		Size:               0, // Размер неизвестен заранее
-	})
+	}}
+}
 
-	return assets
+// assetArchToken returns how sing-box release assets name the architecture (arm -> armv7)
+func assetArchToken(goos, goarch string) (string, bool) {
+	switch goos + "/" + goarch {
+	case "windows/amd64", "windows/arm64", "windows/386",
+		"linux/amd64", "linux/arm64", "linux/386",
+		"darwin/amd64", "darwin/arm64":
+		return goarch, true
+	case "linux/arm":
+		return "armv7", true
+	}
+	return "", false
 }
 
-// findPlatformAsset находит правильный asset для текущей платформы
-func (ac *AppController) findPlatformAsset(assets []Asset) (*Asset, error) {
-	var platformPattern string
+// BuildAssetName returns the canonical name of the sing-box release archive for a platform,
+// e.g. sing-box-1.12.0-windows-amd64.zip or sing-box-1.12.0-linux-armv7.tar.gz
+func BuildAssetName(version, goos, goarch string) string {
+	arch, ok := assetArchToken(goos, goarch)
+	if !ok {
+		arch = goarch
+	}
+	ext := ".tar.gz"
+	if goos == "windows" {
+		ext = ".zip"
+	}
+	return fmt.Sprintf("sing-box-%s-%s-%s%s", strings.TrimPrefix(version, "v"), goos, arch, ext)
+}
 
-	switch runtime.GOOS {
-	case "windows":
-		if runtime.GOARCH == "amd64" {
-			platformPattern = "windows-amd64.zip"
-		} else if runtime.GOARCH == "arm64" {
-			platformPattern = "windows-arm64.zip"
-		} else {
-			return nil, fmt.Errorf("unsupported architecture: %s", runtime.GOARCH)
+// findPlatformAsset находит правильный asset для текущей платформы в списке assets релиза
+func (ac *AppController) findPlatformAsset(release *ReleaseInfo) (*Asset, error) {
+	return selectPlatformAsset(release.Assets, strings.TrimPrefix(release.TagName, "v"),
+		runtime.GOOS, runtime.GOARCH, ac.LoadPreferences().CoreWithoutQUIC)
+}
+
+// selectPlatformAsset picks the archive for goos/goarch from the assets of a release. The naming
+// changed between sing-box versions (extra suffixes like -legacy-windows-7, -glibc, QUIC variants),
+// so the assets are scored instead of guessing one name: the canonical BuildAssetName wins, plain
+// names beat suffixed variants, and without_quic/with_quic builds follow withoutQUIC.
+func selectPlatformAsset(assets []Asset, version, goos, goarch string, withoutQUIC bool) (*Asset, error) {
+	arch, ok := assetArchToken(goos, goarch)
+	if !ok {
+		return nil, fmt.Errorf("unsupported platform: %s/%s", goos, goarch)
+	}
+	canonical := BuildAssetName(version, goos, goarch)
+	platform := fmt.Sprintf("-%s-%s", goos, arch)
+
+	best, bestScore := -1, 0
+	for i, asset := range assets {
+		var ext string
+		switch {
+		case strings.HasSuffix(asset.Name, ".zip"):
+			ext = ".zip"
+		case strings.HasSuffix(asset.Name, ".tar.gz"):
+			ext = ".tar.gz"
+		default:
+			continue // Packages (.deb, .rpm, .apk, ...) and checksums can't be installed
 		}
-	case "linux":
-		if runtime.GOARCH == "amd64" {
-			platformPattern = "linux-amd64.tar.gz"
-		} else if runtime.GOARCH == "arm64" {
-			platformPattern = "linux-arm64.tar.gz"
-		} else if runtime.GOARCH == "arm" {
-			platformPattern = "linux-armv7.tar.gz"
-		} else {
-			return nil, fmt.Errorf("unsupported architecture: %s", runtime.GOARCH)
+		base := strings.TrimSuffix(asset.Name, ext)
+		idx := strings.Index(base, platform)
+		if idx < 0 {
+			continue
 		}
-	case "darwin":
-		if runtime.GOARCH == "amd64" {
-			platformPattern = "darwin-amd64.tar.gz"
-		} else if runtime.GOARCH == "arm64" {
-			platformPattern = "darwin-arm64.tar.gz"
-		} else {
-			return nil, fmt.Errorf("unsupported architecture: %s", runtime.GOARCH)
+		// Everything after "-linux-amd64": "" for the plain build, "-glibc", "v3", "-with_quic", ...
+		variant := base[idx+len(platform):]
+
+		score := 100
+		if asset.Name == canonical {
+			score += 100
+		}
+		if variant != "" && !strings.HasPrefix(variant, "-") {
+			score -= 40 // A different CPU level, e.g. amd64v3
+		}
+		score -= 10 * strings.Count(variant, "-")
+		switch {
+		case strings.Contains(variant, "without_quic"):
+			if withoutQUIC {
+				score += 50
+			} else {
+				score -= 50
+			}
+		case strings.Contains(variant, "with_quic"):
+			if withoutQUIC {
+				score -= 50
+			} else {
+				score += 50
+			}
+		}
+		if (ext == ".zip") == (goos == "windows") {
+			score += 5
+		}
+		if best < 0 || score > bestScore {
+			best, bestScore = i, score
 		}
-	default:
-		return nil, fmt.Errorf("unsupported platform: %s", runtime.GOOS)
 	}
 
-	for i := range assets {
-		if strings.Contains(assets[i].Name, platformPattern) {
-			return &assets[i], nil
+	if best < 0 {
+		names := make([]string, 0, len(assets))
+		for _, asset := range assets {
+			names = append(names, asset.Name)
 		}
+		return nil, fmt.Errorf("no sing-box archive for %s/%s in release v%s (expected %s, available: %s)",
+			goos, goarch, version, canonical, strings.Join(names, ", "))
 	}
-
-	return nil, fmt.Errorf("asset not found for platform %s/%s", runtime.GOOS, runtime.GOARCH)
+	log.Printf("findPlatformAsset: Selected %s for %s/%s", assets[best].Name, goos, goarch)
+	return &assets[best], nil
 }
 
 // downloadFile downloads a file with progress tracking (with SourceForge fallback)
@@ -309,7 +396,7 @@ func (ac *AppController) downloadFile(ctx context.Context, url, destPath string,
 }
 
 // downloadFileFromURL downloads a file from a specific URL
-func (ac *AppController) downloadFileFromURL(ctx context.Context, url, destPath string, progressChan chan DownloadProgress) error {
+func (ac *AppController) downloadFileFromURL(ctx context.Context, url, destPath string, progressChan chan<- DownloadProgress) error {
 	// Use parent context timeout or create one with default timeout
 	downloadTimeout := 5 * time.Minute
 	if _, ok := ctx.Deadline(); !ok {
@@ -326,7 +413,7 @@ func (ac *AppController) downloadFileFromURL(ctx context.Context, url, destPath
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("User-Agent", "singbox-launcher/1.0")
+	req.Header.Set("User-Agent", UserAgent())
 
 	resp, err := client.Do(req)
 	if err != nil {
@@ -514,7 +601,11 @@ func (ac *AppController) extractTarGz(archivePath, destDir string) (string, erro
 	return "", fmt.Errorf("sing-box binary not found in archive")
 }
 
-// installBinary копирует бинарник в целевую директорию
+// installBinary копирует бинарник в целевую директорию.
+// The binary is written to a temporary file next to destPath, compared with the extracted one and
+// then renamed over destPath, so a crash in the middle never leaves a partially written sing-box.
+// sing-box releases publish no checksums, so the download itself can't be verified here; the sha256
+// of the installed binary is stored by DownloadCore and checked on every start.
 func (ac *AppController) installBinary(sourcePath, destPath string) error {
 	// Создаем директорию bin если её нет
 	binDir := filepath.Dir(destPath)
@@ -522,42 +613,94 @@ func (ac *AppController) installBinary(sourcePath, destPath string) error {
 		return fmt.Errorf("failed to create bin directory: %w", err)
 	}
 
-	// If old binary exists, rename it
-	if _, err := os.Stat(destPath); err == nil {
-		oldPath := destPath + ".old"
-		os.Remove(oldPath) // Remove old backup if exists
-		if err := os.Rename(destPath, oldPath); err != nil {
-			log.Printf("Warning: failed to rename old binary: %v", err)
+	// sing-box(.exe) -> sing-box.tmp in the same directory (rename is only atomic within a file system)
+	tempPath := filepath.Join(binDir, strings.TrimSuffix(filepath.Base(destPath), filepath.Ext(destPath))+".tmp")
+	if err := copyFileSynced(sourcePath, tempPath); err != nil {
+		os.Remove(tempPath)
+		return err
+	}
+	// Set execute permissions (for Unix)
+	if runtime.GOOS != "windows" {
+		os.Chmod(tempPath, 0755)
+	}
+
+	// The copy must be identical to the extracted binary
+	sourceSum, err := fileSHA256(sourcePath)
+	if err != nil {
+		os.Remove(tempPath)
+		return fmt.Errorf("failed to compute checksum: %w", err)
+	}
+	if tempSum, err := fileSHA256(tempPath); err != nil || tempSum != sourceSum {
+		os.Remove(tempPath)
+		return fmt.Errorf("copy of the new binary differs from the extracted one")
+	}
+
+	// Backup left by a previous update (still locked if that sing-box is running)
+	os.Remove(destPath + ".old")
+
+	if err := atomicFileReplace(tempPath, destPath); err != nil {
+		if runtime.GOOS != "windows" {
+			os.Remove(tempPath)
+			return err
+		}
+		// Windows can't replace a binary that is in use, but it can rename it: the running
+		// sing-box keeps working from .old, the next start uses the new binary
+		log.Printf("installBinary: %v, moving the binary in use aside", err)
+		if err := replaceLockedBinary(tempPath, destPath); err != nil {
+			os.Remove(tempPath)
+			return err
 		}
 	}
 
-	// Copy new binary
-	sourceFile, err := os.Open(sourcePath)
+	log.Printf("Binary installed successfully to %s", destPath)
+	return nil
+}
+
+// replaceLockedBinary installs src as dst when dst can't be overwritten because it is running:
+// dst is renamed to dst.old first, and restored if src can't be renamed into place
+func replaceLockedBinary(src, dst string) error {
+	backup := dst + ".old"
+	if err := os.Rename(dst, backup); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to move the old binary aside: %w", err)
+	}
+	if err := os.Rename(src, dst); err != nil {
+		if restoreErr := os.Rename(backup, dst); restoreErr != nil {
+			log.Printf("replaceLockedBinary: Failed to restore %s: %v", dst, restoreErr)
+		}
+		return fmt.Errorf("failed to install new binary: %w", err)
+	}
+	return nil
+}
+
+// copyFileSynced copies src to dst and flushes dst to disk
+func copyFileSynced(src, dst string) error {
+	sourceFile, err := os.Open(src)
 	if err != nil {
 		return fmt.Errorf("failed to open source file: %w", err)
 	}
 	defer sourceFile.Close()
 
-	destFile, err := os.Create(destPath)
+	destFile, err := os.Create(dst)
 	if err != nil {
 		return fmt.Errorf("failed to create destination file: %w", err)
 	}
-	defer destFile.Close()
-
-	_, err = io.Copy(destFile, sourceFile)
-	if err != nil {
+	if _, err := io.Copy(destFile, sourceFile); err != nil {
+		destFile.Close()
 		return fmt.Errorf("failed to copy file: %w", err)
 	}
-
-	// Set execute permissions (for Unix)
-	if runtime.GOOS != "windows" {
-		os.Chmod(destPath, 0755)
+	if err := destFile.Sync(); err != nil {
+		destFile.Close()
+		return fmt.Errorf("failed to flush file: %w", err)
 	}
+	return destFile.Close()
+}
 
-	// Remove old backup
-	oldPath := destPath + ".old"
-	os.Remove(oldPath)
-
-	log.Printf("Binary installed successfully to %s", destPath)
+// atomicFileReplace replaces dst with src using os.Rename, which is atomic on most file systems:
+// dst is either the old or the new file, never a partially written one.
+// src must be on the same file system as dst (e.g. in the same directory).
+func atomicFileReplace(src, dst string) error {
+	if err := os.Rename(src, dst); err != nil {
+		return fmt.Errorf("atomicFileReplace: %w", err)
+	}
 	return nil
 }
@@ -0,0 +1,80 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readTestFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestInstallBinary(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "extracted")
+	dest := filepath.Join(dir, "bin", "sing-box")
+	writeTestFile(t, source, "new binary")
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, dest, "old binary")
+	writeTestFile(t, dest+".old", "backup of a previous update")
+
+	ac := &AppController{}
+	if err := ac.installBinary(source, dest); err != nil {
+		t.Fatalf("installBinary() error = %v", err)
+	}
+	if got := readTestFile(t, dest); got != "new binary" {
+		t.Errorf("installed binary = %q, want %q", got, "new binary")
+	}
+	for _, leftover := range []string{filepath.Join(dir, "bin", "sing-box.tmp"), dest + ".old"} {
+		if _, err := os.Stat(leftover); !os.IsNotExist(err) {
+			t.Errorf("%s was not removed (err = %v)", filepath.Base(leftover), err)
+		}
+	}
+}
+
+func TestReplaceLockedBinary(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "sing-box.tmp")
+	dst := filepath.Join(dir, "sing-box.exe")
+	writeTestFile(t, src, "new binary")
+	writeTestFile(t, dst, "running binary")
+
+	if err := replaceLockedBinary(src, dst); err != nil {
+		t.Fatalf("replaceLockedBinary() error = %v", err)
+	}
+	if got := readTestFile(t, dst); got != "new binary" {
+		t.Errorf("%s = %q, want the new binary", filepath.Base(dst), got)
+	}
+	if got := readTestFile(t, dst+".old"); got != "running binary" {
+		t.Errorf("%s.old = %q, want the running binary", filepath.Base(dst), got)
+	}
+}
+
+func TestReplaceLockedBinaryRestoresOnFailure(t *testing.T) {
+	dir := t.TempDir()
+	dst := filepath.Join(dir, "sing-box.exe")
+	writeTestFile(t, dst, "running binary")
+
+	// The new binary is missing, so it can't be renamed into place
+	if err := replaceLockedBinary(filepath.Join(dir, "missing.tmp"), dst); err == nil {
+		t.Fatal("replaceLockedBinary() error = nil, want an error")
+	}
+	if got := readTestFile(t, dst); got != "running binary" {
+		t.Errorf("%s = %q, want the old binary restored", filepath.Base(dst), got)
+	}
+}
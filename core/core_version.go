@@ -3,28 +3,51 @@ package core
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
-	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"singbox-launcher/internal/platform"
 )
 
+// CheckCoreBinary is the binary check of the startup sequence: it looks up sing-box, reads its version
+// and verifies the checksum stored by DownloadCore. Problems are only logged, starting sing-box
+// reports them to the user.
+func (ac *AppController) CheckCoreBinary() {
+	version, err := ac.GetInstalledCoreVersion()
+	if err != nil {
+		log.Printf("CheckCoreBinary: %v", err)
+		return
+	}
+	ok, err := ac.VerifyBinaryChecksum()
+	switch {
+	case err != nil:
+		log.Printf("CheckCoreBinary: sing-box v%s: %v", version, err)
+	case !ok:
+		log.Printf("CheckCoreBinary: sing-box v%s does not match the stored checksum", version)
+	default:
+		log.Printf("CheckCoreBinary: sing-box v%s OK", version)
+	}
+}
+
 // GetInstalledCoreVersion получает установленную версию sing-box
 func (ac *AppController) GetInstalledCoreVersion() (string, error) {
-	// Проверяем существование бинарника
-	if _, err := os.Stat(ac.SingboxPath); os.IsNotExist(err) {
+	// Проверяем существование бинарника (в BinaryDir или в PATH)
+	singboxPath, err := ac.FindSingboxBinary(true)
+	if err != nil {
 		return "", fmt.Errorf("sing-box not found at %s", ac.SingboxPath)
 	}
 
 	// Запускаем sing-box version
-	cmd := exec.Command(ac.SingboxPath, "version")
+	cmd := exec.Command(singboxPath, "version")
 	platform.PrepareCommand(cmd)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
@@ -50,11 +73,14 @@ func (ac *AppController) GetInstalledCoreVersion() (string, error) {
 }
 
 // GetCoreBinaryPath возвращает путь к бинарнику sing-box для отображения
+// (полный путь, если используется sing-box из PATH)
 func (ac *AppController) GetCoreBinaryPath() string {
+	if externalPath, external := ac.ExternalSingboxPath(); external {
+		return externalPath
+	}
 	singboxName, _ := platform.GetExecutableNames()
 	// Для отображения убираем полный путь, оставляем только bin/sing-box.exe или bin/sing-box
-	binDir := platform.GetBinDir(ac.ExecDir)
-	relPath, err := filepath.Rel(ac.ExecDir, binDir)
+	relPath, err := filepath.Rel(ac.ExecDir(), ac.BinaryDir)
 	if err != nil {
 		// Если не удалось получить относительный путь, возвращаем просто имя
 		return singboxName
@@ -62,18 +88,26 @@ func (ac *AppController) GetCoreBinaryPath() string {
 	return filepath.Join(relPath, singboxName)
 }
 
-// CoreVersionInfo содержит информацию о версии sing-box
+// CoreVersionInfo describes the installed and the latest available sing-box versions
 type CoreVersionInfo struct {
-	InstalledVersion string
-	LatestVersion    string
-	UpdateAvailable  bool
-	Error            string
+	InstalledVersion string    // version of bin/sing-box, empty if it is missing
+	LatestVersion    string    // latest release (FallbackVersion if GitHub is unreachable, then UpdateAvailable is false)
+	UpdateAvailable  bool      // LatestVersion is newer than InstalledVersion
+	DownloadURL      string    // URL of the LatestVersion archive for this platform, empty if unknown
+	CheckedAt        time.Time // when the information was gathered
+	Error            string    // why InstalledVersion could not be determined
 }
 
 // FallbackVersion - фиксированная версия для использования, если не удается получить последнюю
 const FallbackVersion = "1.12.12"
 
-// GetLatestCoreVersion получает последнюю версию sing-box (с fallback на фиксированную версию)
+// ErrUsingFallbackVersion is returned by GetLatestCoreVersion together with FallbackVersion
+// when no GitHub source could be reached. The version can still be downloaded (from SourceForge),
+// but it is not known to be the latest one.
+var ErrUsingFallbackVersion = errors.New("latest version unknown, using fallback version " + FallbackVersion)
+
+// GetLatestCoreVersion получает последнюю версию sing-box. Если все источники недоступны,
+// возвращает FallbackVersion вместе с ErrUsingFallbackVersion (проверять через errors.Is).
 func (ac *AppController) GetLatestCoreVersion() (string, error) {
 	sources := []struct {
 		name string
@@ -85,7 +119,7 @@ func (ac *AppController) GetLatestCoreVersion() (string, error) {
 
 	for _, source := range sources {
 		log.Printf("Trying to get latest version from %s...", source.name)
-		version, err := ac.getLatestVersionFromURL(source.url)
+		version, err := ac.getLatestVersionFromSource(source.url)
 		if err == nil {
 			log.Printf("Successfully got latest version %s from %s", version, source.name)
 			return version, nil
@@ -95,11 +129,16 @@ func (ac *AppController) GetLatestCoreVersion() (string, error) {
 
 	// Если GitHub недоступен, используем фиксированную версию для скачивания с SourceForge
 	log.Printf("All GitHub sources failed, using fallback version %s from SourceForge", FallbackVersion)
-	return FallbackVersion, nil
+	return FallbackVersion, ErrUsingFallbackVersion
 }
 
-// getLatestVersionFromURL получает последнюю версию по конкретному URL
-func (ac *AppController) getLatestVersionFromURL(url string) (string, error) {
+// errGitHubNotFound is returned by getGitHubAPI for a 404 response. For /releases/latest it
+// means the repository has no release yet (pre-releases are not "latest").
+var errGitHubNotFound = errors.New("not found")
+
+// getGitHubAPI performs a GET request to the GitHub API and returns the response body.
+// A 404 response is reported as errGitHubNotFound.
+func (ac *AppController) getGitHubAPI(url string) ([]byte, error) {
 	// Создаем контекст с таймаутом
 	ctx, cancel := context.WithTimeout(context.Background(), NetworkRequestTimeout)
 	defer cancel()
@@ -109,29 +148,53 @@ func (ac *AppController) getLatestVersionFromURL(url string) (string, error) {
 
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Accept", "application/vnd.github.v3+json")
-	req.Header.Set("User-Agent", "singbox-launcher/1.0")
+	req.Header.Set("User-Agent", ac.launcherUserAgent())
 
 	resp, err := client.Do(req)
 	if err != nil {
 		// Проверяем тип ошибки
 		if IsNetworkError(err) {
-			return "", fmt.Errorf("network error: %s", GetNetworkErrorMessage(err))
+			return nil, fmt.Errorf("network error: %s", GetNetworkErrorMessage(err))
 		}
-		return "", fmt.Errorf("check failed: %w", err)
+		return nil, fmt.Errorf("check failed: %w", err)
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, errGitHubNotFound
+	}
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("check failed: HTTP %d", resp.StatusCode)
+		return nil, fmt.Errorf("check failed: HTTP %d", resp.StatusCode)
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("failed to read response: %w", err)
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	return body, nil
+}
+
+// getLatestVersionFromSource returns the latest release from a .../releases/latest URL. If the
+// repository has only pre-releases (or no releases) yet, the newest tag is taken instead.
+func (ac *AppController) getLatestVersionFromSource(latestURL string) (string, error) {
+	version, err := ac.getLatestVersionFromURL(latestURL)
+	if errors.Is(err, errGitHubNotFound) {
+		tagsURL := strings.TrimSuffix(latestURL, "/releases/latest") + "/git/refs/tags"
+		log.Printf("No latest release at %s, trying tags...", latestURL)
+		version, err = ac.getLatestTagFromURL(tagsURL)
+	}
+	return version, err
+}
+
+// getLatestVersionFromURL получает последнюю версию по конкретному URL
+func (ac *AppController) getLatestVersionFromURL(url string) (string, error) {
+	body, err := ac.getGitHubAPI(url)
+	if err != nil {
+		return "", err
 	}
 
 	var release struct {
@@ -147,9 +210,111 @@ func (ac *AppController) getLatestVersionFromURL(url string) (string, error) {
 	return version, nil
 }
 
-// GetCoreVersionInfo получает полную информацию о версии
+// tagVersionPattern matches sing-box tag versions: 1.12.0, 1.13.0-alpha.2, ...
+var tagVersionPattern = regexp.MustCompile(`^(\d+\.\d+\.\d+)(-.+)?$`)
+
+// getLatestTagFromURL returns the newest version among the tags listed by /git/refs/tags.
+// The API sorts refs by name, so the versions are compared rather than taking the last one.
+func (ac *AppController) getLatestTagFromURL(url string) (string, error) {
+	body, err := ac.getGitHubAPI(url)
+	if errors.Is(err, errGitHubNotFound) {
+		return "", fmt.Errorf("no tags found")
+	}
+	if err != nil {
+		return "", err
+	}
+
+	var refs []struct {
+		Ref string `json:"ref"`
+	}
+	if err := json.Unmarshal(body, &refs); err != nil {
+		return "", fmt.Errorf("failed to parse tags: %w", err)
+	}
+
+	latest := ""
+	for _, ref := range refs {
+		version := strings.TrimPrefix(strings.TrimPrefix(ref.Ref, "refs/tags/"), "v")
+		if !tagVersionPattern.MatchString(version) {
+			continue
+		}
+		if latest == "" || compareTagVersions(version, latest) > 0 {
+			latest = version
+		}
+	}
+	if latest == "" {
+		return "", fmt.Errorf("no version tags found")
+	}
+	return latest, nil
+}
+
+// compareTagVersions compares X.Y.Z[-pre] versions; a release is newer than its pre-releases
+func compareTagVersions(v1, v2 string) int {
+	m1, m2 := tagVersionPattern.FindStringSubmatch(v1), tagVersionPattern.FindStringSubmatch(v2)
+	if c := CompareVersions(m1[1], m2[1]); c != 0 {
+		return c
+	}
+	switch {
+	case m1[2] == m2[2]:
+		return 0
+	case m1[2] == "":
+		return 1
+	case m2[2] == "":
+		return -1
+	default:
+		return comparePrerelease(m1[2][1:], m2[2][1:])
+	}
+}
+
+// comparePrerelease compares pre-release suffixes (without "-") as semver does: dot-separated
+// identifiers one by one, numeric ones numerically (alpha.9 < alpha.10) and lower than
+// alphanumeric ones; when all shared identifiers are equal, the shorter suffix is older.
+func comparePrerelease(p1, p2 string) int {
+	ids1, ids2 := strings.Split(p1, "."), strings.Split(p2, ".")
+	for i := 0; i < len(ids1) && i < len(ids2); i++ {
+		n1, err1 := strconv.ParseUint(ids1[i], 10, 64)
+		n2, err2 := strconv.ParseUint(ids2[i], 10, 64)
+		switch {
+		case err1 == nil && err2 == nil:
+			if n1 != n2 {
+				if n1 < n2 {
+					return -1
+				}
+				return 1
+			}
+		case err1 == nil:
+			return -1
+		case err2 == nil:
+			return 1
+		default:
+			if c := strings.Compare(ids1[i], ids2[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	switch {
+	case len(ids1) < len(ids2):
+		return -1
+	case len(ids1) > len(ids2):
+		return 1
+	}
+	return 0
+}
+
+// GetCoreVersionInfo returns the installed and latest sing-box versions. Successful results are cached
+// for VersionCacheTTL so repeated calls from the UI don't go to the network; DownloadCore expires the cache.
 func (ac *AppController) GetCoreVersionInfo() CoreVersionInfo {
-	info := CoreVersionInfo{}
+	ttl := ac.VersionCacheTTL
+	if ttl <= 0 {
+		ttl = defaultVersionCacheTTL
+	}
+	ac.versionInfoMutex.Lock()
+	if cached := ac.versionInfo; cached != nil && time.Since(cached.CheckedAt) < ttl {
+		ac.versionInfoMutex.Unlock()
+		return *cached
+	}
+	ac.versionInfoMutex.Unlock()
+
+	info := CoreVersionInfo{CheckedAt: time.Now()}
 
 	// Получаем установленную версию
 	installed, err := ac.GetInstalledCoreVersion()
@@ -161,25 +326,58 @@ func (ac *AppController) GetCoreVersionInfo() CoreVersionInfo {
 
 	// Получаем последнюю версию
 	latest, err := ac.GetLatestCoreVersion()
+	if errors.Is(err, ErrUsingFallbackVersion) {
+		// Версия для скачивания есть, но неизвестно, новее ли она; не кэшируем
+		info.LatestVersion = latest
+		return info
+	}
 	if err != nil {
 		// Не критично, если не удалось получить последнюю версию
 		log.Printf("GetCoreVersionInfo: failed to get latest version: %v", err)
-		info.LatestVersion = ""
 		return info
 	}
 	info.LatestVersion = latest
 
 	// Сравниваем версии
-	info.UpdateAvailable = compareVersions(installed, latest) < 0
+	info.UpdateAvailable = CompareVersions(installed, latest) < 0
+
+	ctx, cancel := context.WithTimeout(context.Background(), NetworkRequestTimeout)
+	defer cancel()
+	if release, err := ac.getReleaseInfo(ctx, latest); err != nil {
+		log.Printf("GetCoreVersionInfo: failed to get release info: %v", err)
+	} else if asset, err := ac.findPlatformAsset(release); err != nil {
+		log.Printf("GetCoreVersionInfo: %v", err)
+	} else {
+		info.DownloadURL = asset.BrowserDownloadURL
+	}
 
+	ac.versionInfoMutex.Lock()
+	ac.versionInfo = &info
+	ac.versionInfoMutex.Unlock()
 	return info
 }
 
-// compareVersions сравнивает две версии (формат X.Y.Z)
+// InvalidateCoreVersionInfo drops the cached GetCoreVersionInfo result, e.g. after a new sing-box is installed
+func (ac *AppController) InvalidateCoreVersionInfo() {
+	ac.versionInfoMutex.Lock()
+	ac.versionInfo = nil
+	ac.versionInfoMutex.Unlock()
+}
+
+// StripVersionPrefix убирает префикс "v"/"V" из тега релиза (v1.10.3 -> 1.10.3)
+func StripVersionPrefix(v string) string {
+	v = strings.TrimSpace(v)
+	if strings.HasPrefix(v, "v") || strings.HasPrefix(v, "V") {
+		return v[1:]
+	}
+	return v
+}
+
+// CompareVersions сравнивает две версии (формат X.Y.Z, допускается префикс "v")
 // Возвращает: -1 если v1 < v2, 0 если v1 == v2, 1 если v1 > v2
-func compareVersions(v1, v2 string) int {
-	parts1 := strings.Split(v1, ".")
-	parts2 := strings.Split(v2, ".")
+func CompareVersions(v1, v2 string) int {
+	parts1 := strings.Split(StripVersionPrefix(v1), ".")
+	parts2 := strings.Split(StripVersionPrefix(v2), ".")
 
 	maxLen := len(parts1)
 	if len(parts2) > maxLen {
@@ -205,3 +403,46 @@ func compareVersions(v1, v2 string) int {
 
 	return 0
 }
+
+// normalizeBlockedVersion strips the "v" prefix so "v1.12.0" and "1.12.0" match
+func normalizeBlockedVersion(version string) string {
+	return strings.TrimPrefix(strings.TrimSpace(version), "v")
+}
+
+// IsCoreVersionBlocked reports whether the user blocked updating to this sing-box version
+func (ac *AppController) IsCoreVersionBlocked(version string) bool {
+	version = normalizeBlockedVersion(version)
+	for _, blocked := range ac.LoadPreferences().BlockedVersions {
+		if normalizeBlockedVersion(blocked) == version {
+			return true
+		}
+	}
+	return false
+}
+
+// BlockCoreVersion adds a sing-box version to the blocked list in preferences.json
+func (ac *AppController) BlockCoreVersion(version string) error {
+	version = normalizeBlockedVersion(version)
+	if version == "" || ac.IsCoreVersionBlocked(version) {
+		return nil
+	}
+	log.Printf("BlockCoreVersion: Blocking sing-box v%s", version)
+	return ac.UpdatePreferences(func(p *Preferences) {
+		p.BlockedVersions = append(p.BlockedVersions, version)
+	})
+}
+
+// UnblockCoreVersion removes a sing-box version from the blocked list in preferences.json
+func (ac *AppController) UnblockCoreVersion(version string) error {
+	version = normalizeBlockedVersion(version)
+	log.Printf("UnblockCoreVersion: Unblocking sing-box v%s", version)
+	return ac.UpdatePreferences(func(p *Preferences) {
+		kept := p.BlockedVersions[:0]
+		for _, blocked := range p.BlockedVersions {
+			if normalizeBlockedVersion(blocked) != version {
+				kept = append(kept, blocked)
+			}
+		}
+		p.BlockedVersions = kept
+	})
+}
@@ -0,0 +1,118 @@
+package core
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		v1, v2 string
+		want   int
+	}{
+		{"1.10.3", "1.9.9", 1},
+		{"1.9.9", "1.10.3", -1},
+		{"1.12.12", "1.12.12", 0},
+		{"v1.12.0", "1.12.0", 0},
+		{"V1.12.1", "v1.12.0", 1},
+		{" 1.12.0 ", "1.12.0", 0},
+		{"1.12", "1.12.0", 0},
+		{"1.12", "1.12.1", -1},
+		{"1.13.0", "1.12.99", 1},
+		{"2.0.0", "1.99.99", 1},
+	}
+	for _, tt := range tests {
+		if got := CompareVersions(tt.v1, tt.v2); got != tt.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", tt.v1, tt.v2, got, tt.want)
+		}
+	}
+}
+
+func TestStripVersionPrefix(t *testing.T) {
+	tests := map[string]string{
+		"v1.12.0":   "1.12.0",
+		"V1.12.0":   "1.12.0",
+		"1.12.0":    "1.12.0",
+		" v1.12.0 ": "1.12.0",
+		"":          "",
+	}
+	for in, want := range tests {
+		if got := StripVersionPrefix(in); got != want {
+			t.Errorf("StripVersionPrefix(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestCompareTagVersions(t *testing.T) {
+	tests := []struct {
+		v1, v2 string
+		want   int
+	}{
+		{"1.13.0-alpha.10", "1.13.0-alpha.9", 1},
+		{"1.13.0-alpha.9", "1.13.0-alpha.10", -1},
+		{"1.13.0-alpha.2", "1.13.0-alpha.2", 0},
+		{"1.13.0-beta.1", "1.13.0-alpha.12", 1},
+		{"1.13.0-rc.1", "1.13.0-beta.3", 1},
+		{"1.13.0", "1.13.0-rc.1", 1},
+		{"1.13.0-rc.1", "1.13.0", -1},
+		{"1.13.0-alpha", "1.13.0-alpha.1", -1},
+		{"1.13.0-alpha.1", "1.13.0-alpha.beta", -1},
+		{"1.12.10", "1.12.9", 1},
+		{"1.12.12", "1.13.0-alpha.1", -1},
+	}
+	for _, tt := range tests {
+		if got := compareTagVersions(tt.v1, tt.v2); got != tt.want {
+			t.Errorf("compareTagVersions(%q, %q) = %d, want %d", tt.v1, tt.v2, got, tt.want)
+		}
+	}
+}
+
+func TestGetLatestVersionFromSourceTagFallback(t *testing.T) {
+	tags := []string{"v1.12.0", "v1.13.0-alpha.9", "v1.13.0-alpha.10", "v1.13.0-alpha.2", "not-a-version", "v1.12.12"}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/SagerNet/sing-box/releases/latest", func(w http.ResponseWriter, r *http.Request) {
+		// No release yet, only pre-release tags
+		http.NotFound(w, r)
+	})
+	mux.HandleFunc("/repos/SagerNet/sing-box/git/refs/tags", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "[")
+		for i, tag := range tags {
+			if i > 0 {
+				fmt.Fprint(w, ",")
+			}
+			fmt.Fprintf(w, `{"ref": "refs/tags/%s"}`, tag)
+		}
+		fmt.Fprint(w, "]")
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	ac := &AppController{execDir: t.TempDir()}
+	version, err := ac.getLatestVersionFromSource(server.URL + "/repos/SagerNet/sing-box/releases/latest")
+	if err != nil {
+		t.Fatalf("getLatestVersionFromSource() error = %v", err)
+	}
+	if version != "1.13.0-alpha.10" {
+		t.Errorf("getLatestVersionFromSource() = %q, want %q", version, "1.13.0-alpha.10")
+	}
+}
+
+func TestGetLatestVersionFromSourceRelease(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/releases/latest" {
+			t.Errorf("unexpected request %s", r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{"tag_name": "v1.12.12"}`)
+	}))
+	defer server.Close()
+
+	ac := &AppController{execDir: t.TempDir()}
+	version, err := ac.getLatestVersionFromSource(server.URL + "/releases/latest")
+	if err != nil || version != "1.12.12" {
+		t.Errorf("getLatestVersionFromSource() = %q, %v, want 1.12.12", version, err)
+	}
+}
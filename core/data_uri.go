@@ -0,0 +1,86 @@
+package core
+
+import (
+	"fmt"
+	"mime"
+	"net/url"
+	"strings"
+)
+
+// supportedDataURITypes are the MIME types accepted in data: subscription sources
+var supportedDataURITypes = map[string]bool{
+	"text/plain":       true,
+	"application/json": true,
+}
+
+// IsDataURI reports whether a subscription source is an inline data: URI
+func IsDataURI(rawURL string) bool {
+	return strings.HasPrefix(strings.ToLower(strings.TrimSpace(rawURL)), "data:")
+}
+
+// fetchDataURI decodes an inline subscription: data:[<mime>][;base64],<payload>.
+// The payload is then decoded like downloaded subscription content.
+func fetchDataURI(rawURL string) ([]byte, error) {
+	payload, err := DecodeDataURI(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if len(payload) == 0 {
+		return nil, fmt.Errorf("data URI has empty content")
+	}
+	decoded, err := DecodeSubscriptionContent(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode subscription content: %w", err)
+	}
+	return decoded, nil
+}
+
+// DecodeDataURI returns the payload of a data: URI (RFC 2397).
+// Only text/plain (the default when the type is omitted) and application/json are accepted.
+func DecodeDataURI(rawURL string) ([]byte, error) {
+	rawURL = strings.TrimSpace(rawURL)
+	if !IsDataURI(rawURL) {
+		return nil, fmt.Errorf("not a data URI")
+	}
+	header, payload, found := strings.Cut(rawURL[len("data:"):], ",")
+	if !found {
+		return nil, fmt.Errorf("invalid data URI: missing ','")
+	}
+
+	isBase64 := false
+	if strings.HasSuffix(strings.ToLower(header), ";base64") {
+		isBase64 = true
+		header = header[:len(header)-len(";base64")]
+	}
+	mediaType := "text/plain"
+	if header != "" && !strings.HasPrefix(header, ";") {
+		parsed, _, err := mime.ParseMediaType(header)
+		if err != nil {
+			return nil, fmt.Errorf("invalid data URI media type %q: %w", header, err)
+		}
+		mediaType = parsed
+	}
+	if !supportedDataURITypes[mediaType] {
+		return nil, fmt.Errorf("unsupported data URI media type %q (use text/plain or application/json)", mediaType)
+	}
+
+	if isBase64 {
+		// Payload may be percent-encoded too, e.g. '+' and '=' escaped by automation tools
+		unescaped, err := url.PathUnescape(payload)
+		if err != nil {
+			return nil, fmt.Errorf("invalid data URI payload: %w", err)
+		}
+		unescaped = strings.TrimSpace(unescaped)
+		decoded, err := DetectBase64Encoding(unescaped).DecodeString(unescaped)
+		if err != nil {
+			return nil, fmt.Errorf("invalid base64 in data URI: %w", err)
+		}
+		return decoded, nil
+	}
+
+	decoded, err := url.PathUnescape(payload)
+	if err != nil {
+		return nil, fmt.Errorf("invalid data URI payload: %w", err)
+	}
+	return []byte(decoded), nil
+}
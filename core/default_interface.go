@@ -0,0 +1,181 @@
+package core
+
+import (
+	"context"
+	"log"
+	"net"
+	"regexp"
+	"sort"
+	"time"
+)
+
+const (
+	// interfacePollInterval - how often WatchDefaultInterface checks the default interface when
+	// the system gives no route change notifications
+	interfacePollInterval = 10 * time.Second
+	// interfaceSettleDelay - route changes come in bursts (address, routes, DNS), the default
+	// interface is checked once they settle
+	interfaceSettleDelay = time.Second
+)
+
+// singboxTunNamePattern matches the names sing-box gives its TUN interface when the tun inbound
+// has no interface_name: tun0, tun1 ... (utun0 ... on macOS)
+var singboxTunNamePattern = regexp.MustCompile(`^u?tun\d+$`)
+
+// InterfaceChange is a change of the interface of the default route, e.g. from Wi-Fi to Ethernet.
+// An empty name means there is no default route (offline).
+type InterfaceChange struct {
+	Previous string
+	Current  string
+}
+
+// defaultRoute is a default route (0.0.0.0/0) of the routing table, see defaultRoutes
+type defaultRoute struct {
+	Interface string
+	Metric    int
+}
+
+// DefaultInterface returns the name of the interface of the default route with the lowest metric.
+// The TUN of sing-box is skipped: it takes the default route while sing-box runs with auto_route.
+// exclude are the TUN names set in config.json, besides the default ones (tun0, utun0 ...).
+// "" if there is no default route.
+func DefaultInterface(exclude ...string) (string, error) {
+	routes, err := defaultRoutes()
+	if err != nil {
+		return "", err
+	}
+	sort.SliceStable(routes, func(i, j int) bool { return routes[i].Metric < routes[j].Metric })
+	for _, route := range routes {
+		if !isSingBoxTunInterface(route.Interface, exclude) {
+			return route.Interface, nil
+		}
+	}
+	return "", nil
+}
+
+// WatchDefaultInterface sends a change to ch whenever the interface of the default route changes.
+// It uses route change notifications of the system (netlink on Linux, the routing socket on macOS,
+// NotifyRouteChange2 on Windows) and falls back to polling every interfacePollInterval.
+// exclude is passed to DefaultInterface. Runs until ctx is cancelled and returns ctx.Err().
+func WatchDefaultInterface(ctx context.Context, ch chan<- InterfaceChange, exclude ...string) error {
+	changed := make(chan struct{}, 1)
+	notify := func() {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	}
+	var poll <-chan time.Time
+	if err := startRouteWatch(ctx, notify); err != nil {
+		log.Printf("WatchDefaultInterface: Route change notifications unavailable, polling every %v: %v", interfacePollInterval, err)
+		ticker := time.NewTicker(interfacePollInterval)
+		defer ticker.Stop()
+		poll = ticker.C
+	}
+
+	current, err := DefaultInterface(exclude...)
+	if err != nil {
+		log.Printf("WatchDefaultInterface: %v", err)
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changed:
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(interfaceSettleDelay):
+			}
+			// Notifications received while settling are covered by this check
+			select {
+			case <-changed:
+			default:
+			}
+		case <-poll:
+		}
+
+		next, err := DefaultInterface(exclude...)
+		if err != nil {
+			log.Printf("WatchDefaultInterface: %v", err)
+			continue
+		}
+		if next == current {
+			continue
+		}
+		select {
+		case ch <- InterfaceChange{Previous: current, Current: next}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		current = next
+	}
+}
+
+// StartInterfaceWatcher publishes InterfaceChanged when the default network interface changes
+// (e.g. Wi-Fi to Ethernet), until the application exits
+func (ac *AppController) StartInterfaceWatcher() {
+	ctx, cancel := context.WithCancel(context.Background())
+	ac.OnShutdown(cancel)
+
+	changes := make(chan InterfaceChange)
+	ac.GoBackground(func() {
+		if err := WatchDefaultInterface(ctx, changes, ac.tunInterfaceNames()...); err != nil && ctx.Err() == nil {
+			log.Printf("StartInterfaceWatcher: %v", err)
+		}
+	})
+	ac.GoBackground(func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case change := <-changes:
+				log.Printf("StartInterfaceWatcher: Default interface changed: %q -> %q", change.Previous, change.Current)
+				ac.Events.Publish(InterfaceChanged(change))
+			}
+		}
+	})
+}
+
+// tunInterfaceNames returns the interface_name of the tun inbounds of config.json
+func (ac *AppController) tunInterfaceNames() []string {
+	jsonData, err := loadConfigJSON(ac.ConfigPath())
+	if err != nil {
+		return nil
+	}
+	var names []string
+	inbounds, _ := jsonData["inbounds"].([]interface{})
+	for _, inbound := range inbounds {
+		inboundMap, ok := inbound.(map[string]interface{})
+		if !ok || inboundMap["type"] != "tun" {
+			continue
+		}
+		if name, ok := inboundMap["interface_name"].(string); ok && name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// isSingBoxTunInterface reports whether name is one of configured or a default sing-box TUN name
+func isSingBoxTunInterface(name string, configured []string) bool {
+	return name != "" && (containsInterface(configured, name) || singboxTunNamePattern.MatchString(name))
+}
+
+func containsInterface(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// interfaceNameByIndex returns the name of the network interface with the given index
+func interfaceNameByIndex(index int) string {
+	iface, err := net.InterfaceByIndex(index)
+	if err != nil {
+		return ""
+	}
+	return iface.Name
+}
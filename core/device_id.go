@@ -0,0 +1,106 @@
+package core
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	"singbox-launcher/internal/constants"
+	"singbox-launcher/internal/platform"
+)
+
+// deviceIDState caches the ID returned by GetDeviceID
+type deviceIDState struct {
+	mutex sync.Mutex
+	id    string
+}
+
+// GetDeviceID returns an anonymous ID of this device, so maintainers can tell reports from the
+// same device apart. On Windows it is derived from the MachineGuid registry value (stable across
+// reinstalls); otherwise a random UUID v4 is generated once and kept in data/device_id.txt.
+// The ID is only sent when the user opts in (Preferences.ShareDeviceID) and never to subscriptions.
+func (ac *AppController) GetDeviceID() (string, error) {
+	ac.deviceID.mutex.Lock()
+	defer ac.deviceID.mutex.Unlock()
+	if ac.deviceID.id != "" {
+		return ac.deviceID.id, nil
+	}
+
+	if runtime.GOOS == "windows" {
+		if guid, err := platform.GetMachineID(); err == nil && guid != "" {
+			// The MachineGuid itself is not sent, only a hash of it
+			sum := sha256.Sum256([]byte("singbox-launcher/device-id/" + guid))
+			ac.deviceID.id = formatUUID(sum[:16])
+			return ac.deviceID.id, nil
+		} else if err != nil {
+			log.Printf("GetDeviceID: Failed to read MachineGuid, using %s: %v", constants.DeviceIDName, err)
+		}
+	}
+
+	path := filepath.Join(ac.ExecDir(), constants.DataDirName, constants.DeviceIDName)
+	if data, err := os.ReadFile(path); err == nil {
+		if id := strings.TrimSpace(string(data)); id != "" {
+			ac.deviceID.id = id
+			return id, nil
+		}
+	} else if !os.IsNotExist(err) {
+		return "", fmt.Errorf("GetDeviceID: %w", err)
+	}
+
+	id, err := newUUIDv4()
+	if err != nil {
+		return "", fmt.Errorf("GetDeviceID: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("GetDeviceID: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(id+"\n"), 0644); err != nil {
+		return "", fmt.Errorf("GetDeviceID: %w", err)
+	}
+	ac.deviceID.id = id
+	return id, nil
+}
+
+// newUUIDv4 generates a random UUID (version 4)
+func newUUIDv4() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40 // version 4
+	b[8] = b[8]&0x3f | 0x80 // RFC 4122 variant
+	return formatUUID(b[:]), nil
+}
+
+// formatUUID formats 16 bytes as xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx
+func formatUUID(b []byte) string {
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// SharedDeviceID returns GetDeviceID if the user opted in to share it ("" otherwise)
+func (ac *AppController) SharedDeviceID() string {
+	if !ac.LoadPreferences().ShareDeviceID {
+		return ""
+	}
+	id, err := ac.GetDeviceID()
+	if err != nil {
+		log.Printf("SharedDeviceID: %v", err)
+		return ""
+	}
+	return id
+}
+
+// launcherUserAgent is UserAgent with the device ID appended if the user opted in to share it.
+// Used for GitHub API requests only; subscriptions, mirrors and file downloads get the plain UserAgent.
+func (ac *AppController) launcherUserAgent() string {
+	if id := ac.SharedDeviceID(); id != "" {
+		return UserAgent() + " device/" + id
+	}
+	return UserAgent()
+}
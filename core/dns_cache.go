@@ -0,0 +1,39 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"time"
+
+	"singbox-launcher/internal/platform"
+)
+
+// dnsFlushTimeout limits the DNS cache flush as a whole, so a hanging command can't delay exit
+const dnsFlushTimeout = 2 * time.Second
+
+// FlushDNSCache clears the system DNS cache after sing-box stopped, so names resolved through
+// the tunnel are not reused: ipconfig /flushdns on Windows, dscacheutil -flushcache on macOS.
+// Restarting mDNSResponder (killall -HUP) would need root, so it is not done.
+// Does nothing on other systems.
+func FlushDNSCache() error {
+	var args []string
+	switch runtime.GOOS {
+	case "windows":
+		args = []string{"ipconfig", "/flushdns"}
+	case "darwin":
+		args = []string{"dscacheutil", "-flushcache"}
+	default:
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), dnsFlushTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	platform.PrepareCommand(cmd) // Hide console window
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("FlushDNSCache: %s: %w (%s)", args[0], err, string(output))
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package core
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/driver/desktop"
+)
+
+// Resources reported in DownloadStarted and DownloadFinished
+const (
+	DownloadResourceCore   = "sing-box"
+	DownloadResourceWintun = "wintun.dll"
+)
+
+// trayDownloadBlinkInterval - how often the tray icon blinks while a download runs
+const trayDownloadBlinkInterval = 500 * time.Millisecond
+
+// downloadState tracks running downloads and the tray animation shown for them
+type downloadState struct {
+	mutex     sync.Mutex
+	active    map[string]*activeDownload // by resource
+	stopBlink chan struct{}              // closes to stop the tray animation, nil while it is not running
+	blinkDone chan struct{}              // closed by the tray animation when it has stopped
+}
+
+// activeDownload is a download between DownloadStarted and DownloadFinished
+type activeDownload struct {
+	startedAt time.Time
+	bytes     int64 // size of the downloaded archive, see setDownloadedBytes
+}
+
+// IsDownloadInProgress reports whether resource (DownloadResourceCore, DownloadResourceWintun)
+// is being downloaded; an empty resource asks about any download
+func (ac *AppController) IsDownloadInProgress(resource string) bool {
+	ac.downloads.mutex.Lock()
+	defer ac.downloads.mutex.Unlock()
+	if resource == "" {
+		return len(ac.downloads.active) > 0
+	}
+	_, ok := ac.downloads.active[resource]
+	return ok
+}
+
+// trackDownload marks resource as being downloaded and publishes DownloadStarted. Downloads write
+// to the same bin/ directory, so it fails with errAnotherDownload while any other one is running.
+// Progress sent to the returned channel is forwarded to progressChan; finish publishes
+// DownloadFinished with the error of the last "error" update, records the download in the
+// download history and must be called (deferred) before progressChan is closed.
+func (ac *AppController) trackDownload(resource, version string, progressChan chan DownloadProgress) (tracked chan DownloadProgress, finish func(), err error) {
+	ac.downloads.mutex.Lock()
+	if len(ac.downloads.active) > 0 {
+		ac.downloads.mutex.Unlock()
+		return nil, nil, errAnotherDownload
+	}
+	if ac.downloads.active == nil {
+		ac.downloads.active = make(map[string]*activeDownload)
+	}
+	download := &activeDownload{startedAt: time.Now()}
+	ac.downloads.active[resource] = download
+	ac.downloads.mutex.Unlock()
+	ac.Events.Publish(DownloadStarted{Resource: resource, Version: version})
+
+	tracked = make(chan DownloadProgress)
+	done := make(chan struct{})
+	var lastErr error
+	go func() {
+		defer close(done)
+		for progress := range tracked {
+			if progress.Status == "error" {
+				lastErr = progress.Error
+			}
+			progressChan <- progress
+		}
+	}()
+
+	return tracked, func() {
+		close(tracked)
+		<-done
+		ac.downloads.mutex.Lock()
+		delete(ac.downloads.active, resource)
+		bytes := download.bytes
+		ac.downloads.mutex.Unlock()
+		ac.appendDownloadHistory(DownloadHistoryEntry{
+			Timestamp:       download.startedAt,
+			Resource:        resource,
+			Version:         version,
+			Success:         lastErr == nil,
+			DurationSeconds: time.Since(download.startedAt).Seconds(),
+			BytesDownloaded: bytes,
+		})
+		ac.Events.Publish(DownloadFinished{Resource: resource, Version: version, Err: lastErr})
+	}, nil
+}
+
+// setDownloadedBytes records the size of the file downloaded for resource in its history entry
+func (ac *AppController) setDownloadedBytes(resource, path string) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+	ac.downloads.mutex.Lock()
+	defer ac.downloads.mutex.Unlock()
+	if download, ok := ac.downloads.active[resource]; ok {
+		download.bytes = info.Size()
+	}
+}
+
+// animateTrayDuringDownload blinks the tray icon between the state icon and the application icon
+// from DownloadStarted to DownloadFinished
+func (ac *AppController) animateTrayDuringDownload(event Event) {
+	desk, ok := ac.Application.(desktop.App)
+	if !ok {
+		return
+	}
+	switch event.(type) {
+	case DownloadStarted:
+		ac.downloads.mutex.Lock()
+		defer ac.downloads.mutex.Unlock()
+		if ac.downloads.stopBlink != nil {
+			return
+		}
+		stop, done := make(chan struct{}), make(chan struct{})
+		ac.downloads.stopBlink, ac.downloads.blinkDone = stop, done
+		go func() {
+			defer close(done)
+			// The binary is only replaced when the download finishes, which also stops the animation,
+			// so it is looked up once instead of on every tick
+			_, err := ac.FindSingboxBinary(true)
+			binaryFound := err == nil
+			ticker := time.NewTicker(trayDownloadBlinkInterval)
+			defer ticker.Stop()
+			blink := false
+			for {
+				select {
+				case <-ticker.C:
+					blink = !blink
+					icon := ac.AppIconData
+					if !blink {
+						icon = ac.trayStateIconFor(binaryFound)
+					}
+					fyne.Do(func() { desk.SetSystemTrayIcon(icon) })
+				case <-stop:
+					return
+				}
+			}
+		}()
+	case DownloadFinished:
+		ac.downloads.mutex.Lock()
+		if ac.downloads.stopBlink == nil || len(ac.downloads.active) > 0 {
+			ac.downloads.mutex.Unlock()
+			return
+		}
+		close(ac.downloads.stopBlink)
+		done := ac.downloads.blinkDone
+		ac.downloads.stopBlink, ac.downloads.blinkDone = nil, nil
+		ac.downloads.mutex.Unlock()
+		// A tick can still be setting the blink icon, wait so it can't override the state icon
+		<-done
+		ac.UpdateUI() // back to the state icon
+	}
+}
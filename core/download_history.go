@@ -0,0 +1,81 @@
+package core
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"singbox-launcher/internal/constants"
+)
+
+// maxDownloadHistoryEntries - how many downloads data/download_history.json keeps (oldest are dropped)
+const maxDownloadHistoryEntries = 100
+
+// DownloadHistoryEntry is one DownloadCore or DownloadWintunDLL call, see LoadDownloadHistory
+type DownloadHistoryEntry struct {
+	Timestamp       time.Time `json:"timestamp"` // when the download started
+	Resource        string    `json:"resource"`  // DownloadResourceCore or DownloadResourceWintun
+	Version         string    `json:"version"`
+	Success         bool      `json:"success"`
+	DurationSeconds float64   `json:"duration_seconds"`
+	BytesDownloaded int64     `json:"bytes_downloaded"` // size of the downloaded archive, 0 if it failed before
+}
+
+// downloadHistoryMutex serializes read-modify-write of download_history.json
+var downloadHistoryMutex sync.Mutex
+
+// getDownloadHistoryPath returns the path to data/download_history.json
+func (ac *AppController) getDownloadHistoryPath() string {
+	return filepath.Join(ac.ExecDir(), constants.DataDirName, constants.DownloadHistoryName)
+}
+
+// LoadDownloadHistory returns the recorded downloads, oldest first
+func (ac *AppController) LoadDownloadHistory() []DownloadHistoryEntry {
+	downloadHistoryMutex.Lock()
+	defer downloadHistoryMutex.Unlock()
+	return ac.readDownloadHistory()
+}
+
+// appendDownloadHistory records a finished download, keeping the last maxDownloadHistoryEntries
+func (ac *AppController) appendDownloadHistory(entry DownloadHistoryEntry) {
+	downloadHistoryMutex.Lock()
+	defer downloadHistoryMutex.Unlock()
+
+	history := append(ac.readDownloadHistory(), entry)
+	if len(history) > maxDownloadHistoryEntries {
+		history = history[len(history)-maxDownloadHistoryEntries:]
+	}
+	data, err := json.MarshalIndent(history, "", "  ")
+	if err != nil {
+		log.Printf("appendDownloadHistory: Failed to encode history: %v", err)
+		return
+	}
+	path := ac.getDownloadHistoryPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		log.Printf("appendDownloadHistory: Failed to create data directory: %v", err)
+		return
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		log.Printf("appendDownloadHistory: Failed to write history: %v", err)
+	}
+}
+
+// readDownloadHistory reads download_history.json without locking
+func (ac *AppController) readDownloadHistory() []DownloadHistoryEntry {
+	data, err := os.ReadFile(ac.getDownloadHistoryPath())
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("LoadDownloadHistory: Failed to read history: %v", err)
+		}
+		return nil
+	}
+	var history []DownloadHistoryEntry
+	if err := json.Unmarshal(data, &history); err != nil {
+		log.Printf("LoadDownloadHistory: Failed to parse history, ignoring: %v", err)
+		return nil
+	}
+	return history
+}
@@ -0,0 +1,50 @@
+package core
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"singbox-launcher/internal/platform"
+)
+
+// WithDownloadHookCommands runs shell commands around a sing-box download (the --pre-download-hook
+// and --post-download-hook flags), see PreDownloadHook and PostDownloadHook. Empty commands are skipped.
+func WithDownloadHookCommands(preCommand, postCommand string) ControllerOption {
+	return func(ac *AppController) {
+		if preCommand != "" {
+			ac.PreDownloadHook = func(version string) error {
+				return runDownloadHookCommand(preCommand, version, "")
+			}
+		}
+		if postCommand != "" {
+			ac.PostDownloadHook = func(version, path string) error {
+				return runDownloadHookCommand(postCommand, version, path)
+			}
+		}
+	}
+}
+
+// runDownloadHookCommand runs command with the system shell. The version (and the installed
+// binary path) are passed in SINGBOX_VERSION and SINGBOX_PATH; a non-zero exit code is an error.
+func runDownloadHookCommand(command, version, path string) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("sh", "-c", command)
+	}
+	platform.PrepareCommand(cmd)
+	cmd.Env = append(os.Environ(), "SINGBOX_VERSION="+version, "SINGBOX_PATH="+path)
+	output, err := cmd.CombinedOutput()
+	if len(output) > 0 {
+		log.Printf("runDownloadHookCommand: %s: %s", command, strings.TrimSpace(string(output)))
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", command, err)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// EmbeddedUIDefaultPort is the port of the built-in web UI (next to the usual Clash API port 9090)
+const EmbeddedUIDefaultPort = 9095
+
+// embeddedUIServer is the HTTP server started by StartEmbeddedUI
+type embeddedUIServer struct {
+	mutex  sync.Mutex
+	server *http.Server
+	port   int
+}
+
+// StartEmbeddedUI serves the built-in Yacd-meta web UI on 127.0.0.1:port.
+// Does nothing if it is already running on that port. The server stops when the application exits.
+func (ac *AppController) StartEmbeddedUI(port int) error {
+	ac.embeddedUI.mutex.Lock()
+	defer ac.embeddedUI.mutex.Unlock()
+	if ac.embeddedUI.server != nil {
+		if ac.embeddedUI.port == port {
+			return nil
+		}
+		ac.stopEmbeddedUILocked()
+	}
+
+	assets, err := embeddedUIAssets()
+	if err != nil {
+		return fmt.Errorf("StartEmbeddedUI: %w", err)
+	}
+	listener, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port)))
+	if err != nil {
+		return fmt.Errorf("StartEmbeddedUI: %w", err)
+	}
+
+	server := &http.Server{
+		Handler:           http.FileServer(http.FS(assets)),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("StartEmbeddedUI: %v", err)
+		}
+	}()
+	if ac.embeddedUI.port == 0 {
+		// First start: stop with the application
+		ac.OnShutdown(ac.StopEmbeddedUI)
+	}
+	ac.embeddedUI.server = server
+	ac.embeddedUI.port = port
+	log.Printf("StartEmbeddedUI: Serving web UI on http://127.0.0.1:%d", port)
+	return nil
+}
+
+// StopEmbeddedUI stops the web UI started by StartEmbeddedUI
+func (ac *AppController) StopEmbeddedUI() {
+	ac.embeddedUI.mutex.Lock()
+	defer ac.embeddedUI.mutex.Unlock()
+	ac.stopEmbeddedUILocked()
+}
+
+func (ac *AppController) stopEmbeddedUILocked() {
+	if ac.embeddedUI.server == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := ac.embeddedUI.server.Shutdown(ctx); err != nil {
+		log.Printf("StopEmbeddedUI: %v", err)
+	}
+	ac.embeddedUI.server = nil
+}
+
+// EmbeddedUIURL returns the address of the running web UI, with the Clash API address as query
+// parameters so Yacd connects to it. The secret is left out: URLs end up in the browser history
+// and in the command line of the browser process, so the user enters it in Yacd
+// (see ClashAPIToken). "" if the web UI is not running.
+func (ac *AppController) EmbeddedUIURL() string {
+	ac.embeddedUI.mutex.Lock()
+	running, port := ac.embeddedUI.server != nil, ac.embeddedUI.port
+	ac.embeddedUI.mutex.Unlock()
+	if !running {
+		return ""
+	}
+
+	uiURL := fmt.Sprintf("http://127.0.0.1:%d/", port)
+	apiURL, err := url.Parse(ac.ClashAPIBaseURL)
+	if err != nil || apiURL.Hostname() == "" {
+		return uiURL
+	}
+	query := url.Values{}
+	query.Set("hostname", apiURL.Hostname())
+	if apiPort := apiURL.Port(); apiPort != "" {
+		query.Set("port", apiPort)
+	}
+	return uiURL + "?" + query.Encode()
+}
@@ -0,0 +1,133 @@
+package core
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Event is a change of the controller state that UI components react to, see EventBus.
+// It is a StateEvent, one of the download events (DownloadStarted, DownloadFinished), InterfaceChanged
+// or ProxySwitched.
+type Event interface {
+	String() string
+}
+
+// StateEvent is an event without payload
+type StateEvent int
+
+const (
+	CoreStarted            StateEvent = iota // sing-box started (RunningState became true)
+	CoreStopped                              // sing-box stopped, crashed or failed to start
+	PreferencesReset                         // preferences and launcher state were deleted by ResetToDefaults
+	SubscriptionsRefreshed                   // at least one subscription was fetched, see SubscriptionStore.LastRefreshed
+	ConfigChanged                            // config.json changed on disk, see StartConfigWatcher
+)
+
+// String returns the event name for logs
+func (e StateEvent) String() string {
+	switch e {
+	case CoreStarted:
+		return "CoreStarted"
+	case CoreStopped:
+		return "CoreStopped"
+	case PreferencesReset:
+		return "PreferencesReset"
+	case SubscriptionsRefreshed:
+		return "SubscriptionsRefreshed"
+	case ConfigChanged:
+		return "ConfigChanged"
+	default:
+		return "Unknown"
+	}
+}
+
+// DownloadStarted is published when DownloadCore or DownloadWintunDLL starts downloading
+type DownloadStarted struct {
+	Resource string // DownloadResourceCore or DownloadResourceWintun
+	Version  string // version being downloaded
+}
+
+func (e DownloadStarted) String() string {
+	return fmt.Sprintf("DownloadStarted(%s %s)", e.Resource, e.Version)
+}
+
+// DownloadFinished is published when a download announced by DownloadStarted ends
+type DownloadFinished struct {
+	Resource string
+	Version  string
+	Err      error // nil if the download succeeded
+}
+
+func (e DownloadFinished) String() string {
+	if e.Err != nil {
+		return fmt.Sprintf("DownloadFinished(%s %s: %v)", e.Resource, e.Version, e.Err)
+	}
+	return fmt.Sprintf("DownloadFinished(%s %s)", e.Resource, e.Version)
+}
+
+// InterfaceChanged is published when the interface of the default route changes, see StartInterfaceWatcher
+type InterfaceChanged InterfaceChange
+
+func (e InterfaceChanged) String() string {
+	return fmt.Sprintf("InterfaceChanged(%q -> %q)", e.Previous, e.Current)
+}
+
+// ProxySwitched is published when AutoFallback switched a selector group away from a slow or
+// unreachable proxy, see StartAutoFallback
+type ProxySwitched struct {
+	Group     string
+	From      string
+	To        string
+	LatencyMs int // last measured latency of From, 0 if its test failed
+}
+
+func (e ProxySwitched) String() string {
+	return fmt.Sprintf("ProxySwitched(%s: %s -> %s)", e.Group, e.From, e.To)
+}
+
+// EventBus delivers controller events to any number of subscribers, so components don't have
+// to wrap each other's callbacks. The zero value is ready to use.
+type EventBus struct {
+	mutex       sync.Mutex
+	nextID      int
+	subscribers []eventSubscriber // in subscription order
+}
+
+// eventSubscriber is a function registered with EventBus.Subscribe
+type eventSubscriber struct {
+	id int
+	f  func(Event)
+}
+
+// Subscribe registers f to be called for every published event. f runs in the goroutine that
+// publishes the event (often not the UI one), so UI updates must go through fyne.Do.
+// Subscribers are called in the order they subscribed, e.g. the controller drops the data
+// cached from config.json on ConfigChanged before the UI reacts to it.
+// Returns a function that unregisters f.
+func (b *EventBus) Subscribe(f func(Event)) (unsubscribe func()) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	id := b.nextID
+	b.nextID++
+	b.subscribers = append(b.subscribers, eventSubscriber{id: id, f: f})
+	return func() {
+		b.mutex.Lock()
+		defer b.mutex.Unlock()
+		for i, sub := range b.subscribers {
+			if sub.id == id {
+				b.subscribers = append(b.subscribers[:i:i], b.subscribers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Publish calls all subscribers with event
+func (b *EventBus) Publish(event Event) {
+	b.mutex.Lock()
+	subscribers := b.subscribers
+	b.mutex.Unlock()
+	for _, sub := range subscribers {
+		sub.f(event)
+	}
+}
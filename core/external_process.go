@@ -0,0 +1,138 @@
+package core
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	ps "github.com/mitchellh/go-ps"
+
+	"singbox-launcher/internal/platform"
+)
+
+// externalProcessPollInterval - how often watchExternalSingBox looks for sing-box started by others
+const externalProcessPollInterval = 2 * time.Second
+
+// IsExternallyManaged reports whether the running sing-box was not started by this launcher
+// (e.g. by a system service or another launcher instance)
+func (ac *AppController) IsExternallyManaged() bool {
+	ac.CmdMutex.Lock()
+	defer ac.CmdMutex.Unlock()
+	return ac.externalPID != 0
+}
+
+// ownSingBoxRunning reports whether the sing-box started by this launcher is still running.
+// Must be called with CmdMutex held.
+func (ac *AppController) ownSingBoxRunning() bool {
+	if ac.SingboxCmd == nil || ac.SingboxCmd.Process == nil || ac.singboxExited == nil {
+		return false
+	}
+	select {
+	case <-ac.singboxExited:
+		return false
+	default:
+		return true
+	}
+}
+
+// findExternalSingBoxPID returns the PID of a running sing-box that the launcher neither started
+// nor recorded in the PID file (that one is handled by restartOrphanedSingBox), 0 if there is none
+func findExternalSingBoxPID(ac *AppController) int {
+	processes, err := ps.Processes()
+	if err != nil {
+		log.Printf("findExternalSingBoxPID: error listing processes: %v", err)
+		return 0
+	}
+	pidFilePID, _ := ac.ReadPIDFile()
+	ownPID := getOurPID(ac)
+	processName := platform.GetProcessNameForCheck()
+	for _, p := range processes {
+		if !strings.EqualFold(p.Executable(), processName) {
+			continue
+		}
+		if pid := p.Pid(); pid != ownPID && pid != pidFilePID {
+			return pid
+		}
+	}
+	return 0
+}
+
+// adoptExternalSingBox treats the sing-box process pid as running: the status shows
+// "Externally managed" and the Clash API tab works with it. The launcher does not restart it.
+func adoptExternalSingBox(ac *AppController, pid int) {
+	ac.CmdMutex.Lock()
+	if ac.ownSingBoxRunning() || ac.externalPID != 0 {
+		ac.CmdMutex.Unlock()
+		return
+	}
+	ac.externalPID = pid
+	ac.StartupFailed = false
+	ac.CmdMutex.Unlock()
+
+	log.Printf("adoptExternalSingBox: sing-box PID=%d was not started by the launcher, adopting it", pid)
+	ac.reloadClashAPISettings("adoptExternalSingBox")
+	ac.RunningState.SetRunning(true)
+}
+
+// releaseExternalSingBox forgets the adopted process after it exited
+func releaseExternalSingBox(ac *AppController, pid int) {
+	ac.CmdMutex.Lock()
+	if ac.externalPID != pid {
+		ac.CmdMutex.Unlock()
+		return
+	}
+	ac.externalPID = 0
+	ac.CmdMutex.Unlock()
+
+	log.Printf("releaseExternalSingBox: Externally managed sing-box PID=%d exited", pid)
+	ac.RunningState.SetRunning(false)
+}
+
+// stopExternalSingBox kills the adopted process on Stop. If a service manager starts it again,
+// watchExternalSingBox adopts the new process.
+func stopExternalSingBox(ac *AppController) {
+	ac.CmdMutex.Lock()
+	pid := ac.externalPID
+	ac.CmdMutex.Unlock()
+	if pid == 0 {
+		return
+	}
+	log.Printf("stopSingBox: Killing externally managed sing-box PID=%d", pid)
+	if err := platform.KillProcessByPID(pid); err != nil {
+		log.Printf("stopSingBox: Failed to kill PID=%d: %v", pid, err)
+		return
+	}
+	releaseExternalSingBox(ac, pid)
+}
+
+// watchExternalSingBox polls the process list every externalProcessPollInterval while the launcher
+// runs no sing-box of its own: a sing-box that appears (e.g. restarted by systemd after Stop) is
+// adopted, an adopted one that disappears sets RunningState to stopped
+func watchExternalSingBox(ac *AppController) {
+	ac.GoBackground(func() {
+		ticker := time.NewTicker(externalProcessPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ac.ShuttingDown():
+				return
+			case <-ticker.C:
+			}
+
+			ac.CmdMutex.Lock()
+			ownRunning, externalPID := ac.ownSingBoxRunning(), ac.externalPID
+			ac.CmdMutex.Unlock()
+			switch {
+			case ownRunning:
+			case externalPID != 0:
+				if !isSingBoxPID(externalPID) {
+					releaseExternalSingBox(ac, externalPID)
+				}
+			case !ac.RunningState.IsRunning():
+				if pid := findExternalSingBoxPID(ac); pid != 0 {
+					adoptExternalSingBox(ac, pid)
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,41 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+// preferencesSchemaVersion is written to preferences.json by MarkFirstRunComplete
+const preferencesSchemaVersion = 1
+
+// firstRunState caches the check done by IsFirstRun
+type firstRunState struct {
+	once     sync.Once
+	firstRun atomic.Bool
+}
+
+// IsFirstRun reports whether the launcher runs for the first time: preferences.json did not exist
+// when it was first asked. The file is checked once; MarkFirstRunComplete ends the first run.
+func (ac *AppController) IsFirstRun() bool {
+	ac.firstRun.once.Do(func() {
+		_, err := os.Stat(ac.getPreferencesPath())
+		ac.firstRun.firstRun.Store(os.IsNotExist(err))
+	})
+	return ac.firstRun.firstRun.Load()
+}
+
+// MarkFirstRunComplete records in preferences.json that the first setup (Config Wizard) is done
+func (ac *AppController) MarkFirstRunComplete() error {
+	ac.IsFirstRun() // check the file before it is created below
+	err := ac.UpdatePreferences(func(p *Preferences) {
+		p.SchemaVersion = preferencesSchemaVersion
+		p.FirstRunComplete = true
+	})
+	if err != nil {
+		return fmt.Errorf("MarkFirstRunComplete: %w", err)
+	}
+	ac.firstRun.firstRun.Store(false)
+	return nil
+}
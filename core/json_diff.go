@@ -0,0 +1,281 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SectionChange describes how one top-level section differs between two configs
+type SectionChange struct {
+	Section    string
+	ChangeType string // "added", "removed" or "modified"
+	Diff       string // unified diff of the pretty-printed section
+}
+
+// diffContextLines is the number of unchanged lines shown around each change
+const diffContextLines = 3
+
+// maxDiffEdits limits the line diff; sections differing more are shown as fully replaced
+const maxDiffEdits = 2000
+
+// JSONSectionDiff compares two configs (JSONC allowed) section by section. Sections are
+// returned in the order of the new config, followed by removed sections sorted by name.
+func JSONSectionDiff(old, new []byte) ([]SectionChange, error) {
+	oldSections, _, err := parseConfigSections(old)
+	if err != nil {
+		return nil, fmt.Errorf("JSONSectionDiff: old config: %w", err)
+	}
+	newSections, newOrder, err := parseConfigSections(new)
+	if err != nil {
+		return nil, fmt.Errorf("JSONSectionDiff: new config: %w", err)
+	}
+
+	var changes []SectionChange
+	for _, name := range newOrder {
+		newText := newSections[name]
+		oldText, existed := oldSections[name]
+		switch {
+		case !existed:
+			changes = append(changes, SectionChange{Section: name, ChangeType: "added", Diff: unifiedDiff(name, "", newText)})
+		case oldText != newText:
+			changes = append(changes, SectionChange{Section: name, ChangeType: "modified", Diff: unifiedDiff(name, oldText, newText)})
+		}
+	}
+
+	var removed []string
+	for name := range oldSections {
+		if _, ok := newSections[name]; !ok {
+			removed = append(removed, name)
+		}
+	}
+	sort.Strings(removed)
+	for _, name := range removed {
+		changes = append(changes, SectionChange{Section: name, ChangeType: "removed", Diff: unifiedDiff(name, oldSections[name], "")})
+	}
+	return changes, nil
+}
+
+// parseConfigSections returns the pretty-printed top-level sections of a config and their order
+func parseConfigSections(data []byte) (map[string]string, []string, error) {
+	cleaned := cleanJSONC(data)
+	var sections map[string]json.RawMessage
+	if err := json.Unmarshal(cleaned, &sections); err != nil {
+		return nil, nil, err
+	}
+
+	// json.Unmarshal into a map loses the order, read the keys with a decoder
+	var order []string
+	dec := json.NewDecoder(bytes.NewReader(cleaned))
+	if _, err := dec.Token(); err != nil {
+		return nil, nil, err
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, nil, err
+		}
+		order = append(order, tok.(string))
+		var skip json.RawMessage
+		if err := dec.Decode(&skip); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	pretty := make(map[string]string, len(sections))
+	for name, raw := range sections {
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, raw, "", "  "); err != nil {
+			return nil, nil, err
+		}
+		pretty[name] = buf.String()
+	}
+	return pretty, order, nil
+}
+
+// diffLine is one line of an edit script: ' ' unchanged, '-' removed, '+' added
+type diffLine struct {
+	op   byte
+	text string
+}
+
+// splitLines splits text into lines, an empty text has no lines
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
+
+// unifiedDiff returns a unified diff of two versions of a section
+func unifiedDiff(name, oldText, newText string) string {
+	lines := diffLines(splitLines(oldText), splitLines(newText))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- old/%s\n+++ new/%s\n", name, name)
+
+	// Line numbers (1-based) in the old and new text at each position of the script
+	oldNum := make([]int, len(lines)+1)
+	newNum := make([]int, len(lines)+1)
+	oldNum[0], newNum[0] = 1, 1
+	for i, l := range lines {
+		oldNum[i+1], newNum[i+1] = oldNum[i], newNum[i]
+		if l.op != '+' {
+			oldNum[i+1]++
+		}
+		if l.op != '-' {
+			newNum[i+1]++
+		}
+	}
+
+	for i := 0; i < len(lines); {
+		if lines[i].op == ' ' {
+			i++
+			continue
+		}
+		// Hunk: changes closer than 2*context lines are merged
+		start := i - diffContextLines
+		if start < 0 {
+			start = 0
+		}
+		end := i
+		for j := i; j < len(lines); j++ {
+			if lines[j].op != ' ' {
+				end = j + 1
+			} else if j-end >= 2*diffContextLines {
+				break
+			}
+		}
+		end += diffContextLines
+		if end > len(lines) {
+			end = len(lines)
+		}
+
+		oldCount, newCount := oldNum[end]-oldNum[start], newNum[end]-newNum[start]
+		fmt.Fprintf(&b, "@@ -%s +%s @@\n", hunkRange(oldNum[start], oldCount), hunkRange(newNum[start], newCount))
+		for _, l := range lines[start:end] {
+			b.WriteByte(l.op)
+			b.WriteString(l.text)
+			b.WriteByte('\n')
+		}
+		i = end
+	}
+	return b.String()
+}
+
+// hunkRange formats "start,count" of a hunk header; an empty range starts at the line before
+func hunkRange(start, count int) string {
+	if count == 0 {
+		start--
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+// diffLines computes a shortest edit script from a to b (Myers' algorithm)
+func diffLines(a, b []string) []diffLine {
+	// Common prefix and suffix don't need the diff algorithm
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var result []diffLine
+	for _, line := range a[:prefix] {
+		result = append(result, diffLine{' ', line})
+	}
+	result = append(result, myersDiff(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		result = append(result, diffLine{' ', line})
+	}
+	return result
+}
+
+// myersDiff is the core of diffLines. If the texts differ by more than maxDiffEdits lines,
+// all of a is reported as removed and all of b as added.
+func myersDiff(a, b []string) []diffLine {
+	n, m := len(a), len(b)
+	maxD := n + m
+	if maxD > maxDiffEdits {
+		maxD = maxDiffEdits
+	}
+
+	// v[k+offset] is the furthest x reached on diagonal k; trace keeps v before each round
+	offset := maxD + 1
+	v := make([]int, 2*maxD+3)
+	var trace [][]int
+	found := -1
+	for d := 0; d <= maxD && found < 0; d++ {
+		trace = append(trace, append([]int(nil), v[offset-d-1:offset+d+2]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				found = d
+				break
+			}
+		}
+	}
+
+	if found < 0 {
+		var result []diffLine
+		for _, line := range a {
+			result = append(result, diffLine{'-', line})
+		}
+		for _, line := range b {
+			result = append(result, diffLine{'+', line})
+		}
+		return result
+	}
+
+	// Walk back from (n, m) through the saved rounds
+	var reversed []diffLine
+	x, y := n, m
+	for d := found; d >= 0; d-- {
+		prev := trace[d] // v before round d, indexed by k+d+1
+		at := func(k int) int { return prev[k+d+1] }
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			reversed = append(reversed, diffLine{' ', a[x-1]})
+			x--
+			y--
+		}
+		if d > 0 {
+			if x == prevX {
+				reversed = append(reversed, diffLine{'+', b[y-1]})
+			} else {
+				reversed = append(reversed, diffLine{'-', a[x-1]})
+			}
+		}
+		x, y = prevX, prevY
+	}
+
+	result := make([]diffLine, len(reversed))
+	for i, l := range reversed {
+		result[len(reversed)-1-i] = l
+	}
+	return result
+}
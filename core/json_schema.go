@@ -0,0 +1,205 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"singbox-launcher/internal/constants"
+)
+
+// maxJSONSchemaSize limits the downloaded schema (the sing-box schema is well under 1 MB)
+const maxJSONSchemaSize = 10 * 1024 * 1024
+
+// jsonSchemaNode is the subset of JSON Schema used to look up field descriptions
+type jsonSchemaNode struct {
+	Ref         string                     `json:"$ref"`
+	Description string                     `json:"description"`
+	Properties  map[string]*jsonSchemaNode `json:"properties"`
+	Items       *jsonSchemaNode            `json:"items"`
+	AnyOf       []*jsonSchemaNode          `json:"anyOf"`
+	OneOf       []*jsonSchemaNode          `json:"oneOf"`
+	AllOf       []*jsonSchemaNode          `json:"allOf"`
+	Definitions map[string]*jsonSchemaNode `json:"definitions"`
+	Defs        map[string]*jsonSchemaNode `json:"$defs"`
+}
+
+// getJSONSchemaPath returns the path to the cached schema in data/
+func (ac *AppController) getJSONSchemaPath() string {
+	return filepath.Join(ac.ExecDir(), constants.DataDirName, constants.JSONSchemaName)
+}
+
+// DownloadJSONSchema downloads the sing-box JSON schema from schemaURL and caches it in data/
+func (ac *AppController) DownloadJSONSchema(schemaURL string) error {
+	schemaURL = strings.TrimSpace(schemaURL)
+	if !strings.HasPrefix(schemaURL, "http://") && !strings.HasPrefix(schemaURL, "https://") {
+		return fmt.Errorf("DownloadJSONSchema: invalid URL: %s", schemaURL)
+	}
+
+	client := createHTTPClient(NetworkRequestTimeout)
+	resp, err := client.Get(schemaURL)
+	if err != nil {
+		return fmt.Errorf("DownloadJSONSchema: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("DownloadJSONSchema: unexpected status %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxJSONSchemaSize+1))
+	if err != nil {
+		return fmt.Errorf("DownloadJSONSchema: failed to read response: %w", err)
+	}
+	if len(data) > maxJSONSchemaSize {
+		return fmt.Errorf("DownloadJSONSchema: response is larger than %d MB", maxJSONSchemaSize/(1024*1024))
+	}
+	var root jsonSchemaNode
+	if err := json.Unmarshal(data, &root); err != nil {
+		return fmt.Errorf("DownloadJSONSchema: response is not a JSON schema: %w", err)
+	}
+
+	path := ac.getJSONSchemaPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("DownloadJSONSchema: failed to create data directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("DownloadJSONSchema: failed to save schema: %w", err)
+	}
+	log.Printf("DownloadJSONSchema: Cached schema from %s", schemaURL)
+	return nil
+}
+
+// HasJSONSchema reports whether a downloaded schema is cached
+func (ac *AppController) HasJSONSchema() bool {
+	_, err := os.Stat(ac.getJSONSchemaPath())
+	return err == nil
+}
+
+// SectionFieldDescriptions returns field name -> description for a top-level config section
+// from the cached schema. It returns nil when no schema is cached or it doesn't describe the section.
+func (ac *AppController) SectionFieldDescriptions(section string) map[string]string {
+	data, err := os.ReadFile(ac.getJSONSchemaPath())
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("SectionFieldDescriptions: Failed to read schema: %v", err)
+		}
+		return nil
+	}
+	var root jsonSchemaNode
+	if err := json.Unmarshal(data, &root); err != nil {
+		log.Printf("SectionFieldDescriptions: Failed to parse schema: %v", err)
+		return nil
+	}
+
+	node := root.resolve(root.Properties[section], 0)
+	if node == nil {
+		return nil
+	}
+	// Array sections (inbounds, outbounds, ...) describe their items
+	if node.Items != nil {
+		node = root.resolve(node.Items, 0)
+	}
+	fields := make(map[string]string)
+	root.collectProperties(node, fields, 0)
+	if len(fields) == 0 {
+		return nil
+	}
+	return fields
+}
+
+// maxSchemaDepth limits $ref resolution so cyclic schemas can't recurse forever
+const maxSchemaDepth = 16
+
+// resolve follows $ref links ("#/definitions/X" or "#/$defs/X") of node
+func (root *jsonSchemaNode) resolve(node *jsonSchemaNode, depth int) *jsonSchemaNode {
+	for node != nil && node.Ref != "" && depth < maxSchemaDepth {
+		name := node.Ref[strings.LastIndex(node.Ref, "/")+1:]
+		switch {
+		case strings.HasPrefix(node.Ref, "#/definitions/"):
+			node = root.Definitions[name]
+		case strings.HasPrefix(node.Ref, "#/$defs/"):
+			node = root.Defs[name]
+		default:
+			return nil
+		}
+		depth++
+	}
+	return node
+}
+
+// collectProperties gathers the properties of node, including those of anyOf/oneOf/allOf
+// variants (e.g. each inbound type is a separate variant)
+func (root *jsonSchemaNode) collectProperties(node *jsonSchemaNode, out map[string]string, depth int) {
+	node = root.resolve(node, depth)
+	if node == nil || depth >= maxSchemaDepth {
+		return
+	}
+	for name, prop := range node.Properties {
+		desc := ""
+		if resolved := root.resolve(prop, depth); resolved != nil {
+			desc = resolved.Description
+		}
+		if prop.Description != "" {
+			desc = prop.Description
+		}
+		if existing, ok := out[name]; !ok || existing == "" {
+			out[name] = desc
+		}
+	}
+	for _, variants := range [][]*jsonSchemaNode{node.AnyOf, node.OneOf, node.AllOf} {
+		for _, variant := range variants {
+			root.collectProperties(variant, out, depth+1)
+		}
+	}
+}
+
+// ValidateConfigSection checks the JSON (comments and trailing commas allowed) of one config section.
+// It returns the section as compact JSON and a list of problems. Unknown field names are reported
+// only when fields (the complete list from the schema) is not empty.
+func ValidateConfigSection(text string, fields map[string]string) (json.RawMessage, []string) {
+	cleaned := cleanJSONC([]byte(text))
+	var value interface{}
+	if err := json.Unmarshal(cleaned, &value); err != nil {
+		return nil, []string{fmt.Sprintf("invalid JSON: %v", err)}
+	}
+	// Compact rather than re-marshal to keep the user's field order
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, cleaned); err != nil {
+		return nil, []string{fmt.Sprintf("invalid JSON: %v", err)}
+	}
+	if len(fields) == 0 {
+		return compact.Bytes(), nil
+	}
+
+	// Objects are checked directly, arrays (inbounds, outbounds, ...) item by item
+	var objects []map[string]interface{}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		objects = append(objects, v)
+	case []interface{}:
+		for _, item := range v {
+			if obj, ok := item.(map[string]interface{}); ok {
+				objects = append(objects, obj)
+			}
+		}
+	}
+	var problems []string
+	reported := make(map[string]bool)
+	for _, obj := range objects {
+		for name := range obj {
+			if _, known := fields[name]; known || reported[name] {
+				continue
+			}
+			reported[name] = true
+			problems = append(problems, fmt.Sprintf("unknown field %q", name))
+		}
+	}
+	sort.Strings(problems)
+	return compact.Bytes(), problems
+}
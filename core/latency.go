@@ -0,0 +1,58 @@
+package core
+
+import (
+	"fmt"
+	"log"
+	"sync"
+
+	"singbox-launcher/api"
+)
+
+// GetURLTestLatency returns the last latency (ms) measured by sing-box for each member of a urltest group.
+// Members without a measurement have latency 0.
+func (ac *AppController) GetURLTestLatency(groupTag string) (map[string]int, error) {
+	if !ac.ClashAPIEnabled {
+		return nil, fmt.Errorf("GetURLTestLatency: Clash API is disabled")
+	}
+	proxies, _, err := api.GetProxiesInGroup(ac.ClashAPIBaseURL, ac.ClashAPIToken, groupTag, ac.ApiLogFile)
+	if err != nil {
+		return nil, fmt.Errorf("GetURLTestLatency: %w", err)
+	}
+	latency := make(map[string]int, len(proxies))
+	for _, p := range proxies {
+		latency[p.Name] = int(p.Delay)
+	}
+	return latency, nil
+}
+
+// TestGroupLatency measures the delay of every member of a group in parallel.
+// Members that failed the test have latency 0.
+func (ac *AppController) TestGroupLatency(groupTag string) (map[string]int, error) {
+	if !ac.ClashAPIEnabled {
+		return nil, fmt.Errorf("TestGroupLatency: Clash API is disabled")
+	}
+	proxies, _, err := api.GetProxiesInGroup(ac.ClashAPIBaseURL, ac.ClashAPIToken, groupTag, ac.ApiLogFile)
+	if err != nil {
+		return nil, fmt.Errorf("TestGroupLatency: %w", err)
+	}
+
+	latency := make(map[string]int, len(proxies))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, p := range proxies {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			delay, err := api.GetDelay(ac.ClashAPIBaseURL, ac.ClashAPIToken, name, ac.ApiLogFile)
+			if err != nil {
+				log.Printf("TestGroupLatency: %s: %v", name, err)
+				delay = 0
+			}
+			mu.Lock()
+			latency[name] = int(delay)
+			mu.Unlock()
+		}(p.Name)
+	}
+	wg.Wait()
+	return latency, nil
+}
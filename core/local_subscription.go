@@ -0,0 +1,133 @@
+package core
+
+import (
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// maxLocalSubscriptionSize guards against pointing a file:// source at something that is not a proxy list
+const maxLocalSubscriptionSize = 10 * 1024 * 1024 // 10 MB
+
+// IsFileURL reports whether a subscription source is a local file (file:// URL)
+func IsFileURL(rawURL string) bool {
+	return strings.HasPrefix(strings.ToLower(strings.TrimSpace(rawURL)), "file:")
+}
+
+// LocalSubscriptionDirs returns the folders file:// subscription sources may be read from:
+// the launcher folder and the folder configured in preferences (if any)
+func (ac *AppController) LocalSubscriptionDirs() []string {
+	dirs := []string{ac.ExecDir()}
+	if dir := strings.TrimSpace(ac.LoadPreferences().LocalSubscriptionsDir); dir != "" {
+		dirs = append(dirs, dir)
+	}
+	return dirs
+}
+
+// ReadLocalSubscription reads a local subscription file (raw content, not decoded)
+func ReadLocalSubscription(path string) ([]byte, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("ReadLocalSubscription: %w", err)
+	}
+	if !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("ReadLocalSubscription: %s is not a regular file", path)
+	}
+	if info.Size() > maxLocalSubscriptionSize {
+		return nil, fmt.Errorf("ReadLocalSubscription: %s is too large (%s)", path, FormatBytesUtil(info.Size()))
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("ReadLocalSubscription: %w", err)
+	}
+	return data, nil
+}
+
+// fetchLocalSubscription reads and decodes a file:// subscription source.
+// The file must be inside one of allowedDirs (relative paths are resolved against the first one).
+func fetchLocalSubscription(rawURL string, allowedDirs []string) ([]byte, error) {
+	if len(allowedDirs) == 0 {
+		return nil, fmt.Errorf("local subscription files are not allowed here: %s", rawURL)
+	}
+	path, err := fileURLPath(rawURL, allowedDirs[0])
+	if err != nil {
+		return nil, err
+	}
+	if !isPathInDirs(path, allowedDirs) {
+		log.Printf("fetchLocalSubscription: Rejected %s, allowed folders: %v", path, allowedDirs)
+		return nil, fmt.Errorf("local subscription %s is outside the allowed folders (launcher folder or the one set in Settings)", path)
+	}
+
+	content, err := ReadLocalSubscription(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(content) == 0 {
+		return nil, fmt.Errorf("local subscription %s is empty", path)
+	}
+	decoded, err := DecodeSubscriptionContent(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode subscription content: %w", err)
+	}
+	return decoded, nil
+}
+
+// fileURLPath converts a file URL to a local path:
+// file:///home/user/list.txt, file:///C:/Users/list.txt, file:subs/list.txt (relative to baseDir)
+func fileURLPath(rawURL, baseDir string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || !strings.EqualFold(u.Scheme, "file") {
+		return "", fmt.Errorf("invalid file URL: %s", rawURL)
+	}
+	if u.Opaque != "" {
+		// file:subs/list.txt
+		p, err := url.PathUnescape(u.Opaque)
+		if err != nil {
+			return "", fmt.Errorf("invalid file URL: %s", rawURL)
+		}
+		return filepath.Join(baseDir, filepath.FromSlash(p)), nil
+	}
+	if u.Host != "" && !strings.EqualFold(u.Host, "localhost") {
+		return "", fmt.Errorf("file URLs on other hosts are not supported: %s", rawURL)
+	}
+	p := u.Path
+	if p == "" {
+		return "", fmt.Errorf("file URL has no path: %s", rawURL)
+	}
+	// file:///C:/dir/list.txt -> C:/dir/list.txt
+	if runtime.GOOS == "windows" && len(p) >= 3 && p[0] == '/' && p[2] == ':' {
+		p = p[1:]
+	}
+	return filepath.Clean(filepath.FromSlash(p)), nil
+}
+
+// isPathInDirs reports whether path (after resolving symlinks and "..") is inside one of dirs
+func isPathInDirs(path string, dirs []string) bool {
+	resolved := resolvePath(path)
+	for _, dir := range dirs {
+		rel, err := filepath.Rel(resolvePath(dir), resolved)
+		if err != nil {
+			continue
+		}
+		if rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) && !filepath.IsAbs(rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolvePath returns the absolute path with symlinks resolved (as far as it exists)
+func resolvePath(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		abs = filepath.Clean(path)
+	}
+	if real, err := filepath.EvalSymlinks(abs); err == nil {
+		return real
+	}
+	return abs
+}
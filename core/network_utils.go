@@ -2,9 +2,15 @@ package core
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
+	"log/slog"
 	"net"
 	"net/http"
+	"net/url"
+	"syscall"
 	"time"
 )
 
@@ -15,13 +21,19 @@ const (
 	NetworkRequestTimeout = 15 * time.Second
 	// NetworkLongTimeout - таймаут для длительных операций (скачивание файлов)
 	NetworkLongTimeout = 30 * time.Second
+	// maxHTTPRedirects - больше редиректов подряд почти всегда означает петлю или ошибку настройки сервера
+	maxHTTPRedirects = 5
 )
 
 // createHTTPClient создает HTTP клиент с правильными таймаутами
 func createHTTPClient(timeout time.Duration) *http.Client {
+	// Системные сертификаты + пользовательские CA из certs/ (загружаются один раз)
+	rootCAs := customCACertPool()
 	return &http.Client{
-		Timeout: timeout,
+		Timeout:       timeout,
+		CheckRedirect: checkRedirect,
 		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{RootCAs: rootCAs},
 			DialContext: (&net.Dialer{
 				Timeout:   NetworkDialTimeout,
 				KeepAlive: 30 * time.Second,
@@ -35,38 +47,64 @@ func createHTTPClient(timeout time.Duration) *http.Client {
 	}
 }
 
+// checkRedirect follows at most maxHTTPRedirects redirects. After that the redirect response itself
+// is returned (http.ErrUseLastResponse), so callers can report it, see isTooManyRedirects.
+func checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) > maxHTTPRedirects {
+		return http.ErrUseLastResponse
+	}
+	slog.Debug("HTTP redirect", "from", via[len(via)-1].URL.Redacted(), "to", req.URL.Redacted(), "hop", len(via))
+	return nil
+}
+
+// isTooManyRedirects reports whether resp is a redirect the client stopped following (see checkRedirect)
+func isTooManyRedirects(resp *http.Response) bool {
+	switch resp.StatusCode {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return resp.Header.Get("Location") != ""
+	}
+	return false
+}
+
+// Windows Sockets error codes (syscall.ECONNREFUSED/ECONNRESET don't match them on Windows)
+const (
+	wsaeConnReset   = syscall.Errno(10054)
+	wsaeConnRefused = syscall.Errno(10061)
+)
+
 // IsNetworkError проверяет, является ли ошибка сетевой ошибкой
 func IsNetworkError(err error) bool {
 	if err == nil {
 		return false
 	}
 
+	// *url.Error сам по себе не означает сетевую ошибку (например, неверная схема URL
+	// или ошибка CheckRedirect), поэтому проверяются только вложенные ошибки ниже.
+
 	// Проверка на timeout
-	if netErr, ok := err.(net.Error); ok {
-		if netErr.Timeout() {
-			return true
-		}
-		if netErr.Temporary() {
-			return true
-		}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
 	}
 
-	// Проверка на отсутствие соединения
-	if _, ok := err.(*net.OpError); ok {
+	// Проверка на отсутствие соединения и DNS ошибку
+	var opErr *net.OpError
+	var dnsErr *net.DNSError
+	if errors.As(err, &opErr) || errors.As(err, &dnsErr) {
 		return true
 	}
-
-	// Проверка на DNS ошибку
-	if _, ok := err.(*net.DNSError); ok {
+	if isConnRefused(err) || isConnReset(err) {
 		return true
 	}
 
-	// Проверка на контекст (отмена/таймаут)
-	if err == context.DeadlineExceeded || err == context.Canceled {
+	// Ошибки TLS сертификатов
+	if tlsCertificateErrorMessage(err) != "" {
 		return true
 	}
 
-	return false
+	// Проверка на контекст (отмена/таймаут)
+	return errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled)
 }
 
 // GetNetworkErrorMessage возвращает понятное сообщение об ошибке сети
@@ -75,29 +113,97 @@ func GetNetworkErrorMessage(err error) string {
 		return "Unknown network error"
 	}
 
-	if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
-		return "Network timeout: connection timed out"
+	if errors.Is(err, context.Canceled) {
+		return "Request canceled"
 	}
 
-	if opErr, ok := err.(*net.OpError); ok {
-		if opErr.Op == "dial" {
-			return "Network error: cannot connect to server"
+	if msg := tlsCertificateErrorMessage(err); msg != "" {
+		return msg
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		switch {
+		case dnsErr.IsNotFound:
+			return fmt.Sprintf("DNS resolution failed for host %s: host not found", dnsErr.Name)
+		case dnsErr.IsTimeout:
+			return fmt.Sprintf("DNS resolution failed for host %s: DNS server timed out", dnsErr.Name)
+		case dnsErr.IsTemporary:
+			return fmt.Sprintf("DNS resolution failed for host %s: temporary failure, try again later", dnsErr.Name)
+		default:
+			return fmt.Sprintf("DNS resolution failed for host %s", dnsErr.Name)
 		}
-		return fmt.Sprintf("Network error: %s", opErr.Error())
 	}
 
-	if dnsErr, ok := err.(*net.DNSError); ok {
-		return fmt.Sprintf("DNS error: cannot resolve hostname (%s)", dnsErr.Name)
+	if isConnRefused(err) {
+		return fmt.Sprintf("Connection refused at %s", errorAddress(err))
+	}
+	if isConnReset(err) {
+		return fmt.Sprintf("Connection reset by %s", errorAddress(err))
 	}
 
-	if err == context.DeadlineExceeded {
+	if errors.Is(err, context.DeadlineExceeded) {
 		return "Request timeout: operation took too long"
 	}
 
-	if err == context.Canceled {
-		return "Request canceled"
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return "Network timeout: connection timed out"
+	}
+
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		if opErr.Op == "dial" {
+			return "Network error: cannot connect to server"
+		}
+		return fmt.Sprintf("Network error: %s", opErr.Error())
 	}
 
 	return fmt.Sprintf("Network error: %s", err.Error())
 }
 
+// isConnRefused reports whether err is "connection refused"
+func isConnRefused(err error) bool {
+	return errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, wsaeConnRefused)
+}
+
+// isConnReset reports whether err is "connection reset by peer"
+func isConnReset(err error) bool {
+	return errors.Is(err, syscall.ECONNRESET) || errors.Is(err, wsaeConnReset)
+}
+
+// errorAddress returns the remote host:port of a failed request, if known
+func errorAddress(err error) string {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Addr != nil {
+		return opErr.Addr.String()
+	}
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		if u, parseErr := url.Parse(urlErr.URL); parseErr == nil && u.Host != "" {
+			return u.Host
+		}
+	}
+	return "server"
+}
+
+// tlsCertificateErrorMessage returns a message for TLS certificate errors, or "" for other errors
+func tlsCertificateErrorMessage(err error) string {
+	var invalidErr x509.CertificateInvalidError
+	if errors.As(err, &invalidErr) {
+		return fmt.Sprintf("TLS certificate invalid: %s", invalidErr.Error())
+	}
+	var authorityErr x509.UnknownAuthorityError
+	if errors.As(err, &authorityErr) {
+		return "TLS certificate invalid: signed by unknown authority (add your CA to the certs folder)"
+	}
+	var hostnameErr x509.HostnameError
+	if errors.As(err, &hostnameErr) {
+		return fmt.Sprintf("TLS certificate invalid: %s", hostnameErr.Error())
+	}
+	var verifyErr *tls.CertificateVerificationError
+	if errors.As(err, &verifyErr) {
+		return fmt.Sprintf("TLS certificate invalid: %s", verifyErr.Err)
+	}
+	return ""
+}
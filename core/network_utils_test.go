@@ -0,0 +1,68 @@
+package core
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+// timeoutError is a net.Error that reports a timeout, like a deadline hit inside net/http
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+// urlError wraps err the way http.Client.Do does
+func urlError(err error) error {
+	return &url.Error{Op: "Get", URL: "https://sub.example.com:8443/path?token=1", Err: err}
+}
+
+// dialError wraps errno the way net.Dial does
+func dialError(errno syscall.Errno) error {
+	addr := &net.TCPAddr{IP: net.IPv4(192, 0, 2, 10), Port: 443}
+	return &net.OpError{Op: "dial", Net: "tcp", Addr: addr, Err: os.NewSyscallError("connect", errno)}
+}
+
+func TestNetworkErrors(t *testing.T) {
+	cert := &x509.Certificate{}
+	tests := []struct {
+		name        string
+		err         error
+		wantNetwork bool
+		wantMessage string // prefix of GetNetworkErrorMessage
+	}{
+		{"dns not found", &net.DNSError{Err: "no such host", Name: "sub.example.com", IsNotFound: true}, true, "DNS resolution failed for host sub.example.com: host not found"},
+		{"dns timeout", &net.DNSError{Err: "timeout", Name: "sub.example.com", IsTimeout: true}, true, "DNS resolution failed for host sub.example.com: DNS server timed out"},
+		{"dns temporary", &net.DNSError{Err: "server misbehaving", Name: "sub.example.com", IsTemporary: true}, true, "DNS resolution failed for host sub.example.com: temporary failure"},
+		{"dns other", urlError(&net.DNSError{Err: "bad", Name: "sub.example.com"}), true, "DNS resolution failed for host sub.example.com"},
+		{"connection refused", urlError(dialError(syscall.ECONNREFUSED)), true, "Connection refused at 192.0.2.10:443"},
+		{"connection refused without address", urlError(syscall.ECONNREFUSED), true, "Connection refused at sub.example.com:8443"},
+		{"connection reset", urlError(dialError(syscall.ECONNRESET)), true, "Connection reset by 192.0.2.10:443"},
+		{"certificate invalid", urlError(x509.CertificateInvalidError{Cert: cert, Reason: x509.Expired}), true, "TLS certificate invalid: x509: certificate has expired"},
+		{"unknown authority", urlError(x509.UnknownAuthorityError{Cert: cert}), true, "TLS certificate invalid: signed by unknown authority"},
+		{"hostname mismatch", urlError(x509.HostnameError{Certificate: cert, Host: "sub.example.com"}), true, "TLS certificate invalid: x509: certificate is not valid"},
+		{"context canceled", urlError(context.Canceled), true, "Request canceled"},
+		{"deadline exceeded", fmt.Errorf("fetch: %w", context.DeadlineExceeded), true, "Request timeout"},
+		{"net timeout", urlError(timeoutError{}), true, "Network timeout"},
+		{"unsupported scheme", urlError(errors.New("unsupported protocol scheme \"ftp\"")), false, "Network error: Get"},
+		{"plain error", errors.New("failed to parse subscription"), false, "Network error: failed to parse subscription"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsNetworkError(tt.err); got != tt.wantNetwork {
+				t.Errorf("IsNetworkError() = %v, want %v", got, tt.wantNetwork)
+			}
+			if got := GetNetworkErrorMessage(tt.err); !strings.HasPrefix(got, tt.wantMessage) {
+				t.Errorf("GetNetworkErrorMessage() = %q, want prefix %q", got, tt.wantMessage)
+			}
+		})
+	}
+}
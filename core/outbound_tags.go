@@ -0,0 +1,53 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// outboundTagsCache holds the outbound tags of config.json read by GetOutboundTags
+type outboundTagsCache struct {
+	mutex     sync.Mutex
+	valid     bool
+	outbounds []configOutbound // in config order, with unique tags
+	modTime   time.Time        // of config.json when tags were read
+	size      int64
+}
+
+// GetOutboundTags returns the tags of the outbounds in config.json, in config order.
+// outboundType, if not empty, keeps only outbounds of that type (e.g. "urltest").
+// The result is cached until config.json changes, so selectors can call it freely.
+func (ac *AppController) GetOutboundTags(outboundType string) ([]string, error) {
+	configPath := ac.ConfigPath()
+	info, err := os.Stat(configPath)
+	if err != nil {
+		ac.invalidateOutboundTags()
+		return nil, fmt.Errorf("GetOutboundTags: %w", err)
+	}
+
+	cache := &ac.outboundTags
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	// The modification time also catches saves made before the watcher noticed them
+	if !cache.valid || !cache.modTime.Equal(info.ModTime()) || cache.size != info.Size() {
+		jsonData, err := loadConfigJSON(configPath)
+		if err != nil {
+			cache.valid = false
+			return nil, fmt.Errorf("GetOutboundTags: %w", err)
+		}
+		cache.outbounds = configOutbounds(jsonData)
+		cache.modTime = info.ModTime()
+		cache.size = info.Size()
+		cache.valid = true
+	}
+	return filterOutboundTags(cache.outbounds, outboundType), nil
+}
+
+// invalidateOutboundTags makes the next GetOutboundTags re-read config.json
+func (ac *AppController) invalidateOutboundTags() {
+	ac.outboundTags.mutex.Lock()
+	ac.outboundTags.valid = false
+	ac.outboundTags.mutex.Unlock()
+}
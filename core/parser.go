@@ -33,12 +33,13 @@ func updateParserProgress(ac *AppController, progress float64, status string) {
 	}
 }
 
-// UpdateConfigFromSubscriptions updates config.json by fetching subscriptions and parsing nodes
-func UpdateConfigFromSubscriptions(ac *AppController) error {
+// UpdateConfigFromSubscriptions updates config.json by fetching subscriptions and parsing nodes.
+// If reviewChanges is set, the user confirms added/removed/changed proxies before config.json is written.
+func UpdateConfigFromSubscriptions(ac *AppController, reviewChanges bool) error {
 	log.Println("Parser: Starting configuration update...")
 
 	// Step 1: Extract configuration
-	config, err := ExtractParcerConfig(ac.ConfigPath)
+	config, err := ExtractParcerConfig(ac.ConfigPath())
 	if err != nil {
 		updateParserProgress(ac, -1, fmt.Sprintf("Error: %v", err))
 		return fmt.Errorf("failed to extract parser config: %w", err)
@@ -72,9 +73,26 @@ func UpdateConfigFromSubscriptions(ac *AppController) error {
 
 		// Update progress: downloading subscription
 		progress := 20 + float64(i)*50.0/float64(totalSubscriptions)
-		updateParserProgress(ac, progress, fmt.Sprintf("Downloading subscription %d/%d: %s", i+1, totalSubscriptions, proxySource.Source))
+		updateParserProgress(ac, progress, fmt.Sprintf("Downloading subscription %d/%d: %s", i+1, totalSubscriptions, proxySource.Label()))
 
-		content, err := FetchSubscription(proxySource.Source)
+		opts, err := proxySource.FetchOptions()
+		if err != nil {
+			// Skipping the source would silently drop its proxies from config.json
+			log.Printf("Parser: Error: Failed to read authentication for %s: %v", proxySource.Source, err)
+			updateParserProgress(ac, -1, fmt.Sprintf("Error: %s: credentials must be re-entered", proxySource.Label()))
+			return fmt.Errorf("subscription %s: %w", proxySource.Label(), err)
+		}
+		opts.LocalDirs = ac.LocalSubscriptionDirs()
+		opts.CacheDir = ac.SubscriptionCacheDir()
+
+		// Compile skip_patterns once per subscription; an invalid pattern stops the update instead of disabling the filter
+		skipPatterns, err := CompileSkipPatterns(proxySource.SkipPatterns)
+		if err != nil {
+			updateParserProgress(ac, -1, fmt.Sprintf("Error: %v", err))
+			return fmt.Errorf("subscription %s: %w", proxySource.Source, err)
+		}
+
+		content, err := FetchSubscriptionWithOptions(proxySource.Source, opts)
 		if err != nil {
 			log.Printf("Parser: Error: Failed to fetch subscription from %s: %v", proxySource.Source, err)
 			continue
@@ -88,7 +106,7 @@ func UpdateConfigFromSubscriptions(ac *AppController) error {
 
 		// Update progress: parsing subscription
 		progress = 20 + float64(i)*50.0/float64(totalSubscriptions) + 10.0/float64(totalSubscriptions)
-		updateParserProgress(ac, progress, fmt.Sprintf("Parsing subscription %d/%d: %s", i+1, totalSubscriptions, proxySource.Source))
+		updateParserProgress(ac, progress, fmt.Sprintf("Parsing subscription %d/%d: %s", i+1, totalSubscriptions, proxySource.Label()))
 
 		// Parse subscription content
 		lines := strings.Split(string(content), "\n")
@@ -105,8 +123,17 @@ func UpdateConfigFromSubscriptions(ac *AppController) error {
 				log.Printf("Parser: Warning: Failed to parse node from %s: %v", proxySource.Source, err)
 				continue
 			}
+			if node != nil {
+				if matched := MatchingSkipPatterns(node.Tag, skipPatterns); len(matched) > 0 {
+					log.Printf("Parser: Skipping '%s' (matches skip pattern %q)", node.Tag, matched[0])
+					continue
+				}
+			}
 
 			if node != nil {
+				// Skip filters and patterns see the original tag, the subscription name is added after them
+				node.Tag = proxySource.PrefixTag(node.Tag)
+
 				// Make tag unique if it already exists
 				originalTag := node.Tag
 				// Check if tag already exists before incrementing
@@ -128,6 +155,7 @@ func UpdateConfigFromSubscriptions(ac *AppController) error {
 
 		if nodesFromThisSubscription > 0 {
 			successfulSubscriptions++
+			ac.markSubscriptionRefreshed(proxySource.Source, time.Now())
 			log.Printf("Parser: Successfully parsed %d nodes from %s", nodesFromThisSubscription, proxySource.Source)
 		} else {
 			log.Printf("Parser: Warning: No valid nodes parsed from %s", proxySource.Source)
@@ -138,6 +166,10 @@ func UpdateConfigFromSubscriptions(ac *AppController) error {
 		updateParserProgress(ac, progress, fmt.Sprintf("Processed subscriptions: %d/%d, nodes: %d", i+1, totalSubscriptions, len(allNodes)))
 	}
 
+	if successfulSubscriptions > 0 {
+		ac.Events.Publish(SubscriptionsRefreshed)
+	}
+
 	// Check if we successfully loaded at least one subscription
 	if successfulSubscriptions == 0 {
 		updateParserProgress(ac, -1, "Error: failed to load any subscriptions")
@@ -182,6 +214,7 @@ func UpdateConfigFromSubscriptions(ac *AppController) error {
 		}
 		selectorsJSON = append(selectorsJSON, nodeJSON)
 	}
+	nodeCount := len(selectorsJSON)
 
 	// Check if we have any node JSON before generating selectors
 	if len(selectorsJSON) == 0 {
@@ -209,19 +242,26 @@ func UpdateConfigFromSubscriptions(ac *AppController) error {
 		return fmt.Errorf("no content generated - cannot write empty result to config")
 	}
 
-	// Step 4: Write to file
+	// Step 4: Let the user review proxy list changes
+	if reviewChanges && ac.ConfirmProxyChangesFunc != nil {
+		if err := reviewProxyChanges(ac, selectorsJSON[:nodeCount]); err != nil {
+			return err
+		}
+	}
+
+	// Step 5: Write to file
 	updateParserProgress(ac, 90, "Writing to config file...")
 
 	content := strings.Join(selectorsJSON, "\n")
-	if err := writeToConfig(ac.ConfigPath, content); err != nil {
+	if err := writeToConfig(ac.ConfigPath(), content); err != nil {
 		updateParserProgress(ac, -1, fmt.Sprintf("Write error: %v", err))
 		return fmt.Errorf("failed to write to config: %w", err)
 	}
 
-	log.Printf("Parser: Done! File %s successfully updated.", ac.ConfigPath)
+	log.Printf("Parser: Done! File %s successfully updated.", ac.ConfigPath())
 
 	// Update last_updated timestamp in @ParcerConfig block
-	if err := UpdateLastUpdatedInConfig(ac.ConfigPath, time.Now().UTC()); err != nil {
+	if err := UpdateLastUpdatedInConfig(ac.ConfigPath(), time.Now().UTC()); err != nil {
 		log.Printf("Parser: Warning: Failed to update last_updated timestamp: %v", err)
 		// Don't fail the whole operation if timestamp update fails
 	} else {
@@ -233,6 +273,37 @@ func UpdateConfigFromSubscriptions(ac *AppController) error {
 	return nil
 }
 
+// reviewProxyChanges compares generated nodes with those in config.json and asks the user to apply the changes
+func reviewProxyChanges(ac *AppController, nodesJSON []string) error {
+	oldOutbounds, err := readParserOutbounds(ac.ConfigPath())
+	if err != nil {
+		log.Printf("Parser: Warning: Failed to read current proxies, skipping review: %v", err)
+		return nil
+	}
+	if len(oldOutbounds) == 0 {
+		return nil // First update, nothing to compare with
+	}
+	newOutbounds, err := parseProxyOutbounds(strings.Join(nodesJSON, "\n"))
+	if err != nil {
+		log.Printf("Parser: Warning: Failed to parse new proxies, skipping review: %v", err)
+		return nil
+	}
+
+	diff := DiffProxyLists(oldOutbounds, newOutbounds)
+	log.Printf("Parser: Proxy changes: %d added, %d removed, %d changed", len(diff.Added), len(diff.Removed), len(diff.Changed))
+	if !diff.HasChanges() {
+		return nil
+	}
+
+	updateParserProgress(ac, 88, "Waiting for proxy changes to be reviewed...")
+	if !ac.ConfirmProxyChangesFunc(diff) {
+		log.Println("Parser: Proxy changes rejected by user, config.json not updated")
+		updateParserProgress(ac, -1, "Update cancelled")
+		return ErrProxyChangesCancelled
+	}
+	return nil
+}
+
 // ParseNode parses a single node URI and applies skip filters (exported for use in UI)
 func ParseNode(uri string, skipFilters []map[string]string) (*ParsedNode, error) {
 	// Determine scheme
@@ -374,6 +445,30 @@ func shouldSkipNode(node *ParsedNode, skipFilters []map[string]string) bool {
 	return false // Don't skip
 }
 
+// CompileSkipPatterns compiles skip_patterns of a subscription (Go regular expressions matched against the proxy tag)
+func CompileSkipPatterns(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid skip pattern %q: %w", pattern, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+// MatchingSkipPatterns returns the skip patterns matching the proxy tag
+func MatchingSkipPatterns(tag string, patterns []*regexp.Regexp) []string {
+	var matched []string
+	for _, re := range patterns {
+		if re.MatchString(tag) {
+			matched = append(matched, re.String())
+		}
+	}
+	return matched
+}
+
 // getNodeValue extracts value from node by key
 func getNodeValue(node *ParsedNode, key string) string {
 	switch key {
@@ -0,0 +1,105 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/muhammadmuzzammil1998/jsonc"
+)
+
+// SupportedParserConfigVersions lists @ParcerConfig format versions this launcher can read
+var SupportedParserConfigVersions = []int{1, 2}
+
+// isSupportedParserConfigVersion reports whether version is in SupportedParserConfigVersions
+func isSupportedParserConfigVersion(version int) bool {
+	for _, v := range SupportedParserConfigVersions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// parseParserConfigBlock parses the JSON(C) content of a @ParcerConfig block
+// and migrates it to the current ParserConfigVersion
+func parseParserConfigBlock(content []byte) (*ParserConfig, error) {
+	// The block may contain // comments for documentation, so convert JSONC to JSON first.
+	// Note: a /* */ comment would close the enclosing /** @ParcerConfig */ block itself.
+	jsonContent := normalizeParserConfigKey(jsonc.ToJSON([]byte(strings.TrimSpace(string(content)))))
+
+	var parserConfig ParserConfig
+	if err := json.Unmarshal(jsonContent, &parserConfig); err != nil {
+		return nil, fmt.Errorf("failed to parse @ParcerConfig JSON: %w", err)
+	}
+
+	// Version 1 keeps the version at top level, version 2 inside ParserConfig.
+	// A missing version means version 1.
+	version := parserConfig.ParserConfig.Version
+	if version == 0 {
+		version = parserConfig.Version
+	}
+	if version == 0 {
+		version = 1
+	}
+	if version != ParserConfigVersion {
+		if err := MigrateParserConfig(&parserConfig, version); err != nil {
+			return nil, err
+		}
+	}
+	return &parserConfig, nil
+}
+
+// MigrateParserConfig upgrades a ParserConfig read in fromVersion format to ParserConfigVersion
+func MigrateParserConfig(raw *ParserConfig, fromVersion int) error {
+	if !isSupportedParserConfigVersion(fromVersion) {
+		return fmt.Errorf("@ParcerConfig version %d is not supported by this launcher (supported versions: %s). "+
+			"Update the launcher or use a config template for a supported version",
+			fromVersion, formatParserConfigVersions())
+	}
+	for v := fromVersion; v < ParserConfigVersion; v++ {
+		switch v {
+		case 1:
+			log.Printf("MigrateParserConfig: Migrating @ParcerConfig from version 1 to version 2")
+			// Version 2 moved the version field inside ParserConfig
+			raw.Version = 0
+		}
+	}
+	raw.ParserConfig.Version = ParserConfigVersion
+	return nil
+}
+
+// formatParserConfigVersions returns SupportedParserConfigVersions as "1, 2"
+func formatParserConfigVersions() string {
+	parts := make([]string, len(SupportedParserConfigVersions))
+	for i, v := range SupportedParserConfigVersions {
+		parts[i] = fmt.Sprint(v)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// normalizeParserConfigKey renames misspelled top-level keys (e.g. "parcerConfig",
+// written after the @ParcerConfig marker) to "ParserConfig". Other content is left as is.
+func normalizeParserConfigKey(data []byte) []byte {
+	var top map[string]json.RawMessage
+	if err := json.Unmarshal(data, &top); err != nil {
+		return data
+	}
+	for key, value := range top {
+		if strings.EqualFold(key, "ParserConfig") {
+			return data
+		}
+		if strings.EqualFold(key, "ParcerConfig") {
+			delete(top, key)
+			top["ParserConfig"] = value
+			log.Printf("normalizeParserConfigKey: Renamed \"%s\" to \"ParserConfig\"", key)
+			normalized, err := json.Marshal(top)
+			if err != nil {
+				return data
+			}
+			return normalized
+		}
+	}
+	return data
+}
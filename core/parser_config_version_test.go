@@ -0,0 +1,85 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseParserConfigStringComments(t *testing.T) {
+	block := `{
+  // Subscriptions used to build the proxy list
+  "ParserConfig": {
+    "version": 2, // current format
+    /* one source per provider,
+       the URL may contain // and /* in query values */
+    "proxies": [{"source": "https://example.com/sub?path=//a/*b"}],
+    "outbounds": [] /* filled by the wizard */
+  }
+}`
+	cfg, err := ParseParserConfigString(block)
+	if err != nil {
+		t.Fatalf("ParseParserConfigString() error = %v", err)
+	}
+	if got := len(cfg.ParserConfig.Proxies); got != 1 {
+		t.Fatalf("got %d proxy sources, want 1", got)
+	}
+	if got, want := cfg.ParserConfig.Proxies[0].Source, "https://example.com/sub?path=//a/*b"; got != want {
+		t.Errorf("Source = %q, want %q", got, want)
+	}
+	if cfg.ParserConfig.Version != ParserConfigVersion {
+		t.Errorf("Version = %d, want %d", cfg.ParserConfig.Version, ParserConfigVersion)
+	}
+}
+
+func TestExtractParcerConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		block   string
+		wantErr string
+	}{
+		{
+			name: "line comments",
+			block: `{
+  // documentation for the block
+  "ParserConfig": {
+    "version": 2,
+    "proxies": [{"source": "https://example.com/sub"}] // main provider
+  }
+}`,
+		},
+		{
+			name:    "no proxies",
+			block:   `{"ParserConfig": {"version": 2, "proxies": []}}`,
+			wantErr: "no proxy sources",
+		},
+		{
+			name:    "invalid json",
+			block:   `{"ParserConfig": {"proxies": [}}`,
+			wantErr: "failed to parse @ParcerConfig JSON",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.json")
+			content := "/** @ParcerConfig\n" + tt.block + "\n*/\n{\"outbounds\": []}\n"
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			cfg, err := ExtractParcerConfig(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ExtractParcerConfig() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ExtractParcerConfig() error = %v", err)
+			}
+			if got := cfg.ParserConfig.Proxies[0].Source; got != "https://example.com/sub" {
+				t.Errorf("Source = %q", got)
+			}
+		})
+	}
+}
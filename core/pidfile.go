@@ -0,0 +1,100 @@
+package core
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	ps "github.com/mitchellh/go-ps"
+
+	"singbox-launcher/internal/constants"
+	"singbox-launcher/internal/platform"
+)
+
+// getPIDFilePath returns the path to bin/sing-box.pid
+func (ac *AppController) getPIDFilePath() string {
+	return filepath.Join(platform.GetBinDir(ac.ExecDir()), constants.SingBoxPIDFileName)
+}
+
+// WritePIDFile stores the PID of the started sing-box process
+func (ac *AppController) WritePIDFile(pid int) error {
+	if err := os.WriteFile(ac.getPIDFilePath(), []byte(strconv.Itoa(pid)), 0644); err != nil {
+		return fmt.Errorf("WritePIDFile: %w", err)
+	}
+	return nil
+}
+
+// ReadPIDFile returns the PID stored by WritePIDFile
+func (ac *AppController) ReadPIDFile() (int, error) {
+	data, err := os.ReadFile(ac.getPIDFilePath())
+	if err != nil {
+		return 0, err
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 {
+		return 0, fmt.Errorf("ReadPIDFile: invalid PID %q", strings.TrimSpace(string(data)))
+	}
+	return pid, nil
+}
+
+// RemovePIDFile deletes the PID file, if any
+func (ac *AppController) RemovePIDFile() {
+	if err := os.Remove(ac.getPIDFilePath()); err != nil && !os.IsNotExist(err) {
+		log.Printf("RemovePIDFile: %v", err)
+	}
+}
+
+// isSingBoxPID reports whether pid belongs to a running sing-box process
+func isSingBoxPID(pid int) bool {
+	p, err := ps.FindProcess(pid)
+	if err != nil || p == nil {
+		return false
+	}
+	return strings.EqualFold(p.Executable(), platform.GetProcessNameForCheck())
+}
+
+// restartOrphanedSingBox handles a sing-box process left running by a previous launcher
+// instance (e.g. after a crash). The orphan cannot be attached to, so it is killed and
+// started again under this launcher. Returns true if an orphan was found.
+func restartOrphanedSingBox(ac *AppController) bool {
+	pid, err := ac.ReadPIDFile()
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("restartOrphanedSingBox: %v", err)
+			ac.RemovePIDFile()
+		}
+		return false
+	}
+	if !isSingBoxPID(pid) {
+		log.Printf("restartOrphanedSingBox: Stale PID file (PID=%d is not running), removing", pid)
+		ac.RemovePIDFile()
+		return false
+	}
+
+	log.Printf("restartOrphanedSingBox: sing-box from a previous session is still running (PID=%d). Restarting it.", pid)
+	go func() {
+		if err := platform.KillProcessByPID(pid); err != nil {
+			log.Printf("restartOrphanedSingBox: Failed to kill PID=%d: %v", pid, err)
+		}
+		deadline := time.Now().Add(gracefulShutdownTimeout)
+		for isSingBoxPID(pid) && time.Now().Before(deadline) {
+			time.Sleep(200 * time.Millisecond)
+		}
+		if isSingBoxPID(pid) {
+			log.Printf("restartOrphanedSingBox: PID=%d is still running, not starting a second instance", pid)
+			ShowSingBoxAlreadyRunningWarningUtil(ac)
+			return
+		}
+		ac.RemovePIDFile()
+		// Start only after the binary check and subscription load of the startup sequence
+		if !ac.ReadyWithTimeout(startupReadyTimeout) {
+			log.Printf("restartOrphanedSingBox: Startup sequence did not finish in %v, starting anyway", startupReadyTimeout)
+		}
+		StartSingBoxProcess(ac, true)
+	}()
+	return true
+}
@@ -0,0 +1,129 @@
+package core
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"singbox-launcher/internal/dialogs"
+)
+
+// externalControllerPattern matches the value of "external_controller" in config.json
+var externalControllerPattern = regexp.MustCompile(`("external_controller"\s*:\s*")([^"]*)(")`)
+
+// CheckPortsAvailable tries to listen on every TCP address ("127.0.0.1:9090") and returns those
+// that are already in use
+func CheckPortsAvailable(addrs ...string) (busy []string) {
+	for _, addr := range addrs {
+		listener, err := net.Listen("tcp", addr)
+		if err != nil {
+			busy = append(busy, addr)
+			continue
+		}
+		listener.Close()
+	}
+	return busy
+}
+
+// FindFreePort returns a TCP port on host that is free right now: the system picks one for ":0"
+func FindFreePort(host string) (int, error) {
+	listener, err := net.Listen("tcp", net.JoinHostPort(host, "0"))
+	if err != nil {
+		return 0, fmt.Errorf("FindFreePort: %w", err)
+	}
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port, nil
+}
+
+// SetClashAPIConfig sets experimental.clash_api.external_controller of config.json to
+// externalController ("127.0.0.1:9091"). Only the value is replaced, comments and formatting are kept.
+func (ac *AppController) SetClashAPIConfig(externalController string) error {
+	if ac.ReadOnly {
+		return fmt.Errorf("SetClashAPIConfig: %w", ErrReadOnly)
+	}
+	configPath := ac.ConfigPath()
+	info, err := os.Stat(configPath)
+	if err != nil {
+		return fmt.Errorf("SetClashAPIConfig: %w", err)
+	}
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return fmt.Errorf("SetClashAPIConfig: %w", err)
+	}
+	if !externalControllerPattern.Match(data) {
+		return fmt.Errorf("SetClashAPIConfig: no external_controller in %s", configPath)
+	}
+	updated := externalControllerPattern.ReplaceAll(data, []byte("${1}"+externalController+"${3}"))
+	if err := os.WriteFile(configPath, updated, info.Mode().Perm()); err != nil {
+		return fmt.Errorf("SetClashAPIConfig: %w", err)
+	}
+	ac.invalidateClashAPIConfig()
+	log.Printf("SetClashAPIConfig: external_controller set to %s", externalController)
+	return nil
+}
+
+// clashAPIPortBlocksStart checks that the Clash API address of config.json is free. A previous
+// instance still holding it makes sing-box fail with an unhelpful message, so instead the user is
+// offered to move the Clash API to a free port.
+func clashAPIPortBlocksStart(ac *AppController) bool {
+	baseURL, _, err := ac.loadClashAPIConfig()
+	if err != nil {
+		// No Clash API in config.json, nothing to check
+		return false
+	}
+	apiURL, err := url.Parse(baseURL)
+	if err != nil || apiURL.Port() == "" {
+		return false
+	}
+	addr := apiURL.Host
+	if len(CheckPortsAvailable(addr)) == 0 {
+		return false
+	}
+
+	if ac.ReadOnly {
+		// config.json can't be changed to another port
+		log.Printf("startSingBox: Clash API port %s is in use", addr)
+		dialogs.ShowError(ac.MainWindow, fmt.Errorf("Clash API address %s is already in use", addr))
+		return true
+	}
+	host := apiURL.Hostname()
+	port, err := FindFreePort(host)
+	if err != nil {
+		log.Printf("startSingBox: Clash API port %s is in use: %v", addr, err)
+		dialogs.ShowError(ac.MainWindow, fmt.Errorf("Clash API address %s is already in use", addr))
+		return true
+	}
+	newAddr := net.JoinHostPort(host, strconv.Itoa(port))
+	log.Printf("startSingBox: Clash API port %s is in use, offering %s", addr, newAddr)
+	fyne.Do(func() {
+		label := widget.NewLabel(fmt.Sprintf(
+			"The Clash API address %s is already in use, sing-box would fail to start.\n"+
+				"A previous sing-box or launcher instance may still be running.\n\n"+
+				"Switch the Clash API to %s in config.json and start?", addr, newAddr))
+		label.Wrapping = fyne.TextWrapWord
+		d := dialog.NewCustomConfirm("Port In Use", fmt.Sprintf("Use Port %d", port), "Cancel", label, func(use bool) {
+			if !use {
+				return
+			}
+			go func() {
+				if err := ac.SetClashAPIConfig(newAddr); err != nil {
+					log.Printf("clashAPIPortBlocksStart: %v", err)
+					dialogs.ShowError(ac.MainWindow, err)
+					return
+				}
+				StartSingBoxProcess(ac)
+			}()
+		}, ac.MainWindow)
+		d.Resize(fyne.NewSize(460, 200))
+		d.Show()
+	})
+	return true
+}
@@ -0,0 +1,123 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"singbox-launcher/internal/constants"
+)
+
+// WindowGeometry is the saved size and position of a launcher window
+type WindowGeometry struct {
+	X      int     `json:"x"`
+	Y      int     `json:"y"`
+	Width  float32 `json:"width"`
+	Height float32 `json:"height"`
+	HasPos bool    `json:"has_pos"` // false if the position could not be read on this platform
+}
+
+// Preferences holds launcher UI settings stored in preferences.json
+type Preferences struct {
+	SchemaVersion    int  `json:"schema_version,omitempty"`
+	FirstRunComplete bool `json:"first_run_complete,omitempty"` // see MarkFirstRunComplete
+
+	Window       *WindowGeometry `json:"window,omitempty"`
+	Theme        string          `json:"theme,omitempty"`         // "system" (default), "light" or "dark"
+	PrimaryColor string          `json:"primary_color,omitempty"` // "#rrggbb", empty for the theme default
+	Language     string          `json:"language,omitempty"`      // UI language code, e.g. "en", "zh-CN"
+
+	// Index of the tab selected when the launcher was closed, restored on start unless ForgetLastTab
+	LastTabIndex  int  `json:"last_tab_index,omitempty"`
+	ForgetLastTab bool `json:"forget_last_tab,omitempty"` // "Remember last tab" is off in Settings
+
+	// Send the anonymous device ID (see AppController.GetDeviceID) with launcher requests and diagnostics
+	ShareDeviceID bool `json:"share_device_id,omitempty"`
+
+	// Floating Diagnostics window (see "Pop Out" on the Diagnostics tab)
+	DiagnosticsWindow *WindowGeometry `json:"diagnostics_window,omitempty"`
+
+	// sing-box versions the user does not want to update to, e.g. "1.12.0"
+	BlockedVersions []string `json:"blocked_versions,omitempty"`
+
+	// Switch selector groups to the fastest proxy when the selected one is slow, see AppController.SetAutoFallback
+	AutoFallback bool `json:"auto_fallback,omitempty"`
+
+	// Prefer "without_quic" sing-box builds when a release offers QUIC variants
+	CoreWithoutQUIC bool `json:"core_without_quic,omitempty"`
+
+	// Config Wizard: expanded/collapsed state of template sections by section name
+	SectionsExpanded map[string]bool `json:"sections_expanded,omitempty"`
+
+	// Folder (besides the launcher folder) file:// subscription sources may be read from
+	LocalSubscriptionsDir string `json:"local_subscriptions_dir,omitempty"`
+
+	// Environment variables added when starting sing-box, see AppController.EnvironmentVars
+	EnvironmentVars map[string]string `json:"environment_vars,omitempty"`
+
+	// Folder for downloaded binaries, empty for <ExecDir>/bin (see AppController.BinaryDir)
+	BinaryDir string `json:"binary_dir,omitempty"`
+
+	// Working directory of sing-box, empty for <ExecDir>/bin (see AppController.SingboxWorkDir)
+	SingboxWorkDir string `json:"singbox_work_dir,omitempty"`
+
+	// URL of the sing-box JSON schema used for field hints in the Config Wizard
+	JSONSchemaURL string `json:"json_schema_url,omitempty"`
+}
+
+// preferencesMutex serializes read-modify-write of preferences.json
+var preferencesMutex sync.Mutex
+
+// getPreferencesPath returns the path to preferences.json
+func (ac *AppController) getPreferencesPath() string {
+	return filepath.Join(ac.ExecDir(), constants.PreferencesName)
+}
+
+// LoadPreferences reads preferences.json. Missing or broken file yields default preferences.
+func (ac *AppController) LoadPreferences() Preferences {
+	preferencesMutex.Lock()
+	defer preferencesMutex.Unlock()
+	return ac.readPreferences()
+}
+
+// UpdatePreferences applies fn to the stored preferences and writes them back.
+// Fails with ErrReadOnly in read-only mode.
+func (ac *AppController) UpdatePreferences(fn func(p *Preferences)) error {
+	if ac.ReadOnly {
+		return fmt.Errorf("UpdatePreferences: %w", ErrReadOnly)
+	}
+	preferencesMutex.Lock()
+	defer preferencesMutex.Unlock()
+
+	prefs := ac.readPreferences()
+	fn(&prefs)
+
+	data, err := json.MarshalIndent(prefs, "", "  ")
+	if err != nil {
+		return fmt.Errorf("UpdatePreferences: failed to encode preferences: %w", err)
+	}
+	if err := os.WriteFile(ac.getPreferencesPath(), data, 0644); err != nil {
+		return fmt.Errorf("UpdatePreferences: failed to write preferences: %w", err)
+	}
+	return nil
+}
+
+// readPreferences reads preferences.json without locking
+func (ac *AppController) readPreferences() Preferences {
+	var prefs Preferences
+	data, err := os.ReadFile(ac.getPreferencesPath())
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("LoadPreferences: Failed to read preferences: %v", err)
+		}
+		return prefs
+	}
+	if err := json.Unmarshal(data, &prefs); err != nil {
+		log.Printf("LoadPreferences: Failed to parse preferences, using defaults: %v", err)
+		return Preferences{}
+	}
+	return prefs
+}
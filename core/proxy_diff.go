@@ -0,0 +1,103 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// ErrProxyChangesCancelled is returned by UpdateConfigFromSubscriptions when the user rejects the proxy list changes
+var ErrProxyChangesCancelled = errors.New("proxy list changes were not applied")
+
+// ProxyListDiff lists proxy tags that differ between the current config and a refreshed subscription
+type ProxyListDiff struct {
+	Added   []string
+	Removed []string
+	Changed []string // same tag, different settings (server, port, credentials, ...)
+}
+
+// HasChanges reports whether any proxy was added, removed or changed
+func (d ProxyListDiff) HasChanges() bool {
+	return len(d.Added) > 0 || len(d.Removed) > 0 || len(d.Changed) > 0
+}
+
+// DiffProxyLists compares two lists of proxy outbounds by tag
+func DiffProxyLists(old, new []map[string]interface{}) ProxyListDiff {
+	indexByTag := func(list []map[string]interface{}) map[string]string {
+		index := make(map[string]string, len(list))
+		for _, outbound := range list {
+			tag, _ := outbound["tag"].(string)
+			if tag == "" {
+				continue
+			}
+			// json.Marshal sorts map keys, so equal outbounds encode identically
+			encoded, _ := json.Marshal(outbound)
+			index[tag] = string(encoded)
+		}
+		return index
+	}
+	oldIndex := indexByTag(old)
+	newIndex := indexByTag(new)
+
+	var diff ProxyListDiff
+	for tag, encoded := range newIndex {
+		oldEncoded, ok := oldIndex[tag]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, tag)
+		case oldEncoded != encoded:
+			diff.Changed = append(diff.Changed, tag)
+		}
+	}
+	for tag := range oldIndex {
+		if _, ok := newIndex[tag]; !ok {
+			diff.Removed = append(diff.Removed, tag)
+		}
+	}
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+	sort.Strings(diff.Changed)
+	return diff
+}
+
+// parseProxyOutbounds parses generated outbound lines (JSONC objects separated by commas) skipping selectors
+func parseProxyOutbounds(content string) ([]map[string]interface{}, error) {
+	var outbounds []map[string]interface{}
+	if strings.TrimSpace(content) == "" {
+		return outbounds, nil
+	}
+	if err := json.Unmarshal(cleanJSONC([]byte("[\n"+content+"\n]")), &outbounds); err != nil {
+		return nil, fmt.Errorf("failed to parse outbounds: %w", err)
+	}
+
+	proxies := outbounds[:0]
+	for _, outbound := range outbounds {
+		switch outbound["type"] {
+		case "selector", "urltest":
+			continue
+		}
+		proxies = append(proxies, outbound)
+	}
+	return proxies, nil
+}
+
+// readParserOutbounds returns proxy outbounds currently written between @ParserSTART and @ParserEND
+func readParserOutbounds(configPath string) ([]map[string]interface{}, error) {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	configStr := string(data)
+
+	startMarker := "/** @ParserSTART */"
+	endMarker := "/** @ParserEND */"
+	startIdx := strings.Index(configStr, startMarker)
+	endIdx := strings.Index(configStr, endMarker)
+	if startIdx == -1 || endIdx == -1 || endIdx <= startIdx {
+		return nil, fmt.Errorf("markers @ParserSTART or @ParserEND not found in config.json")
+	}
+	return parseProxyOutbounds(configStr[startIdx+len(startMarker) : endIdx])
+}
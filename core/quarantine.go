@@ -0,0 +1,58 @@
+package core
+
+import (
+	"log"
+	"runtime"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"singbox-launcher/internal/dialogs"
+)
+
+// quarantineBlocksStart checks the sing-box binary for the macOS quarantine before it is started.
+// Returns true (and offers to remove the quarantine) if the binary is quarantined.
+func quarantineBlocksStart(ac *AppController) bool {
+	quarantined, err := IsQuarantined(ac.SingboxPath)
+	if err != nil {
+		log.Printf("startSingBox: Failed to check quarantine: %v", err)
+		return false
+	}
+	if !quarantined {
+		return false
+	}
+	log.Printf("startSingBox: %s is quarantined by macOS", ac.SingboxPath)
+	showQuarantineDialog(ac)
+	return true
+}
+
+// isQuarantineStartError reports whether a start error looks like Gatekeeper blocking the binary
+func isQuarantineStartError(err error) bool {
+	return runtime.GOOS == "darwin" && strings.Contains(strings.ToLower(err.Error()), "operation not permitted")
+}
+
+// showQuarantineDialog offers to remove the quarantine from the sing-box binary and start it again
+func showQuarantineDialog(ac *AppController) {
+	fyne.Do(func() {
+		label := widget.NewLabel("The sing-box binary is quarantined by macOS. Remove quarantine?\n\n" + ac.SingboxPath)
+		label.Wrapping = fyne.TextWrapWord
+		d := dialog.NewCustomConfirm("sing-box Quarantined", "Fix It", "Cancel", label, func(fix bool) {
+			if !fix {
+				return
+			}
+			go func() {
+				if err := RemoveQuarantine(ac.SingboxPath); err != nil {
+					log.Printf("showQuarantineDialog: %v", err)
+					dialogs.ShowError(ac.MainWindow, err)
+					return
+				}
+				log.Printf("showQuarantineDialog: Quarantine removed from %s, starting sing-box", ac.SingboxPath)
+				StartSingBoxProcess(ac)
+			}()
+		}, ac.MainWindow)
+		d.Resize(fyne.NewSize(420, 180))
+		d.Show()
+	})
+}
@@ -0,0 +1,37 @@
+//go:build darwin
+// +build darwin
+
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// quarantineAttr is the extended attribute Gatekeeper sets on downloaded files
+const quarantineAttr = "com.apple.quarantine"
+
+// IsQuarantined reports whether path has the com.apple.quarantine extended attribute
+func IsQuarantined(path string) (bool, error) {
+	_, err := unix.Getxattr(path, quarantineAttr, nil)
+	if err == nil {
+		return true, nil
+	}
+	if errors.Is(err, unix.ENOATTR) {
+		return false, nil
+	}
+	return false, fmt.Errorf("IsQuarantined: %w", err)
+}
+
+// RemoveQuarantine removes the com.apple.quarantine attribute from path (xattr -d)
+func RemoveQuarantine(path string) error {
+	out, err := exec.Command("xattr", "-d", quarantineAttr, path).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("RemoveQuarantine: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,14 @@
+//go:build !darwin
+// +build !darwin
+
+package core
+
+// IsQuarantined reports whether path is quarantined by Gatekeeper; only macOS has a quarantine
+func IsQuarantined(path string) (bool, error) {
+	return false, nil
+}
+
+// RemoveQuarantine is a no-op outside macOS
+func RemoveQuarantine(path string) error {
+	return nil
+}
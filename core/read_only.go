@@ -0,0 +1,34 @@
+package core
+
+import (
+	"errors"
+	"log"
+	"os"
+)
+
+// ErrReadOnly is returned by operations that would change the configuration in read-only mode
+var ErrReadOnly = errors.New("read-only mode: configuration changes are disabled")
+
+// WithReadOnly starts the launcher in read-only mode (the --read-only flag), see AppController.ReadOnly
+func WithReadOnly() ControllerOption {
+	return func(ac *AppController) { ac.ReadOnly = true }
+}
+
+// initReadOnly switches to read-only mode when config.json exists but cannot be written,
+// e.g. it was locked down by an administrator on a shared workstation
+func (ac *AppController) initReadOnly() {
+	if ac.ReadOnly {
+		log.Println("NewAppController: Read-only mode (--read-only)")
+		return
+	}
+	file, err := os.OpenFile(ac.ConfigPath(), os.O_WRONLY, 0)
+	if err == nil {
+		file.Close()
+		return
+	}
+	if os.IsNotExist(err) {
+		return
+	}
+	log.Printf("NewAppController: config.json is not writable, read-only mode: %v", err)
+	ac.ReadOnly = true
+}
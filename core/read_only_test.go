@@ -0,0 +1,40 @@
+package core
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestReadOnlyRejectsChanges(t *testing.T) {
+	ac := &AppController{ReadOnly: true, execDir: t.TempDir()}
+
+	if _, err := ac.SaveConfig(`{}`, ConfigAudit{}); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("SaveConfig() error = %v, want ErrReadOnly", err)
+	}
+	if err := ac.UpdatePreferences(func(p *Preferences) { p.Theme = "dark" }); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("UpdatePreferences() error = %v, want ErrReadOnly", err)
+	}
+	if err := ac.ResetToDefaults(); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("ResetToDefaults() error = %v, want ErrReadOnly", err)
+	}
+	if err := ac.SaveSnapshot("label"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("SaveSnapshot() error = %v, want ErrReadOnly", err)
+	}
+	if err := ac.RestoreSnapshot("id"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("RestoreSnapshot() error = %v, want ErrReadOnly", err)
+	}
+	if err := ac.DeleteSnapshot("id"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("DeleteSnapshot() error = %v, want ErrReadOnly", err)
+	}
+	if err := ac.ImportFullState("state.zip"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("ImportFullState() error = %v, want ErrReadOnly", err)
+	}
+
+	generated, err := ac.AutoGenerateConfigIfMissing(func() (string, ConfigAudit, error) {
+		t.Error("AutoGenerateConfigIfMissing built a config in read-only mode")
+		return `{}`, ConfigAudit{}, nil
+	})
+	if generated || err != nil {
+		t.Errorf("AutoGenerateConfigIfMissing() = %v, %v, want false, nil", generated, err)
+	}
+}
@@ -0,0 +1,85 @@
+package core
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// resetPaths returns the launcher state removed by ResetToDefaults. config.json, snapshots
+// and the downloaded binaries are kept: deleting config.json would break the running setup.
+func (ac *AppController) resetPaths() []string {
+	return []string{
+		ac.getPreferencesPath(),
+		ac.getSelectorChoicesPath(),
+		ac.getSubscriptionStorePath(),
+		ac.SubscriptionCacheDir(),
+	}
+}
+
+// ResetFiles returns the files and folders ResetToDefaults would delete: those of the
+// launcher state (preferences, selector choices, subscription refresh times and cache) that exist
+func (ac *AppController) ResetFiles() []string {
+	var existing []string
+	for _, path := range ac.resetPaths() {
+		if _, err := os.Stat(path); err == nil {
+			existing = append(existing, path)
+		}
+	}
+	return existing
+}
+
+// ResetToDefaults stops sing-box (unless it is externally managed), deletes the files listed by ResetFiles and drops what the
+// controller cached from them. Publishes PreferencesReset so the UI reloads the settings.
+func (ac *AppController) ResetToDefaults() error {
+	if ac.ReadOnly {
+		return fmt.Errorf("ResetToDefaults: %w", ErrReadOnly)
+	}
+	// Only the Stop button stops an adopted sing-box (system service, another launcher)
+	if ac.IsExternallyManaged() {
+		log.Println("ResetToDefaults: Leaving the externally managed sing-box running.")
+	} else if ac.RunningState.IsRunning() {
+		log.Println("ResetToDefaults: Stopping sing-box before reset...")
+		stopSingBox(ac, false)
+		if !waitForSingBoxStop(ac) {
+			log.Println("ResetToDefaults: Timeout waiting for sing-box to stop, resetting anyway.")
+		}
+	}
+
+	// Files that are read-modify-written at runtime are removed under their locks
+	preferencesMutex.Lock()
+	selectorChoicesMutex.Lock()
+	subscriptionStoreMutex.Lock()
+	var removeErr error
+	for _, path := range ac.ResetFiles() {
+		if err := os.RemoveAll(path); err != nil {
+			removeErr = fmt.Errorf("%s: %w", filepath.Base(path), err)
+			break
+		}
+		log.Printf("ResetToDefaults: Deleted %s", path)
+	}
+	subscriptionStoreMutex.Unlock()
+	selectorChoicesMutex.Unlock()
+	preferencesMutex.Unlock()
+
+	ac.invalidateOutboundTags()
+	ac.invalidateClashAPIConfig()
+	ac.reloadClashAPISettings("ResetToDefaults")
+	ac.SetAutoFallback(false)
+	// Settings loaded from preferences.json at startup go back to their defaults as well
+	ac.envMutex.Lock()
+	ac.EnvironmentVars = nil
+	ac.envMutex.Unlock()
+	ac.resetBinaryDir()
+	ac.setSingboxWorkDir(ac.BinaryDir)
+	// Without preferences.json the next start is a first run again
+	ac.IsFirstRun()
+	ac.firstRun.firstRun.Store(true)
+
+	ac.Events.Publish(PreferencesReset)
+	if removeErr != nil {
+		return fmt.Errorf("ResetToDefaults: %w", removeErr)
+	}
+	return nil
+}
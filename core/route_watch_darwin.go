@@ -0,0 +1,70 @@
+//go:build darwin
+// +build darwin
+
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// startRouteWatch calls notify after every route or interface change, reported by the
+// routing socket, until ctx is done
+func startRouteWatch(ctx context.Context, notify func()) error {
+	fd, err := unix.Socket(unix.AF_ROUTE, unix.SOCK_RAW, unix.AF_UNSPEC)
+	if err != nil {
+		return fmt.Errorf("startRouteWatch: %w", err)
+	}
+	// Reads time out to check ctx
+	timeout := unix.Timeval{Sec: 1}
+	if err := unix.SetsockoptTimeval(fd, unix.SOL_SOCKET, unix.SO_RCVTIMEO, &timeout); err != nil {
+		unix.Close(fd)
+		return fmt.Errorf("startRouteWatch: %w", err)
+	}
+
+	go func() {
+		defer unix.Close(fd)
+		buf := make([]byte, 1<<16)
+		for ctx.Err() == nil {
+			n, err := unix.Read(fd, buf)
+			if err != nil {
+				if errors.Is(err, unix.EAGAIN) || errors.Is(err, unix.EINTR) {
+					continue
+				}
+				notify()
+				continue
+			}
+			// rt_msghdr: u_short rtm_msglen; u_char rtm_version; u_char rtm_type; ...
+			// Other messages (e.g. answers to "route get") must not trigger a check, or it would loop
+			if n < 4 {
+				continue
+			}
+			switch buf[3] {
+			case unix.RTM_ADD, unix.RTM_DELETE, unix.RTM_CHANGE, unix.RTM_IFINFO:
+				notify()
+			}
+		}
+	}()
+	return nil
+}
+
+// defaultRoutes returns the interface of the IPv4 default route ("route -n get default").
+// sing-box auto_route adds more specific routes, the default route keeps the physical interface.
+func defaultRoutes() ([]defaultRoute, error) {
+	output, err := exec.Command("route", "-n", "get", "default").Output()
+	if err != nil {
+		// No default route: "route: writing to routing socket: not in table"
+		return nil, nil
+	}
+	for _, line := range strings.Split(string(output), "\n") {
+		if name, ok := strings.CutPrefix(strings.TrimSpace(line), "interface:"); ok {
+			return []defaultRoute{{Interface: strings.TrimSpace(name)}}, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,97 @@
+//go:build linux
+// +build linux
+
+package core
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// startRouteWatch calls notify after every change of routes or links, reported by netlink,
+// until ctx is done
+func startRouteWatch(ctx context.Context, notify func()) error {
+	fd, err := unix.Socket(unix.AF_NETLINK, unix.SOCK_RAW|unix.SOCK_CLOEXEC, unix.NETLINK_ROUTE)
+	if err != nil {
+		return fmt.Errorf("startRouteWatch: %w", err)
+	}
+	addr := &unix.SockaddrNetlink{
+		Family: unix.AF_NETLINK,
+		Groups: unix.RTMGRP_LINK | unix.RTMGRP_IPV4_ROUTE | unix.RTMGRP_IPV6_ROUTE,
+	}
+	if err := unix.Bind(fd, addr); err != nil {
+		unix.Close(fd)
+		return fmt.Errorf("startRouteWatch: %w", err)
+	}
+	// Closing the socket does not interrupt a blocked read, so reads time out to check ctx
+	timeout := unix.Timeval{Sec: 1}
+	if err := unix.SetsockoptTimeval(fd, unix.SOL_SOCKET, unix.SO_RCVTIMEO, &timeout); err != nil {
+		unix.Close(fd)
+		return fmt.Errorf("startRouteWatch: %w", err)
+	}
+
+	go func() {
+		defer unix.Close(fd)
+		buf := make([]byte, 1<<16)
+		for ctx.Err() == nil {
+			n, _, err := unix.Recvfrom(fd, buf, 0)
+			if err != nil {
+				if errors.Is(err, unix.EAGAIN) || errors.Is(err, unix.EINTR) {
+					continue
+				}
+				// ENOBUFS: messages were dropped, the routing table may have changed
+				notify()
+				continue
+			}
+			messages, err := syscall.ParseNetlinkMessage(buf[:n])
+			if err != nil {
+				continue
+			}
+			for _, message := range messages {
+				switch message.Header.Type {
+				case unix.RTM_NEWROUTE, unix.RTM_DELROUTE, unix.RTM_NEWLINK, unix.RTM_DELLINK:
+					notify()
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// defaultRoutes returns the IPv4 default routes of the main routing table (/proc/net/route).
+// sing-box auto_route uses its own table, so its TUN does not appear here.
+func defaultRoutes() ([]defaultRoute, error) {
+	file, err := os.Open("/proc/net/route")
+	if err != nil {
+		return nil, fmt.Errorf("defaultRoutes: %w", err)
+	}
+	defer file.Close()
+
+	var routes []defaultRoute
+	scanner := bufio.NewScanner(file)
+	scanner.Scan() // Header: Iface Destination Gateway Flags RefCnt Use Metric Mask ...
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 8 || fields[1] != "00000000" || fields[7] != "00000000" {
+			continue
+		}
+		flags, err := strconv.ParseUint(fields[3], 16, 32)
+		if err != nil || flags&unix.RTF_UP == 0 {
+			continue
+		}
+		metric, _ := strconv.Atoi(fields[6])
+		routes = append(routes, defaultRoute{Interface: fields[0], Metric: metric})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("defaultRoutes: %w", err)
+	}
+	return routes, nil
+}
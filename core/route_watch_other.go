@@ -0,0 +1,22 @@
+//go:build !linux && !darwin && !windows
+// +build !linux,!darwin,!windows
+
+package core
+
+import (
+	"context"
+	"errors"
+)
+
+// errRouteWatchUnsupported - no way to read the routing table on this system
+var errRouteWatchUnsupported = errors.New("routing table is not supported on this system")
+
+// startRouteWatch is not supported, WatchDefaultInterface polls instead
+func startRouteWatch(ctx context.Context, notify func()) error {
+	return errRouteWatchUnsupported
+}
+
+// defaultRoutes is not supported on this system
+func defaultRoutes() ([]defaultRoute, error) {
+	return nil, errRouteWatchUnsupported
+}
@@ -0,0 +1,139 @@
+//go:build windows
+// +build windows
+
+package core
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	iphlpapi                   = windows.NewLazySystemDLL("iphlpapi.dll")
+	procNotifyRouteChange2     = iphlpapi.NewProc("NotifyRouteChange2")
+	procCancelMibChangeNotify2 = iphlpapi.NewProc("CancelMibChangeNotify2")
+	procGetIpForwardTable      = iphlpapi.NewProc("GetIpForwardTable")
+)
+
+// MIB_IPFORWARDROW is 14 DWORDs: dest, mask, policy, next hop, if index, type, proto, age,
+// next hop AS, metric1..metric5
+const (
+	ipForwardRowSize    = 14 * 4
+	ipForwardDest       = 0
+	ipForwardMask       = 1
+	ipForwardIfIndex    = 4
+	ipForwardMetric1    = 9
+	errInsufficientBuf  = uintptr(windows.ERROR_INSUFFICIENT_BUFFER)
+	addressFamilyUnspec = windows.AF_UNSPEC
+)
+
+var (
+	// routeChangeCallback is created once: callbacks made by syscall.NewCallback are never freed
+	routeChangeCallbackOnce sync.Once
+	routeChangeCallback     uintptr
+
+	// routeWatchers maps the caller context given to NotifyRouteChange2 to the notify function
+	routeWatchersMutex sync.Mutex
+	routeWatchers      = make(map[uintptr]func())
+	nextRouteWatcherID uintptr
+)
+
+// onRouteChange is called by Windows on its own thread for every changed route
+func onRouteChange(callerContext, row, notificationType uintptr) uintptr {
+	routeWatchersMutex.Lock()
+	notify := routeWatchers[callerContext]
+	routeWatchersMutex.Unlock()
+	if notify != nil {
+		notify()
+	}
+	return 0
+}
+
+// startRouteWatch calls notify after every route change (NotifyRouteChange2) until ctx is done
+func startRouteWatch(ctx context.Context, notify func()) error {
+	if err := procNotifyRouteChange2.Find(); err != nil {
+		return fmt.Errorf("startRouteWatch: %w", err)
+	}
+	routeChangeCallbackOnce.Do(func() {
+		routeChangeCallback = syscall.NewCallback(onRouteChange)
+	})
+
+	routeWatchersMutex.Lock()
+	nextRouteWatcherID++
+	id := nextRouteWatcherID
+	routeWatchers[id] = notify
+	routeWatchersMutex.Unlock()
+
+	var handle windows.Handle
+	r, _, _ := procNotifyRouteChange2.Call(
+		uintptr(addressFamilyUnspec),
+		routeChangeCallback,
+		id,
+		0, // No initial notification
+		uintptr(unsafe.Pointer(&handle)),
+	)
+	if r != 0 {
+		routeWatchersMutex.Lock()
+		delete(routeWatchers, id)
+		routeWatchersMutex.Unlock()
+		return fmt.Errorf("startRouteWatch: NotifyRouteChange2: %w", syscall.Errno(r))
+	}
+
+	go func() {
+		<-ctx.Done()
+		// Blocks until running callbacks return
+		procCancelMibChangeNotify2.Call(uintptr(handle))
+		routeWatchersMutex.Lock()
+		delete(routeWatchers, id)
+		routeWatchersMutex.Unlock()
+	}()
+	return nil
+}
+
+// defaultRoutes returns the IPv4 default routes (GetIpForwardTable). sing-box auto_route on
+// Windows adds its own default route through the TUN, DefaultInterface skips it.
+func defaultRoutes() ([]defaultRoute, error) {
+	if err := procGetIpForwardTable.Find(); err != nil {
+		return nil, fmt.Errorf("defaultRoutes: %w", err)
+	}
+	var size uint32
+	r, _, _ := procGetIpForwardTable.Call(0, uintptr(unsafe.Pointer(&size)), 0)
+	if r != errInsufficientBuf && r != 0 {
+		return nil, fmt.Errorf("defaultRoutes: GetIpForwardTable: %w", syscall.Errno(r))
+	}
+	if size < 4 {
+		return nil, nil
+	}
+	buf := make([]byte, size)
+	r, _, _ = procGetIpForwardTable.Call(uintptr(unsafe.Pointer(&buf[0])), uintptr(unsafe.Pointer(&size)), 0)
+	if r != 0 {
+		return nil, fmt.Errorf("defaultRoutes: GetIpForwardTable: %w", syscall.Errno(r))
+	}
+
+	// MIB_IPFORWARDTABLE: DWORD dwNumEntries, then the rows
+	count := int(*(*uint32)(unsafe.Pointer(&buf[0])))
+	var routes []defaultRoute
+	for i := 0; i < count; i++ {
+		offset := 4 + i*ipForwardRowSize
+		if offset+ipForwardRowSize > len(buf) {
+			break
+		}
+		field := func(n int) uint32 {
+			return *(*uint32)(unsafe.Pointer(&buf[offset+n*4]))
+		}
+		if field(ipForwardDest) != 0 || field(ipForwardMask) != 0 {
+			continue
+		}
+		name := interfaceNameByIndex(int(field(ipForwardIfIndex)))
+		if name == "" {
+			continue
+		}
+		routes = append(routes, defaultRoute{Interface: name, Metric: int(field(ipForwardMetric1))})
+	}
+	return routes, nil
+}
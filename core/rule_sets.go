@@ -0,0 +1,174 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"singbox-launcher/internal/constants"
+)
+
+// RuleSetInfo describes a local sing-box rule-set file in bin/rule-sets
+type RuleSetInfo struct {
+	Name      string
+	Path      string
+	Size      int64
+	ModTime   time.Time
+	SourceURL string // URL the file was downloaded from, empty if added manually
+}
+
+// ruleSetSourcesMutex serializes read-modify-write of rule_set_sources.json
+var ruleSetSourcesMutex sync.Mutex
+
+// GetRuleSetsDir returns the rule-sets folder (<BinaryDir>/rule-sets)
+func (ac *AppController) GetRuleSetsDir() string {
+	return filepath.Join(ac.BinaryDir, constants.RuleSetsDirName)
+}
+
+// getRuleSetSourcesPath returns the path to data/rule_set_sources.json (file name -> source URL)
+func (ac *AppController) getRuleSetSourcesPath() string {
+	return filepath.Join(ac.ExecDir(), constants.DataDirName, constants.RuleSetSourcesName)
+}
+
+// isRuleSetFile reports whether name has a rule-set extension (.srs binary or .json source format)
+func isRuleSetFile(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	return ext == ".srs" || ext == ".json"
+}
+
+// ListRuleSets returns rule-set files from bin/rule-sets sorted by name
+func (ac *AppController) ListRuleSets() ([]RuleSetInfo, error) {
+	entries, err := os.ReadDir(ac.GetRuleSetsDir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("ListRuleSets: %w", err)
+	}
+
+	ruleSetSourcesMutex.Lock()
+	sources := ac.readRuleSetSources()
+	ruleSetSourcesMutex.Unlock()
+
+	var ruleSets []RuleSetInfo
+	for _, entry := range entries {
+		if entry.IsDir() || !isRuleSetFile(entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			log.Printf("ListRuleSets: Skipping %s: %v", entry.Name(), err)
+			continue
+		}
+		ruleSets = append(ruleSets, RuleSetInfo{
+			Name:      entry.Name(),
+			Path:      filepath.Join(ac.GetRuleSetsDir(), entry.Name()),
+			Size:      info.Size(),
+			ModTime:   info.ModTime(),
+			SourceURL: sources[entry.Name()],
+		})
+	}
+	sort.Slice(ruleSets, func(i, j int) bool { return ruleSets[i].Name < ruleSets[j].Name })
+	return ruleSets, nil
+}
+
+// RuleSetFileName returns the file name a rule-set downloaded from rawURL is saved as
+func RuleSetFileName(rawURL string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("invalid rule-set URL: %s", rawURL)
+	}
+	name := filepath.Base(u.Path)
+	if !isRuleSetFile(name) {
+		return "", fmt.Errorf("rule-set URL must point to a .srs or .json file: %s", rawURL)
+	}
+	return name, nil
+}
+
+// DownloadRuleSet downloads a rule-set from sourceURL to destPath and remembers its source URL.
+// The file is replaced only after a successful download. Progress is reported as 0-100.
+func (ac *AppController) DownloadRuleSet(sourceURL, destPath string, progressChan chan<- DownloadProgress) error {
+	if !isRuleSetFile(destPath) {
+		return fmt.Errorf("DownloadRuleSet: rule-set must be a .srs or .json file: %s", filepath.Base(destPath))
+	}
+	if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+		return fmt.Errorf("DownloadRuleSet: failed to create rule-sets folder: %w", err)
+	}
+
+	// downloadFileFromURL reports 15-80%, rescale to 0-100
+	rawProgress := make(chan DownloadProgress, 10)
+	forwarded := make(chan struct{})
+	go func() {
+		defer close(forwarded)
+		for p := range rawProgress {
+			p.Progress = (p.Progress - 15) * 100 / 65
+			progressChan <- p
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	tempPath := destPath + ".part"
+	err := ac.downloadFileFromURL(ctx, sourceURL, tempPath, rawProgress)
+	close(rawProgress)
+	<-forwarded
+	if err != nil {
+		os.Remove(tempPath)
+		return fmt.Errorf("DownloadRuleSet: %w", err)
+	}
+	if err := os.Rename(tempPath, destPath); err != nil {
+		os.Remove(tempPath)
+		return fmt.Errorf("DownloadRuleSet: failed to save %s: %w", filepath.Base(destPath), err)
+	}
+
+	if err := ac.saveRuleSetSource(filepath.Base(destPath), sourceURL); err != nil {
+		log.Printf("DownloadRuleSet: Failed to save source URL: %v", err)
+	}
+
+	log.Printf("DownloadRuleSet: Downloaded %s from %s", filepath.Base(destPath), sourceURL)
+	progressChan <- DownloadProgress{Progress: 100, Message: fmt.Sprintf("%s downloaded", filepath.Base(destPath)), Status: "done"}
+	return nil
+}
+
+// readRuleSetSources reads rule_set_sources.json without locking
+func (ac *AppController) readRuleSetSources() map[string]string {
+	sources := make(map[string]string)
+	data, err := os.ReadFile(ac.getRuleSetSourcesPath())
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("readRuleSetSources: %v", err)
+		}
+		return sources
+	}
+	if err := json.Unmarshal(data, &sources); err != nil {
+		log.Printf("readRuleSetSources: Failed to parse, ignoring: %v", err)
+		return make(map[string]string)
+	}
+	return sources
+}
+
+// saveRuleSetSource remembers the URL a rule-set file was downloaded from
+func (ac *AppController) saveRuleSetSource(name, sourceURL string) error {
+	ruleSetSourcesMutex.Lock()
+	defer ruleSetSourcesMutex.Unlock()
+
+	sources := ac.readRuleSetSources()
+	sources[name] = sourceURL
+	data, err := json.MarshalIndent(sources, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := ac.getRuleSetSourcesPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
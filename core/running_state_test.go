@@ -0,0 +1,38 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSetRunningPublishesOutsideCmdMutex(t *testing.T) {
+	ac := &AppController{}
+	ac.RunningState = NewRunningState(ac)
+	events := make(chan Event, 2)
+	ac.Events.Subscribe(func(event Event) {
+		// Takes CmdMutex: deadlocks if the event is published while SetRunning's caller holds it
+		ac.IsExternallyManaged()
+		events <- event
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ac.CmdMutex.Lock()
+		ac.RunningState.SetRunning(true)
+		ac.RunningState.SetRunning(false)
+		ac.CmdMutex.Unlock()
+	}()
+
+	for _, want := range []Event{CoreStarted, CoreStopped} {
+		select {
+		case got := <-events:
+			if got != want {
+				t.Fatalf("got %v, want %v", got, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%v was not published", want)
+		}
+	}
+	<-done
+}
@@ -0,0 +1,201 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+
+	"singbox-launcher/api"
+	"singbox-launcher/internal/constants"
+)
+
+// selectorSyncInterval is how often selector groups are polled for changes made outside the launcher (e.g. in Yacd)
+const selectorSyncInterval = 5 * time.Second
+
+// selectorChoicesMutex serializes read-modify-write of selector_choices.json
+var selectorChoicesMutex sync.Mutex
+
+// getSelectorChoicesPath returns the path to data/selector_choices.json
+func (ac *AppController) getSelectorChoicesPath() string {
+	return filepath.Join(ac.ExecDir(), constants.DataDirName, constants.SelectorChoicesName)
+}
+
+// LoadSelectorChoices returns saved selector choices (selector tag -> selected outbound tag)
+func (ac *AppController) LoadSelectorChoices() map[string]string {
+	selectorChoicesMutex.Lock()
+	defer selectorChoicesMutex.Unlock()
+	return ac.readSelectorChoices()
+}
+
+// SaveSelectorChoice remembers the outbound selected in a selector group
+func (ac *AppController) SaveSelectorChoice(tag, selected string) {
+	if tag == "" || selected == "" {
+		return
+	}
+	selectorChoicesMutex.Lock()
+	defer selectorChoicesMutex.Unlock()
+
+	choices := ac.readSelectorChoices()
+	if choices[tag] == selected {
+		return
+	}
+	choices[tag] = selected
+
+	data, err := json.MarshalIndent(choices, "", "  ")
+	if err != nil {
+		log.Printf("SaveSelectorChoice: Failed to encode choices: %v", err)
+		return
+	}
+	path := ac.getSelectorChoicesPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		log.Printf("SaveSelectorChoice: Failed to create data directory: %v", err)
+		return
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		log.Printf("SaveSelectorChoice: Failed to write choices: %v", err)
+		return
+	}
+	log.Printf("SaveSelectorChoice: Saved '%s' -> '%s'", tag, selected)
+}
+
+// readSelectorChoices reads selector_choices.json without locking
+func (ac *AppController) readSelectorChoices() map[string]string {
+	choices := make(map[string]string)
+	data, err := os.ReadFile(ac.getSelectorChoicesPath())
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("LoadSelectorChoices: Failed to read choices: %v", err)
+		}
+		return choices
+	}
+	if err := json.Unmarshal(data, &choices); err != nil {
+		log.Printf("LoadSelectorChoices: Failed to parse choices, ignoring: %v", err)
+		return make(map[string]string)
+	}
+	return choices
+}
+
+// SyncSelectorChoices restores saved selector choices once the Clash API of the given
+// sing-box process becomes available, then polls the selector groups and saves changes
+// made elsewhere. It returns when the process stops or is replaced.
+func (ac *AppController) SyncSelectorChoices(pid int) {
+	if !ac.ClashAPIEnabled {
+		return
+	}
+	groups, _, err := GetSelectorGroupsFromConfig(ac.ConfigPath())
+	if err != nil || len(groups) == 0 {
+		log.Printf("SyncSelectorChoices: No selector groups to sync: %v", err)
+		return
+	}
+
+	alive := func() bool {
+		if !ac.RunningState.IsRunning() {
+			return false
+		}
+		ac.CmdMutex.Lock()
+		defer ac.CmdMutex.Unlock()
+		return ac.SingboxCmd != nil && ac.SingboxCmd.Process != nil && ac.SingboxCmd.Process.Pid == pid
+	}
+
+	// Wait for the Clash API (same retry schedule as AutoLoadProxies)
+	current := make(map[string]string)
+	intervals := []time.Duration{1, 3, 3, 5, 5, 5, 5, 5, 10, 10, 10, 10, 15, 15}
+	for attempt, interval := range intervals {
+		select {
+		case <-ac.ShuttingDown():
+			return
+		case <-time.After(interval * time.Second):
+		}
+		if !alive() {
+			return
+		}
+		now, err := api.GetGroupNow(ac.ClashAPIBaseURL, ac.ClashAPIToken, groups[0], ac.ApiLogFile)
+		if err == nil {
+			current[groups[0]] = now
+			break
+		}
+		log.Printf("SyncSelectorChoices: Clash API not ready (attempt %d/%d): %v", attempt+1, len(intervals), err)
+		if attempt == len(intervals)-1 {
+			log.Printf("SyncSelectorChoices: Clash API did not become available, giving up")
+			return
+		}
+	}
+
+	// Restore saved choices
+	saved := ac.LoadSelectorChoices()
+	for _, group := range groups {
+		selected, ok := saved[group]
+		if !ok {
+			continue
+		}
+		if err := api.SwitchProxy(ac.ClashAPIBaseURL, ac.ClashAPIToken, group, selected, ac.ApiLogFile); err != nil {
+			// The outbound may no longer exist after a config update
+			log.Printf("SyncSelectorChoices: Failed to restore '%s' -> '%s': %v", group, selected, err)
+			continue
+		}
+		log.Printf("SyncSelectorChoices: Restored '%s' -> '%s'", group, selected)
+		current[group] = selected
+		ac.onSelectorChanged(group, selected)
+	}
+
+	// Watch for changes made outside the launcher
+	ticker := time.NewTicker(selectorSyncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ac.ShuttingDown():
+			return
+		case <-ticker.C:
+		}
+		if !alive() {
+			log.Printf("SyncSelectorChoices: sing-box process %d stopped, exiting", pid)
+			return
+		}
+		for _, group := range groups {
+			now, err := api.GetGroupNow(ac.ClashAPIBaseURL, ac.ClashAPIToken, group, ac.ApiLogFile)
+			if err != nil || now == "" {
+				continue
+			}
+			if prev, ok := current[group]; ok && prev == now {
+				continue
+			}
+			current[group] = now
+			ac.SaveSelectorChoice(group, now)
+			ac.onSelectorChanged(group, now)
+		}
+	}
+}
+
+// onSelectorChanged updates the active proxy shown in the UI when the selected group changes
+func (ac *AppController) onSelectorChanged(group, selected string) {
+	if group != ac.GetSelectedClashGroup() || ac.GetActiveProxyName() == selected {
+		return
+	}
+	fyne.Do(func() {
+		ac.SetActiveProxyName(selected)
+		if ac.ProxiesListWidget != nil {
+			ac.ProxiesListWidget.Refresh()
+		}
+		if ac.UpdateTrayMenuFunc != nil {
+			ac.UpdateTrayMenuFunc()
+		}
+	})
+}
+
+// SwitchProxyGroup selects proxy in a selector group via the Clash API and remembers the choice
+func (ac *AppController) SwitchProxyGroup(group, proxy string) error {
+	if !ac.ClashAPIEnabled {
+		return fmt.Errorf("SwitchProxyGroup: Clash API is disabled")
+	}
+	if err := api.SwitchProxy(ac.ClashAPIBaseURL, ac.ClashAPIToken, group, proxy, ac.ApiLogFile); err != nil {
+		return err
+	}
+	ac.SaveSelectorChoice(group, proxy)
+	return nil
+}
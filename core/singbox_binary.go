@@ -0,0 +1,44 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// FindSingboxBinary returns the sing-box binary to run: SingboxPath (<BinaryDir>/sing-box) if it
+// exists, otherwise, if searchPath, a sing-box found in PATH (e.g. installed by a package manager).
+// Returns an error wrapping os.ErrNotExist if there is none.
+func (ac *AppController) FindSingboxBinary(searchPath bool) (string, error) {
+	if info, err := os.Stat(ac.SingboxPath); err == nil && info.Mode().IsRegular() {
+		return ac.SingboxPath, nil
+	}
+	if searchPath {
+		name := strings.TrimSuffix(filepath.Base(ac.SingboxPath), ".exe") // LookPath adds .exe on Windows
+		if path, err := exec.LookPath(name); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("FindSingboxBinary: sing-box not found at %s: %w", ac.SingboxPath, os.ErrNotExist)
+}
+
+// ExternalSingboxPath returns the sing-box from PATH that is used because SingboxPath does not
+// exist. The launcher does not download updates over such a binary. ok is false otherwise.
+func (ac *AppController) ExternalSingboxPath() (path string, ok bool) {
+	path, err := ac.FindSingboxBinary(true)
+	if err != nil || path == ac.SingboxPath {
+		return "", false
+	}
+	return path, true
+}
+
+// singboxBinary returns the binary to start and query: FindSingboxBinary(true), or SingboxPath
+// if there is none (the error then surfaces where it is run)
+func (ac *AppController) singboxBinary() string {
+	if path, err := ac.FindSingboxBinary(true); err == nil {
+		return path
+	}
+	return ac.SingboxPath
+}
@@ -0,0 +1,63 @@
+package core
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+)
+
+// ValidateEnvironmentVarName checks that name can be used as an environment variable name
+func ValidateEnvironmentVarName(name string) error {
+	if name == "" {
+		return fmt.Errorf("environment variable name is empty")
+	}
+	if strings.ContainsAny(name, "=\x00") || strings.TrimSpace(name) != name {
+		return fmt.Errorf("invalid environment variable name %q", name)
+	}
+	return nil
+}
+
+// SetEnvironmentVars replaces the environment variables passed to sing-box and saves them
+// in preferences. They are applied on the next start of sing-box.
+func (ac *AppController) SetEnvironmentVars(vars map[string]string) error {
+	for name, value := range vars {
+		if err := ValidateEnvironmentVarName(name); err != nil {
+			return fmt.Errorf("SetEnvironmentVars: %w", err)
+		}
+		if strings.ContainsRune(value, 0) {
+			return fmt.Errorf("SetEnvironmentVars: value of %s contains a NUL character", name)
+		}
+	}
+	if err := ac.UpdatePreferences(func(p *Preferences) { p.EnvironmentVars = vars }); err != nil {
+		return fmt.Errorf("SetEnvironmentVars: %w", err)
+	}
+
+	ac.envMutex.Lock()
+	ac.EnvironmentVars = vars
+	ac.envMutex.Unlock()
+	log.Printf("SetEnvironmentVars: %d variables set for sing-box", len(vars))
+	return nil
+}
+
+// singboxEnvironment returns the launcher environment with EnvironmentVars on top
+// (exec keeps the last value of a duplicated variable)
+func (ac *AppController) singboxEnvironment() []string {
+	ac.envMutex.Lock()
+	defer ac.envMutex.Unlock()
+
+	env := os.Environ()
+	names := make([]string, 0, len(ac.EnvironmentVars))
+	for name := range ac.EnvironmentVars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		env = append(env, name+"="+ac.EnvironmentVars[name])
+	}
+	if len(names) > 0 {
+		log.Printf("startSingBox: Environment overrides: %s", strings.Join(names, ", "))
+	}
+	return env
+}
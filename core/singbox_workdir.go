@@ -0,0 +1,60 @@
+package core
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ValidateSingboxWorkDir checks that dir exists, is a directory and is writable
+// (sing-box writes caches and downloaded rule sets relative to its working directory)
+func ValidateSingboxWorkDir(dir string) error {
+	if !filepath.IsAbs(dir) {
+		return fmt.Errorf("working directory must be an absolute path: %s", dir)
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return fmt.Errorf("working directory is not accessible: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", dir)
+	}
+	if err := checkDirWritable(dir); err != nil {
+		return fmt.Errorf("working directory is not writable: %w", err)
+	}
+	return nil
+}
+
+// SetSingboxWorkDir validates dir and saves it as the sing-box working directory.
+// An empty dir restores the default (BinaryDir). Applied on the next start of sing-box.
+func (ac *AppController) SetSingboxWorkDir(dir string) error {
+	dir = strings.TrimSpace(dir)
+	workDir := dir
+	if dir == "" {
+		workDir = ac.BinaryDir
+	} else if err := ValidateSingboxWorkDir(dir); err != nil {
+		return fmt.Errorf("SetSingboxWorkDir: %w", err)
+	}
+	if err := ac.UpdatePreferences(func(p *Preferences) { p.SingboxWorkDir = dir }); err != nil {
+		return fmt.Errorf("SetSingboxWorkDir: %w", err)
+	}
+	ac.setSingboxWorkDir(workDir)
+	log.Printf("SetSingboxWorkDir: sing-box working directory set to %s", workDir)
+	return nil
+}
+
+// GetSingboxWorkDir returns the working directory sing-box is started in
+func (ac *AppController) GetSingboxWorkDir() string {
+	ac.workDirMutex.Lock()
+	defer ac.workDirMutex.Unlock()
+	return ac.SingboxWorkDir
+}
+
+// setSingboxWorkDir replaces SingboxWorkDir under workDirMutex
+func (ac *AppController) setSingboxWorkDir(dir string) {
+	ac.workDirMutex.Lock()
+	defer ac.workDirMutex.Unlock()
+	ac.SingboxWorkDir = dir
+}
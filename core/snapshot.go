@@ -0,0 +1,208 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// snapshotsDirName is the directory (relative to ExecDir) where config snapshots are stored
+const snapshotsDirName = "snapshots"
+
+// Snapshot describes a labelled save point of config.json
+type Snapshot struct {
+	ID         string    `json:"id"`
+	Label      string    `json:"label"`
+	CreatedAt  time.Time `json:"created_at"`
+	ConfigHash string    `json:"config_hash"` // SHA-256 of config contents
+}
+
+// snapshotFile is the on-disk representation of a snapshot (metadata + config contents)
+type snapshotFile struct {
+	Snapshot
+	Config string `json:"config"`
+}
+
+// getSnapshotsDir returns the path to the snapshots directory
+func (ac *AppController) getSnapshotsDir() string {
+	return filepath.Join(ac.ExecDir(), snapshotsDirName)
+}
+
+// getSnapshotPath returns the path to the snapshot file with the given ID
+func (ac *AppController) getSnapshotPath(id string) (string, error) {
+	// IDs are generated by newUUIDv4, reject anything that could escape the directory
+	if id == "" || strings.ContainsAny(id, `/\.`) {
+		return "", fmt.Errorf("invalid snapshot id: %q", id)
+	}
+	return filepath.Join(ac.getSnapshotsDir(), id+".json"), nil
+}
+
+// SaveSnapshot stores the current config.json as a labelled snapshot.
+// Fails with ErrReadOnly in read-only mode.
+func (ac *AppController) SaveSnapshot(label string) error {
+	if ac.ReadOnly {
+		return fmt.Errorf("SaveSnapshot: %w", ErrReadOnly)
+	}
+	data, err := os.ReadFile(ac.ConfigPath())
+	if err != nil {
+		return fmt.Errorf("SaveSnapshot: failed to read config.json: %w", err)
+	}
+
+	id, err := newUUIDv4()
+	if err != nil {
+		return fmt.Errorf("SaveSnapshot: failed to generate snapshot id: %w", err)
+	}
+
+	hash := sha256.Sum256(data)
+	file := snapshotFile{
+		Snapshot: Snapshot{
+			ID:         id,
+			Label:      strings.TrimSpace(label),
+			CreatedAt:  time.Now().UTC(),
+			ConfigHash: hex.EncodeToString(hash[:]),
+		},
+		Config: string(data),
+	}
+
+	encoded, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("SaveSnapshot: failed to encode snapshot: %w", err)
+	}
+
+	if err := os.MkdirAll(ac.getSnapshotsDir(), 0755); err != nil {
+		return fmt.Errorf("SaveSnapshot: failed to create snapshots directory: %w", err)
+	}
+	path, err := ac.getSnapshotPath(id)
+	if err != nil {
+		return fmt.Errorf("SaveSnapshot: %w", err)
+	}
+	if err := os.WriteFile(path, encoded, 0644); err != nil {
+		return fmt.Errorf("SaveSnapshot: failed to write snapshot: %w", err)
+	}
+
+	log.Printf("SaveSnapshot: Saved snapshot %s (label: %q)", id, file.Label)
+	if ac.UpdateSnapshotsFunc != nil {
+		ac.UpdateSnapshotsFunc()
+	}
+	return nil
+}
+
+// ListSnapshots returns all stored snapshots, newest first
+func (ac *AppController) ListSnapshots() ([]Snapshot, error) {
+	entries, err := os.ReadDir(ac.getSnapshotsDir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Snapshot{}, nil
+		}
+		return nil, fmt.Errorf("ListSnapshots: failed to read snapshots directory: %w", err)
+	}
+
+	snapshots := make([]Snapshot, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		file, err := readSnapshotFile(filepath.Join(ac.getSnapshotsDir(), entry.Name()))
+		if err != nil {
+			log.Printf("ListSnapshots: Skipping %s: %v", entry.Name(), err)
+			continue
+		}
+		snapshots = append(snapshots, file.Snapshot)
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].CreatedAt.After(snapshots[j].CreatedAt)
+	})
+	return snapshots, nil
+}
+
+// RestoreSnapshot stops sing-box, writes the snapshot contents to config.json
+// and restarts sing-box if it was running before the restore.
+// Fails with ErrReadOnly in read-only mode.
+func (ac *AppController) RestoreSnapshot(id string) error {
+	if ac.ReadOnly {
+		return fmt.Errorf("RestoreSnapshot: %w", ErrReadOnly)
+	}
+	path, err := ac.getSnapshotPath(id)
+	if err != nil {
+		return fmt.Errorf("RestoreSnapshot: %w", err)
+	}
+	file, err := readSnapshotFile(path)
+	if err != nil {
+		return fmt.Errorf("RestoreSnapshot: %w", err)
+	}
+
+	if !snapshotHashMatches(file) {
+		return fmt.Errorf("RestoreSnapshot: snapshot %s is corrupted (hash mismatch)", id)
+	}
+
+	wasRunning := ac.RunningState.IsRunning()
+	if wasRunning {
+		log.Println("RestoreSnapshot: Stopping sing-box before restore...")
+		StopSingBoxProcess(ac)
+		if !waitForSingBoxStop(ac) {
+			log.Println("RestoreSnapshot: Timeout waiting for sing-box to stop, restoring anyway.")
+		}
+	}
+
+	if err := os.WriteFile(ac.ConfigPath(), []byte(file.Config), 0644); err != nil {
+		return fmt.Errorf("RestoreSnapshot: failed to write config.json: %w", err)
+	}
+	log.Printf("RestoreSnapshot: Restored snapshot %s (label: %q)", id, file.Label)
+
+	if ac.UpdateConfigStatusFunc != nil {
+		ac.UpdateConfigStatusFunc()
+	}
+
+	if wasRunning {
+		log.Println("RestoreSnapshot: Restarting sing-box with restored config...")
+		StartSingBoxProcess(ac, true)
+	}
+	return nil
+}
+
+// DeleteSnapshot removes the snapshot with the given ID.
+// Fails with ErrReadOnly in read-only mode.
+func (ac *AppController) DeleteSnapshot(id string) error {
+	if ac.ReadOnly {
+		return fmt.Errorf("DeleteSnapshot: %w", ErrReadOnly)
+	}
+	path, err := ac.getSnapshotPath(id)
+	if err != nil {
+		return fmt.Errorf("DeleteSnapshot: %w", err)
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("DeleteSnapshot: failed to remove snapshot: %w", err)
+	}
+	log.Printf("DeleteSnapshot: Deleted snapshot %s", id)
+	if ac.UpdateSnapshotsFunc != nil {
+		ac.UpdateSnapshotsFunc()
+	}
+	return nil
+}
+
+// readSnapshotFile reads and decodes a snapshot file
+func readSnapshotFile(path string) (*snapshotFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	var file snapshotFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot: %w", err)
+	}
+	return &file, nil
+}
+
+// snapshotHashMatches reports whether the config contents of a snapshot match its stored hash
+func snapshotHashMatches(file *snapshotFile) bool {
+	hash := sha256.Sum256([]byte(file.Config))
+	return hex.EncodeToString(hash[:]) == file.ConfigHash
+}
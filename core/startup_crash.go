@@ -0,0 +1,96 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"singbox-launcher/internal/dialogs"
+)
+
+const (
+	// startupCrashWindow - sing-box exiting sooner than this after start failed to start
+	// (bad config, port in use, missing permissions) rather than crashed
+	startupCrashWindow = 2 * time.Second
+	// startupCrashLogLines - how many lines of sing-box output the startup error shows
+	startupCrashLogLines = 20
+)
+
+// childLogSize returns the current size of sing-box.log, so the output of the process being
+// started can be told apart from the output of earlier runs
+func (ac *AppController) childLogSize() int64 {
+	info, err := os.Stat(filepath.Join(ac.ExecDir(), childLogFileName))
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
+// isStartupCrash reports whether the monitored process exited within startupCrashWindow of its start.
+// Must be called with CmdMutex held.
+func (ac *AppController) isStartupCrash() bool {
+	return !ac.singboxStartedAt.IsZero() && time.Since(ac.singboxStartedAt) < startupCrashWindow
+}
+
+// handleStartupCrash is called instead of the auto-restart when sing-box exits right after start:
+// restarting would fail the same way. Shows the last lines sing-box wrote.
+func (ac *AppController) handleStartupCrash(exitErr error) {
+	// StartupFailed is set first: the Core Dashboard reads it on CoreStopped
+	ac.StartupFailed = true
+	ac.ConsecutiveCrashAttempts = 0
+	ac.RunningState.SetRunning(false)
+
+	output := readLogTail(filepath.Join(ac.ExecDir(), childLogFileName), ac.singboxLogOffset, startupCrashLogLines)
+	log.Printf("monitorSingBox: Sing-Box failed to start (exited after %v): %v", time.Since(ac.singboxStartedAt).Round(time.Millisecond), exitErr)
+
+	reason := "exited immediately after start"
+	if exitErr != nil {
+		reason = fmt.Sprintf("exited immediately after start: %v", exitErr)
+	}
+	if output == "" {
+		output = "(sing-box wrote nothing)"
+	}
+	fyne.Do(func() {
+		outputLabel := widget.NewLabel(output)
+		outputLabel.TextStyle = fyne.TextStyle{Monospace: true}
+		scroll := container.NewScroll(outputLabel)
+		scroll.SetMinSize(fyne.NewSize(600, 300))
+		content := container.NewBorder(
+			widget.NewLabel(fmt.Sprintf("Sing-Box %s. Last lines of sing-box.log:", reason)),
+			nil, nil, nil, scroll)
+		dialogs.ShowCustom(ac.MainWindow, "Failed to start", "Close", content)
+	})
+}
+
+// readLogTail returns the last n lines written to path after offset. If the file was
+// rotated (it is shorter than offset), the whole file is read.
+func readLogTail(path string, offset int64, n int) string {
+	file, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+	if info, err := file.Stat(); err == nil && info.Size() < offset {
+		offset = 0
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return ""
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return ""
+	}
+	lines := strings.Split(strings.TrimRight(string(data), "\r\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,323 @@
+package core
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"singbox-launcher/internal/constants"
+)
+
+// Names of the files inside a full state archive (see ExportFullState)
+const (
+	stateArchiveConfig          = constants.ConfigFileName
+	stateArchivePreferences     = constants.PreferencesName
+	stateArchiveSelectorChoices = constants.DataDirName + "/" + constants.SelectorChoicesName
+	stateArchiveSubscriptions   = constants.DataDirName + "/" + constants.SubscriptionsName
+	stateArchiveSnapshotsDir    = snapshotsDirName + "/"
+)
+
+// maxStateArchiveFileSize limits each extracted file, a real state file is far smaller
+const maxStateArchiveFileSize = 50 * 1024 * 1024 // 50 MB
+
+// stateFile is a file that is part of the full app state
+type stateFile struct {
+	Name string // name inside the archive (forward slashes)
+	Path string // path on disk
+}
+
+// listStateFiles returns the existing app state files: config.json, preferences.json,
+// selector choices, subscription refresh times and config snapshots
+func (ac *AppController) listStateFiles() ([]stateFile, error) {
+	candidates := []stateFile{
+		{Name: stateArchiveConfig, Path: ac.ConfigPath()},
+		{Name: stateArchivePreferences, Path: ac.getPreferencesPath()},
+		{Name: stateArchiveSelectorChoices, Path: ac.getSelectorChoicesPath()},
+		{Name: stateArchiveSubscriptions, Path: ac.getSubscriptionStorePath()},
+	}
+	snapshots, err := ac.ListSnapshots()
+	if err != nil {
+		return nil, err
+	}
+	for _, snapshot := range snapshots {
+		snapshotPath, err := ac.getSnapshotPath(snapshot.ID)
+		if err != nil {
+			continue
+		}
+		candidates = append(candidates, stateFile{Name: stateArchiveSnapshotsDir + snapshot.ID + ".json", Path: snapshotPath})
+	}
+
+	var files []stateFile
+	for _, file := range candidates {
+		if _, err := os.Stat(file.Path); err == nil {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+// stateFilePath maps a name inside a state archive to its path on disk.
+// Unknown names (and anything that could escape the launcher folder) are rejected.
+func (ac *AppController) stateFilePath(name string) (string, error) {
+	switch name {
+	case stateArchiveConfig:
+		return ac.ConfigPath(), nil
+	case stateArchivePreferences:
+		return ac.getPreferencesPath(), nil
+	case stateArchiveSelectorChoices:
+		return ac.getSelectorChoicesPath(), nil
+	case stateArchiveSubscriptions:
+		return ac.getSubscriptionStorePath(), nil
+	}
+	if dir, file := path.Split(name); dir == stateArchiveSnapshotsDir && strings.HasSuffix(file, ".json") {
+		return ac.getSnapshotPath(strings.TrimSuffix(file, ".json"))
+	}
+	return "", fmt.Errorf("unexpected file %q", name)
+}
+
+// ExportFullState writes config.json, preferences, selector choices, subscription refresh times and all snapshots
+// into a ZIP archive at destPath ("backup everything" for moving to another machine)
+func (ac *AppController) ExportFullState(destPath string) error {
+	files, err := ac.listStateFiles()
+	if err != nil {
+		return fmt.Errorf("ExportFullState: %w", err)
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("ExportFullState: nothing to export")
+	}
+
+	// Write to a temporary file first so a failed export doesn't leave a broken archive
+	tempPath := destPath + ".part"
+	out, err := os.Create(tempPath)
+	if err != nil {
+		return fmt.Errorf("ExportFullState: %w", err)
+	}
+	zw := zip.NewWriter(out)
+	for _, file := range files {
+		if err := addFileToZip(zw, file.Name, file.Path); err != nil {
+			zw.Close()
+			out.Close()
+			os.Remove(tempPath)
+			return fmt.Errorf("ExportFullState: %s: %w", file.Name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		out.Close()
+		os.Remove(tempPath)
+		return fmt.Errorf("ExportFullState: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(tempPath)
+		return fmt.Errorf("ExportFullState: %w", err)
+	}
+	if err := os.Rename(tempPath, destPath); err != nil {
+		os.Remove(tempPath)
+		return fmt.Errorf("ExportFullState: %w", err)
+	}
+
+	log.Printf("ExportFullState: Exported %d files to %s", len(files), destPath)
+	return nil
+}
+
+// addFileToZip copies the file at diskPath into zw as name
+func addFileToZip(zw *zip.Writer, name, diskPath string) error {
+	in, err := os.Open(diskPath)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return err
+	}
+	header.Name = name
+	header.Method = zip.Deflate
+	w, err := zw.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, in)
+	return err
+}
+
+// ReadStateArchiveManifest lists the files of a state archive that ImportFullState would restore
+func (ac *AppController) ReadStateArchiveManifest(srcPath string) ([]string, error) {
+	zr, err := zip.OpenReader(srcPath)
+	if err != nil {
+		return nil, fmt.Errorf("ReadStateArchiveManifest: %w", err)
+	}
+	defer zr.Close()
+
+	var names []string
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		if _, err := ac.stateFilePath(f.Name); err != nil {
+			return nil, fmt.Errorf("ReadStateArchiveManifest: not a launcher state archive: %w", err)
+		}
+		names = append(names, f.Name)
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("ReadStateArchiveManifest: archive is empty")
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// ImportFullState restores the files of a state archive created by ExportFullState.
+// The archive is extracted to a temporary folder and every file is validated before anything
+// is replaced. sing-box is stopped for the import and restarted if it was running.
+// Fails with ErrReadOnly in read-only mode.
+func (ac *AppController) ImportFullState(srcPath string) error {
+	if ac.ReadOnly {
+		return fmt.Errorf("ImportFullState: %w", ErrReadOnly)
+	}
+	zr, err := zip.OpenReader(srcPath)
+	if err != nil {
+		return fmt.Errorf("ImportFullState: %w", err)
+	}
+	defer zr.Close()
+
+	// Temporary folder next to the destination files, so they can be moved with os.Rename
+	tempDir, err := os.MkdirTemp(ac.ExecDir(), ".import-")
+	if err != nil {
+		return fmt.Errorf("ImportFullState: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	type extracted struct {
+		name, tempPath, destPath string
+	}
+	var files []extracted
+	for i, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		destPath, err := ac.stateFilePath(f.Name)
+		if err != nil {
+			return fmt.Errorf("ImportFullState: %w", err)
+		}
+		tempPath := filepath.Join(tempDir, fmt.Sprintf("%d.json", i))
+		if err := extractZipFile(f, tempPath); err != nil {
+			return fmt.Errorf("ImportFullState: %s: %w", f.Name, err)
+		}
+		if err := validateStateFile(f.Name, tempPath); err != nil {
+			return fmt.Errorf("ImportFullState: %s is invalid: %w", f.Name, err)
+		}
+		files = append(files, extracted{name: f.Name, tempPath: tempPath, destPath: destPath})
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("ImportFullState: archive is empty")
+	}
+
+	wasRunning := ac.RunningState.IsRunning()
+	if wasRunning {
+		log.Println("ImportFullState: Stopping sing-box before import...")
+		StopSingBoxProcess(ac)
+		if !waitForSingBoxStop(ac) {
+			log.Println("ImportFullState: Timeout waiting for sing-box to stop, importing anyway.")
+		}
+	}
+
+	// Files that are read-modify-written at runtime are replaced under their locks
+	preferencesMutex.Lock()
+	selectorChoicesMutex.Lock()
+	subscriptionStoreMutex.Lock()
+	var moveErr error
+	for _, file := range files {
+		if err := os.MkdirAll(filepath.Dir(file.destPath), 0755); err != nil {
+			moveErr = fmt.Errorf("%s: %w", file.name, err)
+			break
+		}
+		if err := os.Rename(file.tempPath, file.destPath); err != nil {
+			moveErr = fmt.Errorf("%s: %w", file.name, err)
+			break
+		}
+		log.Printf("ImportFullState: Restored %s", file.name)
+	}
+	subscriptionStoreMutex.Unlock()
+	selectorChoicesMutex.Unlock()
+	preferencesMutex.Unlock()
+
+	if ac.UpdateConfigStatusFunc != nil {
+		ac.UpdateConfigStatusFunc()
+	}
+	if ac.UpdateSnapshotsFunc != nil {
+		ac.UpdateSnapshotsFunc()
+	}
+	if moveErr != nil {
+		return fmt.Errorf("ImportFullState: %w", moveErr)
+	}
+
+	if wasRunning {
+		log.Println("ImportFullState: Restarting sing-box with imported config...")
+		StartSingBoxProcess(ac, true)
+	}
+	return nil
+}
+
+// extractZipFile writes a file of the archive to destPath
+func extractZipFile(f *zip.File, destPath string) error {
+	if f.UncompressedSize64 > maxStateArchiveFileSize {
+		return fmt.Errorf("file is too large (%s)", FormatBytesUtil(int64(f.UncompressedSize64)))
+	}
+	in, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(destPath)
+	if err != nil {
+		return err
+	}
+	// The header size can lie, limit what is actually copied too
+	if _, err := io.Copy(out, io.LimitReader(in, maxStateArchiveFileSize)); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// validateStateFile checks that an extracted file has the format expected for its name
+func validateStateFile(name, filePath string) error {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+	switch {
+	case name == stateArchiveConfig:
+		if !json.Valid(cleanJSONC(data)) {
+			return fmt.Errorf("not valid JSON")
+		}
+	case name == stateArchivePreferences:
+		var prefs Preferences
+		return json.Unmarshal(data, &prefs)
+	case name == stateArchiveSelectorChoices:
+		var choices map[string]string
+		return json.Unmarshal(data, &choices)
+	case name == stateArchiveSubscriptions:
+		var store SubscriptionStore
+		return json.Unmarshal(data, &store)
+	default: // snapshot
+		file, err := readSnapshotFile(filePath)
+		if err != nil {
+			return err
+		}
+		if !snapshotHashMatches(file) {
+			return fmt.Errorf("snapshot is corrupted (hash mismatch)")
+		}
+	}
+	return nil
+}
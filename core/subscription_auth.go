@@ -0,0 +1,162 @@
+package core
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/chacha20poly1305"
+
+	"singbox-launcher/internal/platform"
+)
+
+// encryptedSecretPrefix marks values encrypted by EncryptSecret
+const encryptedSecretPrefix = "enc:v1:"
+
+// maskedSecret is shown in the UI instead of secret values
+const maskedSecret = "****"
+
+// ErrCredentialsUnreadable is returned by FetchOptions when the saved credentials of a source
+// can't be decrypted, e.g. the machine ID changed or config.json was copied from another machine.
+// They have to be entered again in the Config Wizard (Auth...).
+var ErrCredentialsUnreadable = errors.New("saved credentials can't be decrypted on this machine")
+
+// FetchOptions holds optional request settings for FetchSubscriptionWithOptions
+type FetchOptions struct {
+	Authorization string            // Value of the Authorization header (e.g. "Bearer <token>")
+	Headers       map[string]string // Extra headers (e.g. Cookie)
+	LocalDirs     []string          // Folders file:// sources may be read from, see LocalSubscriptionDirs
+	CacheDir      string            // Folder for conditional requests (ETag/Last-Modified), see SubscriptionCacheDir; "" disables
+	MaxBodySize   int64             // Largest accepted response in bytes; 0 means DefaultMaxSubscriptionSize
+}
+
+// DefaultMaxSubscriptionSize limits subscription responses, so a misbehaving server can't exhaust memory
+const DefaultMaxSubscriptionSize = 10 * 1024 * 1024 // 10 MB
+
+var (
+	secretKeyOnce sync.Once
+	secretKey     []byte
+	secretKeyErr  error
+)
+
+// getSecretKey derives the encryption key for subscription secrets from the machine ID,
+// so encrypted values can only be decrypted on the same machine
+func getSecretKey() ([]byte, error) {
+	secretKeyOnce.Do(func() {
+		id, err := platform.GetMachineID()
+		if err != nil {
+			secretKeyErr = fmt.Errorf("failed to get machine id: %w", err)
+			return
+		}
+		sum := sha256.Sum256([]byte("singbox-launcher/subscription-secrets/" + id))
+		secretKey = sum[:]
+	})
+	return secretKey, secretKeyErr
+}
+
+// EncryptSecret encrypts a secret value for storing in config.json
+// Empty values are returned as is
+func EncryptSecret(plain string) (string, error) {
+	if plain == "" || strings.HasPrefix(plain, encryptedSecretPrefix) {
+		return plain, nil
+	}
+	key, err := getSecretKey()
+	if err != nil {
+		return "", err
+	}
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return "", fmt.Errorf("failed to create cipher: %w", err)
+	}
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plain)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(plain), nil)
+	return encryptedSecretPrefix + base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// DecryptSecret decrypts a value produced by EncryptSecret
+// Values without the encryption prefix are treated as plain text (e.g. edited by hand)
+func DecryptSecret(value string) (string, error) {
+	if !strings.HasPrefix(value, encryptedSecretPrefix) {
+		return value, nil
+	}
+	sealed, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(value, encryptedSecretPrefix))
+	if err != nil {
+		return "", fmt.Errorf("failed to decode secret: %w", err)
+	}
+	key, err := getSecretKey()
+	if err != nil {
+		return "", err
+	}
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return "", fmt.Errorf("failed to create cipher: %w", err)
+	}
+	if len(sealed) < aead.NonceSize() {
+		return "", fmt.Errorf("secret is too short")
+	}
+	plain, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt secret (was it saved on another machine?): %w", err)
+	}
+	return string(plain), nil
+}
+
+// MaskSecret returns a placeholder for non-empty secret values
+func MaskSecret(value string) string {
+	if value == "" {
+		return ""
+	}
+	return maskedSecret
+}
+
+// FetchOptions returns decrypted request options for this source
+func (p ProxySource) FetchOptions() (FetchOptions, error) {
+	var opts FetchOptions
+	auth, err := DecryptSecret(p.Authorization)
+	if err != nil {
+		return opts, fmt.Errorf("%w: authorization: %v", ErrCredentialsUnreadable, err)
+	}
+	opts.Authorization = auth
+	if len(p.ExtraHeaders) > 0 {
+		opts.Headers = make(map[string]string, len(p.ExtraHeaders))
+		for name, value := range p.ExtraHeaders {
+			plain, err := DecryptSecret(value)
+			if err != nil {
+				return opts, fmt.Errorf("%w: header %s: %v", ErrCredentialsUnreadable, name, err)
+			}
+			opts.Headers[name] = plain
+		}
+	}
+	return opts, nil
+}
+
+// SetAuth encrypts and stores authentication settings for this source
+func (p *ProxySource) SetAuth(authorization string, headers map[string]string) error {
+	auth, err := EncryptSecret(strings.TrimSpace(authorization))
+	if err != nil {
+		return fmt.Errorf("SetAuth: %w", err)
+	}
+	var encrypted map[string]string
+	if len(headers) > 0 {
+		encrypted = make(map[string]string, len(headers))
+		for name, value := range headers {
+			enc, err := EncryptSecret(value)
+			if err != nil {
+				return fmt.Errorf("SetAuth: %w", err)
+			}
+			encrypted[name] = enc
+		}
+	}
+	p.Authorization = auth
+	p.ExtraHeaders = encrypted
+	log.Printf("SetAuth: Updated authentication for %s (authorization: %v, headers: %d)", p.Source, auth != "", len(encrypted))
+	return nil
+}
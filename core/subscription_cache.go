@@ -0,0 +1,92 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"singbox-launcher/internal/constants"
+)
+
+// HTTPCacheMetadata holds the validators of a cached subscription response.
+// They are sent back as If-None-Match / If-Modified-Since on the next refresh.
+type HTTPCacheMetadata struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+}
+
+// SubscriptionCacheDir returns the folder where downloaded subscriptions are cached (data/subscriptions)
+func (ac *AppController) SubscriptionCacheDir() string {
+	return filepath.Join(ac.ExecDir(), constants.DataDirName, constants.SubscriptionCacheDirName)
+}
+
+// subscriptionCachePaths returns the content and metadata files for a subscription.
+// The authorization is part of the key: a different token may return a different list.
+func subscriptionCachePaths(cacheDir, url string, opts FetchOptions) (contentPath, metaPath string) {
+	sum := sha256.Sum256([]byte(url + "\n" + opts.Authorization))
+	name := hex.EncodeToString(sum[:])
+	return filepath.Join(cacheDir, name+".txt"), filepath.Join(cacheDir, name+".json")
+}
+
+// loadSubscriptionCache returns the cached (decoded) content and its metadata, nil if there is no usable cache
+func loadSubscriptionCache(cacheDir, url string, opts FetchOptions) ([]byte, *HTTPCacheMetadata) {
+	contentPath, metaPath := subscriptionCachePaths(cacheDir, url, opts)
+	data, err := os.ReadFile(metaPath)
+	if err != nil {
+		return nil, nil
+	}
+	var meta HTTPCacheMetadata
+	if err := json.Unmarshal(data, &meta); err != nil || (meta.ETag == "" && meta.LastModified == "") {
+		return nil, nil
+	}
+	content, err := os.ReadFile(contentPath)
+	if err != nil || len(content) == 0 {
+		return nil, nil
+	}
+	return content, &meta
+}
+
+// saveSubscriptionCache stores decoded content with the validators of resp.
+// Responses without ETag and Last-Modified can't be revalidated and are not cached.
+func saveSubscriptionCache(cacheDir, url string, opts FetchOptions, resp *http.Response, content []byte) {
+	meta := HTTPCacheMetadata{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+	contentPath, metaPath := subscriptionCachePaths(cacheDir, url, opts)
+	if meta.ETag == "" && meta.LastModified == "" {
+		os.Remove(metaPath)
+		return
+	}
+
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err == nil {
+		err = os.MkdirAll(cacheDir, 0755)
+	}
+	if err == nil {
+		err = os.WriteFile(contentPath, content, 0600)
+	}
+	if err == nil {
+		err = os.WriteFile(metaPath, data, 0600)
+	}
+	if err != nil {
+		log.Printf("saveSubscriptionCache: Failed to cache subscription: %v", err)
+	}
+}
+
+// setConditionalHeaders adds If-None-Match / If-Modified-Since for a cached response
+func setConditionalHeaders(req *http.Request, meta *HTTPCacheMetadata) {
+	if meta == nil {
+		return
+	}
+	if meta.ETag != "" {
+		req.Header.Set("If-None-Match", meta.ETag)
+	}
+	if meta.LastModified != "" {
+		req.Header.Set("If-Modified-Since", meta.LastModified)
+	}
+}
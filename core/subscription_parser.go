@@ -8,30 +8,65 @@ import (
 	"io"
 	"log"
 	"net/http"
+	"net/http/httptrace"
+	"net/url"
 	"os"
 	"regexp"
 	"strings"
 	"time"
 )
 
+// DetectBase64Encoding heuristically picks the base64 variant of s:
+// URL-safe alphabet if it contains '-' or '_', standard otherwise,
+// and the raw (unpadded) variant if the input has no '=' padding
+func DetectBase64Encoding(s string) *base64.Encoding {
+	urlSafe := strings.ContainsAny(s, "-_")
+	padded := strings.HasSuffix(s, "=") || len(s)%4 == 0
+	switch {
+	case urlSafe && padded:
+		return base64.URLEncoding
+	case urlSafe:
+		return base64.RawURLEncoding
+	case padded:
+		return base64.StdEncoding
+	default:
+		return base64.RawStdEncoding
+	}
+}
+
 // DecodeSubscriptionContent decodes subscription content from base64 or returns plain text
+// Supports standard and URL-safe alphabets, with or without padding, and CRLF line breaks
 // Returns decoded content and error if decoding fails
 func DecodeSubscriptionContent(content []byte) ([]byte, error) {
 	if len(content) == 0 {
 		return nil, fmt.Errorf("content is empty")
 	}
 
-	// Try to decode as base64
-	decoded, err := base64.URLEncoding.DecodeString(strings.TrimSpace(string(content)))
-	if err != nil {
-		// If URL encoding fails, try standard encoding
-		decoded, err = base64.StdEncoding.DecodeString(strings.TrimSpace(string(content)))
-		if err != nil {
-			// If both fail, assume it's plain text
-			log.Printf("DecodeSubscriptionContent: Content is not base64, treating as plain text")
-			return content, nil
+	// Some servers wrap base64 into lines (often with Windows line endings)
+	trimmed := strings.TrimSpace(string(content))
+	trimmed = strings.NewReplacer("\r", "", "\n", "").Replace(trimmed)
+
+	// Try the detected variant first, then all the others
+	encodings := []*base64.Encoding{
+		DetectBase64Encoding(trimmed),
+		base64.URLEncoding,
+		base64.StdEncoding,
+		base64.RawURLEncoding,
+		base64.RawStdEncoding,
+	}
+	var decoded []byte
+	var err error
+	for _, enc := range encodings {
+		decoded, err = enc.DecodeString(trimmed)
+		if err == nil {
+			break
 		}
 	}
+	if err != nil {
+		// If all variants fail, assume it's plain text
+		log.Printf("DecodeSubscriptionContent: Content is not base64, treating as plain text")
+		return content, nil
+	}
 
 	// Check if decoded content is empty
 	if len(decoded) == 0 {
@@ -44,6 +79,20 @@ func DecodeSubscriptionContent(content []byte) ([]byte, error) {
 // FetchSubscription fetches subscription content from URL and decodes it
 // Returns decoded content and error if fetch or decode fails
 func FetchSubscription(url string) ([]byte, error) {
+	return FetchSubscriptionWithOptions(url, FetchOptions{})
+}
+
+// FetchSubscriptionWithOptions fetches subscription content from URL with extra request
+// settings (authorization and custom headers) and decodes it.
+// file:// URLs are read from disk, only inside opts.LocalDirs; data: URIs carry the content inline.
+func FetchSubscriptionWithOptions(url string, opts FetchOptions) ([]byte, error) {
+	if IsFileURL(url) {
+		return fetchLocalSubscription(url, opts.LocalDirs)
+	}
+	if IsDataURI(url) {
+		return fetchDataURI(url)
+	}
+
 	// Создаем контекст с таймаутом
 	ctx, cancel := context.WithTimeout(context.Background(), NetworkRequestTimeout)
 	defer cancel()
@@ -57,7 +106,29 @@ func FetchSubscription(url string) ([]byte, error) {
 	}
 
 	// Set user agent to avoid blocking
-	req.Header.Set("User-Agent", "singbox-launcher/1.0")
+	req.Header.Set("User-Agent", UserAgent())
+	if opts.Authorization != "" {
+		req.Header.Set("Authorization", opts.Authorization)
+	}
+	for name, value := range opts.Headers {
+		req.Header.Set(name, value)
+	}
+
+	// Remember the server address for the size limit error below
+	var remoteAddr string
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			remoteAddr = info.Conn.RemoteAddr().String()
+		},
+	}))
+
+	// Conditional request: the server answers 304 if the cached content is still current
+	var cached []byte
+	if opts.CacheDir != "" {
+		var meta *HTTPCacheMetadata
+		cached, meta = loadSubscriptionCache(opts.CacheDir, url, opts)
+		setConditionalHeaders(req, meta)
+	}
 
 	resp, err := client.Do(req)
 	if err != nil {
@@ -69,14 +140,30 @@ func FetchSubscription(url string) ([]byte, error) {
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		log.Printf("FetchSubscription: Not modified, using cached content for %s", url)
+		return cached, nil
+	}
+	if isTooManyRedirects(resp) {
+		return nil, fmt.Errorf("Too many redirects (>%d) fetching subscription", maxHTTPRedirects)
+	}
 	if resp.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("subscription server returned status %d", resp.StatusCode)
 	}
 
-	content, err := io.ReadAll(resp.Body)
+	maxBodySize := opts.MaxBodySize
+	if maxBodySize <= 0 {
+		maxBodySize = DefaultMaxSubscriptionSize
+	}
+	// One byte more than the limit tells a response of exactly maxBodySize from a longer one
+	content, err := io.ReadAll(io.LimitReader(resp.Body, maxBodySize+1))
 	if err != nil {
 		return nil, fmt.Errorf("failed to read subscription content: %w", err)
 	}
+	if int64(len(content)) > maxBodySize {
+		log.Printf("FetchSubscription: Response of %s (server %s) exceeded %d bytes", url, remoteAddr, maxBodySize)
+		return nil, fmt.Errorf("subscription response exceeded %d bytes limit", maxBodySize)
+	}
 
 	// Check if content is empty
 	if len(content) == 0 {
@@ -89,6 +176,9 @@ func FetchSubscription(url string) ([]byte, error) {
 		return nil, fmt.Errorf("failed to decode subscription content: %w", err)
 	}
 
+	if opts.CacheDir != "" {
+		saveSubscriptionCache(opts.CacheDir, url, opts, resp, decoded)
+	}
 	return decoded, nil
 }
 
@@ -114,8 +204,58 @@ const ParserConfigVersion = 2
 
 // ProxySource represents a proxy subscription source
 type ProxySource struct {
-	Source string              `json:"source"`
-	Skip   []map[string]string `json:"skip,omitempty"`
+	Source        string              `json:"source"`
+	Name          string              `json:"name,omitempty"`        // Human-readable label, see DisplayName
+	Icon          string              `json:"icon,omitempty"`        // Optional emoji shown before the name
+	PrefixTags    bool                `json:"prefix_tags,omitempty"` // Prefix proxy tags with the name, see PrefixTag
+	Skip          []map[string]string `json:"skip,omitempty"`
+	SkipPatterns  []string            `json:"skip_patterns,omitempty"` // Go regular expressions matched against the proxy tag
+	Authorization string              `json:"authorization,omitempty"` // Encrypted, see EncryptSecret
+	ExtraHeaders  map[string]string   `json:"extra_headers,omitempty"` // Values encrypted, see EncryptSecret
+}
+
+// DisplayName returns Name, or a name generated from Source when it is empty:
+// the host for URLs (subscription.example.com), the file name for file:// sources
+func (p ProxySource) DisplayName() string {
+	if name := strings.TrimSpace(p.Name); name != "" {
+		return name
+	}
+	return ProxySourceNameFromURL(p.Source)
+}
+
+// Label returns the icon (if any) and DisplayName, for progress messages and lists
+func (p ProxySource) Label() string {
+	if icon := strings.TrimSpace(p.Icon); icon != "" {
+		return icon + " " + p.DisplayName()
+	}
+	return p.DisplayName()
+}
+
+// PrefixTag returns tag prefixed with the subscription name ("Name / tag") if PrefixTags is set.
+// This keeps servers with the same name in different subscriptions apart.
+func (p ProxySource) PrefixTag(tag string) string {
+	if !p.PrefixTags {
+		return tag
+	}
+	return p.DisplayName() + " / " + tag
+}
+
+// ProxySourceNameFromURL generates a subscription name from its source
+func ProxySourceNameFromURL(source string) string {
+	source = strings.TrimSpace(source)
+	switch {
+	case source == "":
+		return ""
+	case IsDataURI(source):
+		return "inline"
+	case IsFileURL(source):
+		trimmed := strings.TrimRight(strings.ReplaceAll(source, "\\", "/"), "/")
+		return trimmed[strings.LastIndex(trimmed, "/")+1:]
+	}
+	if u, err := url.Parse(source); err == nil && u.Hostname() != "" {
+		return u.Hostname()
+	}
+	return source
 }
 
 // OutboundConfig represents an outbound selector configuration
@@ -149,25 +289,13 @@ func ExtractParcerConfig(configPath string) (*ParserConfig, error) {
 	}
 
 	// Extract the JSON content from the comment block
-	jsonContent := strings.TrimSpace(string(matches[1]))
-
-	// Parse the JSON
-	var parserConfig ParserConfig
-	if err := json.Unmarshal([]byte(jsonContent), &parserConfig); err != nil {
-		return nil, fmt.Errorf("failed to parse @ParcerConfig JSON: %w", err)
-	}
-
-	// Backward compatibility: if version is at top level (version 1), migrate to version 2
-	if parserConfig.Version > 0 && parserConfig.ParserConfig.Version == 0 {
-		log.Printf("ExtractParcerConfig: Detected version 1 format, migrating to version 2")
-		parserConfig.ParserConfig.Version = parserConfig.Version
-		parserConfig.Version = 0 // Clear top-level version
+	parserConfig, err := ParseParserConfigString(string(matches[1]))
+	if err != nil {
+		return nil, err
 	}
 
-	// If no version specified, set to current version
-	if parserConfig.ParserConfig.Version == 0 {
-		parserConfig.ParserConfig.Version = ParserConfigVersion
-		log.Printf("ExtractParcerConfig: No version specified, defaulting to version %d", ParserConfigVersion)
+	if len(parserConfig.ParserConfig.Proxies) == 0 {
+		return nil, fmt.Errorf("@ParcerConfig has no proxy sources (\"proxies\" is empty)")
 	}
 
 	log.Printf("ExtractParcerConfig: Successfully extracted @ParcerConfig (version %d) with %d proxy sources and %d outbounds",
@@ -175,7 +303,14 @@ func ExtractParcerConfig(configPath string) (*ParserConfig, error) {
 		len(parserConfig.ParserConfig.Proxies),
 		len(parserConfig.ParserConfig.Outbounds))
 
-	return &parserConfig, nil
+	return parserConfig, nil
+}
+
+// ParseParserConfigString parses the contents of an @ParcerConfig block that was already extracted,
+// e.g. TemplateData.ParserConfig from config_template.json or the text edited in the Config Wizard.
+// Comments are allowed, older versions are migrated to ParserConfigVersion.
+func ParseParserConfigString(jsonStr string) (*ParserConfig, error) {
+	return parseParserConfigBlock([]byte(jsonStr))
 }
 
 // UpdateLastUpdatedInConfig updates the last_updated field in the @ParcerConfig block
@@ -197,23 +332,9 @@ func UpdateLastUpdatedInConfig(configPath string, lastUpdated time.Time) error {
 	}
 
 	// Extract the JSON content from the comment block
-	jsonContent := strings.TrimSpace(string(matches[2]))
-
-	// Parse the JSON
-	var parserConfig ParserConfig
-	if err := json.Unmarshal([]byte(jsonContent), &parserConfig); err != nil {
-		return fmt.Errorf("failed to parse @ParcerConfig JSON: %w", err)
-	}
-
-	// Backward compatibility: migrate version 1 to version 2 if needed
-	if parserConfig.Version > 0 && parserConfig.ParserConfig.Version == 0 {
-		parserConfig.ParserConfig.Version = parserConfig.Version
-		parserConfig.Version = 0
-	}
-
-	// Ensure version is set
-	if parserConfig.ParserConfig.Version == 0 {
-		parserConfig.ParserConfig.Version = ParserConfigVersion
+	parserConfig, err := parseParserConfigBlock(matches[2])
+	if err != nil {
+		return err
 	}
 
 	// Update last_updated field (create parser object if it doesn't exist)
@@ -0,0 +1,137 @@
+package core
+
+import (
+	"bytes"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// Subscription bodies in the formats providers typically serve
+const (
+	subscriptionVLESS  = "vless://11111111-2222-3333-4444-555555555555@example.com:443?encryption=none&security=reality&sni=www.microsoft.com&fp=chrome&pbk=abc&sid=01&type=tcp&flow=xtls-rprx-vision#%F0%9F%87%A9%F0%9F%87%AA%20Germany\n"
+	subscriptionMixed  = "trojan://password@tr.example.com:443?sni=tr.example.com#Trojan\nss://YWVzLTI1Ni1nY206cGFzcw@ss.example.com:8388#SS\nvmess://eyJhZGQiOiJ2bS5leGFtcGxlLmNvbSJ9\n"
+	subscriptionCRLF   = "vless://uuid@a.example.com:443#A\r\nvless://uuid@b.example.com:443#B\r\n"
+	subscriptionBinary = "\xfb\xff\xbf~?>" // encodes to characters that differ between the std and URL alphabets
+)
+
+// wrapLines splits s into lines of n characters joined with sep, as some servers do
+func wrapLines(s string, n int, sep string) string {
+	var lines []string
+	for len(s) > n {
+		lines = append(lines, s[:n])
+		s = s[n:]
+	}
+	return strings.Join(append(lines, s), sep)
+}
+
+func TestDecodeSubscriptionContent(t *testing.T) {
+	std := base64.StdEncoding.EncodeToString
+	rawStd := base64.RawStdEncoding.EncodeToString
+	url := base64.URLEncoding.EncodeToString
+	rawURL := base64.RawURLEncoding.EncodeToString
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"std padded vless", std([]byte(subscriptionVLESS)), subscriptionVLESS},
+		{"std unpadded vless", rawStd([]byte(subscriptionVLESS)), subscriptionVLESS},
+		{"url padded vless", url([]byte(subscriptionVLESS)), subscriptionVLESS},
+		{"url unpadded vless", rawURL([]byte(subscriptionVLESS)), subscriptionVLESS},
+		{"std padded mixed", std([]byte(subscriptionMixed)), subscriptionMixed},
+		{"std unpadded mixed", rawStd([]byte(subscriptionMixed)), subscriptionMixed},
+		{"url padded mixed", url([]byte(subscriptionMixed)), subscriptionMixed},
+		{"url unpadded mixed", rawURL([]byte(subscriptionMixed)), subscriptionMixed},
+		{"std with trailing newline", std([]byte(subscriptionMixed)) + "\n", subscriptionMixed},
+		{"std with trailing CRLF", std([]byte(subscriptionMixed)) + "\r\n", subscriptionMixed},
+		{"std wrapped at 76 LF", wrapLines(std([]byte(subscriptionMixed)), 76, "\n"), subscriptionMixed},
+		{"std wrapped at 76 CRLF", wrapLines(std([]byte(subscriptionMixed)), 76, "\r\n"), subscriptionMixed},
+		{"url unpadded wrapped CRLF", wrapLines(rawURL([]byte(subscriptionMixed)), 64, "\r\n"), subscriptionMixed},
+		{"std of CRLF body", std([]byte(subscriptionCRLF)), subscriptionCRLF},
+		{"url unpadded of CRLF body", rawURL([]byte(subscriptionCRLF)), subscriptionCRLF},
+		{"leading whitespace", "  \n" + std([]byte(subscriptionVLESS)), subscriptionVLESS},
+		{"std alphabet plus and slash", std([]byte(subscriptionBinary)), subscriptionBinary},
+		{"url alphabet dash and underscore", rawURL([]byte(subscriptionBinary)), subscriptionBinary},
+		{"plain text links", subscriptionMixed, subscriptionMixed},
+		{"plain text CRLF links", subscriptionCRLF, subscriptionCRLF},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeSubscriptionContent([]byte(tt.content))
+			if err != nil {
+				t.Fatalf("DecodeSubscriptionContent: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("DecodeSubscriptionContent = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeSubscriptionContentEmpty(t *testing.T) {
+	if _, err := DecodeSubscriptionContent(nil); err == nil {
+		t.Error("DecodeSubscriptionContent(nil): expected an error")
+	}
+}
+
+func TestDetectBase64Encoding(t *testing.T) {
+	tests := []struct {
+		s    string
+		want *base64.Encoding
+	}{
+		{"YWJj", base64.StdEncoding},
+		{"YWI=", base64.StdEncoding},
+		{"YWI", base64.RawStdEncoding},
+		{"-_-_", base64.URLEncoding},
+		{"-_8=", base64.URLEncoding},
+		{"-_8", base64.RawURLEncoding},
+	}
+	for _, tt := range tests {
+		if got := DetectBase64Encoding(tt.s); got != tt.want {
+			t.Errorf("DetectBase64Encoding(%q) returned the wrong variant", tt.s)
+		}
+	}
+}
+
+// subscriptionServer serves body as the subscription
+func subscriptionServer(t *testing.T, body []byte) string {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+func TestFetchSubscriptionMaxBodySize(t *testing.T) {
+	t.Run("20 MB response over the default limit", func(t *testing.T) {
+		url := subscriptionServer(t, bytes.Repeat([]byte(subscriptionVLESS), 20*1024*1024/len(subscriptionVLESS)+1))
+		if _, err := FetchSubscriptionWithOptions(url, FetchOptions{}); err == nil || !strings.Contains(err.Error(), "exceeded") {
+			t.Fatalf("FetchSubscriptionWithOptions() error = %v, want the size limit error", err)
+		}
+	})
+
+	body := []byte(subscriptionVLESS)
+	tests := []struct {
+		name    string
+		limit   int64
+		wantErr bool
+	}{
+		{"exactly the limit", int64(len(body)), false},
+		{"one byte over the limit", int64(len(body)) - 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, err := FetchSubscriptionWithOptions(subscriptionServer(t, body), FetchOptions{MaxBodySize: tt.limit})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FetchSubscriptionWithOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && string(content) != subscriptionVLESS {
+				t.Errorf("FetchSubscriptionWithOptions() = %q, want %q", content, subscriptionVLESS)
+			}
+		})
+	}
+}
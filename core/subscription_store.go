@@ -0,0 +1,73 @@
+package core
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"singbox-launcher/internal/constants"
+)
+
+// SubscriptionStore is the launcher state kept for subscription sources in data/subscriptions.json
+type SubscriptionStore struct {
+	// LastRefreshed is the time of the last successful fetch of each source, keyed by its URL
+	LastRefreshed map[string]time.Time `json:"last_refreshed,omitempty"`
+}
+
+// subscriptionStoreMutex serializes read-modify-write of subscriptions.json
+var subscriptionStoreMutex sync.Mutex
+
+// getSubscriptionStorePath returns the path to data/subscriptions.json
+func (ac *AppController) getSubscriptionStorePath() string {
+	return filepath.Join(ac.ExecDir(), constants.DataDirName, constants.SubscriptionsName)
+}
+
+// LoadSubscriptionStore returns the stored subscription state; LastRefreshed is never nil
+func (ac *AppController) LoadSubscriptionStore() SubscriptionStore {
+	subscriptionStoreMutex.Lock()
+	defer subscriptionStoreMutex.Unlock()
+	return ac.readSubscriptionStore()
+}
+
+// markSubscriptionRefreshed records that source was fetched successfully at t
+func (ac *AppController) markSubscriptionRefreshed(source string, t time.Time) {
+	subscriptionStoreMutex.Lock()
+	defer subscriptionStoreMutex.Unlock()
+
+	store := ac.readSubscriptionStore()
+	store.LastRefreshed[source] = t.UTC()
+	data, err := json.MarshalIndent(store, "", "  ")
+	if err != nil {
+		log.Printf("markSubscriptionRefreshed: Failed to encode store: %v", err)
+		return
+	}
+	path := ac.getSubscriptionStorePath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		log.Printf("markSubscriptionRefreshed: Failed to create data directory: %v", err)
+		return
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		log.Printf("markSubscriptionRefreshed: Failed to write store: %v", err)
+	}
+}
+
+// readSubscriptionStore reads subscriptions.json without locking
+func (ac *AppController) readSubscriptionStore() SubscriptionStore {
+	store := SubscriptionStore{}
+	data, err := os.ReadFile(ac.getSubscriptionStorePath())
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("LoadSubscriptionStore: Failed to read store: %v", err)
+		}
+	} else if err := json.Unmarshal(data, &store); err != nil {
+		log.Printf("LoadSubscriptionStore: Failed to parse store, ignoring: %v", err)
+		store = SubscriptionStore{}
+	}
+	if store.LastRefreshed == nil {
+		store.LastRefreshed = make(map[string]time.Time)
+	}
+	return store
+}
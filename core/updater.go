@@ -41,9 +41,7 @@ func (ac *AppController) UpdateAvailable(version string, downloadURL string) {
 
 // GetCurrentVersion returns the current application version
 func GetCurrentVersion() string {
-	// This should be set during build using -ldflags
-	// For now, return a placeholder
-	return "1.0.0"
+	return AppVersion
 }
 
 // GetUpdateURL returns the URL to check for updates based on platform
@@ -0,0 +1,17 @@
+package core
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// AppVersion is the launcher version, set at build time:
+//
+//	go build -ldflags="-X singbox-launcher/core.AppVersion=1.2.3"
+var AppVersion = "dev"
+
+// UserAgent returns the User-Agent sent with HTTP requests,
+// e.g. "singbox-launcher/1.2.3 (linux; amd64)"
+func UserAgent() string {
+	return fmt.Sprintf("singbox-launcher/%s (%s; %s)", AppVersion, runtime.GOOS, runtime.GOARCH)
+}
@@ -0,0 +1,21 @@
+//go:build with_embedded_ui
+
+package core
+
+import (
+	"embed"
+	"io/fs"
+)
+
+// webUIFiles holds the Yacd-meta web UI (make webui downloads it into core/webui)
+//
+//go:embed all:webui
+var webUIFiles embed.FS
+
+// EmbeddedUIAvailable reports whether the launcher was built with the web UI (-tags with_embedded_ui)
+const EmbeddedUIAvailable = true
+
+// embeddedUIAssets returns the files served by StartEmbeddedUI
+func embeddedUIAssets() (fs.FS, error) {
+	return fs.Sub(webUIFiles, "webui")
+}
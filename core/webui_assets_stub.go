@@ -0,0 +1,16 @@
+//go:build !with_embedded_ui
+
+package core
+
+import (
+	"fmt"
+	"io/fs"
+)
+
+// EmbeddedUIAvailable reports whether the launcher was built with the web UI (-tags with_embedded_ui)
+const EmbeddedUIAvailable = false
+
+// embeddedUIAssets returns the files served by StartEmbeddedUI
+func embeddedUIAssets() (fs.FS, error) {
+	return nil, fmt.Errorf("the launcher was built without the web UI (build tag with_embedded_ui)")
+}
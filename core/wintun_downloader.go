@@ -1,14 +1,17 @@
 package core
 
 import (
-	"archive/zip"
 	"context"
+	"errors"
 	"fmt"
-	"io"
+	"log"
 	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
+	"time"
+
+	"singbox-launcher/internal/platform"
 )
 
 // WinTunVersion - версия wintun.dll для скачивания
@@ -17,22 +20,87 @@ const WinTunVersion = "0.14.1"
 // WinTunDownloadURL - URL для скачивания wintun.dll
 const WinTunDownloadURL = "https://www.wintun.net/builds/wintun-%s.zip"
 
-// CheckWintunDLL проверяет наличие wintun.dll
-func (ac *AppController) CheckWintunDLL() (bool, error) {
+// WintunInfo describes the wintun.dll used by sing-box
+type WintunInfo struct {
+	Found       bool      // true if wintun.dll exists (always true outside Windows, where it isn't needed)
+	Path        string    // where wintun.dll was found, or where it is expected
+	NonStandard bool      // found outside the launcher's bin folder, e.g. installed globally in System32
+	Size        int64     // file size in bytes
+	ModTime     time.Time // last modification time
+	Version     string    // file version from the DLL version resource, empty if unavailable
+}
+
+// FindWintunDLL searches for wintun.dll where sing-box can load it from: the folder of
+// sing-box (BinaryDir, also its working directory) and %SystemRoot%\System32. A copy next to
+// the launcher is not used by sing-box, so it doesn't count.
+// Returns an error wrapping os.ErrNotExist if it is in none of them.
+func (ac *AppController) FindWintunDLL() (string, error) {
+	candidates := []string{ac.WintunPath}
+	if systemRoot := os.Getenv("SystemRoot"); systemRoot != "" {
+		candidates = append(candidates, filepath.Join(systemRoot, "System32", filepath.Base(ac.WintunPath)))
+	}
+
+	for _, path := range candidates {
+		stat, err := os.Stat(path)
+		if err == nil && stat.Mode().IsRegular() {
+			return path, nil
+		}
+		if err != nil && !os.IsNotExist(err) {
+			return "", fmt.Errorf("FindWintunDLL: %w", err)
+		}
+	}
+	return "", fmt.Errorf("FindWintunDLL: %w", os.ErrNotExist)
+}
+
+// CheckWintunDLL проверяет наличие wintun.dll и возвращает информацию о нём
+func (ac *AppController) CheckWintunDLL() (WintunInfo, error) {
+	info := WintunInfo{Path: ac.WintunPath}
 	if runtime.GOOS != "windows" {
-		return true, nil // На не-Windows системах wintun не нужен
+		info.Found = true // На не-Windows системах wintun не нужен
+		return info, nil
 	}
 
-	if _, err := os.Stat(ac.WintunPath); os.IsNotExist(err) {
-		return false, nil
+	path, err := ac.FindWintunDLL()
+	if errors.Is(err, os.ErrNotExist) {
+		return info, nil
+	}
+	if err != nil {
+		return info, fmt.Errorf("CheckWintunDLL: %w", err)
+	}
+	stat, err := os.Stat(path)
+	if err != nil {
+		return info, fmt.Errorf("CheckWintunDLL: %w", err)
 	}
-	return true, nil
+	info.Found = true
+	info.Path = path
+	info.NonStandard = !strings.EqualFold(filepath.Clean(path), filepath.Clean(ac.WintunPath))
+	info.Size = stat.Size()
+	info.ModTime = stat.ModTime()
+	if version, err := platform.GetFileVersion(path); err != nil {
+		log.Printf("CheckWintunDLL: Failed to read version of %s: %v", path, err)
+	} else {
+		info.Version = version
+	}
+	return info, nil
 }
 
 // DownloadWintunDLL downloads and installs wintun.dll
 func (ac *AppController) DownloadWintunDLL(ctx context.Context, progressChan chan DownloadProgress) {
 	defer close(progressChan)
 
+	tracked, finish, err := ac.trackDownload(DownloadResourceWintun, WinTunVersion, progressChan)
+	if err != nil {
+		progressChan <- DownloadProgress{
+			Progress: 0,
+			Message:  err.Error(),
+			Status:   "error",
+			Error:    err,
+		}
+		return
+	}
+	defer finish()
+	progressChan = tracked
+
 	if runtime.GOOS != "windows" {
 		progressChan <- DownloadProgress{
 			Progress: 0,
@@ -44,8 +112,8 @@ func (ac *AppController) DownloadWintunDLL(ctx context.Context, progressChan cha
 	}
 
 	// 1. Создаем временную директорию
-	tempDir := filepath.Join(ac.ExecDir, "temp")
-	if err := os.MkdirAll(tempDir, 0755); err != nil {
+	tempDir, err := ac.downloadTempDir()
+	if err != nil {
 		progressChan <- DownloadProgress{
 			Progress: 0,
 			Message:  fmt.Sprintf("Failed to create temp dir: %v", err),
@@ -70,83 +138,11 @@ func (ac *AppController) DownloadWintunDLL(ctx context.Context, progressChan cha
 		}
 		return
 	}
+	ac.setDownloadedBytes(DownloadResourceWintun, zipPath)
 
-	// 3. Распаковываем ZIP и извлекаем wintun.dll
+	// 3. Извлекаем wintun.dll для текущей архитектуры, проверяем подпись и устанавливаем
 	progressChan <- DownloadProgress{Progress: 80, Message: "Extracting wintun.dll...", Status: "extracting"}
 
-	// Определяем архитектуру
-	var archDir string
-	if runtime.GOARCH == "amd64" {
-		archDir = "amd64"
-	} else if runtime.GOARCH == "arm64" {
-		archDir = "arm64"
-	} else {
-		progressChan <- DownloadProgress{
-			Progress: 0,
-			Message:  fmt.Sprintf("Unsupported architecture: %s", runtime.GOARCH),
-			Status:   "error",
-			Error:    fmt.Errorf("unsupported architecture: %s", runtime.GOARCH),
-		}
-		return
-	}
-
-	// Открываем ZIP
-	r, err := zip.OpenReader(zipPath)
-	if err != nil {
-		progressChan <- DownloadProgress{
-			Progress: 0,
-			Message:  fmt.Sprintf("Failed to open zip: %v", err),
-			Status:   "error",
-			Error:    err,
-		}
-		return
-	}
-	defer r.Close()
-
-	// Ищем wintun.dll в нужной директории
-	var dllPath string
-	targetPath := fmt.Sprintf("bin/%s/wintun.dll", archDir)
-
-	for _, f := range r.File {
-		if strings.HasSuffix(f.Name, targetPath) || strings.Contains(f.Name, fmt.Sprintf("/%s/wintun.dll", archDir)) {
-			rc, err := f.Open()
-			if err != nil {
-				continue
-			}
-
-			// Извлекаем файл
-			dllPath = filepath.Join(tempDir, "wintun.dll")
-			outFile, err := os.Create(dllPath)
-			if err != nil {
-				rc.Close()
-				continue
-			}
-
-			_, err = io.Copy(outFile, rc)
-			outFile.Close()
-			rc.Close()
-
-			if err != nil {
-				continue
-			}
-
-			break
-		}
-	}
-
-	if dllPath == "" {
-		progressChan <- DownloadProgress{
-			Progress: 0,
-			Message:  "wintun.dll not found in archive",
-			Status:   "error",
-			Error:    fmt.Errorf("wintun.dll not found in archive"),
-		}
-		return
-	}
-
-	// 4. Копируем wintun.dll в целевую директорию
-	progressChan <- DownloadProgress{Progress: 90, Message: "Installing wintun.dll...", Status: "extracting"}
-
 	// Создаем директорию bin если её нет
 	binDir := filepath.Dir(ac.WintunPath)
 	if err := os.MkdirAll(binDir, 0755); err != nil {
@@ -159,43 +155,17 @@ func (ac *AppController) DownloadWintunDLL(ctx context.Context, progressChan cha
 		return
 	}
 
-	// Копируем файл
-	sourceFile, err := os.Open(dllPath)
-	if err != nil {
-		progressChan <- DownloadProgress{
-			Progress: 0,
-			Message:  fmt.Sprintf("Failed to open source file: %v", err),
-			Status:   "error",
-			Error:    err,
-		}
-		return
-	}
-	defer sourceFile.Close()
-
-	destFile, err := os.Create(ac.WintunPath)
-	if err != nil {
-		progressChan <- DownloadProgress{
-			Progress: 0,
-			Message:  fmt.Sprintf("Failed to create destination file: %v", err),
-			Status:   "error",
-			Error:    err,
-		}
-		return
-	}
-	defer destFile.Close()
-
-	_, err = io.Copy(destFile, sourceFile)
-	if err != nil {
+	if err := extractWintunFromZip(zipPath, runtime.GOARCH, ac.WintunPath); err != nil {
 		progressChan <- DownloadProgress{
 			Progress: 0,
-			Message:  fmt.Sprintf("Failed to copy file: %v", err),
+			Message:  fmt.Sprintf("Failed to install wintun.dll: %v", err),
 			Status:   "error",
 			Error:    err,
 		}
 		return
 	}
 
-	// 5. Готово!
+	// 4. Готово!
 	progressChan <- DownloadProgress{
 		Progress: 100,
 		Message:  fmt.Sprintf("wintun.dll v%s installed successfully!", WinTunVersion),
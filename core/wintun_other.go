@@ -0,0 +1,11 @@
+//go:build !windows
+// +build !windows
+
+package core
+
+import "fmt"
+
+// extractWintunFromZip is only supported on Windows, the only platform that needs wintun.dll
+func extractWintunFromZip(zipPath, goarch, destPath string) error {
+	return fmt.Errorf("extractWintunFromZip: wintun.dll is only needed on Windows")
+}
@@ -0,0 +1,159 @@
+//go:build windows
+// +build windows
+
+package core
+
+import (
+	"archive/zip"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// wintunSigner is the subject of the certificate the official wintun.dll is signed with
+const wintunSigner = "WireGuard LLC"
+
+var (
+	modwintrust                        = windows.NewLazySystemDLL("wintrust.dll")
+	procWTHelperProvDataFromStateData  = modwintrust.NewProc("WTHelperProvDataFromStateData")
+	procWTHelperGetProvSignerFromChain = modwintrust.NewProc("WTHelperGetProvSignerFromChain")
+)
+
+// cryptProviderSgnr is the beginning of CRYPT_PROVIDER_SGNR (wintrust.h)
+type cryptProviderSgnr struct {
+	size           uint32
+	verifyAsOf     windows.Filetime
+	certChainCount uint32
+	certChain      *cryptProviderCert
+}
+
+// cryptProviderCert is the beginning of CRYPT_PROVIDER_CERT (wintrust.h)
+type cryptProviderCert struct {
+	size uint32
+	cert *windows.CertContext
+}
+
+// wintunArchDirs maps GOARCH to the folder of the WinTun ZIP holding wintun.dll for it
+var wintunArchDirs = map[string]string{
+	"amd64": "amd64",
+	"arm64": "arm64",
+	"arm":   "arm",
+	"386":   "x86",
+}
+
+// extractWintunFromZip extracts <arch>/wintun.dll for goarch from the WinTun ZIP at zipPath,
+// checks its Authenticode signature and atomically renames it to destPath.
+// destPath is not touched if anything fails.
+func extractWintunFromZip(zipPath, goarch, destPath string) error {
+	archDir, ok := wintunArchDirs[goarch]
+	if !ok {
+		return fmt.Errorf("extractWintunFromZip: unsupported architecture: %s", goarch)
+	}
+
+	r, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return fmt.Errorf("extractWintunFromZip: failed to open zip: %w", err)
+	}
+	defer r.Close()
+
+	// In the official archive the DLL is wintun/bin/<arch>/wintun.dll
+	suffix := path.Join(archDir, "wintun.dll")
+	var dllFile *zip.File
+	for _, f := range r.File {
+		name := strings.TrimPrefix(f.Name, "/")
+		if name == suffix || strings.HasSuffix(name, "/"+suffix) {
+			dllFile = f
+			break
+		}
+	}
+	if dllFile == nil {
+		return fmt.Errorf("extractWintunFromZip: %s not found in archive", suffix)
+	}
+
+	// Temporary file next to destPath, so the rename stays on one file system
+	tempPath := destPath + ".tmp"
+	if err := extractZipFile(dllFile, tempPath); err != nil {
+		os.Remove(tempPath)
+		return fmt.Errorf("extractWintunFromZip: %w", err)
+	}
+	if err := verifyAuthenticode(tempPath, wintunSigner); err != nil {
+		os.Remove(tempPath)
+		return fmt.Errorf("extractWintunFromZip: signature check of wintun.dll failed: %w", err)
+	}
+	// Backup left by a previous update (still locked if that sing-box is running)
+	os.Remove(destPath + ".old")
+	if err := atomicFileReplace(tempPath, destPath); err != nil {
+		// A running sing-box has wintun.dll loaded: it can't be overwritten, only renamed.
+		// sing-box keeps the loaded copy, the next start loads the new one.
+		if err := replaceLockedBinary(tempPath, destPath); err != nil {
+			os.Remove(tempPath)
+			return fmt.Errorf("extractWintunFromZip: %w", err)
+		}
+	}
+	return nil
+}
+
+// verifyAuthenticode checks the Authenticode signature of path with WinVerifyTrust and that the
+// signing certificate was issued to signer: a valid signature of someone else is rejected.
+// Revocation is not checked, so it works offline.
+func verifyAuthenticode(path, signer string) error {
+	pathPtr, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return err
+	}
+	fileInfo := &windows.WinTrustFileInfo{
+		Size:     uint32(unsafe.Sizeof(windows.WinTrustFileInfo{})),
+		FilePath: pathPtr,
+	}
+	data := &windows.WinTrustData{
+		Size:                            uint32(unsafe.Sizeof(windows.WinTrustData{})),
+		UIChoice:                        windows.WTD_UI_NONE,
+		RevocationChecks:                windows.WTD_REVOKE_NONE,
+		UnionChoice:                     windows.WTD_CHOICE_FILE,
+		StateAction:                     windows.WTD_STATEACTION_VERIFY,
+		FileOrCatalogOrBlobOrSgnrOrCert: unsafe.Pointer(fileInfo),
+	}
+	verifyErr := windows.WinVerifyTrustEx(windows.InvalidHWND, &windows.WINTRUST_ACTION_GENERIC_VERIFY_V2, data)
+	if verifyErr == nil {
+		// The state data is valid until WTD_STATEACTION_CLOSE
+		var subject string
+		subject, verifyErr = signerSubject(data.StateData)
+		if verifyErr == nil && subject != signer {
+			verifyErr = fmt.Errorf("signed by %q, expected %q", subject, signer)
+		}
+	}
+	data.StateAction = windows.WTD_STATEACTION_CLOSE
+	windows.WinVerifyTrustEx(windows.InvalidHWND, &windows.WINTRUST_ACTION_GENERIC_VERIFY_V2, data)
+	return verifyErr
+}
+
+// signerSubject returns the subject name of the certificate that signed the file verified with
+// stateData (WinTrustData.StateData after WTD_STATEACTION_VERIFY)
+func signerSubject(stateData windows.Handle) (string, error) {
+	provData, _, _ := procWTHelperProvDataFromStateData.Call(uintptr(stateData))
+	if provData == 0 {
+		return "", fmt.Errorf("no signature data")
+	}
+	sgnrPtr, _, _ := procWTHelperGetProvSignerFromChain.Call(provData, 0, 0, 0)
+	if sgnrPtr == 0 {
+		return "", fmt.Errorf("no signer")
+	}
+	sgnr := *(**cryptProviderSgnr)(unsafe.Pointer(&sgnrPtr))
+	if sgnr.certChainCount == 0 || sgnr.certChain == nil || sgnr.certChain.cert == nil {
+		return "", fmt.Errorf("no signer certificate")
+	}
+
+	// The first certificate of the chain is the signer's
+	cert := sgnr.certChain.cert
+	size := windows.CertGetNameString(cert, windows.CERT_NAME_SIMPLE_DISPLAY_TYPE, 0, nil, nil, 0)
+	if size <= 1 {
+		return "", fmt.Errorf("signer certificate has no subject")
+	}
+	name := make([]uint16, size)
+	windows.CertGetNameString(cert, windows.CERT_NAME_SIMPLE_DISPLAY_TYPE, 0, nil, &name[0], size)
+	return windows.UTF16ToString(name), nil
+}
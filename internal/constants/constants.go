@@ -2,18 +2,32 @@ package constants
 
 // File names
 const (
-	WinTunDLLName     = "wintun.dll"
-	TunDLLName        = "tun.dll"
-	ConfigFileName    = "config.json"
-	ConfigExampleName = "config.example.json"
-	SingBoxExecName   = "sing-box"
-	ParserExecName    = "parser"
+	WinTunDLLName       = "wintun.dll"
+	TunDLLName          = "tun.dll"
+	ConfigFileName      = "config.json"
+	ConfigExampleName   = "config.example.json"
+	SingBoxExecName     = "sing-box"
+	ParserExecName      = "parser"
+	PreferencesName     = "preferences.json"
+	SelectorChoicesName = "selector_choices.json"
+	SingBoxPIDFileName  = "sing-box.pid"
+	RuleSetSourcesName  = "rule_set_sources.json"
+	CoreChecksumName    = "core_checksum.json"
+	JSONSchemaName      = "singbox_schema.json"
+	DownloadHistoryName = "download_history.json"
+	DeviceIDName        = "device_id.txt"
+	SubscriptionsName   = "subscriptions.json"
+	AuditLogName        = "audit.log"
 )
 
 // Directory names
 const (
-	BinDirName  = "bin"
-	LogsDirName = "logs"
+	BinDirName               = "bin"
+	LogsDirName              = "logs"
+	DataDirName              = "data"
+	CertsDirName             = "certs"
+	RuleSetsDirName          = "rule-sets"
+	SubscriptionCacheDirName = "subscriptions" // inside DataDirName
 )
 
 // Log file names
@@ -9,8 +9,16 @@ import (
 	"fyne.io/fyne/v2/widget"
 )
 
+// EnqueueFunc is set by the ui package so dialogs shown from core go through the same
+// dialog queue as the UI ones. If nil, dialogs are shown immediately.
+var EnqueueFunc func(window fyne.Window, title, message string, isError bool)
+
 // ShowError shows an error dialog to the user
 func ShowError(window fyne.Window, err error) {
+	if EnqueueFunc != nil {
+		EnqueueFunc(window, "Error", err.Error(), true)
+		return
+	}
 	fyne.Do(func() {
 		dialog.ShowError(err, window)
 	})
@@ -18,6 +26,10 @@ func ShowError(window fyne.Window, err error) {
 
 // ShowErrorText shows an error dialog with a text message
 func ShowErrorText(window fyne.Window, title, message string) {
+	if EnqueueFunc != nil {
+		EnqueueFunc(window, title, fmt.Sprintf("%s: %s", title, message), true)
+		return
+	}
 	fyne.Do(func() {
 		dialog.ShowError(fmt.Errorf("%s: %s", title, message), window)
 	})
@@ -25,6 +37,10 @@ func ShowErrorText(window fyne.Window, title, message string) {
 
 // ShowInfo shows an information dialog to the user
 func ShowInfo(window fyne.Window, title, message string) {
+	if EnqueueFunc != nil {
+		EnqueueFunc(window, title, message, false)
+		return
+	}
 	fyne.Do(func() {
 		dialog.ShowInformation(title, message, window)
 	})
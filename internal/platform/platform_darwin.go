@@ -4,9 +4,14 @@
 package platform
 
 import (
+	"fmt"
+	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"strconv"
+	"strings"
+	"syscall"
 
 	"singbox-launcher/internal/constants"
 )
@@ -41,6 +46,11 @@ func KillProcessByPID(pid int) error {
 	return exec.Command("kill", "-9", strconv.Itoa(pid)).Run()
 }
 
+// SendStopSignal asks a process to exit gracefully (SIGTERM)
+func SendStopSignal(process *os.Process) error {
+	return process.Signal(syscall.SIGTERM)
+}
+
 // PrepareCommand prepares a command with platform-specific attributes
 func PrepareCommand(cmd *exec.Cmd) {
 	// No special attributes needed for macOS
@@ -76,3 +86,56 @@ func CheckAndSuggestCapabilities(singboxPath string) string {
 	return "" // Capabilities are Linux-specific, not needed on macOS
 }
 
+// GetMachineID returns a machine-unique identifier (IOPlatformUUID)
+func GetMachineID() (string, error) {
+	out, err := exec.Command("ioreg", "-rd1", "-c", "IOPlatformExpertDevice").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to run ioreg: %w", err)
+	}
+	match := regexp.MustCompile(`"IOPlatformUUID"\s*=\s*"([^"]+)"`).FindSubmatch(out)
+	if match == nil {
+		return "", fmt.Errorf("IOPlatformUUID not found")
+	}
+	return string(match[1]), nil
+}
+
+// WindowPositionSupported - window positions can't be read or set on macOS (window managers control
+// placement), only the window size is saved and restored
+const WindowPositionSupported = false
+
+// GetWindowPosition is not supported on macOS, see WindowPositionSupported
+func GetWindowPosition(handle uintptr) (x, y int, ok bool) {
+	return 0, 0, false
+}
+
+// SetWindowPosition is not supported on macOS, see WindowPositionSupported
+func SetWindowPosition(handle uintptr, x, y int) bool {
+	return false
+}
+
+// ClearSystemProxy turns off HTTP, HTTPS and SOCKS proxies of all network services (set by sing-box "set_system_proxy")
+func ClearSystemProxy() error {
+	out, err := exec.Command("networksetup", "-listallnetworkservices").Output()
+	if err != nil {
+		return err
+	}
+	var lastErr error
+	for i, service := range strings.Split(string(out), "\n") {
+		// The first line is a notice, disabled services are marked with "*"
+		service = strings.TrimSpace(service)
+		if i == 0 || service == "" || strings.HasPrefix(service, "*") {
+			continue
+		}
+		for _, flag := range []string{"-setwebproxystate", "-setsecurewebproxystate", "-setsocksfirewallproxystate"} {
+			if err := exec.Command("networksetup", flag, service, "off").Run(); err != nil {
+				lastErr = fmt.Errorf("%s %s: %w", flag, service, err)
+			}
+		}
+	}
+	return lastErr
+}
+
+// GetFileVersion is not supported on macOS (no version resources in binaries)
+func GetFileVersion(path string) (string, error) {
+	return "", nil
+}
@@ -10,6 +10,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"syscall"
 
 	"singbox-launcher/internal/constants"
 )
@@ -44,6 +45,11 @@ func KillProcessByPID(pid int) error {
 	return exec.Command("kill", "-9", strconv.Itoa(pid)).Run()
 }
 
+// SendStopSignal asks a process to exit gracefully (SIGTERM)
+func SendStopSignal(process *os.Process) error {
+	return process.Signal(syscall.SIGTERM)
+}
+
 // PrepareCommand prepares a command with platform-specific attributes
 func PrepareCommand(cmd *exec.Cmd) {
 	// No special attributes needed for Linux
@@ -127,3 +133,45 @@ func CheckAndSuggestCapabilities(singboxPath string) string {
 	return "" // Capabilities are OK
 }
 
+
+// GetMachineID returns a machine-unique identifier (systemd/dbus machine-id)
+func GetMachineID() (string, error) {
+	for _, path := range []string{"/etc/machine-id", "/var/lib/dbus/machine-id"} {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		if id := strings.TrimSpace(string(data)); id != "" {
+			return id, nil
+		}
+	}
+	return "", fmt.Errorf("machine-id not found")
+}
+
+// WindowPositionSupported - window positions can't be read or set on Linux (window managers control
+// placement), only the window size is saved and restored
+const WindowPositionSupported = false
+
+// GetWindowPosition is not supported on Linux, see WindowPositionSupported
+func GetWindowPosition(handle uintptr) (x, y int, ok bool) {
+	return 0, 0, false
+}
+
+// SetWindowPosition is not supported on Linux, see WindowPositionSupported
+func SetWindowPosition(handle uintptr, x, y int) bool {
+	return false
+}
+
+// ClearSystemProxy turns off the GNOME proxy (set by sing-box "set_system_proxy").
+// Does nothing on desktops without gsettings.
+func ClearSystemProxy() error {
+	if _, err := exec.LookPath("gsettings"); err != nil {
+		return nil
+	}
+	return exec.Command("gsettings", "set", "org.gnome.system.proxy", "mode", "none").Run()
+}
+
+// GetFileVersion is not supported on Linux (no version resources in binaries)
+func GetFileVersion(path string) (string, error) {
+	return "", nil
+}
@@ -4,10 +4,16 @@
 package platform
 
 import (
+	"fmt"
+	"os"
 	"os/exec"
 	"path/filepath"
 	"strconv"
 	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
 
 	"singbox-launcher/internal/constants"
 )
@@ -42,6 +48,15 @@ func KillProcessByPID(pid int) error {
 	return exec.Command("taskkill", "/PID", strconv.Itoa(pid), "/T", "/F").Run()
 }
 
+// SendStopSignal asks a process to exit gracefully: sing-box handles CTRL_BREAK_EVENT on Windows
+func SendStopSignal(process *os.Process) error {
+	proc := syscall.NewLazyDLL("kernel32.dll").NewProc("GenerateConsoleCtrlEvent")
+	if r, _, err := proc.Call(uintptr(syscall.CTRL_BREAK_EVENT), uintptr(process.Pid)); r == 0 {
+		return err
+	}
+	return nil
+}
+
 // PrepareCommand prepares a command with platform-specific attributes
 func PrepareCommand(cmd *exec.Cmd) {
 	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
@@ -78,3 +93,94 @@ func CheckAndSuggestCapabilities(singboxPath string) string {
 	return "" // Capabilities are Windows-specific, not needed here
 }
 
+// GetMachineID returns a machine-unique identifier (MachineGuid from the registry)
+func GetMachineID() (string, error) {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, `SOFTWARE\Microsoft\Cryptography`, registry.QUERY_VALUE|registry.WOW64_64KEY)
+	if err != nil {
+		return "", err
+	}
+	defer key.Close()
+	id, _, err := key.GetStringValue("MachineGuid")
+	return id, err
+}
+
+var (
+	user32              = syscall.NewLazyDLL("user32.dll")
+	procGetWindowRect   = user32.NewProc("GetWindowRect")
+	procSetWindowPos    = user32.NewProc("SetWindowPos")
+	procMonitorFromRect = user32.NewProc("MonitorFromRect")
+)
+
+const (
+	swpNoSize            = 0x0001
+	swpNoZOrder          = 0x0004
+	swpNoActivate        = 0x0010
+	monitorDefaultToNull = 0x00000000
+)
+
+type winRect struct {
+	Left, Top, Right, Bottom int32
+}
+
+// WindowPositionSupported - the position of a window is saved and restored along with its size
+const WindowPositionSupported = true
+
+// GetWindowPosition returns the screen position of a native window
+func GetWindowPosition(handle uintptr) (x, y int, ok bool) {
+	if handle == 0 {
+		return 0, 0, false
+	}
+	var rect winRect
+	if r, _, _ := procGetWindowRect.Call(handle, uintptr(unsafe.Pointer(&rect))); r == 0 {
+		return 0, 0, false
+	}
+	return int(rect.Left), int(rect.Top), true
+}
+
+// SetWindowPosition moves a native window to (x, y).
+// Returns false if the position is not on any connected monitor (e.g. it was unplugged).
+func SetWindowPosition(handle uintptr, x, y int) bool {
+	if handle == 0 {
+		return false
+	}
+	// The title bar area must be visible so the user can still drag the window
+	titleBar := winRect{Left: int32(x), Top: int32(y), Right: int32(x) + 100, Bottom: int32(y) + 30}
+	if monitor, _, _ := procMonitorFromRect.Call(uintptr(unsafe.Pointer(&titleBar)), monitorDefaultToNull); monitor == 0 {
+		return false
+	}
+	r, _, _ := procSetWindowPos.Call(handle, 0, uintptr(x), uintptr(y), 0, 0, swpNoSize|swpNoZOrder|swpNoActivate)
+	return r != 0
+}
+
+// ClearSystemProxy turns off the user proxy in Internet Settings (set by sing-box "set_system_proxy")
+func ClearSystemProxy() error {
+	key, err := registry.OpenKey(registry.CURRENT_USER, `Software\Microsoft\Windows\CurrentVersion\Internet Settings`, registry.SET_VALUE)
+	if err != nil {
+		return err
+	}
+	defer key.Close()
+	return key.SetDWordValue("ProxyEnable", 0)
+}
+
+// GetFileVersion returns the file version from the version resource of an executable or DLL, e.g. "0.14.1.0"
+func GetFileVersion(path string) (string, error) {
+	size, err := windows.GetFileVersionInfoSize(path, nil)
+	if err != nil {
+		return "", err
+	}
+	buf := make([]byte, size)
+	if err := windows.GetFileVersionInfo(path, 0, size, unsafe.Pointer(&buf[0])); err != nil {
+		return "", err
+	}
+	var fixed *windows.VS_FIXEDFILEINFO
+	var fixedLen uint32
+	if err := windows.VerQueryValue(unsafe.Pointer(&buf[0]), `\`, unsafe.Pointer(&fixed), &fixedLen); err != nil {
+		return "", err
+	}
+	if fixed == nil || fixedLen == 0 {
+		return "", fmt.Errorf("no version information in %s", filepath.Base(path))
+	}
+	return fmt.Sprintf("%d.%d.%d.%d",
+		fixed.FileVersionMS>>16, fixed.FileVersionMS&0xffff,
+		fixed.FileVersionLS>>16, fixed.FileVersionLS&0xffff), nil
+}
@@ -2,7 +2,9 @@ package main
 
 import (
 	_ "embed" // For embedding resource files (icons)
+	"flag"
 	"log"
+	"path/filepath"
 	"time"
 
 	"fyne.io/fyne/v2"
@@ -26,9 +28,30 @@ var greenIconData []byte // Icon for "on" state
 
 // main is the application's entry point. It simply creates and runs the AppController.
 func main() {
+	binaryDir := flag.String("binary-dir", "", "folder for downloaded sing-box, wintun.dll and rule-sets (default: bin next to the launcher)")
+	readOnly := flag.Bool("read-only", false, "disable configuration changes (for shared workstations); monitoring stays available")
+	preDownloadHook := flag.String("pre-download-hook", "", "shell command run before a sing-box download; a non-zero exit cancels it")
+	postDownloadHook := flag.String("post-download-hook", "", "shell command run after a sing-box download is installed and verified")
+	flag.Parse()
+
+	var opts []core.ControllerOption
+	if *binaryDir != "" {
+		dir, err := filepath.Abs(*binaryDir)
+		if err != nil {
+			log.Fatalf("Invalid --binary-dir: %v", err)
+		}
+		opts = append(opts, core.WithBinaryDir(dir))
+	}
+	if *readOnly {
+		opts = append(opts, core.WithReadOnly())
+	}
+	if *preDownloadHook != "" || *postDownloadHook != "" {
+		opts = append(opts, core.WithDownloadHookCommands(*preDownloadHook, *postDownloadHook))
+	}
+
 	// Create the application controller. If an error occurs, print it and exit the program.
 	// Use greyIconData for red icon (no separate red icon yet)
-	controller, err := core.NewAppController(appIconData, greyIconData, greenIconData, greyIconData)
+	controller, err := core.NewAppController(appIconData, greyIconData, greenIconData, greyIconData, opts...)
 	if err != nil {
 		log.Fatalf("Failed to initialize application: %v", err)
 	}
@@ -68,16 +91,27 @@ func main() {
 	controller.MainWindow = controller.Application.NewWindow("Singbox Launcher") // Create the main application window
 	controller.MainWindow.SetIcon(controller.AppIconData)
 
-	// Create App structure to manage UI
-	app := ui.NewApp(controller.MainWindow, controller)
-	controller.MainWindow.SetContent(app.GetTabs())      // Set the window's content
+	// Create App structure to manage UI. The builder applies the saved theme and language
+	// before the window is shown, to avoid a flash of the default theme
+	app, err := ui.NewAppBuilder(controller.MainWindow, controller).Build()
+	if err != nil {
+		log.Fatalf("Failed to create UI: %v", err)
+	}
+	controller.MainWindow.SetContent(app.Content())      // Set the window's content
 	controller.MainWindow.Resize(fyne.NewSize(350, 450)) // initial window size
 	controller.MainWindow.CenterOnScreen()               // Center the window on the screen
+	ui.RestoreWindowGeometry(controller, controller.MainWindow)
+	ui.WatchWindowGeometry(controller, controller.MainWindow)
+	controller.SaveUIStateFunc = func() {
+		ui.SaveWindowGeometry(controller, controller.MainWindow)
+		app.SaveDiagnosticsWindowGeometry()
+	}
 
 	core.CheckIfLauncherAlreadyRunningUtil(controller)
 
 	// Intercept the window close event (clicking "X") to hide it instead of exiting completely.
 	controller.MainWindow.SetCloseIntercept(func() {
+		ui.SaveWindowGeometry(controller, controller.MainWindow)
 		controller.MainWindow.Hide()
 	})
 
@@ -94,6 +128,25 @@ func main() {
 		})
 	}()
 
+	// Startup sequence: Ready() is closed once these tasks have finished.
+	// Binary check, and a config update if the subscription reload interval has passed
+	controller.GoStartup(controller.CheckCoreBinary)
+	controller.GoStartup(func() { core.LoadSubscriptionsAtStartup(controller) })
+	controller.FinishStartup()
+
+	// Latest version fetch (the result is cached for later GetCoreVersionInfo calls).
+	// Not part of the startup sequence: starting sing-box must not wait for GitHub
+	go controller.GetCoreVersionInfo()
+
+	// Notify the UI when config.json is edited outside the launcher
+	controller.StartConfigWatcher()
+
+	// Notify the UI when the default network interface changes (e.g. Wi-Fi to Ethernet)
+	controller.StartInterfaceWatcher()
+
+	// Switch selector groups away from slow proxies (if enabled in Settings)
+	controller.StartAutoFallback()
+
 	// Check if config.json exists and show a warning if it doesn't
 	core.CheckConfigFileExists(controller)
 
@@ -107,15 +160,5 @@ func main() {
 	// The code below executes only after ShowAndRun() finishes.
 	// This is where final cleanup is performed.
 	log.Println("Application shutting down.")
-	controller.GracefulExit()
-
-	if controller.MainLogFile != nil {
-		controller.MainLogFile.Close()
-	}
-	if controller.ChildLogFile != nil {
-		controller.ChildLogFile.Close()
-	}
-	if controller.ApiLogFile != nil {
-		controller.ApiLogFile.Close()
-	}
+	controller.GracefulExit() // Also closes the log files
 }
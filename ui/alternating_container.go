@@ -0,0 +1,85 @@
+package ui
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+)
+
+// AlternatingContainer shows at most one of its children at a time, e.g. a download button
+// that turns into a progress bar. Its minimum size is the largest minimum size of all children
+// (hidden ones included), so switching between them doesn't change the row height or width.
+// With no active child it takes no space.
+type AlternatingContainer struct {
+	widget.BaseWidget
+
+	children []fyne.CanvasObject
+	active   fyne.CanvasObject
+}
+
+// NewAlternatingContainer creates a container with the given children, none of them shown.
+// A child that is never activated (e.g. a transparent rectangle) only reserves space.
+func NewAlternatingContainer(children ...fyne.CanvasObject) *AlternatingContainer {
+	c := &AlternatingContainer{children: children}
+	for _, child := range children {
+		child.Hide()
+	}
+	c.ExtendBaseWidget(c)
+	return c
+}
+
+// Active returns the shown child, nil if none
+func (c *AlternatingContainer) Active() fyne.CanvasObject {
+	return c.active
+}
+
+// SetActive shows child and hides the others. nil hides all children.
+func (c *AlternatingContainer) SetActive(child fyne.CanvasObject) {
+	c.active = child
+	for _, o := range c.children {
+		if o == child {
+			o.Show()
+		} else {
+			o.Hide()
+		}
+	}
+	c.Refresh()
+}
+
+// CreateRenderer implements fyne.Widget
+func (c *AlternatingContainer) CreateRenderer() fyne.WidgetRenderer {
+	return &alternatingRenderer{container: c}
+}
+
+type alternatingRenderer struct {
+	container *AlternatingContainer
+}
+
+func (r *alternatingRenderer) Layout(size fyne.Size) {
+	for _, o := range r.container.children {
+		o.Move(fyne.NewPos(0, 0))
+		o.Resize(size)
+	}
+}
+
+func (r *alternatingRenderer) MinSize() fyne.Size {
+	if r.container.active == nil {
+		return fyne.NewSize(0, 0)
+	}
+	minSize := fyne.NewSize(0, 0)
+	for _, o := range r.container.children {
+		minSize = minSize.Max(o.MinSize())
+	}
+	return minSize
+}
+
+func (r *alternatingRenderer) Refresh() {
+	if r.container.active != nil {
+		r.container.active.Refresh()
+	}
+}
+
+func (r *alternatingRenderer) Objects() []fyne.CanvasObject {
+	return r.container.children
+}
+
+func (r *alternatingRenderer) Destroy() {}
@@ -1,10 +1,15 @@
 package ui
 
 import (
+	"log"
+	"time"
+
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
 
 	"singbox-launcher/core"
+	"singbox-launcher/internal/dialogs"
 )
 
 // App manages the UI structure and tabs
@@ -13,30 +18,67 @@ type App struct {
 	core        *core.AppController
 	tabs        *container.AppTabs
 	clashAPITab *container.TabItem
+	settingsTab *container.TabItem
 	currentTab  *container.TabItem
+	coreTab     *CoreDashboardTab
+
+	// tabBuilders create the content of tabs on first selection (keyed by tab title)
+	tabBuilders map[string]func() fyne.CanvasObject
+
+	// Diagnostics content can be popped out into its own window (see diagnostics_window.go)
+	diagnosticsSlot        *fyne.Container // holds diagnosticsView or diagnosticsPlaceholder inside the tab
+	diagnosticsView        fyne.CanvasObject
+	diagnosticsPlaceholder fyne.CanvasObject
+	diagnosticsPopOut      *widget.Button
+	diagnosticsWindow      fyne.Window // nil while the content is in the tab
+
+	// scheduleClashAPITabState applies updateClashAPITabState once RunningState settles
+	scheduleClashAPITabState func()
+
+	// clashAPIGate shows the Clash API tab content only while the API is reachable
+	clashAPIGate *clashAPIGate
 }
 
-// NewApp creates a new App instance
+// clashAPITabDebounce - за это время быстрые переключения RunningState (start → crash → restart)
+// сводятся к одному обновлению вкладки Clash API, без мерцания
+const clashAPITabDebounce = 150 * time.Millisecond
+
+// NewApp creates a new App instance.
+//
+// Deprecated: use NewAppBuilder, which also applies the theme and language.
 func NewApp(window fyne.Window, controller *core.AppController) *App {
 	app := &App{
 		window: window,
 		core:   controller,
 	}
 
+	// Error and info dialogs from core go through the same queue as the UI ones
+	dialogs.EnqueueFunc = enqueueDialog
+
 	// Create tabs - Core is first (opens on startup)
 	// Создаем вкладку Core первой, чтобы её callback установился
-	coreTabItem := container.NewTabItem("Core", CreateCoreDashboardTab(controller))
-	app.clashAPITab = container.NewTabItem("Clash API", CreateClashAPITab(controller))
+	coreTab, coreContent := newCoreDashboardTab(controller)
+	app.coreTab = coreTab
+	coreTabItem := container.NewTabItem("Core", coreContent)
+	// Clash API is created eagerly: it registers the controller callbacks used by auto-loading and the tray
+	app.clashAPIGate = newClashAPIGate(controller, CreateClashAPITab(controller))
+	app.clashAPITab = container.NewTabItem("Clash API", app.clashAPIGate.Content())
+	// Diagnostics and Tools are built on first selection so they don't slow down startup
+	app.tabBuilders = make(map[string]func() fyne.CanvasObject)
+	app.settingsTab = container.NewTabItem("Settings", CreateSettingsTab(controller))
 	app.tabs = container.NewAppTabs(
 		coreTabItem,
 		app.clashAPITab,
-		container.NewTabItem("Diagnostics", CreateDiagnosticsTab(controller)),
-		container.NewTabItem("Tools", CreateToolsTab(controller)),
+		app.newLazyTab("Diagnostics", app.createDiagnosticsContent),
+		app.newLazyTab("Tools", func() fyne.CanvasObject { return CreateToolsTab(controller) }),
+		app.settingsTab,
 	)
 
 	// Set tab selection handler
 	app.tabs.OnSelected = func(item *container.TabItem) {
 		app.currentTab = item
+		app.buildTabContent(item)
+		app.saveLastTab()
 		if item == app.clashAPITab {
 			// Проверяем, запущен ли sing-box
 			if !controller.RunningState.IsRunning() {
@@ -45,36 +87,130 @@ func NewApp(window fyne.Window, controller *core.AppController) *App {
 				// Можно показать сообщение пользователю
 				return
 			}
-			controller.RefreshAPIFunc()
+			// The content is shown once the API answers, an error with Retry otherwise
+			app.clashAPIGate.Check()
 		}
 	}
 
-	// Сохраняем оригинальный callback, который был установлен в CreateCoreDashboardTab
-	originalUpdateCoreStatusFunc := controller.UpdateCoreStatusFunc
+	app.scheduleClashAPITabState = debounce(clashAPITabDebounce, func() {
+		fyne.Do(app.updateClashAPITabState)
+	})
 
-	// Регистрируем комбинированный callback для обновления состояния вкладки Clash API
-	controller.UpdateCoreStatusFunc = func() {
-		// Вызываем оригинальный callback, если он есть
-		if originalUpdateCoreStatusFunc != nil {
-			originalUpdateCoreStatusFunc()
+	// Вкладка Clash API доступна только при запущенном sing-box (применяется только итоговое состояние)
+	controller.Events.Subscribe(func(event core.Event) {
+		app.scheduleClashAPITabState()
+		if event == core.PreferencesReset {
+			fyne.Do(app.reloadPreferences)
 		}
-		// Обновляем состояние вкладки Clash API
-		fyne.Do(func() {
-			app.updateClashAPITabState()
-		})
-	}
+		if change, ok := event.(core.InterfaceChanged); ok && controller.RunningState.IsRunning() {
+			app.showNetworkChanged(change)
+		}
+		if switched, ok := event.(core.ProxySwitched); ok {
+			ShowAutoHideInfo(controller.Application, window, T("app.fallback.title"),
+				T("app.fallback.switched", switched.Group, switched.From, switched.To))
+		}
+	})
 
 	// Инициализируем состояние вкладки
 	app.updateClashAPITabState()
 
+	// The main window only hides on close, tabs are destroyed when the application exits
+	controller.OnShutdown(app.Destroy)
+
 	return app
 }
 
+// showNetworkChanged tells the user that the default interface changed while sing-box runs:
+// outbounds bound to the old interface may keep failing until sing-box is restarted
+func (a *App) showNetworkChanged(change core.InterfaceChanged) {
+	name := func(iface string) string {
+		if iface == "" {
+			return T("app.network.offline")
+		}
+		return iface
+	}
+	ShowAutoHideInfo(a.core.Application, a.window,
+		T("app.network.title"), T("app.network.changed", name(change.Previous), name(change.Current)))
+}
+
+// newLazyTab creates a tab showing "Loading…" until it is first selected
+func (a *App) newLazyTab(title string, build func() fyne.CanvasObject) *container.TabItem {
+	a.tabBuilders[title] = build
+	return container.NewTabItem(title, container.NewStack(widget.NewLabel("Loading…")))
+}
+
+// buildTabContent replaces the placeholder of a lazily created tab with its real content (once)
+func (a *App) buildTabContent(item *container.TabItem) {
+	build, ok := a.tabBuilders[item.Text]
+	if !ok {
+		return
+	}
+	delete(a.tabBuilders, item.Text)
+	placeholder := item.Content.(*fyne.Container)
+	placeholder.Objects = []fyne.CanvasObject{build()}
+	placeholder.Refresh()
+}
+
+// restoreLastTab selects the tab saved by saveLastTab. The Clash API tab is not restored
+// while sing-box is not running: the launcher stays on Core.
+func (a *App) restoreLastTab() {
+	prefs := a.core.LoadPreferences()
+	if prefs.ForgetLastTab || prefs.LastTabIndex <= 0 || prefs.LastTabIndex >= len(a.tabs.Items) {
+		return
+	}
+	item := a.tabs.Items[prefs.LastTabIndex]
+	if item == a.clashAPITab && !a.core.RunningState.IsRunning() {
+		return
+	}
+	a.tabs.Select(item)
+}
+
+// saveLastTab stores the index of the selected tab in preferences.json (see restoreLastTab)
+func (a *App) saveLastTab() {
+	index := a.tabs.SelectedIndex()
+	if index < 0 || a.core.ReadOnly {
+		return
+	}
+	prefs := a.core.LoadPreferences()
+	if prefs.ForgetLastTab || prefs.LastTabIndex == index {
+		return
+	}
+	if err := a.core.UpdatePreferences(func(p *core.Preferences) { p.LastTabIndex = index }); err != nil {
+		log.Printf("App: Failed to save last tab: %v", err)
+	}
+}
+
+// reloadPreferences applies the default preferences after "Reset to Defaults":
+// the theme and the Settings tab are rebuilt, the launcher returns to the Core tab
+func (a *App) reloadPreferences() {
+	ApplyTheme(a.core)
+	a.settingsTab.Content = CreateSettingsTab(a.core)
+	a.tabs.Refresh()
+	a.tabs.SelectIndex(0)
+}
+
+// Destroy stops background work of the tabs
+func (a *App) Destroy() {
+	a.coreTab.Destroy()
+}
+
 // GetTabs returns the tabs container
 func (a *App) GetTabs() *container.AppTabs {
 	return a.tabs
 }
 
+// Content returns the main window content: the tabs, with a banner above them in read-only mode
+func (a *App) Content() fyne.CanvasObject {
+	if !a.core.ReadOnly {
+		return a.tabs
+	}
+	banner := widget.NewLabel(T("app.readonly"))
+	banner.Importance = widget.WarningImportance
+	banner.Alignment = fyne.TextAlignCenter
+	banner.Wrapping = fyne.TextWrapWord
+	return container.NewBorder(banner, nil, nil, nil, a.tabs)
+}
+
 // GetWindow returns the main window
 func (a *App) GetWindow() fyne.Window {
 	return a.window
@@ -93,11 +229,12 @@ func (a *App) updateClashAPITabState() {
 
 	isRunning := a.core.RunningState.IsRunning()
 
-	// Используем DisableItem/EnableItem из AppTabs для визуальной индикации неактивности
-	if !isRunning {
+	// Используем DisableItem/EnableItem из AppTabs для визуальной индикации неактивности.
+	// Если вкладка уже в нужном состоянии, не трогаем её (лишний Refresh даёт мерцание)
+	if !isRunning && !a.clashAPITab.Disabled() {
 		// Вкладка неактивна - отключаем её (будет показана серым цветом)
 		a.tabs.DisableItem(a.clashAPITab)
-	} else {
+	} else if isRunning && a.clashAPITab.Disabled() {
 		// Вкладка активна - включаем её
 		a.tabs.EnableItem(a.clashAPITab)
 	}
@@ -0,0 +1,81 @@
+package ui
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+
+	"singbox-launcher/core"
+)
+
+// AppBuilder configures the UI before the main window is shown:
+//
+//	app, err := ui.NewAppBuilder(window, controller).WithLocale("en").Build()
+//
+// Without options it applies the theme and language saved in preferences.
+type AppBuilder struct {
+	window     fyne.Window
+	controller *core.AppController
+	theme      fyne.Theme
+	language   string
+	extraTabs  []*container.TabItem
+}
+
+// NewAppBuilder creates a builder for the UI shown in window
+func NewAppBuilder(window fyne.Window, controller *core.AppController) *AppBuilder {
+	return &AppBuilder{window: window, controller: controller}
+}
+
+// WithTheme uses t instead of the theme saved in preferences
+func (b *AppBuilder) WithTheme(t fyne.Theme) *AppBuilder {
+	b.theme = t
+	return b
+}
+
+// WithLocale uses the language lang (e.g. "en", "zh-CN") instead of the one saved in preferences
+func (b *AppBuilder) WithLocale(lang string) *AppBuilder {
+	b.language = lang
+	return b
+}
+
+// WithExtraTab adds a tab after the built-in ones
+func (b *AppBuilder) WithExtraTab(name string, content fyne.CanvasObject) *AppBuilder {
+	b.extraTabs = append(b.extraTabs, container.NewTabItem(name, content))
+	return b
+}
+
+// Build applies the theme and language and creates the App
+func (b *AppBuilder) Build() (*App, error) {
+	if b.window == nil || b.controller == nil {
+		return nil, fmt.Errorf("AppBuilder: window and controller are required")
+	}
+
+	if b.language != "" {
+		l := NewLocalizer(b.controller.ExecDir(), b.language)
+		if !containsString(l.Languages(), b.language) {
+			return nil, fmt.Errorf("AppBuilder: unknown language %q (available: %v)", b.language, l.Languages())
+		}
+		localizer = l
+	} else {
+		InitLocalizer(b.controller)
+	}
+
+	if b.theme != nil {
+		b.controller.Application.Settings().SetTheme(b.theme)
+	} else {
+		ApplyTheme(b.controller)
+	}
+
+	app := NewApp(b.window, b.controller)
+	for _, tab := range b.extraTabs {
+		for _, existing := range app.tabs.Items {
+			if existing.Text == tab.Text {
+				return nil, fmt.Errorf("AppBuilder: duplicate tab %q", tab.Text)
+			}
+		}
+		app.tabs.Append(tab)
+	}
+	app.restoreLastTab()
+	return app, nil
+}
@@ -0,0 +1,79 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+
+	"singbox-launcher/core"
+)
+
+// clashAPIGate shows the Clash API tab content only after TestClashAPIReachability succeeded.
+// Otherwise the tab shows why the API can't be reached and a Retry button instead of a list
+// that fails with an error dialog on every request.
+type clashAPIGate struct {
+	ac      *core.AppController
+	content fyne.CanvasObject
+
+	errorLabel  *widget.Label
+	detailLabel *widget.Label
+	retryButton *widget.Button
+	errorPanel  fyne.CanvasObject
+	stack       *fyne.Container
+}
+
+// newClashAPIGate wraps the content of the Clash API tab (CreateClashAPITab)
+func newClashAPIGate(ac *core.AppController, content fyne.CanvasObject) *clashAPIGate {
+	g := &clashAPIGate{ac: ac, content: content}
+	g.errorLabel = widget.NewLabel("")
+	g.errorLabel.Wrapping = fyne.TextWrapWord
+	g.errorLabel.TextStyle.Bold = true
+	g.detailLabel = widget.NewLabel("")
+	g.detailLabel.Wrapping = fyne.TextWrapWord
+	g.retryButton = widget.NewButton("Retry", g.Check)
+	g.errorPanel = container.NewVBox(
+		g.errorLabel,
+		g.detailLabel,
+		container.NewHBox(g.retryButton, layout.NewSpacer()),
+	)
+	g.errorPanel.Hide()
+	g.stack = container.NewStack(content, g.errorPanel)
+	return g
+}
+
+// Content returns the object to put into the tab
+func (g *clashAPIGate) Content() fyne.CanvasObject {
+	return g.stack
+}
+
+// Check tests the Clash API in the background. The tab content is shown and refreshed
+// (RefreshAPIFunc) if it answers, the error panel otherwise. Must be called on the UI goroutine.
+func (g *clashAPIGate) Check() {
+	g.retryButton.Disable()
+	go func() {
+		err := g.ac.TestClashAPIReachability(context.Background())
+		fyne.Do(func() {
+			g.retryButton.Enable()
+			if err != nil {
+				log.Printf("clash_api_tab: %v", err)
+				baseURL := g.ac.ClashAPIBaseURL
+				if baseURL == "" {
+					baseURL = "(no experimental.clash_api in config.json)"
+				}
+				g.errorLabel.SetText(fmt.Sprintf("Cannot reach Clash API at %s. Is sing-box running?", baseURL))
+				g.detailLabel.SetText(err.Error())
+				g.content.Hide()
+				g.errorPanel.Show()
+				return
+			}
+			g.errorPanel.Hide()
+			g.content.Show()
+			g.ac.RefreshAPIFunc()
+		})
+	}()
+}
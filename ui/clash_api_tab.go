@@ -13,6 +13,7 @@ import (
 
 	"singbox-launcher/api"
 	"singbox-launcher/core"
+	"singbox-launcher/internal/platform"
 )
 
 // CreateClashAPITab creates and returns the content for the "Clash API" tab.
@@ -21,7 +22,7 @@ func CreateClashAPITab(ac *core.AppController) fyne.CanvasObject {
 	status := widget.NewLabel("Click 'Load Proxies' or 'Test API'")
 	ac.ListStatusLabel = status
 
-	selectorOptions, defaultSelector, err := core.GetSelectorGroupsFromConfig(ac.ConfigPath)
+	selectorOptions, defaultSelector, err := core.GetSelectorGroupsFromConfig(ac.ConfigPath())
 	if err != nil {
 		log.Printf("clash_api_tab: failed to get selector groups: %v", err)
 	}
@@ -33,11 +34,11 @@ func CreateClashAPITab(ac *core.AppController) fyne.CanvasObject {
 		selectedGroup = selectorOptions[0]
 	}
 	// Only set SelectedClashGroup if it's not already set (to preserve value from initialization)
-	if ac.SelectedClashGroup == "" {
-		ac.SelectedClashGroup = selectedGroup
+	if current := ac.GetSelectedClashGroup(); current == "" {
+		ac.SetSelectedClashGroup(selectedGroup)
 	} else {
 		// Use existing value, but update selectedGroup variable for UI
-		selectedGroup = ac.SelectedClashGroup
+		selectedGroup = current
 	}
 
 	var (
@@ -224,7 +225,7 @@ func CreateClashAPITab(ac *core.AppController) fyne.CanvasObject {
 				return
 			}
 			go func(group string) {
-				err := api.SwitchProxy(ac.ClashAPIBaseURL, ac.ClashAPIToken, group, proxyNameForCallback, ac.ApiLogFile)
+				err := ac.SwitchProxyGroup(group, proxyNameForCallback)
 				fyne.Do(func() {
 					if err != nil {
 						ShowError(ac.MainWindow, err)
@@ -271,7 +272,7 @@ func CreateClashAPITab(ac *core.AppController) fyne.CanvasObject {
 			return
 		}
 		selectedGroup = value
-		ac.SelectedClashGroup = value
+		ac.SetSelectedClashGroup(value)
 		if suppressSelectCallback {
 			return
 		}
@@ -295,9 +296,14 @@ func CreateClashAPITab(ac *core.AppController) fyne.CanvasObject {
 		ac.ApiStatusLabel,
 		container.NewHBox(widget.NewLabel("Selector group:"), groupSelect),
 		testAPIButton,
-		widget.NewSeparator(),
-		loadButton,
 	)
+	if core.EmbeddedUIAvailable {
+		topControls.Add(widget.NewButton("Open Web UI", func() { openEmbeddedUI(ac) }))
+	}
+	topControls.Add(widget.NewSeparator())
+	topControls.Add(loadButton)
+	topControls.Add(createSelectorGroupsPanel(ac))
+	topControls.Add(createLatencyPanel(ac))
 
 	contentContainer := container.NewBorder(
 		topControls,
@@ -309,3 +315,21 @@ func CreateClashAPITab(ac *core.AppController) fyne.CanvasObject {
 
 	return contentContainer
 }
+
+// openEmbeddedUI starts the built-in web UI (builds with -tags with_embedded_ui) and opens it in the browser.
+// The Clash API secret is not passed in the URL, it is copied to the clipboard for the login form of the web UI.
+func openEmbeddedUI(ac *core.AppController) {
+	if err := ac.StartEmbeddedUI(core.EmbeddedUIDefaultPort); err != nil {
+		ShowError(ac.MainWindow, err)
+		return
+	}
+	if err := platform.OpenURL(ac.EmbeddedUIURL()); err != nil {
+		log.Printf("clash_api_tab: Failed to open web UI: %v", err)
+		ShowError(ac.MainWindow, err)
+		return
+	}
+	if ac.ClashAPIToken != "" {
+		ac.MainWindow.Clipboard().SetContent(ac.ClashAPIToken)
+		ShowInfo(ac.MainWindow, "Open Web UI", "The Clash API secret was copied to the clipboard.\nPaste it into the Secret field of the web UI.")
+	}
+}
@@ -0,0 +1,73 @@
+package ui
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+// CollapsibleSection shows a clickable header row (▶/▼ + title) and toggles the visibility of its content
+type CollapsibleSection struct {
+	widget.BaseWidget
+
+	Title     string
+	Content   fyne.CanvasObject
+	OnToggled func(expanded bool) // Called when the user expands or collapses the section
+
+	expanded bool
+	header   *widget.Button
+}
+
+// NewCollapsibleSection creates a section with the given title and content
+func NewCollapsibleSection(title string, content fyne.CanvasObject, expanded bool) *CollapsibleSection {
+	s := &CollapsibleSection{
+		Title:    title,
+		Content:  content,
+		expanded: expanded,
+	}
+	s.header = widget.NewButton("", func() {
+		s.SetExpanded(!s.expanded)
+		if s.OnToggled != nil {
+			s.OnToggled(s.expanded)
+		}
+	})
+	s.header.Alignment = widget.ButtonAlignLeading
+	s.header.Importance = widget.LowImportance
+	s.ExtendBaseWidget(s)
+	s.applyState()
+	return s
+}
+
+// IsExpanded reports whether the content is visible
+func (s *CollapsibleSection) IsExpanded() bool {
+	return s.expanded
+}
+
+// SetExpanded shows or hides the content
+func (s *CollapsibleSection) SetExpanded(expanded bool) {
+	s.expanded = expanded
+	s.applyState()
+	s.Refresh()
+}
+
+// applyState updates the header triangle and content visibility
+func (s *CollapsibleSection) applyState() {
+	if s.expanded {
+		s.header.SetText("▼ " + s.Title)
+		s.Content.Show()
+	} else {
+		s.header.SetText("▶ " + s.Title)
+		s.Content.Hide()
+	}
+}
+
+// CreateRenderer implements fyne.Widget
+func (s *CollapsibleSection) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(container.NewBorder(s.header, nil, nil, nil, s.Content))
+}
+
+// SetTitle changes the header title, e.g. to show a status next to the section name
+func (s *CollapsibleSection) SetTitle(title string) {
+	s.Title = title
+	s.applyState()
+}
@@ -0,0 +1,73 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"singbox-launcher/core"
+)
+
+// configDiffGrid renders the section diffs with added lines in green and removed lines in red
+func configDiffGrid(changes []core.SectionChange) *widget.TextGrid {
+	var lines []string
+	for _, change := range changes {
+		lines = append(lines, fmt.Sprintf("=== %s (%s) ===", change.Section, change.ChangeType))
+		lines = append(lines, strings.Split(strings.TrimSuffix(change.Diff, "\n"), "\n")...)
+		lines = append(lines, "")
+	}
+
+	grid := widget.NewTextGridFromString(strings.Join(lines, "\n"))
+	added := &widget.CustomTextGridStyle{FGColor: theme.Color(theme.ColorNameSuccess)}
+	removed := &widget.CustomTextGridStyle{FGColor: theme.Color(theme.ColorNameError)}
+	header := &widget.CustomTextGridStyle{FGColor: theme.Color(theme.ColorNamePrimary), TextStyle: fyne.TextStyle{Bold: true}}
+	for row, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "==="), strings.HasPrefix(line, "@@"),
+			strings.HasPrefix(line, "---"), strings.HasPrefix(line, "+++"):
+			grid.SetRowStyle(row, header)
+		case strings.HasPrefix(line, "+"):
+			grid.SetRowStyle(row, added)
+		case strings.HasPrefix(line, "-"):
+			grid.SetRowStyle(row, removed)
+		}
+	}
+	return grid
+}
+
+// showConfigDiffDialog shows what the new config changes in config.json section by section.
+// onApply is called with restart=true for "Apply and Restart"; Cancel leaves config.json as is.
+func showConfigDiffDialog(window fyne.Window, changes []core.SectionChange, onApply func(restart bool)) {
+	summary := make([]string, 0, len(changes))
+	for _, change := range changes {
+		summary = append(summary, fmt.Sprintf("%s (%s)", change.Section, change.ChangeType))
+	}
+
+	diffScroll := container.NewScroll(configDiffGrid(changes))
+	diffScroll.SetMinSize(fyne.NewSize(700, 420))
+	summaryLabel := widget.NewLabel("Changed sections: " + strings.Join(summary, ", "))
+	summaryLabel.Wrapping = fyne.TextWrapWord
+	content := container.NewBorder(summaryLabel, nil, nil, nil, diffScroll)
+
+	d := dialog.NewCustomWithoutButtons("Config Changes", content, window)
+	applyButton := widget.NewButton("Apply", func() {
+		d.Hide()
+		onApply(false)
+	})
+	applyRestartButton := widget.NewButton("Apply and Restart", func() {
+		d.Hide()
+		onApply(true)
+	})
+	applyRestartButton.Importance = widget.HighImportance
+	d.SetButtons([]fyne.CanvasObject{
+		widget.NewButton("Cancel", func() { d.Hide() }),
+		applyButton,
+		applyRestartButton,
+	})
+	d.Show()
+}
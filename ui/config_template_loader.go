@@ -3,22 +3,28 @@ package ui
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
 
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/theme"
 	"github.com/muhammadmuzzammil1998/jsonc"
 
+	"singbox-launcher/core"
 	"singbox-launcher/internal/debuglog"
 )
 
-const templateLoaderLogLevel = debuglog.LevelOff
-
-func tplLog(level debuglog.Level, format string, args ...interface{}) {
-	debuglog.Log("TemplateLoader", level, templateLoaderLogLevel, format, args...)
-}
+// Template loader logging (templateLoaderLogLevel, tplLog, tplEnabled) is defined in
+// debug_template.go (built with -tags debug) and nodebug_template.go (release builds).
 
 type TemplateData struct {
 	ParserConfig            string
@@ -26,8 +32,15 @@ type TemplateData struct {
 	SectionOrder            []string
 	SelectableRules         []TemplateSelectableRule
 	DefaultFinal            string
-	HasParserOutboundsBlock bool   // true if @PARSER_OUTBOUNDS_BLOCK marker was found in template
-	OutboundsAfterMarker    string // Elements after @PARSER_OUTBOUNDS_BLOCK marker (e.g., direct-out)
+	HasParserOutboundsBlock bool                // true if @PARSER_OUTBOUNDS_BLOCK marker was found in template
+	OutboundsAfterMarker    string              // Elements after @PARSER_OUTBOUNDS_BLOCK marker (e.g., direct-out)
+	Warnings                []ValidationWarning // Non-fatal template problems, shown as a banner in the wizard
+}
+
+// ValidationWarning is a template authoring mistake that does not prevent loading the template,
+// but would make sing-box reject the generated config
+type ValidationWarning struct {
+	Message string
 }
 
 type TemplateSelectableRule struct {
@@ -35,52 +48,132 @@ type TemplateSelectableRule struct {
 	Description     string
 	Raw             map[string]interface{}
 	DefaultOutbound string
-	HasOutbound     bool // true if rule has "outbound" field that can be selected
-	IsDefault       bool // true if rule should be enabled by default
+	HasOutbound     bool                 // true if rule has "outbound" field that can be selected
+	IsDefault       bool                 // true if rule should be enabled by default
+	Icon            string               // emoji or Fyne theme icon name from @icon, shown before the label
+	Schema          map[string]FieldSpec // fields edited in a form in the wizard, from @schema
+}
+
+// templateCacheKey identifies a version of a template file
+type templateCacheKey struct {
+	path    string
+	modTime time.Time
+	size    int64
 }
 
+// templateCache holds parsed templates, so reopening the wizard doesn't parse an unchanged file again
+var templateCache = struct {
+	sync.RWMutex
+	entries map[string]templateCacheEntry // keyed by path
+}{entries: make(map[string]templateCacheEntry)}
+
+type templateCacheEntry struct {
+	key  templateCacheKey
+	data *TemplateData
+}
+
+// loadTemplateData returns the parsed bin/config_template.json. The result is cached until
+// the file changes; callers get their own copy and may change its sections.
 func loadTemplateData(execDir string) (*TemplateData, error) {
 	templatePath := filepath.Join(execDir, "bin", "config_template.json")
-	tplLog(debuglog.LevelInfo, "Starting to load template from: %s", templatePath)
+	info, err := os.Stat(templatePath)
+	if err != nil {
+		tplLog(debuglog.LevelError, "Failed to read template file", "file", templatePath, "err", err)
+		invalidateTemplateCache(templatePath)
+		return nil, err
+	}
+	key := templateCacheKey{path: templatePath, modTime: info.ModTime(), size: info.Size()}
+
+	templateCache.RLock()
+	entry, ok := templateCache.entries[templatePath]
+	templateCache.RUnlock()
+	if ok && entry.key == key {
+		tplLog(debuglog.LevelVerbose, "Template unchanged, using cached data", "file", templatePath)
+		return entry.data.clone(), nil
+	}
+
+	data, err := parseTemplateFile(templatePath)
+	if err != nil {
+		invalidateTemplateCache(templatePath)
+		return nil, err
+	}
+	templateCache.Lock()
+	templateCache.entries[templatePath] = templateCacheEntry{key: key, data: data}
+	templateCache.Unlock()
+	return data.clone(), nil
+}
+
+// invalidateTemplateCache drops the cached template, e.g. after a new one was downloaded
+func invalidateTemplateCache(templatePath string) {
+	templateCache.Lock()
+	delete(templateCache.entries, templatePath)
+	templateCache.Unlock()
+}
+
+// clone copies the parts of the template the wizard changes (sections), so the cached
+// template stays as it is in the file
+func (t *TemplateData) clone() *TemplateData {
+	cloned := *t
+	cloned.Sections = make(map[string]json.RawMessage, len(t.Sections))
+	for key, value := range t.Sections {
+		cloned.Sections[key] = value
+	}
+	cloned.SectionOrder = append([]string(nil), t.SectionOrder...)
+	cloned.SelectableRules = append([]TemplateSelectableRule(nil), t.SelectableRules...)
+	return &cloned
+}
+
+// parseTemplateFile reads and parses a template file
+func parseTemplateFile(templatePath string) (*TemplateData, error) {
+	tplLog(debuglog.LevelInfo, "Starting to load template", "file", templatePath)
 	raw, err := os.ReadFile(templatePath)
 	if err != nil {
-		tplLog(debuglog.LevelError, "Failed to read template file: %v", err)
+		tplLog(debuglog.LevelError, "Failed to read template file", "file", templatePath, "err", err)
 		return nil, err
 	}
-	tplLog(debuglog.LevelVerbose, "Successfully read template file, size: %d bytes", len(raw))
+	tplLog(debuglog.LevelVerbose, "Successfully read template file", "file", templatePath, "size", len(raw))
 
 	rawStr := string(raw)
-	parserConfig, cleaned := extractCommentBlock(rawStr, "ParcerConfig")
-	tplLog(debuglog.LevelVerbose, "After extractCommentBlock, parserConfig length: %d, cleaned length: %d", len(parserConfig), len(cleaned))
+	parserConfig, cleaned, err := extractCommentBlock(rawStr, "ParcerConfig")
+	if err != nil {
+		tplLog(debuglog.LevelError, "extractCommentBlock failed", "file", templatePath, "section", "ParcerConfig", "err", err)
+		return nil, err
+	}
+	tplLog(debuglog.LevelVerbose, "After extractCommentBlock", "section", "ParcerConfig", "parserConfigLength", len(parserConfig), "cleanedLength", len(cleaned))
 
 	selectableBlocks, cleaned := extractAllSelectableBlocks(cleaned)
-	tplLog(debuglog.LevelVerbose, "After extractAllSelectableBlocks, found %d blocks, cleaned length: %d", len(selectableBlocks), len(cleaned))
-	if len(selectableBlocks) > 0 {
+	tplLog(debuglog.LevelVerbose, "After extractAllSelectableBlocks", "blocks", len(selectableBlocks), "cleanedLength", len(cleaned))
+	if tplEnabled(debuglog.LevelTrace) {
 		for i, block := range selectableBlocks {
-			tplLog(debuglog.LevelTrace, "Block %d (first 100 chars): %s", i+1, truncateString(block, 100))
+			tplLog(debuglog.LevelTrace, "Selectable block", "blockIndex", i+1, "body", truncateString(block, 100))
 		}
 	}
 
 	// Check for @PARSER_OUTBOUNDS_BLOCK marker before parsing JSON
 	// (JSON parser will ignore comments, so we need to check the raw string)
 	hasParserBlock := strings.Contains(cleaned, "@PARSER_OUTBOUNDS_BLOCK")
-	tplLog(debuglog.LevelVerbose, "Has @PARSER_OUTBOUNDS_BLOCK marker: %v", hasParserBlock)
+	tplLog(debuglog.LevelVerbose, "Checked @PARSER_OUTBOUNDS_BLOCK marker", "section", "outbounds", "found", hasParserBlock)
 
 	// Extract elements after the marker (e.g., direct-out)
 	var outboundsAfterMarker string
 	if hasParserBlock {
 		outboundsAfterMarker = extractOutboundsAfterMarker(cleaned)
-		if outboundsAfterMarker != "" {
-			tplLog(debuglog.LevelVerbose, "Extracted outbounds after marker (first 200 chars): %s", truncateString(outboundsAfterMarker, 200))
+		if outboundsAfterMarker != "" && tplEnabled(debuglog.LevelVerbose) {
+			tplLog(debuglog.LevelVerbose, "Extracted outbounds after marker", "section", "outbounds", "content", truncateString(outboundsAfterMarker, 200))
 		}
 	}
 
 	// Validate JSON before parsing
 	jsonBytes := jsonc.ToJSON([]byte(cleaned))
-	tplLog(debuglog.LevelVerbose, "After jsonc.ToJSON, jsonBytes length: %d", len(jsonBytes))
+	tplLog(debuglog.LevelVerbose, "After jsonc.ToJSON", "length", len(jsonBytes))
 
 	if !json.Valid(jsonBytes) {
-		tplLog(debuglog.LevelWarn, "JSON validation failed. First 500 chars: %s", truncateString(string(jsonBytes), 500))
+		if tplEnabled(debuglog.LevelWarn) {
+			tplLog(debuglog.LevelWarn, "JSON validation failed", "file", templatePath, "json", truncateString(string(jsonBytes), 500))
+		}
+		if posErr := templateJSONError(rawStr, cleaned, ""); posErr != nil {
+			return nil, posErr
+		}
 		return nil, fmt.Errorf("invalid JSON after removing @SelectableRule blocks. This may indicate a syntax error in config_template.json")
 	}
 
@@ -89,25 +182,25 @@ func loadTemplateData(execDir string) (*TemplateData, error) {
 	// Parse JSON while preserving key order from template
 	sections, sectionOrder, err := parseJSONWithOrder(jsonBytes)
 	if err != nil {
-		tplLog(debuglog.LevelError, "JSON unmarshal failed: %v", err)
+		tplLog(debuglog.LevelError, "JSON unmarshal failed", "file", templatePath, "err", err)
 		return nil, fmt.Errorf("failed to parse config_template.json: %w", err)
 	}
 
-	tplLog(debuglog.LevelVerbose, "Successfully unmarshaled %d sections", len(sections))
-	tplLog(debuglog.LevelTrace, "Section order from template: %v", sectionOrder)
+	tplLog(debuglog.LevelVerbose, "Successfully unmarshaled sections", "sections", len(sections))
+	tplLog(debuglog.LevelTrace, "Section order from template", "order", sectionOrder)
 
 	defaultFinal := extractDefaultFinal(sections)
 	if defaultFinal != "" {
-		tplLog(debuglog.LevelVerbose, "Detected default final outbound: %s", defaultFinal)
+		tplLog(debuglog.LevelVerbose, "Detected default final outbound", "section", "route", "final", defaultFinal)
 	}
 
-	selectableRules, err := parseSelectableRules(selectableBlocks)
+	selectableRules, err := parseSelectableRules(rawStr, selectableBlocks)
 	if err != nil {
-		tplLog(debuglog.LevelError, "parseSelectableRules failed: %v", err)
+		tplLog(debuglog.LevelError, "parseSelectableRules failed", "file", templatePath, "err", err)
 		return nil, err
 	}
 
-	tplLog(debuglog.LevelVerbose, "Successfully parsed %d selectable rules", len(selectableRules))
+	tplLog(debuglog.LevelVerbose, "Successfully parsed selectable rules", "rules", len(selectableRules))
 
 	result := &TemplateData{
 		ParserConfig:            strings.TrimSpace(parserConfig),
@@ -118,59 +211,232 @@ func loadTemplateData(execDir string) (*TemplateData, error) {
 		HasParserOutboundsBlock: hasParserBlock,
 		OutboundsAfterMarker:    outboundsAfterMarker,
 	}
+	if warning := validateDefaultFinal(result); warning != nil {
+		tplLog(debuglog.LevelWarn, "Template validation warning", "file", templatePath, "warning", warning.Message)
+		result.Warnings = append(result.Warnings, *warning)
+	}
 
-	tplLog(debuglog.LevelInfo, "Successfully loaded template data with %d sections and %d selectable rules", len(sections), len(selectableRules))
+	tplLog(debuglog.LevelInfo, "Successfully loaded template data", "file", templatePath, "sections", len(sections), "rules", len(selectableRules))
 
 	return result, nil
 }
 
-// truncateString truncates a string to maxLen characters, adding "..." if truncated
+// truncateString truncates a string to maxLen characters (runes), adding "..." if truncated.
+// Never splits a multi-byte UTF-8 sequence.
 func truncateString(s string, maxLen int) string {
-	if len(s) <= maxLen {
+	if len(s) <= maxLen || utf8.RuneCountInString(s) <= maxLen {
 		return s
 	}
-	return s[:maxLen] + "..."
+	if maxLen <= 0 {
+		return "..."
+	}
+	count := 0
+	for i := range s {
+		if count == maxLen {
+			return s[:i] + "..."
+		}
+		count++
+	}
+	return s
+}
+
+// templateJSONError validates text (JSON with comments) and returns an error
+// pointing at the failing line and column, or nil if the text is valid JSON.
+// text may be a processed fragment of rawTemplate; the position is mapped back
+// to the original file when the failing line can be found there unambiguously.
+// blockName, if not empty, names the @SelectableRule block the text came from.
+func templateJSONError(rawTemplate, text, blockName string) error {
+	data := blankJSONComments([]byte(text))
+	var v interface{}
+	err := json.Unmarshal(data, &v)
+	if err == nil {
+		return nil
+	}
+
+	// Decoding into interface{} can only fail with a syntax error
+	var syntaxErr *json.SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		return fmt.Errorf("config_template.json: %w", err)
+	}
+
+	// Offset points right after the offending byte
+	line, col := byteOffsetToLineCol(data, int(syntaxErr.Offset-1))
+	textLine := lineAt(text, line)
+	mapped := false
+	if srcLine := findUniqueLine(rawTemplate, textLine); srcLine > 0 {
+		// Lines match up to surrounding whitespace, adjust for a different indent
+		col += indentWidth(lineAt(rawTemplate, srcLine)) - indentWidth(textLine)
+		line = srcLine
+		mapped = true
+	}
+
+	switch {
+	case mapped && blockName != "":
+		return fmt.Errorf("config_template.json line %d, col %d (%s): %v", line, col, blockName, err)
+	case mapped:
+		return fmt.Errorf("config_template.json line %d, col %d: %v", line, col, err)
+	case blockName != "":
+		// Could not map back to the file, report position inside the block
+		return fmt.Errorf("config_template.json %s, line %d, col %d: %v", blockName, line, col, err)
+	default:
+		return fmt.Errorf("config_template.json (after removing comment blocks) line %d, col %d: %v", line, col, err)
+	}
+}
+
+// byteOffsetToLineCol converts a byte offset in data into 1-based line and column numbers.
+// Columns are counted in characters, not bytes.
+func byteOffsetToLineCol(data []byte, offset int) (line, col int) {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > len(data) {
+		offset = len(data)
+	}
+	line = 1 + bytes.Count(data[:offset], []byte("\n"))
+	lineStart := bytes.LastIndexByte(data[:offset], '\n') + 1
+	col = 1 + utf8.RuneCount(data[lineStart:offset])
+	return line, col
+}
+
+// blankJSONComments replaces // and /* */ comments with spaces (newlines are kept),
+// so offsets reported by encoding/json match the original text
+func blankJSONComments(src []byte) []byte {
+	out := make([]byte, len(src))
+	copy(out, src)
+	inString := false
+	for i := 0; i < len(out); i++ {
+		c := out[i]
+		if inString {
+			if c == '\\' {
+				i++
+			} else if c == '"' {
+				inString = false
+			}
+			continue
+		}
+		switch {
+		case c == '"':
+			inString = true
+		case c == '/' && i+1 < len(out) && out[i+1] == '/':
+			for ; i < len(out) && out[i] != '\n'; i++ {
+				out[i] = ' '
+			}
+		case c == '/' && i+1 < len(out) && out[i+1] == '*':
+			end := bytes.Index(out[i+2:], []byte("*/"))
+			stop := len(out)
+			if end >= 0 {
+				stop = i + 2 + end + 2
+			}
+			for ; i < stop; i++ {
+				if out[i] != '\n' && out[i] != '\r' {
+					out[i] = ' '
+				}
+			}
+			i--
+		}
+	}
+	return out
+}
+
+// blankTrailingCommas replaces commas directly before a closing ] or } (comments and whitespace
+// in between are skipped) with spaces, keeping byte offsets and line breaks of src
+func blankTrailingCommas(src []byte) []byte {
+	out := make([]byte, len(src))
+	copy(out, src)
+	blanked := blankJSONComments(src)
+	inString := false
+	for i := 0; i < len(blanked); i++ {
+		c := blanked[i]
+		if inString {
+			if c == '\\' {
+				i++
+			} else if c == '"' {
+				inString = false
+			}
+			continue
+		}
+		switch c {
+		case '"':
+			inString = true
+		case ',':
+			next := i + 1
+			for next < len(blanked) && strings.IndexByte(" \t\r\n", blanked[next]) >= 0 {
+				next++
+			}
+			if next < len(blanked) && (blanked[next] == ']' || blanked[next] == '}') {
+				out[i] = ' '
+			}
+		}
+	}
+	return out
+}
+
+// lineAt returns the 1-based line of s, or "" if out of range
+func lineAt(s string, line int) string {
+	lines := strings.Split(s, "\n")
+	if line < 1 || line > len(lines) {
+		return ""
+	}
+	return lines[line-1]
+}
+
+// indentWidth returns the number of leading whitespace characters in s
+func indentWidth(s string) int {
+	return len(s) - len(strings.TrimLeft(s, " \t"))
+}
+
+// findUniqueLine returns the 1-based number of the only line in src equal to target
+// (ignoring surrounding whitespace), or 0 if there is no such line or more than one
+func findUniqueLine(src, target string) int {
+	target = strings.TrimSpace(target)
+	if target == "" {
+		return 0
+	}
+	found := 0
+	for i, l := range strings.Split(src, "\n") {
+		if strings.TrimSpace(l) == target {
+			if found != 0 {
+				return 0
+			}
+			found = i + 1
+		}
+	}
+	return found
 }
 
-func extractCommentBlock(src, marker string) (string, string) {
+// extractCommentBlock returns the body of the /** @marker ... */ block and src without it.
+// More than one block with the same marker is an error, since only one of them would be used.
+func extractCommentBlock(src, marker string) (string, string, error) {
 	pattern := regexp.MustCompile(`(?s)/\*\*\s*@` + marker + `\s*(.*?)\*/`)
+	if all := pattern.FindAllStringSubmatch(src, -1); len(all) > 1 {
+		return "", src, fmt.Errorf("config_template.json contains %d @%s blocks, expected one. Check the template for duplicate comment blocks", len(all), marker)
+	}
 	matches := pattern.FindStringSubmatch(src)
 	if len(matches) < 2 {
-		return "", src
+		return "", src, nil
 	}
 	cleaned := pattern.ReplaceAllString(src, "")
-	return strings.TrimSpace(matches[1]), cleaned
+	return strings.TrimSpace(matches[1]), cleaned, nil
 }
 
 func extractAllSelectableBlocks(src string) ([]string, string) {
-	tplLog(debuglog.LevelTrace, "extractAllSelectableBlocks: input length: %d", len(src))
+	tplLog(debuglog.LevelTrace, "extractAllSelectableBlocks: input", "length", len(src))
 	// Only support @SelectableRule
-	// Match the block including optional leading/trailing commas, whitespace, and empty lines
-	pattern := regexp.MustCompile(`(?is)(\s*,?\s*)/\*\*\s*@selectablerule\s*(.*?)\*/(\s*,?\s*)`)
-	matches := pattern.FindAllStringSubmatch(src, -1)
-	tplLog(debuglog.LevelTrace, "extractAllSelectableBlocks: found %d matches", len(matches))
-	if len(matches) == 0 {
+	// Blocks are located by a small state machine (see scanSelectableBlocks) rather than a regex,
+	// so URLs with "//" and "*/" inside JSON strings do not terminate a block early
+	blocks, cleaned := scanSelectableBlocks(src)
+	tplLog(debuglog.LevelTrace, "extractAllSelectableBlocks: found matches", "matches", len(blocks))
+	if len(blocks) == 0 {
 		tplLog(debuglog.LevelTrace, "extractAllSelectableBlocks: no matches, returning original source")
 		return nil, src
 	}
-
-	// Extract blocks first before removing
-	var blocks []string
-	for _, m := range matches {
-		if len(m) >= 3 {
-			blocks = append(blocks, strings.TrimSpace(m[2]))
-		}
-	}
-	tplLog(debuglog.LevelVerbose, "extractAllSelectableBlocks: extracted %d blocks", len(blocks))
-
-	// Remove the blocks, including surrounding commas and whitespace
-	// Use a more aggressive pattern that also removes empty lines after blocks
-	cleaned := pattern.ReplaceAllString(src, "")
-	tplLog(debuglog.LevelTrace, "extractAllSelectableBlocks: after removing blocks, length: %d", len(cleaned))
+	tplLog(debuglog.LevelVerbose, "extractAllSelectableBlocks: extracted blocks", "blocks", len(blocks))
+	warnDuplicateSelectableLabels(blocks)
+	tplLog(debuglog.LevelTrace, "extractAllSelectableBlocks: after removing blocks", "length", len(cleaned))
 
 	// Remove empty lines that might be left (lines with only whitespace)
 	cleaned = regexp.MustCompile(`(?m)^\s*$\n?`).ReplaceAllString(cleaned, "")
-	tplLog(debuglog.LevelTrace, "extractAllSelectableBlocks: after removing empty lines, length: %d", len(cleaned))
+	tplLog(debuglog.LevelTrace, "extractAllSelectableBlocks: after removing empty lines", "length", len(cleaned))
 
 	// Clean up any double commas that might result
 	cleaned = regexp.MustCompile(`,\s*,`).ReplaceAllString(cleaned, ",")
@@ -178,16 +444,176 @@ func extractAllSelectableBlocks(src string) ([]string, string) {
 	cleaned = regexp.MustCompile(`,\s*\]`).ReplaceAllString(cleaned, "]")
 	// Clean up comma after opening bracket
 	cleaned = regexp.MustCompile(`\[\s*,`).ReplaceAllString(cleaned, "[")
-	tplLog(debuglog.LevelTrace, "extractAllSelectableBlocks: after cleaning commas, length: %d", len(cleaned))
-	tplLog(debuglog.LevelTrace, "extractAllSelectableBlocks: first 200 chars of cleaned: %s", truncateString(cleaned, 200))
+	tplLog(debuglog.LevelTrace, "extractAllSelectableBlocks: after cleaning commas", "length", len(cleaned))
+	if tplEnabled(debuglog.LevelTrace) {
+		tplLog(debuglog.LevelTrace, "extractAllSelectableBlocks: cleaned", "content", truncateString(cleaned, 200))
+	}
 
 	return blocks, cleaned
 }
 
-func parseSelectableRules(blocks []string) ([]TemplateSelectableRule, error) {
-	tplLog(debuglog.LevelVerbose, "parseSelectableRules: incoming blocks (%d total)", len(blocks))
+// warnDuplicateSelectableLabels logs a warning for every @label used by more than one block:
+// rules with the same label cannot be told apart in the rules tab of the wizard
+func warnDuplicateSelectableLabels(blocks []string) {
+	blockNumbers := make(map[string][]int)
+	var labels []string
 	for i, block := range blocks {
-		tplLog(debuglog.LevelTrace, "parseSelectableRules: incoming block %d raw (first 200 chars): %s", i+1, truncateString(block, 200))
+		label, _, _, _, _, _ := extractRuleMetadata(block, i+1)
+		if label == "" {
+			continue
+		}
+		if _, seen := blockNumbers[label]; !seen {
+			labels = append(labels, label)
+		}
+		blockNumbers[label] = append(blockNumbers[label], i+1)
+	}
+	for _, label := range labels {
+		if numbers := blockNumbers[label]; len(numbers) > 1 {
+			log.Printf("extractAllSelectableBlocks: Warning: @label %q is used by %d @SelectableRule blocks (%v)", label, len(numbers), numbers)
+		}
+	}
+}
+
+const selectableRuleMarker = "@selectablerule"
+
+// scanSelectableBlocks walks src character by character and cuts out every
+// /** @SelectableRule ... */ block together with the commas and whitespace around it.
+// It returns the block bodies (text after the marker) and the remaining source.
+// Outside blocks it skips JSON strings and comments, so markers inside them are ignored.
+func scanSelectableBlocks(src string) ([]string, string) {
+	var blocks []string
+	var out strings.Builder
+	i := 0
+	for i < len(src) {
+		c := src[i]
+		switch {
+		case c == '"':
+			end := skipJSONString(src, i)
+			out.WriteString(src[i:end])
+			i = end
+		case strings.HasPrefix(src[i:], "//"):
+			end := strings.IndexByte(src[i:], '\n')
+			if end < 0 {
+				end = len(src) - i
+			}
+			out.WriteString(src[i : i+end])
+			i += end
+		case strings.HasPrefix(src[i:], "/**") && isSelectableRuleStart(src[i+3:]):
+			bodyStart := i + 3
+			bodyStart += len(src[bodyStart:]) - len(strings.TrimLeft(src[bodyStart:], " \t\r\n"))
+			bodyStart += len(selectableRuleMarker)
+			bodyEnd, blockEnd := findSelectableBlockEnd(src, bodyStart)
+			blocks = append(blocks, strings.TrimSpace(src[bodyStart:bodyEnd]))
+
+			// Drop whitespace and one comma before the block...
+			kept := strings.TrimRight(out.String(), " \t\r\n")
+			kept = strings.TrimSuffix(kept, ",")
+			kept = strings.TrimRight(kept, " \t\r\n")
+			out.Reset()
+			out.WriteString(kept)
+			// ...and after it
+			i = blockEnd
+			for i < len(src) && strings.IndexByte(" \t\r\n", src[i]) >= 0 {
+				i++
+			}
+			if i < len(src) && src[i] == ',' {
+				i++
+			}
+			for i < len(src) && strings.IndexByte(" \t\r\n", src[i]) >= 0 {
+				i++
+			}
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				end = len(src)
+			} else {
+				end = i + 2 + end + 2
+			}
+			out.WriteString(src[i:end])
+			i = end
+		default:
+			out.WriteByte(c)
+			i++
+		}
+	}
+	return blocks, out.String()
+}
+
+// isSelectableRuleStart reports whether s (text right after "/**") starts with the @SelectableRule marker
+func isSelectableRuleStart(s string) bool {
+	s = strings.TrimLeft(s, " \t\r\n")
+	return len(s) >= len(selectableRuleMarker) && strings.EqualFold(s[:len(selectableRuleMarker)], selectableRuleMarker)
+}
+
+// findSelectableBlockEnd returns the end of the block body and the position right after
+// the closing "*/" for a block whose body starts at start. "*/" inside JSON strings and
+// "//" line comments is ignored, and nested /* */ pairs are balanced.
+// Directive lines (@label, @description, ...) are free text and only checked for "*/".
+// An unterminated block extends to the end of src.
+func findSelectableBlockEnd(src string, start int) (int, int) {
+	depth := 1
+	lineStart := true
+	directive := false
+	i := start
+	for i < len(src) {
+		c := src[i]
+		if lineStart && c != ' ' && c != '\t' && c != '\r' {
+			directive = c == '@'
+			lineStart = false
+		}
+		switch {
+		case c == '\n':
+			lineStart = true
+			directive = false
+			i++
+		case strings.HasPrefix(src[i:], "*/"):
+			depth--
+			if depth == 0 {
+				return i, i + 2
+			}
+			i += 2
+		case directive:
+			i++
+		case c == '"':
+			i = skipJSONString(src, i)
+		case strings.HasPrefix(src[i:], "//"):
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(src[i:], "/*"):
+			depth++
+			i += 2
+		default:
+			i++
+		}
+	}
+	return len(src), len(src)
+}
+
+// skipJSONString returns the position right after the JSON string literal starting at src[start] == '"'.
+// A string that is not closed on the same line ends at the line break.
+func skipJSONString(src string, start int) int {
+	for i := start + 1; i < len(src); i++ {
+		switch src[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		case '\n':
+			return i
+		}
+	}
+	return len(src)
+}
+
+// parseSelectableRules parses extracted @SelectableRule blocks.
+// rawTemplate is the original template text, used only to report error positions.
+func parseSelectableRules(rawTemplate string, blocks []string) ([]TemplateSelectableRule, error) {
+	tplLog(debuglog.LevelVerbose, "parseSelectableRules: incoming blocks", "blocks", len(blocks))
+	if tplEnabled(debuglog.LevelTrace) {
+		for i, block := range blocks {
+			tplLog(debuglog.LevelTrace, "parseSelectableRules: incoming block", "blockIndex", i+1, "raw", truncateString(block, 200))
+		}
 	}
 
 	if len(blocks) == 0 {
@@ -197,38 +623,55 @@ func parseSelectableRules(blocks []string) ([]TemplateSelectableRule, error) {
 
 	var rules []TemplateSelectableRule
 	for i, rawBlock := range blocks {
-		tplLog(debuglog.LevelVerbose, "parseSelectableRules: processing block %d/%d", i+1, len(blocks))
+		tplLog(debuglog.LevelVerbose, "parseSelectableRules: processing block", "blockIndex", i+1, "blocks", len(blocks))
 		if strings.TrimSpace(rawBlock) == "" {
-			tplLog(debuglog.LevelTrace, "parseSelectableRules: block %d is empty after trimming, skipping", i+1)
+			tplLog(debuglog.LevelTrace, "parseSelectableRules: block is empty after trimming, skipping", "blockIndex", i+1)
 			continue
 		}
 
-		label, description, isDefault, cleanedBlock := extractRuleMetadata(rawBlock, i+1)
-		tplLog(debuglog.LevelVerbose, "parseSelectableRules: block %d label='%s', description='%s', isDefault=%v", i+1, label, description, isDefault)
-		tplLog(debuglog.LevelTrace, "parseSelectableRules: block %d cleaned body (first 200 chars): %s", i+1, truncateString(cleanedBlock, 200))
+		label, description, isDefault, icon, schema, cleanedBlock := extractRuleMetadata(rawBlock, i+1)
+		tplLog(debuglog.LevelVerbose, "parseSelectableRules: block metadata", "blockIndex", i+1, "label", label, "description", description, "isDefault", isDefault)
+		if tplEnabled(debuglog.LevelTrace) {
+			tplLog(debuglog.LevelTrace, "parseSelectableRules: cleaned body", "blockIndex", i+1, "body", truncateString(cleanedBlock, 200))
+		}
+
+		blockName := fmt.Sprintf("selectable rule block %d", i+1)
+		if label != "" {
+			blockName = fmt.Sprintf("selectable rule block %d (%q)", i+1, label)
+		}
 
 		if cleanedBlock == "" {
-			return nil, fmt.Errorf("selectable rule block %d has no JSON content", i+1)
+			return nil, fmt.Errorf("%s has no JSON content", blockName)
 		}
 
 		jsonStr, err := normalizeRuleJSON(cleanedBlock, i+1)
 		if err != nil {
-			return nil, fmt.Errorf("selectable rule block %d: %w", i+1, err)
+			return nil, fmt.Errorf("%s: %w", blockName, err)
+		}
+		if tplEnabled(debuglog.LevelTrace) {
+			tplLog(debuglog.LevelTrace, "parseSelectableRules: normalized JSON", "blockIndex", i+1, "json", truncateString(jsonStr, 200))
 		}
-		tplLog(debuglog.LevelTrace, "parseSelectableRules: block %d normalized JSON (first 200 chars): %s", i+1, truncateString(jsonStr, 200))
 
 		jsonBytes := jsonc.ToJSON([]byte(jsonStr))
 		if !json.Valid(jsonBytes) {
-			tplLog(debuglog.LevelWarn, "parseSelectableRules: block %d JSON invalid after jsonc conversion (first 200 chars): %s", i+1, truncateString(string(jsonBytes), 200))
-			return nil, fmt.Errorf("selectable rule block %d contains invalid JSON", i+1)
+			if tplEnabled(debuglog.LevelWarn) {
+				tplLog(debuglog.LevelWarn, "parseSelectableRules: JSON invalid after jsonc conversion", "blockIndex", i+1, "json", truncateString(string(jsonBytes), 200))
+			}
+			if posErr := templateJSONError(rawTemplate, jsonStr, blockName); posErr != nil {
+				return nil, posErr
+			}
+			return nil, fmt.Errorf("%s contains invalid JSON", blockName)
 		}
 
 		var items []map[string]interface{}
 		if err := json.Unmarshal(jsonBytes, &items); err != nil {
-			tplLog(debuglog.LevelError, "parseSelectableRules: block %d JSON unmarshal failed: %v", i+1, err)
-			return nil, fmt.Errorf("failed to parse selectable rule block %d: %w", i+1, err)
+			tplLog(debuglog.LevelError, "parseSelectableRules: JSON unmarshal failed", "blockIndex", i+1, "err", err)
+			if posErr := templateJSONError(rawTemplate, jsonStr, blockName); posErr != nil {
+				return nil, posErr
+			}
+			return nil, fmt.Errorf("failed to parse %s: %w", blockName, err)
 		}
-		tplLog(debuglog.LevelVerbose, "parseSelectableRules: block %d parsed into %d item(s)", i+1, len(items))
+		tplLog(debuglog.LevelVerbose, "parseSelectableRules: block parsed", "blockIndex", i+1, "items", len(items))
 
 		for _, item := range items {
 			rule := TemplateSelectableRule{
@@ -236,6 +679,8 @@ func parseSelectableRules(blocks []string) ([]TemplateSelectableRule, error) {
 				Label:       label,
 				Description: description,
 				IsDefault:   isDefault,
+				Icon:        icon,
+				Schema:      schema,
 			}
 
 			for key, value := range item {
@@ -296,43 +741,75 @@ func parseSelectableRules(blocks []string) ([]TemplateSelectableRule, error) {
 		}
 	}
 
-	tplLog(debuglog.LevelVerbose, "parseSelectableRules: completed with %d rule(s)", len(rules))
+	tplLog(debuglog.LevelVerbose, "parseSelectableRules: completed", "rules", len(rules))
 	return rules, nil
 }
 
-func extractRuleMetadata(block string, blockIndex int) (string, string, bool, string) {
+func extractRuleMetadata(block string, blockIndex int) (string, string, bool, string, map[string]FieldSpec, string) {
 	const (
 		labelDirective   = "@label"
 		descDirective    = "@description"
 		defaultDirective = "@default"
+		iconDirective    = "@icon"
+		schemaDirective  = "@schema"
 	)
 
 	var builder strings.Builder
 	var label string
 	var description string
 	var isDefault bool
+	var icon string
+	var schema map[string]FieldSpec
 
 	lines := strings.Split(block, "\n")
-	for lineIdx, line := range lines {
+	for lineIdx := 0; lineIdx < len(lines); lineIdx++ {
+		line := lines[lineIdx]
 		trimmed := strings.TrimSpace(line)
 		switch {
+		case strings.HasPrefix(trimmed, schemaDirective):
+			// The JSON object may span several lines: take lines until it is complete
+			value := strings.TrimSpace(trimmed[len(schemaDirective):])
+			directiveIdx := lineIdx
+			for !json.Valid(jsonc.ToJSON([]byte(value))) && lineIdx+1 < len(lines) {
+				lineIdx++
+				value += "\n" + lines[lineIdx]
+			}
+			parsed, err := parseRuleSchema(value)
+			if err != nil {
+				// Only the directive line is dropped: the lines taken above are the rule body
+				log.Printf("parseSelectableRules: Warning: block %d: %v, ignoring", blockIndex, err)
+				lineIdx = directiveIdx
+				continue
+			}
+			schema = parsed
+			tplLog(debuglog.LevelTrace, "parseSelectableRules: schema parsed", "blockIndex", blockIndex, "fields", len(schema))
+			continue
 		case strings.HasPrefix(trimmed, labelDirective):
 			value := strings.TrimSpace(trimmed[len(labelDirective):])
 			if value != "" {
 				label = value
-				tplLog(debuglog.LevelTrace, "parseSelectableRules: block %d line %d label parsed: %s", blockIndex, lineIdx+1, value)
+				tplLog(debuglog.LevelTrace, "parseSelectableRules: label parsed", "blockIndex", blockIndex, "line", lineIdx+1, "label", value)
 			}
 			continue
 		case strings.HasPrefix(trimmed, descDirective):
 			value := strings.TrimSpace(trimmed[len(descDirective):])
 			if value != "" {
 				description = value
-				tplLog(debuglog.LevelTrace, "parseSelectableRules: block %d line %d description parsed: %s", blockIndex, lineIdx+1, value)
+				tplLog(debuglog.LevelTrace, "parseSelectableRules: description parsed", "blockIndex", blockIndex, "line", lineIdx+1, "description", value)
 			}
 			continue
 		case strings.HasPrefix(trimmed, defaultDirective):
 			isDefault = true
-			tplLog(debuglog.LevelTrace, "parseSelectableRules: block %d line %d @default directive found", blockIndex, lineIdx+1)
+			tplLog(debuglog.LevelTrace, "parseSelectableRules: @default directive found", "blockIndex", blockIndex, "line", lineIdx+1)
+			continue
+		case strings.HasPrefix(trimmed, iconDirective):
+			value := strings.TrimSpace(trimmed[len(iconDirective):])
+			if isValidRuleIcon(value) {
+				icon = value
+				tplLog(debuglog.LevelTrace, "parseSelectableRules: icon parsed", "blockIndex", blockIndex, "line", lineIdx+1, "icon", value)
+			} else {
+				log.Printf("parseSelectableRules: Warning: block %d: @icon %q is neither a single emoji nor a Fyne theme icon name, ignoring", blockIndex, value)
+			}
 			continue
 		default:
 			builder.WriteString(line)
@@ -341,19 +818,85 @@ func extractRuleMetadata(block string, blockIndex int) (string, string, bool, st
 	}
 
 	cleaned := strings.TrimSpace(builder.String())
-	tplLog(debuglog.LevelTrace, "parseSelectableRules: block %d body length after removing directives: %d", blockIndex, len(cleaned))
-	return label, description, isDefault, cleaned
+	tplLog(debuglog.LevelTrace, "parseSelectableRules: body after removing directives", "blockIndex", blockIndex, "length", len(cleaned))
+	return label, description, isDefault, icon, schema, cleaned
+}
+
+// isValidRuleIcon reports whether value is a single emoji or a Fyne theme icon name (e.g. "confirm")
+func isValidRuleIcon(value string) bool {
+	if value == "" {
+		return false
+	}
+	if theme.DefaultTheme().Icon(fyne.ThemeIconName(value)) != nil {
+		return true
+	}
+	return isSingleEmoji(value)
+}
+
+// isSingleEmoji reports whether s is one emoji grapheme cluster: a base emoji with optional
+// variation selector, skin tone and keycap modifiers, ZWJ sequences (👨‍💻) and flags (🇷🇺)
+func isSingleEmoji(s string) bool {
+	runes := []rune(s)
+	// Keycap: digit, # or * + variation selector + combining keycap (#️⃣)
+	if len(runes) == 3 && strings.ContainsRune("0123456789#*", runes[0]) && runes[1] == 0xFE0F && runes[2] == 0x20E3 {
+		return true
+	}
+	if len(runes) == 0 || !isEmojiBase(runes[0]) {
+		return false
+	}
+	// Flag: exactly two regional indicators
+	if isRegionalIndicator(runes[0]) {
+		return len(runes) == 2 && isRegionalIndicator(runes[1])
+	}
+	for i := 1; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == 0xFE0F || r == 0x20E3 || (r >= 0x1F3FB && r <= 0x1F3FF):
+			// variation selector, keycap, skin tone
+		case r == 0x200D:
+			// ZWJ must join with another emoji
+			if i+1 >= len(runes) || !isEmojiBase(runes[i+1]) {
+				return false
+			}
+			i++
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// isEmojiBase reports whether r is in one of the main emoji blocks
+func isEmojiBase(r rune) bool {
+	switch {
+	case r >= 0x1F000 && r <= 0x1FAFF, // pictographs, emoticons, transport, flags, supplemental symbols
+		r >= 0x2600 && r <= 0x27BF, // misc symbols, dingbats
+		r >= 0x2B00 && r <= 0x2BFF, // arrows (⬆, ⭐)
+		r >= 0x2190 && r <= 0x21FF, // arrows
+		r >= 0x2300 && r <= 0x23FF: // misc technical (⌛, ⏰)
+		return true
+	}
+	return false
+}
+
+// isRegionalIndicator reports whether r is a regional indicator letter used in flag emoji
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1F1E6 && r <= 0x1F1FF
 }
 
 func normalizeRuleJSON(body string, blockIndex int) (string, error) {
-	trimmed := strings.TrimSpace(body)
+	// JSONC: trailing commas inside objects and arrays (comments are removed later by jsonc.ToJSON,
+	// which keeps trailing commas). The layout is kept so error positions map back to the template.
+	trimmed := strings.TrimSpace(string(blankTrailingCommas([]byte(body))))
 	if trimmed == "" {
 		return "", fmt.Errorf("no JSON content after trimming block %d", blockIndex)
 	}
 
 	trimmed = strings.TrimRight(trimmed, " \t\r\n,")
 	trimmed = strings.TrimSpace(trimmed)
-	tplLog(debuglog.LevelTrace, "parseSelectableRules: block %d body after trimming trailing commas (first 200 chars): %s", blockIndex, truncateString(trimmed, 200))
+	if tplEnabled(debuglog.LevelTrace) {
+		tplLog(debuglog.LevelTrace, "parseSelectableRules: body after trimming trailing commas", "blockIndex", blockIndex, "body", truncateString(trimmed, 200))
+	}
 
 	if trimmed == "" {
 		return "", fmt.Errorf("no JSON content remains in block %d after trimming", blockIndex)
@@ -363,7 +906,8 @@ func normalizeRuleJSON(body string, blockIndex int) (string, error) {
 		return trimmed, nil
 	}
 
-	normalized := fmt.Sprintf("[%s]", trimmed)
+	// Keep the body on its own lines so error positions can be mapped back to the template
+	normalized := fmt.Sprintf("[\n%s\n]", trimmed)
 	return normalized, nil
 }
 
@@ -424,37 +968,41 @@ func extractOutboundsAfterMarker(src string) string {
 	outboundsPattern := regexp.MustCompile(`(?is)"outbounds"\s*:\s*\[(.*?)\]`)
 	match := outboundsPattern.FindStringSubmatch(src)
 	if len(match) < 2 {
-		tplLog(debuglog.LevelTrace, "extractOutboundsAfterMarker: outbounds section not found")
+		tplLog(debuglog.LevelTrace, "extractOutboundsAfterMarker: section not found", "section", "outbounds")
 		return ""
 	}
 
 	outboundsContent := match[1]
-	tplLog(debuglog.LevelTrace, "extractOutboundsAfterMarker: found outbounds content (first 200 chars): %s", truncateString(outboundsContent, 200))
+	if tplEnabled(debuglog.LevelTrace) {
+		tplLog(debuglog.LevelTrace, "extractOutboundsAfterMarker: found section content", "section", "outbounds", "content", truncateString(outboundsContent, 200))
+	}
 
 	// Find the marker
 	markerPattern := regexp.MustCompile(`(?is)/\*\*\s*@PARSER_OUTBOUNDS_BLOCK\s*\*/(.*)`)
 	markerMatch := markerPattern.FindStringSubmatch(outboundsContent)
 	if len(markerMatch) < 2 {
-		tplLog(debuglog.LevelTrace, "extractOutboundsAfterMarker: marker not found in outbounds content")
+		tplLog(debuglog.LevelTrace, "extractOutboundsAfterMarker: marker not found", "section", "outbounds")
 		return ""
 	}
 
 	// Extract content after marker
 	afterMarker := strings.TrimSpace(markerMatch[1])
-	tplLog(debuglog.LevelTrace, "extractOutboundsAfterMarker: content after marker (first 200 chars): %s", truncateString(afterMarker, 200))
+	if tplEnabled(debuglog.LevelTrace) {
+		tplLog(debuglog.LevelTrace, "extractOutboundsAfterMarker: content after marker", "section", "outbounds", "content", truncateString(afterMarker, 200))
+	}
 
 	// Remove leading commas and whitespace
 	afterMarker = strings.TrimLeft(afterMarker, ",\n\r\t ")
 
 	if afterMarker == "" {
-		tplLog(debuglog.LevelTrace, "extractOutboundsAfterMarker: no content after marker")
+		tplLog(debuglog.LevelTrace, "extractOutboundsAfterMarker: no content after marker", "section", "outbounds")
 		return ""
 	}
 
 	// Remove trailing comma if present
 	afterMarker = strings.TrimRight(afterMarker, ",\n\r\t ")
 
-	tplLog(debuglog.LevelVerbose, "extractOutboundsAfterMarker: extracted %d chars", len(afterMarker))
+	tplLog(debuglog.LevelVerbose, "extractOutboundsAfterMarker: extracted", "section", "outbounds", "length", len(afterMarker))
 	return afterMarker
 }
 
@@ -468,12 +1016,16 @@ func orderTemplateSections(sections map[string]json.RawMessage) []string {
 			seen[key] = true
 		}
 	}
+	// Unknown sections go after the known ones in alphabetical order,
+	// so the generated config is the same on every run
+	var unknown []string
 	for key := range sections {
 		if !seen[key] {
-			ordered = append(ordered, key)
+			unknown = append(unknown, key)
 		}
 	}
-	return ordered
+	sort.Strings(unknown)
+	return append(ordered, unknown...)
 }
 
 func extractDefaultFinal(sections map[string]json.RawMessage) string {
@@ -483,7 +1035,7 @@ func extractDefaultFinal(sections map[string]json.RawMessage) string {
 	}
 	var route map[string]interface{}
 	if err := json.Unmarshal(raw, &route); err != nil {
-		tplLog(debuglog.LevelWarn, "extractDefaultFinal: failed to unmarshal route section: %v", err)
+		tplLog(debuglog.LevelWarn, "extractDefaultFinal: failed to unmarshal section", "section", "route", "err", err)
 		return ""
 	}
 	if finalVal, ok := route["final"]; ok {
@@ -493,3 +1045,45 @@ func extractDefaultFinal(sections map[string]json.RawMessage) string {
 	}
 	return ""
 }
+
+// templateOutboundTags returns the outbound tags a config generated from the template has: those of
+// the outbounds section and, with @PARSER_OUTBOUNDS_BLOCK, the selectors generated from @ParcerConfig
+func templateOutboundTags(data *TemplateData) map[string]bool {
+	tags := make(map[string]bool)
+	if raw, ok := data.Sections["outbounds"]; ok {
+		var outbounds []map[string]interface{}
+		if err := json.Unmarshal(raw, &outbounds); err != nil {
+			tplLog(debuglog.LevelWarn, "templateOutboundTags: failed to unmarshal section", "section", "outbounds", "err", err)
+		}
+		for _, outbound := range outbounds {
+			if tag, ok := outbound["tag"].(string); ok && tag != "" {
+				tags[tag] = true
+			}
+		}
+	}
+	if data.HasParserOutboundsBlock && data.ParserConfig != "" {
+		if parserConfig, err := core.ParseParserConfigString(data.ParserConfig); err == nil {
+			for _, outbound := range parserConfig.ParserConfig.Outbounds {
+				if outbound.Tag != "" {
+					tags[outbound.Tag] = true
+				}
+			}
+		}
+	}
+	return tags
+}
+
+// validateDefaultFinal checks that route.final of the template is one of its outbound tags
+func validateDefaultFinal(data *TemplateData) *ValidationWarning {
+	if data.DefaultFinal == "" {
+		return nil
+	}
+	tags := templateOutboundTags(data)
+	if len(tags) == 0 || tags[data.DefaultFinal] {
+		// Without outbounds in the template there is nothing to check against
+		return nil
+	}
+	return &ValidationWarning{
+		Message: fmt.Sprintf("Default final outbound '%s' not found in template outbounds.", data.DefaultFinal),
+	}
+}
@@ -0,0 +1,189 @@
+package ui
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncateString(t *testing.T) {
+	tests := []struct {
+		name   string
+		s      string
+		maxLen int
+		want   string
+	}{
+		{"ascii shorter", "abc", 5, "abc"},
+		{"ascii exact", "abcde", 5, "abcde"},
+		{"ascii cut", "abcdef", 5, "abcde..."},
+		{"japanese exact", "日本語テキスト", 7, "日本語テキスト"},
+		{"japanese cut", "日本語テキスト", 3, "日本語..."},
+		// Byte length exceeds maxLen, rune count does not: nothing is cut
+		{"japanese bytes over limit", "日本語", 5, "日本語"},
+		{"arabic cut", "مرحبا بالعالم", 5, "مرحبا..."},
+		{"emoji cut", "🚀🌍🔥✅", 2, "🚀🌍..."},
+		{"emoji after ascii", "ab🚀cd", 3, "ab🚀..."},
+		{"zero length", "日本語", 0, "..."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := truncateString(tt.s, tt.maxLen)
+			if got != tt.want {
+				t.Errorf("truncateString(%q, %d) = %q, want %q", tt.s, tt.maxLen, got, tt.want)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("truncateString(%q, %d) = %q is not valid UTF-8", tt.s, tt.maxLen, got)
+			}
+		})
+	}
+}
+
+func TestExtractAllSelectableBlocks(t *testing.T) {
+	tests := []struct {
+		name       string
+		src        string
+		wantBlocks []string
+		wantClean  string
+	}{
+		{
+			name: "url with double slash",
+			src: `"rules": [
+  {"outbound": "direct-out"},
+  /** @SelectableRule
+    @label Block ads
+    {"rule_set": "ads", "url": "https://example.com/ads.srs", "outbound": "block"}
+  */
+]`,
+			wantBlocks: []string{`@label Block ads
+    {"rule_set": "ads", "url": "https://example.com/ads.srs", "outbound": "block"}`},
+			wantClean: `"rules": [
+  {"outbound": "direct-out"}]`,
+		},
+		{
+			name: "comment terminator inside string",
+			src: `[
+  /** @SelectableRule
+    @label Paths
+    {"path": "/usr/*/bin", "note": "ends with */ here"}
+  */
+]`,
+			wantBlocks: []string{`@label Paths
+    {"path": "/usr/*/bin", "note": "ends with */ here"}`},
+			wantClean: `[]`,
+		},
+		{
+			name: "windows path and asterisks",
+			src: `[
+  /** @SelectableRule
+    @label Apps
+    {"process_path": ["C:\\Program Files\\*\\app.exe"], "domain_keyword": ["**"]}
+  */,
+  {"outbound": "proxy-out"}
+]`,
+			wantBlocks: []string{`@label Apps
+    {"process_path": ["C:\\Program Files\\*\\app.exe"], "domain_keyword": ["**"]}`},
+			wantClean: `[{"outbound": "proxy-out"}
+]`,
+		},
+		{
+			name: "two blocks and nested comment",
+			src: `[
+  /** @SelectableRule
+    @label First
+    {"domain": ["a.com"] /* inline */, "outbound": "direct-out"}
+  */,
+  /** @SelectableRule
+    @label Second
+    {"domain": ["b.com"], "outbound": "proxy-out"} // trailing */ in comment
+  */
+]`,
+			wantBlocks: []string{
+				`@label First
+    {"domain": ["a.com"] /* inline */, "outbound": "direct-out"}`,
+				`@label Second
+    {"domain": ["b.com"], "outbound": "proxy-out"} // trailing */ in comment`,
+			},
+			wantClean: `[]`,
+		},
+		{
+			name:      "marker inside string is not a block",
+			src:       `{"note": "/** @SelectableRule */"}`,
+			wantClean: `{"note": "/** @SelectableRule */"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blocks, cleaned := extractAllSelectableBlocks(tt.src)
+			if len(blocks) != len(tt.wantBlocks) {
+				t.Fatalf("got %d blocks %q, want %d", len(blocks), blocks, len(tt.wantBlocks))
+			}
+			for i := range blocks {
+				if blocks[i] != tt.wantBlocks[i] {
+					t.Errorf("block %d = %q, want %q", i, blocks[i], tt.wantBlocks[i])
+				}
+			}
+			if cleaned != tt.wantClean {
+				t.Errorf("cleaned = %q, want %q", cleaned, tt.wantClean)
+			}
+		})
+	}
+}
+
+func TestOrderTemplateSections(t *testing.T) {
+	sections := map[string]json.RawMessage{
+		"zeta":      json.RawMessage(`{}`),
+		"route":     json.RawMessage(`{}`),
+		"alpha":     json.RawMessage(`{}`),
+		"log":       json.RawMessage(`{}`),
+		"middle":    json.RawMessage(`{}`),
+		"outbounds": json.RawMessage(`[]`),
+	}
+	want := []string{"log", "outbounds", "route", "alpha", "middle", "zeta"}
+	// Map iteration order is randomized, so repeat to catch order leaking into the result
+	for i := 0; i < 50; i++ {
+		if got := orderTemplateSections(sections); !reflect.DeepEqual(got, want) {
+			t.Fatalf("call %d: orderTemplateSections() = %v, want %v", i, got, want)
+		}
+	}
+}
+
+func TestExtractRuleMetadataSchema(t *testing.T) {
+	body := `{"domain_suffix": ["example.com"], "outbound": "proxy-out"}`
+	tests := []struct {
+		name       string
+		block      string
+		wantFields int
+	}{
+		{
+			name: "multi-line schema",
+			block: `@label Example
+@schema {
+  "domain_suffix": {"type": "string"}
+}
+` + body,
+			wantFields: 1,
+		},
+		{
+			name: "invalid schema keeps the rule body",
+			block: `@label Example
+@schema {"domain_suffix": {"type": "string"
+` + body,
+			wantFields: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			label, _, _, _, schema, cleaned := extractRuleMetadata(tt.block, 0)
+			if label != "Example" {
+				t.Errorf("label = %q, want %q", label, "Example")
+			}
+			if len(schema) != tt.wantFields {
+				t.Errorf("schema has %d fields, want %d", len(schema), tt.wantFields)
+			}
+			if cleaned != body {
+				t.Errorf("body = %q, want %q", cleaned, body)
+			}
+		})
+	}
+}
@@ -14,13 +14,12 @@ import (
 
 	"image/color"
 
-	"github.com/muhammadmuzzammil1998/jsonc"
-
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 
 	"singbox-launcher/core"
@@ -39,6 +38,10 @@ type WizardState struct {
 	OutboundsPreview     *widget.Entry
 	OutboundsPreviewText string // Храним текст для read-only режима
 	CheckURLButton       *widget.Button
+	EditAuthButton       *widget.Button
+	AuthStatusLabel      *widget.Label
+	SourceNameEntry      *widget.Entry
+	PrefixTagsCheck      *widget.Check
 	ParseButton          *widget.Button
 	parserConfigUpdating bool
 
@@ -76,16 +79,36 @@ type SelectableRuleState struct {
 	Enabled          bool
 	SelectedOutbound string
 	OutboundSelect   *widget.Select
+	FieldValues      map[string]string // values of the @schema fields, see createRuleSchemaForm
 }
 
 const (
+	// wizardReadyTimeout is how long opening the wizard waits for the startup sequence
+	wizardReadyTimeout = 10 * time.Second
+
 	defaultOutboundTag = "direct-out"
 	rejectActionName   = "reject"
 	rejectActionMethod = "drop"
 )
 
-// ShowConfigWizard открывает окно мастера конфигурации
+// ShowConfigWizard открывает окно мастера конфигурации.
+// Если запуск приложения ещё не завершён (проверка бинарника, загрузка подписок), окно открывается после него.
 func ShowConfigWizard(parent fyne.Window, controller *core.AppController) {
+	select {
+	case <-controller.Ready():
+		showConfigWizard(parent, controller)
+	default:
+		go func() {
+			if !controller.ReadyWithTimeout(wizardReadyTimeout) {
+				log.Printf("ConfigWizard: Startup sequence did not finish in %v, opening anyway", wizardReadyTimeout)
+			}
+			fyne.Do(func() { showConfigWizard(parent, controller) })
+		}()
+	}
+}
+
+// showConfigWizard creates and shows the wizard window
+func showConfigWizard(parent fyne.Window, controller *core.AppController) {
 	state := &WizardState{
 		Controller:        controller,
 		previewNeedsParse: true,
@@ -97,8 +120,8 @@ func ShowConfigWizard(parent fyne.Window, controller *core.AppController) {
 	wizardWindow.CenterOnScreen()
 	state.Window = wizardWindow
 
-	if templateData, err := loadTemplateData(controller.ExecDir); err != nil {
-		log.Printf("ConfigWizard: failed to load config_template.json from %s: %v", filepath.Join(controller.ExecDir, "bin", "config_template.json"), err)
+	if templateData, err := loadTemplateData(controller.ExecDir()); err != nil {
+		log.Printf("ConfigWizard: failed to load config_template.json from %s: %v", filepath.Join(controller.ExecDir(), "bin", "config_template.json"), err)
 		// Show error to user
 		dialog.ShowError(fmt.Errorf("Failed to load template file:\n%v\n\nPlease ensure bin/config_template.json exists and is valid.", err), wizardWindow)
 	} else {
@@ -122,6 +145,12 @@ func ShowConfigWizard(parent fyne.Window, controller *core.AppController) {
 				state.parserConfigUpdating = false
 				state.previewNeedsParse = true
 			}
+			// The template's config is used as-is, without reading config.json again
+			if parserConfig, err := core.ParseParserConfigString(state.TemplateData.ParserConfig); err != nil {
+				log.Printf("ConfigWizard: Failed to parse template ParserConfig: %v", err)
+			} else {
+				state.ParserConfig = parserConfig
+			}
 		} else {
 			// Нет конфига и нет шаблона - показываем ошибку и закрываем визард
 			dialog.ShowError(fmt.Errorf("No config found and template file (bin/config_template.json) is missing or invalid.\nPlease create config_template.json or ensure config.json exists."), wizardWindow)
@@ -209,14 +238,35 @@ func ShowConfigWizard(parent fyne.Window, controller *core.AppController) {
 			dialog.ShowError(err, state.Window)
 			return
 		}
-		if path, err := state.saveConfigWithBackup(text); err != nil {
-			dialog.ShowError(err, state.Window)
-		} else {
+		save := func(restart bool) {
+			path, err := state.Controller.SaveConfig(text, state.configAudit())
+			if err != nil {
+				dialog.ShowError(err, state.Window)
+				return
+			}
+			if restart {
+				go core.RestartSingBoxProcess(state.Controller)
+			}
 			dialog.ShowInformation("Config Saved", fmt.Sprintf("Config written to %s", path), state.Window)
 			state.Window.Close()
 		}
+
+		// Show what changes in the existing config before overwriting it
+		if oldConfig, err := os.ReadFile(state.Controller.ConfigPath()); err == nil {
+			changes, err := core.JSONSectionDiff(oldConfig, []byte(text))
+			if err != nil {
+				log.Printf("ConfigWizard: Failed to diff with existing config, saving without preview: %v", err)
+			} else if len(changes) > 0 {
+				showConfigDiffDialog(state.Window, changes, save)
+				return
+			}
+		}
+		save(false)
 	})
 	state.SaveButton.Importance = widget.HighImportance
+	if controller.ReadOnly {
+		state.SaveButton.Disable()
+	}
 
 	// Сохраняем ссылку на tabs в state
 	state.tabs = tabs
@@ -256,6 +306,9 @@ func ShowConfigWizard(parent fyne.Window, controller *core.AppController) {
 	// Инициализируем контейнер кнопок
 	updateNavigationButtons()
 
+	// Баннеры над вкладками: предупреждения шаблона и "config.json изменён на диске"
+	topBanner := container.NewVBox(state.createTemplateWarningsBanner(), state.createConfigChangedBanner())
+
 	// Обновляем кнопки при переключении вкладок
 	tabs.OnChanged = func(item *container.TabItem) {
 		// Обновляем индекс текущей вкладки
@@ -271,7 +324,7 @@ func ShowConfigWizard(parent fyne.Window, controller *core.AppController) {
 		updateNavigationButtons()
 		// Обновляем Border контейнер с новыми кнопками
 		content := container.NewBorder(
-			nil,                    // top
+			topBanner,              // top
 			state.ButtonsContainer, // bottom
 			nil,                    // left
 			nil,                    // right
@@ -284,7 +337,7 @@ func ShowConfigWizard(parent fyne.Window, controller *core.AppController) {
 	state.updateTemplatePreview()
 
 	content := container.NewBorder(
-		nil,                    // top
+		topBanner,              // top
 		state.ButtonsContainer, // bottom
 		nil,                    // left
 		nil,                    // right
@@ -307,25 +360,38 @@ func createVLESSSourceTab(state *WizardState) fyne.CanvasObject {
 	state.VLESSURLEntry.OnChanged = func(value string) {
 		state.previewNeedsParse = true
 		state.applyURLToParserConfig(strings.TrimSpace(value))
+		state.syncSourceNameFields()
+	}
+	if state.Controller.ReadOnly {
+		// No subscription import in read-only mode
+		state.VLESSURLEntry.Disable()
 	}
 
 	state.CheckURLButton = widget.NewButton("Check URL", func() {
 		go checkURL(state)
 	})
 
+	state.EditAuthButton = widget.NewButton("Auth...", func() {
+		showSourceAuthDialog(state)
+	})
+
 	state.URLStatusLabel = widget.NewLabel("")
 	state.URLStatusLabel.Wrapping = fyne.TextWrapWord
 
+	state.AuthStatusLabel = widget.NewLabel("Authentication: none")
+
 	urlContainer := container.NewVBox(
 		urlLabel,
 		container.NewBorder(
-			nil,                  // top
-			nil,                  // bottom
-			nil,                  // left
-			state.CheckURLButton, // right - кнопка справа
-			state.VLESSURLEntry,  // center - поле ввода занимает всё доступное пространство
+			nil, // top
+			nil, // bottom
+			nil, // left
+			container.NewHBox(state.CheckURLButton, state.EditAuthButton), // right - кнопки справа
+			state.VLESSURLEntry, // center - поле ввода занимает всё доступное пространство
 		),
+		createSourceNameRow(state),
 		state.URLStatusLabel,
+		state.AuthStatusLabel,
 	)
 
 	// Секция 2: ParserConfig
@@ -348,6 +414,8 @@ func createVLESSSourceTab(state *WizardState) fyne.CanvasObject {
 		state.previewUpdateTimer = time.AfterFunc(500*time.Millisecond, func() {
 			fyne.Do(func() {
 				state.updateTemplatePreview()
+				state.updateAuthStatus()
+				state.syncSourceNameFields()
 			})
 		})
 		state.previewUpdateMutex.Unlock()
@@ -393,9 +461,21 @@ func createVLESSSourceTab(state *WizardState) fyne.CanvasObject {
 		docButton,
 	)
 
+	// Проверка skip_patterns на примере тега
+	sampleTagEntry := widget.NewEntry()
+	sampleTagEntry.SetPlaceHolder("Sample proxy tag, e.g. 🇩🇪 Germany")
+	patternsResultLabel := widget.NewLabel("")
+	patternsResultLabel.Wrapping = fyne.TextWrapWord
+	testPatternsButton := widget.NewButton("Test Patterns", func() {
+		patternsResultLabel.SetText(testSkipPatterns(state.ParserConfigEntry.Text, strings.TrimSpace(sampleTagEntry.Text)))
+	})
+	patternsRow := container.NewBorder(nil, nil, widget.NewLabel("skip_patterns:"), testPatternsButton, sampleTagEntry)
+
 	parserContainer := container.NewVBox(
 		headerRow,
 		parserConfigWithHeight,
+		patternsRow,
+		patternsResultLabel,
 	)
 
 	// Секция 3: Preview Generated Outbounds
@@ -511,8 +591,11 @@ func createTemplateTab(state *WizardState) fyne.CanvasObject {
 			})
 			checkbox.SetChecked(ruleState.Enabled)
 
-			// Create checkbox container with optional info button for description
+			// Create checkbox container with optional icon and info button for description
 			checkboxContainer := container.NewHBox(checkbox)
+			if ruleState.Rule.Icon != "" {
+				checkboxContainer = container.NewHBox(ruleIcon(ruleState.Rule.Icon), checkbox)
+			}
 			if ruleState.Rule.Description != "" {
 				infoButton := widget.NewButton("?", func() {
 					dialog.ShowInformation(ruleState.Rule.Label, ruleState.Rule.Description, state.Window)
@@ -526,6 +609,9 @@ func createTemplateTab(state *WizardState) fyne.CanvasObject {
 				rowContent = append(rowContent, outboundRow)
 			}
 			rulesBox.Add(container.NewHBox(rowContent...))
+			if len(ruleState.Rule.Schema) > 0 {
+				rulesBox.Add(container.NewPadded(createRuleSchemaForm(state, ruleState)))
+			}
 		}
 	}
 
@@ -537,12 +623,17 @@ func createTemplateTab(state *WizardState) fyne.CanvasObject {
 	finalSelect.SetSelected(state.SelectedFinalOutbound)
 	state.FinalOutboundSelect = finalSelect
 
-	rulesScroll := createRulesScroll(state, rulesBox)
+	rulesScroll := createRulesScroll(state, container.NewVBox(
+		widget.NewLabel("Template sections"),
+		createTemplateSectionsBox(state),
+		widget.NewSeparator(),
+		widget.NewLabel("Selectable rules"),
+		rulesBox,
+	))
 
 	state.refreshOutboundOptions()
 
 	return container.NewVBox(
-		widget.NewLabel("Selectable rules"),
 		rulesScroll,
 		widget.NewSeparator(),
 		container.NewHBox(
@@ -553,6 +644,175 @@ func createTemplateTab(state *WizardState) fyne.CanvasObject {
 	)
 }
 
+// ruleIcon renders an @icon value: a Fyne theme icon by name or the emoji as text
+func ruleIcon(icon string) fyne.CanvasObject {
+	if res := theme.Icon(fyne.ThemeIconName(icon)); res != nil {
+		return widget.NewIcon(res)
+	}
+	return widget.NewLabel(icon)
+}
+
+// defaultExpandedSections are template sections shown expanded until the user toggles them
+var defaultExpandedSections = map[string]bool{
+	"outbounds": true,
+}
+
+// sectionEditDebounce delays the preview rebuild while the user is typing in a section editor
+const sectionEditDebounce = 300 * time.Millisecond
+
+// createTemplateSectionsBox creates a collapsible block per template section with an
+// "Include in config" checkbox, a JSON editor validated independently of other sections
+// and field hints from the sing-box JSON schema
+func createTemplateSectionsBox(state *WizardState) fyne.CanvasObject {
+	expandedPrefs := state.Controller.LoadPreferences().SectionsExpanded
+	updatePreview := debounce(sectionEditDebounce, func() { fyne.Do(state.updateTemplatePreview) })
+
+	box := container.NewVBox()
+	for _, key := range state.TemplateData.SectionOrder {
+		sectionKey := key
+
+		include := widget.NewCheck("Include in config", func(val bool) {
+			state.TemplateSectionSelections[sectionKey] = val
+			state.updateTemplatePreview()
+		})
+		include.SetChecked(state.TemplateSectionSelections[sectionKey])
+		if state.Controller.ReadOnly {
+			include.Disable()
+		}
+
+		formatted, err := formatSectionJSON(state.TemplateData.Sections[sectionKey], 0)
+		if err != nil {
+			formatted = string(state.TemplateData.Sections[sectionKey])
+		}
+
+		expanded, ok := expandedPrefs[sectionKey]
+		if !ok {
+			expanded = defaultExpandedSections[sectionKey]
+		}
+
+		var content fyne.CanvasObject
+		var section *CollapsibleSection
+		var validate func(text string) json.RawMessage
+		if sectionKey == "outbounds" && state.TemplateData.HasParserOutboundsBlock {
+			// The outbounds are generated from subscriptions, edits here would be ignored
+			jsonLabel := widget.NewLabelWithStyle(formatted, fyne.TextAlignLeading, fyne.TextStyle{Monospace: true})
+			jsonScroll := container.NewScroll(jsonLabel)
+			jsonScroll.SetMinSize(fyne.NewSize(0, 150))
+			content = container.NewVBox(include, widget.NewLabel("Generated from subscriptions (@PARSER_OUTBOUNDS_BLOCK)"), jsonScroll)
+		} else {
+			fields := state.Controller.SectionFieldDescriptions(sectionKey)
+
+			errorsLabel := widget.NewLabel("")
+			errorsLabel.Wrapping = fyne.TextWrapWord
+			errorsLabel.Importance = widget.DangerImportance
+			errorsLabel.Hide()
+
+			editor := widget.NewMultiLineEntry()
+			editor.TextStyle = fyne.TextStyle{Monospace: true}
+			editor.Wrapping = fyne.TextWrapOff
+			editor.SetText(formatted)
+			editor.SetMinRowsVisible(8)
+			validate = func(text string) json.RawMessage {
+				raw, problems := core.ValidateConfigSection(text, fields)
+				section.SetTitle(sectionTitle(sectionKey, len(problems)))
+				if len(problems) > 0 {
+					errorsLabel.SetText(strings.Join(problems, "\n"))
+					errorsLabel.Show()
+				} else {
+					errorsLabel.Hide()
+				}
+				return raw
+			}
+			editor.OnChanged = func(text string) {
+				// Only valid JSON goes into the config, unknown fields are kept as a warning
+				if raw := validate(text); raw != nil {
+					state.TemplateData.Sections[sectionKey] = raw
+					updatePreview()
+				}
+			}
+
+			if state.Controller.ReadOnly {
+				editor.Disable()
+			}
+			content = container.NewVBox(include, editor, errorsLabel, createFieldHintsRow(fields))
+		}
+
+		section = NewCollapsibleSection(sectionKey, content, expanded)
+		section.OnToggled = func(expanded bool) {
+			if state.Controller.ReadOnly {
+				return // preferences.json is not written in read-only mode
+			}
+			err := state.Controller.UpdatePreferences(func(p *core.Preferences) {
+				if p.SectionsExpanded == nil {
+					p.SectionsExpanded = make(map[string]bool)
+				}
+				p.SectionsExpanded[sectionKey] = expanded
+			})
+			if err != nil {
+				log.Printf("ConfigWizard: Failed to save section state: %v", err)
+			}
+		}
+		if validate != nil {
+			validate(formatted)
+		}
+		box.Add(section)
+	}
+	return box
+}
+
+// sectionTitle returns the section header text with its error count
+func sectionTitle(key string, errorCount int) string {
+	switch errorCount {
+	case 0:
+		return key
+	case 1:
+		return key + " (1 error)"
+	default:
+		return fmt.Sprintf("%s (%d errors)", key, errorCount)
+	}
+}
+
+// createFieldHintsRow creates a row of the section's field names; tapping a name shows its
+// description from the schema in a tooltip
+func createFieldHintsRow(fields map[string]string) fyne.CanvasObject {
+	if len(fields) == 0 {
+		return widget.NewLabel("No field hints for this section (download the JSON schema in Settings)")
+	}
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	row := container.NewHBox(widget.NewLabel("Fields:"))
+	for _, name := range names {
+		desc := fields[name]
+		if desc == "" {
+			desc = "No description in the schema"
+		}
+		var button *widget.Button
+		button = widget.NewButton(name, func() {
+			showFieldTooltip(button, desc)
+		})
+		button.Importance = widget.LowImportance
+		row.Add(button)
+	}
+	return container.NewHScroll(row)
+}
+
+// showFieldTooltip shows text in a pop-up right below anchor; it closes on the next tap
+func showFieldTooltip(anchor fyne.CanvasObject, text string) {
+	c := fyne.CurrentApp().Driver().CanvasForObject(anchor)
+	if c == nil {
+		return
+	}
+	label := widget.NewLabel(text)
+	label.Wrapping = fyne.TextWrapWord
+	content := container.NewGridWrap(fyne.NewSize(360, label.MinSize().Height*3), label)
+	pos := fyne.CurrentApp().Driver().AbsolutePositionForObject(anchor).AddXY(0, anchor.Size().Height)
+	widget.ShowPopUpAtPosition(content, c, pos)
+}
+
 func createPreviewTab(state *WizardState) fyne.CanvasObject {
 	state.TemplatePreviewEntry = widget.NewMultiLineEntry()
 	state.TemplatePreviewEntry.SetPlaceHolder("Preview will appear here")
@@ -592,63 +852,53 @@ func createRulesScroll(state *WizardState, content fyne.CanvasObject) fyne.Canva
 	return scroll
 }
 
-func (state *WizardState) saveConfigWithBackup(text string) (string, error) {
-	// Validate JSON before saving (support JSONC with comments)
-	jsonBytes := jsonc.ToJSON([]byte(text))
-	var testJSON interface{}
-	if err := json.Unmarshal(jsonBytes, &testJSON); err != nil {
-		return "", fmt.Errorf("invalid JSON: %w", err)
-	}
+// configAudit returns what the wizard config is generated from, for the audit log
+func (state *WizardState) configAudit() core.ConfigAudit {
+	return core.ConfigAudit{Sources: state.auditSources(), RuleAssignments: state.auditRuleAssignments()}
+}
 
-	configPath := state.Controller.ConfigPath
-	if err := os.MkdirAll(filepath.Dir(configPath), 0o755); err != nil {
-		return "", err
+// auditSources returns the subscription sources the config is generated from, for the audit log
+func (state *WizardState) auditSources() []string {
+	if state.ParserConfig == nil {
+		return nil
 	}
-	if info, err := os.Stat(configPath); err == nil && !info.IsDir() {
-		backup := state.nextBackupPath(configPath)
-		if err := os.Rename(configPath, backup); err != nil {
-			return "", err
+	var sources []string
+	for _, proxy := range state.ParserConfig.ParserConfig.Proxies {
+		if source := strings.TrimSpace(proxy.Source); source != "" {
+			sources = append(sources, source)
 		}
-	} else if err != nil && !os.IsNotExist(err) {
-		return "", err
 	}
-	if err := os.WriteFile(configPath, []byte(text), 0o644); err != nil {
-		return "", err
-	}
-	// Update config status in Core Dashboard if callback is set
-	if state.Controller != nil && state.Controller.UpdateConfigStatusFunc != nil {
-		state.Controller.UpdateConfigStatusFunc()
-	}
-	return configPath, nil
+	return sources
 }
 
-func (state *WizardState) nextBackupPath(path string) string {
-	dir := filepath.Dir(path)
-	ext := filepath.Ext(path)
-	base := strings.TrimSuffix(filepath.Base(path), ext)
-	candidate := filepath.Join(dir, fmt.Sprintf("%s-old%s", base, ext))
-	if _, err := os.Stat(candidate); os.IsNotExist(err) {
-		return candidate
-	}
-	for i := 1; ; i++ {
-		candidate = filepath.Join(dir, fmt.Sprintf("%s-old-%d%s", base, i, ext))
-		if _, err := os.Stat(candidate); os.IsNotExist(err) {
-			return candidate
+// auditRuleAssignments returns the outbound chosen for each enabled selectable rule, keyed by
+// its label, as applied by the template builder
+func (state *WizardState) auditRuleAssignments() map[string]string {
+	assignments := make(map[string]string)
+	for _, ruleState := range state.SelectableRuleStates {
+		if !ruleState.Enabled || !ruleState.Rule.HasOutbound {
+			continue
 		}
+		outbound := ruleState.SelectedOutbound
+		if outbound == "" {
+			outbound = ruleState.Rule.DefaultOutbound
+		}
+		assignments[ruleState.Rule.Label] = outbound
 	}
+	return assignments
 }
 
 // loadConfigFromFile загружает данные из существующего config.json
 func loadConfigFromFile(state *WizardState) (bool, error) {
 	// Проверяем наличие config.json
-	if _, err := os.Stat(state.Controller.ConfigPath); os.IsNotExist(err) {
+	if _, err := os.Stat(state.Controller.ConfigPath()); os.IsNotExist(err) {
 		// Конфиг не существует - оставляем значения по умолчанию
 		log.Println("ConfigWizard: config.json not found, using default values")
 		return false, nil
 	}
 
 	// Извлекаем ParserConfig
-	parserConfig, err := core.ExtractParcerConfig(state.Controller.ConfigPath)
+	parserConfig, err := core.ExtractParcerConfig(state.Controller.ConfigPath())
 	if err != nil {
 		// Если не удалось извлечь - оставляем значения по умолчанию
 		log.Printf("ConfigWizard: Failed to extract ParserConfig: %v", err)
@@ -672,6 +922,8 @@ func loadConfigFromFile(state *WizardState) (bool, error) {
 	state.ParserConfigEntry.SetText(string(parserConfigJSON))
 	state.parserConfigUpdating = false
 	state.previewNeedsParse = true
+	state.updateAuthStatus()
+	state.syncSourceNameFields()
 
 	log.Println("ConfigWizard: Successfully loaded config from file")
 	return true, nil
@@ -694,7 +946,16 @@ func checkURL(state *WizardState) {
 	})
 
 	// Проверяем URL в горутине
-	content, err := core.FetchSubscription(url)
+	opts, err := state.sourceFetchOptions()
+	if err != nil {
+		fyne.Do(func() {
+			state.URLStatusLabel.SetText(fmt.Sprintf("❌ Failed to read authentication: %v", err))
+			state.CheckURLButton.Enable()
+		})
+		return
+	}
+	opts.LocalDirs = state.Controller.LocalSubscriptionDirs()
+	content, err := core.FetchSubscriptionWithOptions(url, opts)
 	if err != nil {
 		fyne.Do(func() {
 			state.URLStatusLabel.SetText(fmt.Sprintf("❌ Failed: %v", err))
@@ -758,8 +1019,8 @@ func parseAndPreview(state *WizardState) {
 		return
 	}
 
-	var parserConfig core.ParserConfig
-	if err := json.Unmarshal([]byte(parserConfigJSON), &parserConfig); err != nil {
+	parserConfig, err := core.ParseParserConfigString(parserConfigJSON)
+	if err != nil {
 		fyne.Do(func() {
 			setPreviewText(state, fmt.Sprintf("Error: Failed to parse ParserConfig JSON: %v", err))
 			state.ParseButton.Enable()
@@ -794,7 +1055,19 @@ func parseAndPreview(state *WizardState) {
 		setPreviewText(state, "Downloading subscription...")
 	})
 
-	content, err := core.FetchSubscription(url)
+	opts, err := parserConfig.ParserConfig.Proxies[0].FetchOptions()
+	if err != nil {
+		fyne.Do(func() {
+			setPreviewText(state, fmt.Sprintf("Error: Failed to read authentication: %v", err))
+			state.ParseButton.Enable()
+			state.ParseButton.SetText("Parse")
+		})
+		return
+	}
+	opts.LocalDirs = state.Controller.LocalSubscriptionDirs()
+	opts.CacheDir = state.Controller.SubscriptionCacheDir()
+
+	content, err := core.FetchSubscriptionWithOptions(url, opts)
 	if err != nil {
 		fyne.Do(func() {
 			setPreviewText(state, fmt.Sprintf("Error: Failed to fetch subscription: %v", err))
@@ -809,6 +1082,16 @@ func parseAndPreview(state *WizardState) {
 		setPreviewText(state, "Parsing nodes from subscription...")
 	})
 
+	skipPatterns, err := core.CompileSkipPatterns(parserConfig.ParserConfig.Proxies[0].SkipPatterns)
+	if err != nil {
+		fyne.Do(func() {
+			setPreviewText(state, fmt.Sprintf("Error: %v", err))
+			state.ParseButton.Enable()
+			state.ParseButton.SetText("Parse")
+		})
+		return
+	}
+
 	allNodes := make([]*core.ParsedNode, 0)
 	lines := strings.Split(string(content), "\n")
 
@@ -832,8 +1115,13 @@ func parseAndPreview(state *WizardState) {
 			log.Printf("ConfigWizard: Failed to parse node: %v", err)
 			continue
 		}
+		if node != nil && len(core.MatchingSkipPatterns(node.Tag, skipPatterns)) > 0 {
+			continue
+		}
 
 		if node != nil {
+			node.Tag = parserConfig.ParserConfig.Proxies[0].PrefixTag(node.Tag)
+
 			// Make tag unique if it already exists (same logic as UpdateConfigFromSubscriptions)
 			originalTag := node.Tag
 			// Check if tag already exists before incrementing
@@ -912,11 +1200,70 @@ func parseAndPreview(state *WizardState) {
 		state.ParseButton.Enable()
 		state.ParseButton.SetText("Parse")
 		state.GeneratedOutbounds = selectorsJSON
-		state.ParserConfig = &parserConfig
+		state.ParserConfig = parserConfig
 		state.previewNeedsParse = false
 		state.refreshOutboundOptions()
 		state.updateTemplatePreview()
 	})
+	state.autoGenerateConfigIfMissing()
+}
+
+// autoGenerateConfigIfMissing saves the wizard result after the first successful subscription parse
+// when config.json does not exist yet, using the default rule outbounds. New users can start
+// sing-box right away; later changes are still saved with the Save button.
+// Called from the parse goroutine: the config is built on the UI goroutine, saved outside it.
+func (state *WizardState) autoGenerateConfigIfMissing() {
+	if state.Controller == nil {
+		return
+	}
+	generated, err := state.Controller.AutoGenerateConfigIfMissing(func() (text string, audit core.ConfigAudit, err error) {
+		fyne.DoAndWait(func() {
+			if state.TemplateData == nil {
+				err = fmt.Errorf("template data not available")
+				return
+			}
+			text, err = buildTemplateConfig(state)
+			audit = state.configAudit()
+		})
+		return text, audit, err
+	})
+	if err != nil {
+		log.Printf("ConfigWizard: %v", err)
+		return
+	}
+	if generated {
+		fyne.Do(func() {
+			ShowAutoHideInfo(state.Controller.Application, state.Window, "Config", "Config generated from subscription. Ready to start.")
+		})
+	}
+}
+
+// testSkipPatterns reports which skip_patterns from the ParserConfig JSON match the sample tag
+func testSkipPatterns(parserConfigJSON, tag string) string {
+	if tag == "" {
+		return "Enter a sample proxy tag to test skip_patterns"
+	}
+	var parserConfig core.ParserConfig
+	if err := json.Unmarshal([]byte(strings.TrimSpace(parserConfigJSON)), &parserConfig); err != nil {
+		return fmt.Sprintf("Error: Failed to parse ParserConfig JSON: %v", err)
+	}
+
+	var patterns []string
+	for _, proxy := range parserConfig.ParserConfig.Proxies {
+		patterns = append(patterns, proxy.SkipPatterns...)
+	}
+	if len(patterns) == 0 {
+		return "No skip_patterns in ParserConfig"
+	}
+	compiled, err := core.CompileSkipPatterns(patterns)
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
+	matched := core.MatchingSkipPatterns(tag, compiled)
+	if len(matched) == 0 {
+		return fmt.Sprintf("No pattern matches '%s' — the proxy is kept", tag)
+	}
+	return fmt.Sprintf("'%s' matches %s — the proxy is skipped", tag, strings.Join(matched, ", "))
 }
 
 func setPreviewText(state *WizardState, text string) {
@@ -1048,21 +1395,13 @@ func buildTemplateConfig(state *WizardState) (string, error) {
 	}
 
 	// Parse ParserConfig JSON to ensure it has version 2 and parser object
-	var parserConfig core.ParserConfig
-	if err := json.Unmarshal([]byte(parserConfigText), &parserConfig); err != nil {
+	// (ParseParserConfigString migrates older versions)
+	parserConfig, err := core.ParseParserConfigString(parserConfigText)
+	if err != nil {
 		// If parsing fails, use text as-is (might be invalid JSON, but let user fix it)
 		log.Printf("buildTemplateConfig: Warning: Failed to parse ParserConfig JSON: %v", err)
 	} else {
-		// Backward compatibility: migrate version 1 to version 2 if needed
-		if parserConfig.Version > 0 && parserConfig.ParserConfig.Version == 0 {
-			parserConfig.ParserConfig.Version = parserConfig.Version
-			parserConfig.Version = 0
-		}
-
-		// Ensure version is set to 2
-		if parserConfig.ParserConfig.Version == 0 {
-			parserConfig.ParserConfig.Version = core.ParserConfigVersion
-		}
+		parserConfig.ParserConfig.Version = core.ParserConfigVersion
 
 		// Ensure parser object exists (create if missing)
 		// Set default reload to "4h" if not specified
@@ -1163,6 +1502,9 @@ func mergeRouteSection(raw json.RawMessage, states []*SelectableRuleState, final
 			continue
 		}
 		cloned := cloneRule(state.Rule)
+		if state.FieldValues != nil {
+			applySchemaValues(cloned, state.Rule.Schema, state.FieldValues)
+		}
 
 		outbound := state.SelectedOutbound
 		if outbound == "" {
@@ -1311,6 +1653,7 @@ func (state *WizardState) initializeTemplateState() {
 				Rule:             rule,
 				SelectedOutbound: outbound,
 				Enabled:          rule.IsDefault, // Enable rule if @default directive is present
+				FieldValues:      defaultSchemaValues(rule),
 			})
 		}
 	} else {
@@ -2,19 +2,19 @@ package ui
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"image/color"
 	"io"
 	"math/rand"
 	"net/http"
 	"os"
 	"path/filepath"
 	"runtime"
-	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"fyne.io/fyne/v2"
-	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/layout"
@@ -23,46 +23,46 @@ import (
 	"singbox-launcher/core"
 )
 
-const downloadPlaceholderWidth = 180
-
 // CoreDashboardTab управляет вкладкой Core Dashboard
 type CoreDashboardTab struct {
 	controller *core.AppController
 
 	// UI elements
-	statusLabel               *widget.Label // Full status: "Core Status" + icon + text
-	singboxStatusLabel        *widget.Label // sing-box status (version or "not found")
-	downloadButton            *widget.Button
-	downloadProgress          *widget.ProgressBar // Progress bar for download
-	downloadContainer         fyne.CanvasObject   // Container for button/progress bar
-	downloadPlaceholder       *canvas.Rectangle   // keeps width when button hidden
-	startButton               *widget.Button      // Start button
-	stopButton                *widget.Button      // Stop button
-	wintunStatusLabel         *widget.Label       // wintun.dll status
-	wintunDownloadButton      *widget.Button      // wintun.dll download button
-	wintunDownloadProgress    *widget.ProgressBar // Progress bar for wintun.dll download
-	wintunDownloadContainer   fyne.CanvasObject   // Container for wintun button/progress bar
-	wintunDownloadPlaceholder *canvas.Rectangle   // keeps width when button hidden
-	configStatusLabel         *widget.Label
-	templateDownloadButton    *widget.Button
-	wizardButton              *widget.Button
-	updateConfigButton        *widget.Button
-	parserProgressBar         *widget.ProgressBar // Progress bar for parser
-	parserStatusLabel         *widget.Label       // Status label for parser
+	statusLabel            *widget.Label          // Full status: "Core Status" + icon + text
+	singbox                *DownloadableComponent // sing-box row: version or "not found", download/update button
+	versionCheckIndicator  fyne.CanvasObject      // Spinner with "Checking…" while the latest version is fetched
+	blockVersionButton     *widget.Button         // Block/Unblock the latest sing-box version
+	startButton            *widget.Button         // Start button
+	configMissingLabel     *widget.Label          // Warning above Start/Stop while config.json does not exist
+	stopButton             *widget.Button         // Stop button
+	wintun                 *DownloadableComponent // wintun.dll row (Windows only)
+	configStatusLabel      *widget.Label
+	templateDownloadButton *widget.Button
+	wizardButton           *widget.Button
+	updateConfigButton     *widget.Button
+	parserProgressBar      *widget.ProgressBar // Progress bar for parser
+	parserStatusLabel      *widget.Label       // Status label for parser
+	subscriptionsBox       *fyne.Container     // One "Last refreshed" line per subscription source
+
+	updateUI func() // tab.controller.UpdateUI через debounce
 
 	// Data
-	stopAutoUpdate           chan bool
-	lastUpdateSuccess        bool // Track success of last version update
-	downloadInProgress       bool // Flag for sing-box download process
-	wintunDownloadInProgress bool // Flag for wintun.dll download process
+	autoUpdateCancel  context.CancelFunc // Stops the auto-update goroutine (see Destroy)
+	autoUpdateDone    sync.WaitGroup
+	destroyOnce       sync.Once
+	lastUpdateSuccess atomic.Bool // Result of the last latest-version check (set from the check goroutine)
 }
 
-// CreateCoreDashboardTab creates and returns the Core Dashboard tab
-func CreateCoreDashboardTab(ac *core.AppController) fyne.CanvasObject {
+// uiUpdateDebounce - задержка, за которую несколько вызовов UpdateUI объединяются в один
+const uiUpdateDebounce = 100 * time.Millisecond
+
+// newCoreDashboardTab creates the Core Dashboard tab and returns it with its content.
+// The caller must call Destroy when the tab is no longer used.
+func newCoreDashboardTab(ac *core.AppController) (*CoreDashboardTab, fyne.CanvasObject) {
 	tab := &CoreDashboardTab{
-		controller:     ac,
-		stopAutoUpdate: make(chan bool),
+		controller: ac,
 	}
+	tab.updateUI = debounce(uiUpdateDebounce, ac.UpdateUI)
 
 	// Status block with buttons in one row
 	statusRow := tab.createStatusRow()
@@ -90,26 +90,46 @@ func CreateCoreDashboardTab(ac *core.AppController) fyne.CanvasObject {
 	}
 
 	// Горизонтальная линия и кнопка Exit в конце списка
-	exitButton := widget.NewButton("Exit", ac.GracefulExit)
+	exitButton := widget.NewButton(T("core.exit"), ac.GracefulExit)
 	// Кнопка Exit в отдельной строке с отступом вниз
 	contentItems = append(contentItems, widget.NewLabel("")) // Отступ
 	contentItems = append(contentItems, container.NewCenter(exitButton))
 
 	content := container.NewVBox(contentItems...)
 
-	// Регистрируем callback для обновления статуса при изменении RunningState
-	// Сохраняем оригинальный callback, если он есть
-	originalUpdateCoreStatusFunc := tab.controller.UpdateCoreStatusFunc
+	// Статус обновляется при запуске/остановке sing-box и по запросу из core (например, сброс счётчика перезапусков)
 	tab.controller.UpdateCoreStatusFunc = func() {
-		// Вызываем оригинальный callback, если он есть
-		if originalUpdateCoreStatusFunc != nil {
-			originalUpdateCoreStatusFunc()
-		}
-		// Вызываем наш callback
 		fyne.Do(func() {
 			tab.updateRunningStatus()
 		})
 	}
+	tab.controller.Events.Subscribe(func(event core.Event) {
+		switch event.(type) {
+		case core.DownloadStarted, core.DownloadFinished:
+			fyne.Do(func() { tab.onDownloadEvent(event) })
+		case core.StateEvent:
+			if event == core.SubscriptionsRefreshed {
+				fyne.Do(tab.updateSubscriptionsInfo)
+				return
+			}
+			tab.controller.UpdateCoreStatusFunc()
+		default:
+			tab.controller.UpdateCoreStatusFunc()
+		}
+	})
+
+	// Повторное скачивание ядра при несовпадении контрольной суммы
+	tab.controller.RedownloadCoreFunc = func(version string) {
+		if tab.anyDownloadInProgress() || version == "" || tab.controller.ReadOnly {
+			return
+		}
+		tab.startDownloadWithVersion(version)
+	}
+
+	// Подтверждение изменений списка прокси перед записью в config.json
+	tab.controller.ConfirmProxyChangesFunc = func(diff core.ProxyListDiff) bool {
+		return confirmProxyChanges(tab.controller.MainWindow, diff)
+	}
 
 	// Регистрируем callback для обновления статуса конфига
 	tab.controller.UpdateConfigStatusFunc = func() {
@@ -117,6 +137,12 @@ func CreateCoreDashboardTab(ac *core.AppController) fyne.CanvasObject {
 			tab.updateConfigInfo()
 		})
 	}
+	// config.json, изменённый во внешнем редакторе, тоже обновляет статус
+	tab.controller.Events.Subscribe(func(event core.Event) {
+		if event == core.ConfigChanged {
+			tab.controller.UpdateConfigStatusFunc()
+		}
+	})
 
 	// Регистрируем callback для обновления прогресса парсера
 	tab.controller.UpdateParserProgressFunc = func(progress float64, status string) {
@@ -172,23 +198,33 @@ func CreateCoreDashboardTab(ac *core.AppController) fyne.CanvasObject {
 	// Запускаем автообновление версии
 	tab.startAutoUpdate()
 
-	return content
+	return tab, content
+}
+
+// Destroy stops the auto-update goroutine and waits for it to exit. Safe to call more than once.
+func (tab *CoreDashboardTab) Destroy() {
+	tab.destroyOnce.Do(func() {
+		if tab.autoUpdateCancel != nil {
+			tab.autoUpdateCancel()
+		}
+		tab.autoUpdateDone.Wait()
+	})
 }
 
 // createStatusRow creates a row with status and buttons
 func (tab *CoreDashboardTab) createStatusRow() fyne.CanvasObject {
 	// Объединяем все в один label: "Core Status" + иконка + текст статуса
-	tab.statusLabel = widget.NewLabel("Core Status Checking...")
+	tab.statusLabel = widget.NewLabel(T("core.status.checking"))
 	tab.statusLabel.Wrapping = fyne.TextWrapOff       // Отключаем перенос текста
 	tab.statusLabel.Alignment = fyne.TextAlignLeading // Выравнивание текста
 	tab.statusLabel.Importance = widget.MediumImportance
 
-	startButton := widget.NewButton("Start", func() {
+	startButton := widget.NewButton(T("core.start"), func() {
 		core.StartSingBoxProcess(tab.controller)
 		// Status will be updated automatically via UpdateCoreStatusFunc
 	})
 
-	stopButton := widget.NewButton("Stop", func() {
+	stopButton := widget.NewButton(T("core.stop"), func() {
 		core.StopSingBoxProcess(tab.controller)
 		// Status will be updated automatically via UpdateCoreStatusFunc
 	})
@@ -207,20 +243,38 @@ func (tab *CoreDashboardTab) createStatusRow() fyne.CanvasObject {
 		container.NewHBox(startButton, stopButton),
 	)
 
+	// Предупреждение о missing config.json, чтобы не было вопроса "почему не запускается"; видимость задаёт updateConfigInfo
+	tab.configMissingLabel = widget.NewLabel(T("core.config.missing"))
+	tab.configMissingLabel.Importance = widget.HighImportance
+	tab.configMissingLabel.Wrapping = fyne.TextWrapWord
+	tab.configMissingLabel.Alignment = fyne.TextAlignCenter
+	tab.configMissingLabel.Hide()
+
+	// BinaryDir проверяется один раз при запуске: без прав на запись скачивание ядра не сработает
+	binaryDirLabel := widget.NewLabel(T("core.bindir.readonly", tab.controller.BinaryDir))
+	binaryDirLabel.Importance = widget.WarningImportance
+	binaryDirLabel.Wrapping = fyne.TextWrapWord
+	binaryDirLabel.Alignment = fyne.TextAlignCenter
+	if tab.controller.BinaryDirError() == nil {
+		binaryDirLabel.Hide()
+	}
+
 	// Return container with status and buttons, with empty lines before and after buttons
 	return container.NewVBox(
 		statusContainer,
 		widget.NewLabel(""), // Empty line before buttons
+		tab.configMissingLabel,
+		binaryDirLabel,
 		buttonsContainer,
 		widget.NewLabel(""), // Empty line after buttons
 	)
 }
 
 func (tab *CoreDashboardTab) createConfigBlock() fyne.CanvasObject {
-	title := widget.NewLabel("Config")
+	title := widget.NewLabel(T("core.config.title"))
 	title.Importance = widget.MediumImportance
 
-	tab.configStatusLabel = widget.NewLabel("Checking config...")
+	tab.configStatusLabel = widget.NewLabel(T("core.config.checking"))
 	tab.configStatusLabel.Wrapping = fyne.TextWrapOff
 
 	// Создаем прогрессбар и статус для парсера
@@ -233,26 +287,28 @@ func (tab *CoreDashboardTab) createConfigBlock() fyne.CanvasObject {
 	tab.parserStatusLabel.Wrapping = fyne.TextWrapWord
 	tab.parserStatusLabel.Alignment = fyne.TextAlignCenter
 
+	tab.subscriptionsBox = container.NewVBox()
+
 	// Кнопка Update
-	tab.updateConfigButton = widget.NewButton("🔄 Update", func() {
+	tab.updateConfigButton = widget.NewButton(T("core.config.update"), func() {
 		// Деактивируем кнопку и показываем прогрессбар
 		tab.updateConfigButton.Disable()
 		tab.parserProgressBar.Show()
 		tab.parserProgressBar.SetValue(0)
 		tab.parserStatusLabel.Show()
-		tab.parserStatusLabel.SetText("Starting...")
+		tab.parserStatusLabel.SetText(T("core.config.starting"))
 
 		// Запускаем парсер в отдельной горутине
-		go core.RunParserProcess(tab.controller)
+		go core.RunParserProcess(tab.controller, true)
 	})
 	tab.updateConfigButton.Importance = widget.MediumImportance
 
-	tab.wizardButton = widget.NewButton("⚙️ Wizard", func() {
+	tab.wizardButton = widget.NewButton(T("core.config.wizard"), func() {
 		ShowConfigWizard(tab.controller.MainWindow, tab.controller)
 	})
 	tab.wizardButton.Importance = widget.MediumImportance
 
-	tab.templateDownloadButton = widget.NewButton("Download Config Template", func() {
+	tab.templateDownloadButton = widget.NewButton(T("core.template.download"), func() {
 		tab.downloadConfigTemplate()
 	})
 	tab.templateDownloadButton.Importance = widget.MediumImportance
@@ -285,162 +341,205 @@ func (tab *CoreDashboardTab) createConfigBlock() fyne.CanvasObject {
 
 	return container.NewVBox(
 		statusRow,
+		tab.subscriptionsBox,
 		buttonsRow,
 		parserProgressRow, // Прогрессбар и статус парсера в отдельной строке
 	)
 }
 
-// createVersionBlock creates a block with version (similar to wintun)
-func (tab *CoreDashboardTab) createVersionBlock() fyne.CanvasObject {
-	title := widget.NewLabel("Sing-box")
-	title.Importance = widget.MediumImportance
+const (
+	// subscriptionStaleAge - a subscription not refreshed for longer is shown in orange
+	subscriptionStaleAge = 24 * time.Hour
+	// subscriptionOutdatedAge - a subscription not refreshed for longer is shown in red
+	subscriptionOutdatedAge = 7 * 24 * time.Hour
+)
 
-	tab.singboxStatusLabel = widget.NewLabel("Checking...")
-	tab.singboxStatusLabel.Wrapping = fyne.TextWrapOff
+// updateSubscriptionsInfo lists the subscription sources of config.json with the time of their last
+// successful refresh; stale ones are highlighted to prompt an update
+func (tab *CoreDashboardTab) updateSubscriptionsInfo() {
+	if tab.subscriptionsBox == nil {
+		return
+	}
+	tab.subscriptionsBox.RemoveAll()
+	config, err := core.ExtractParcerConfig(tab.controller.ConfigPath())
+	if err != nil {
+		// No config.json or no @ParcerConfig: the config status line already says so
+		tab.subscriptionsBox.Refresh()
+		return
+	}
+	lastRefreshed := tab.controller.LoadSubscriptionStore().LastRefreshed
+	for _, source := range config.ParserConfig.Proxies {
+		label := widget.NewLabel("")
+		label.Truncation = fyne.TextTruncateEllipsis
+		refreshed, ok := lastRefreshed[source.Source]
+		if !ok {
+			label.SetText(T("core.subs.refreshed", source.Label(), T("core.subs.never")))
+		} else {
+			label.SetText(T("core.subs.refreshed", source.Label(), formatTimeAgo(refreshed)))
+			switch age := time.Since(refreshed); {
+			case age > subscriptionOutdatedAge:
+				label.Importance = widget.DangerImportance
+			case age > subscriptionStaleAge:
+				label.Importance = widget.WarningImportance
+			}
+		}
+		tab.subscriptionsBox.Add(label)
+	}
+	tab.subscriptionsBox.Refresh()
+}
 
-	tab.downloadButton = widget.NewButton("Download", func() {
-		tab.handleDownload()
-	})
-	tab.downloadButton.Importance = widget.MediumImportance
-	tab.downloadButton.Disable()
+// formatTimeAgo returns how long ago t was: "just now", "5 minutes ago", "2 hours ago", "3 days ago"
+func formatTimeAgo(t time.Time) string {
+	d := time.Since(t)
+	switch {
+	case d < time.Minute:
+		return T("time.just_now")
+	case d < 2*time.Minute:
+		return T("time.minute_ago")
+	case d < time.Hour:
+		return T("time.minutes_ago", int(d/time.Minute))
+	case d < 2*time.Hour:
+		return T("time.hour_ago")
+	case d < 24*time.Hour:
+		return T("time.hours_ago", int(d/time.Hour))
+	case d < 48*time.Hour:
+		return T("time.day_ago")
+	default:
+		return T("time.days_ago", int(d/(24*time.Hour)))
+	}
+}
 
-	tab.downloadProgress = widget.NewProgressBar()
-	tab.downloadProgress.Hide()
-	tab.downloadProgress.SetValue(0)
+// createVersionBlock creates a block with version (similar to wintun)
+func (tab *CoreDashboardTab) createVersionBlock() fyne.CanvasObject {
+	tab.singbox = NewDownloadableComponent(T("core.singbox.title"), tab.handleDownload)
+	tab.singbox.DownloadsDisabled = tab.controller.ReadOnly
 
-	if tab.downloadPlaceholder == nil {
-		tab.downloadPlaceholder = canvas.NewRectangle(color.Transparent)
-	}
-	placeholderSize := fyne.NewSize(downloadPlaceholderWidth, tab.downloadButton.MinSize().Height)
-	tab.downloadPlaceholder.SetMinSize(placeholderSize)
-	tab.downloadPlaceholder.Hide()
+	checkingLabel := widget.NewLabelWithStyle(T("core.version.checking"), fyne.TextAlignCenter, fyne.TextStyle{})
+	tab.versionCheckIndicator = container.NewStack(widget.NewProgressBarInfinite(), checkingLabel)
+	tab.singbox.AddAlternative(tab.versionCheckIndicator)
 
-	tab.downloadContainer = container.NewStack(
-		tab.downloadPlaceholder,
-		tab.downloadButton,
-		tab.downloadProgress,
-	)
+	tab.blockVersionButton = widget.NewButton(T("core.version.block"), nil)
+	tab.blockVersionButton.Importance = widget.LowImportance
+	tab.blockVersionButton.Hide()
+	tab.singbox.AddTrailing(tab.blockVersionButton)
 
-	return container.NewHBox(
-		title,
-		layout.NewSpacer(),
-		tab.singboxStatusLabel,
-		tab.downloadContainer,
-	)
+	return tab.singbox.CreateWidget()
 }
 
-// setWintunState - управляет состоянием wintun (лейбл, кнопка, прогресс)
-// statusText: текст для статус-лейбла (если "", не менять)
-// buttonText: текст кнопки (если "", скрыть кнопку; иначе показать с этим текстом и включить)
-// progress: значение прогресса (если < 0, скрыть прогресс; иначе показать с этим значением 0.0-1.0)
-func (tab *CoreDashboardTab) setWintunState(statusText string, buttonText string, progress float64) {
-	// Управление статус-лейблом
-	if statusText != "" {
-		tab.wintunStatusLabel.SetText(statusText)
+// setBlockVersionState shows "Block This Version" (or "Unblock" if blocked) for the given latest version.
+// An empty version hides the button, as does read-only mode (the list is kept in preferences.json).
+func (tab *CoreDashboardTab) setBlockVersionState(version string, blocked bool) {
+	if version == "" || tab.controller.ReadOnly {
+		tab.blockVersionButton.Hide()
+		return
 	}
-
-	// Управление прогресс-баром
-	progressVisible := false
-	if progress < 0 {
-		// Скрыть прогресс
-		tab.wintunDownloadProgress.Hide()
-		tab.wintunDownloadProgress.SetValue(0)
-	} else {
-		// Показать прогресс с значением
-		tab.wintunDownloadProgress.SetValue(progress)
-		tab.wintunDownloadProgress.Show()
-		progressVisible = true
-	}
-
-	// Управление кнопкой (если прогресс виден, кнопка всегда скрыта)
-	buttonVisible := false
-	if progressVisible {
-		// Если показываем прогресс, кнопка всегда скрыта
-		tab.wintunDownloadButton.Hide()
-	} else if buttonText == "" {
-		// Скрыть кнопку
-		tab.wintunDownloadButton.Hide()
+	if blocked {
+		tab.blockVersionButton.SetText(T("core.version.unblock", version))
 	} else {
-		// Показать кнопку с текстом
-		tab.wintunDownloadButton.SetText(buttonText)
-		tab.wintunDownloadButton.Show()
-		tab.wintunDownloadButton.Enable()
-		buttonVisible = true
+		tab.blockVersionButton.SetText(T("core.version.block"))
 	}
-
-	// Управление placeholder: показывать если есть кнопка ИЛИ прогресс-бар
-	if tab.wintunDownloadPlaceholder != nil {
-		if buttonVisible || progressVisible {
-			tab.wintunDownloadPlaceholder.Show()
+	tab.blockVersionButton.OnTapped = func() {
+		var err error
+		if blocked {
+			err = tab.controller.UnblockCoreVersion(version)
 		} else {
-			tab.wintunDownloadPlaceholder.Hide()
+			err = tab.controller.BlockCoreVersion(version)
+		}
+		if err != nil {
+			ShowError(tab.controller.MainWindow, err)
+			return
 		}
+		tab.updateVersionInfo()
 	}
+	tab.blockVersionButton.Show()
 }
 
-// setSingboxState - управляет состоянием sing-box (лейбл, кнопка, прогресс)
-// statusText: текст для статус-лейбла (если "", не менять)
-// buttonText: текст кнопки (если "", скрыть кнопку; иначе показать с этим текстом и включить)
-// progress: значение прогресса (если < 0, скрыть прогресс; иначе показать с этим значением 0.0-1.0)
-func (tab *CoreDashboardTab) setSingboxState(statusText string, buttonText string, progress float64) {
-	// Управление статус-лейблом
-	if statusText != "" {
-		tab.singboxStatusLabel.SetText(statusText)
+// setWintunState - управляет состоянием wintun (лейбл, кнопка, прогресс), см. DownloadableComponent.SetState
+func (tab *CoreDashboardTab) setWintunState(statusText string, buttonText string, progress float64) {
+	tab.wintun.SetState(statusText, buttonText, progress, tab.anyDownloadInProgress())
+}
+
+// anyDownloadInProgress возвращает true, если идет скачивание sing-box или wintun.dll
+func (tab *CoreDashboardTab) anyDownloadInProgress() bool {
+	return tab.controller.IsDownloadInProgress("")
+}
+
+// onDownloadEvent обновляет блоки sing-box и wintun.dll по событиям DownloadStarted/DownloadFinished.
+// Прогресс приходит через progressChan, состояние кнопок восстанавливается здесь.
+func (tab *CoreDashboardTab) onDownloadEvent(event core.Event) {
+	switch event := event.(type) {
+	case core.DownloadStarted:
+		if event.Resource == core.DownloadResourceWintun && tab.wintun != nil {
+			tab.setWintunState("", "", 0.0)
+		} else if event.Resource == core.DownloadResourceCore {
+			tab.setSingboxState("", "", 0.0)
+		}
+		tab.disableIdleDownloadButtons()
+	case core.DownloadFinished:
+		switch event.Resource {
+		case core.DownloadResourceCore:
+			tab.updateWintunStatus() // Возвращаем кнопку wintun.dll, отключенную на время скачивания
+			if event.Err != nil {
+				tab.setSingboxState("", T("core.download"), -1)
+				return
+			}
+			// Обновляем статусы после успешного скачивания (это уберет ошибки и обновит статус)
+			tab.updateVersionInfo()
+			tab.updateBinaryStatus() // Это вызовет updateRunningStatus() и обновит статус
+			// Обновляем иконку трея (может измениться с красной на черную/зеленую)
+			tab.updateUI()
+		case core.DownloadResourceWintun:
+			tab.updateVersionInfo() // Возвращаем кнопку sing-box, отключенную на время скачивания
+			if event.Err != nil {
+				tab.setWintunState("", T("core.wintun.download"), -1)
+				return
+			}
+			tab.updateWintunStatus() // Обновляет статус и управляет кнопкой
+		}
 	}
+}
 
-	// Управление прогресс-баром
-	progressVisible := false
-	if progress < 0 {
-		// Скрыть прогресс
-		tab.downloadProgress.Hide()
-		tab.downloadProgress.SetValue(0)
-	} else {
-		// Показать прогресс с значением
-		tab.downloadProgress.SetValue(progress)
-		tab.downloadProgress.Show()
-		progressVisible = true
-	}
-
-	// Управление кнопкой (если прогресс виден, кнопка всегда скрыта)
-	buttonVisible := false
-	if progressVisible {
-		// Если показываем прогресс, кнопка всегда скрыта
-		tab.downloadButton.Hide()
-	} else if buttonText == "" {
-		// Скрыть кнопку
-		tab.downloadButton.Hide()
-	} else {
-		// Показать кнопку с текстом
-		tab.downloadButton.SetText(buttonText)
-		tab.downloadButton.Show()
-		tab.downloadButton.Enable()
-		buttonVisible = true
+// disableIdleDownloadButtons отключает видимые кнопки скачивания, пока идет другое скачивание
+func (tab *CoreDashboardTab) disableIdleDownloadButtons() {
+	if tab.singbox != nil && tab.singbox.DownloadButton.Visible() {
+		tab.singbox.DownloadButton.Disable()
+		tab.singbox.StatusLabel.SetText(T("core.download.busy"))
+	}
+	if tab.wintun != nil && tab.wintun.DownloadButton.Visible() {
+		tab.wintun.DownloadButton.Disable()
+		tab.wintun.StatusLabel.SetText(T("core.download.busy"))
 	}
+}
 
-	// Управление placeholder: показывать если есть кнопка ИЛИ прогресс-бар
-	if tab.downloadPlaceholder != nil {
-		if buttonVisible || progressVisible {
-			tab.downloadPlaceholder.Show()
-		} else {
-			tab.downloadPlaceholder.Hide()
-		}
+// setSingboxState - управляет состоянием sing-box (лейбл, кнопка, прогресс), см. DownloadableComponent.SetState
+func (tab *CoreDashboardTab) setSingboxState(statusText string, buttonText string, progress float64) {
+	tab.singbox.SetState(statusText, buttonText, progress, tab.anyDownloadInProgress())
+}
+
+// setVersionChecking shows a spinner in place of the download button while the latest version is fetched.
+// The caller updates the button with setSingboxState after the check.
+func (tab *CoreDashboardTab) setVersionChecking(checking bool) {
+	if !checking || tab.controller.IsDownloadInProgress(core.DownloadResourceCore) {
+		tab.singbox.ShowAlternative(nil)
+		return
 	}
+	tab.singbox.ShowAlternative(tab.versionCheckIndicator)
 }
 
 // updateBinaryStatus проверяет наличие бинарника и обновляет статус
 func (tab *CoreDashboardTab) updateBinaryStatus() {
 	// Проверяем, существует ли бинарник
 	if _, err := tab.controller.GetInstalledCoreVersion(); err != nil {
-		tab.statusLabel.SetText("Core Status ❌ Error: sing-box not found")
+		tab.statusLabel.SetText(T("core.status.not_found"))
 		tab.statusLabel.Importance = widget.MediumImportance // Текст всегда черный
 		// Обновляем иконку трея (красная при ошибке)
-		tab.controller.UpdateUI()
+		tab.updateUI()
 		return
 	}
 	// Если бинарник найден, обновляем статус запуска
 	tab.updateRunningStatus()
 	// Обновляем иконку трея (может измениться с красной на черную/зеленую)
-	tab.controller.UpdateUI()
+	tab.updateUI()
 }
 
 // updateRunningStatus обновляет статус Running/Stopped на основе RunningState
@@ -451,17 +550,25 @@ func (tab *CoreDashboardTab) updateRunningStatus() {
 	// Update status label based on state
 	restartInfo := ""
 	if tab.controller.ConsecutiveCrashAttempts > 0 {
-		restartInfo = fmt.Sprintf(" [restart %d/%d]", tab.controller.ConsecutiveCrashAttempts, 3)
+		restartInfo = T("core.status.restart", tab.controller.ConsecutiveCrashAttempts, 3)
 	}
 
 	if !buttonState.BinaryExists {
-		tab.statusLabel.SetText("Core Status ❌ Error: sing-box not found" + restartInfo)
+		tab.statusLabel.SetText(T("core.status.not_found") + restartInfo)
+		tab.statusLabel.Importance = widget.MediumImportance // Текст всегда черный
+	} else if buttonState.IsRunning && tab.controller.IsExternallyManaged() {
+		// sing-box started by a system service or another launcher, see core.adoptExternalSingBox
+		tab.statusLabel.SetText(T("core.status.external"))
 		tab.statusLabel.Importance = widget.MediumImportance // Текст всегда черный
 	} else if buttonState.IsRunning {
-		tab.statusLabel.SetText("Core Status ✅ Running" + restartInfo)
+		tab.statusLabel.SetText(T("core.status.running") + restartInfo)
+		tab.statusLabel.Importance = widget.MediumImportance // Текст всегда черный
+	} else if tab.controller.StartupFailed {
+		// sing-box exited right after start; the log lines are shown in a dialog
+		tab.statusLabel.SetText(T("core.status.failed"))
 		tab.statusLabel.Importance = widget.MediumImportance // Текст всегда черный
 	} else {
-		tab.statusLabel.SetText("Core Status ⏸️ Stopped" + restartInfo)
+		tab.statusLabel.SetText(T("core.status.stopped") + restartInfo)
 		tab.statusLabel.Importance = widget.MediumImportance // Текст всегда черный
 	}
 
@@ -494,24 +601,31 @@ func (tab *CoreDashboardTab) updateConfigInfo() {
 	if tab.configStatusLabel == nil {
 		return
 	}
-	configPath := tab.controller.ConfigPath
+	configPath := tab.controller.ConfigPath()
 	configExists := false
 	if info, err := os.Stat(configPath); err == nil {
 		modTime := info.ModTime().Format("2006-01-02")
-		tab.configStatusLabel.SetText(fmt.Sprintf("%s ✅ %s", filepath.Base(configPath), modTime))
+		tab.configStatusLabel.SetText(T("core.config.ok", filepath.Base(configPath), modTime))
 		configExists = true
 	} else if os.IsNotExist(err) {
-		tab.configStatusLabel.SetText(fmt.Sprintf("%s ❌ not found", filepath.Base(configPath)))
+		tab.configStatusLabel.SetText(T("core.config.not_found", filepath.Base(configPath)))
 		configExists = false
 	} else {
-		tab.configStatusLabel.SetText(fmt.Sprintf("Config error: %v", err))
+		tab.configStatusLabel.SetText(T("core.config.error", err))
 		configExists = false
 	}
+	if tab.configMissingLabel != nil {
+		if configExists {
+			tab.configMissingLabel.Hide()
+		} else {
+			tab.configMissingLabel.Show()
+		}
+	}
 
-	templatePath := filepath.Join(tab.controller.ExecDir, "bin", "config_template.json")
+	templatePath := filepath.Join(tab.controller.ExecDir(), "bin", "config_template.json")
 	if _, err := os.Stat(templatePath); err != nil {
 		// Template not found - show download button, hide wizard
-		if tab.templateDownloadButton != nil {
+		if tab.templateDownloadButton != nil && !tab.controller.ReadOnly {
 			tab.templateDownloadButton.Show()
 			tab.templateDownloadButton.Enable()
 			// Если шаблона нет, делаем кнопку синей (HighImportance)
@@ -542,7 +656,7 @@ func (tab *CoreDashboardTab) updateConfigInfo() {
 			tab.controller.ParserMutex.Lock()
 			parserRunning := tab.controller.ParserRunning
 			tab.controller.ParserMutex.Unlock()
-			if configExists && !parserRunning {
+			if configExists && !parserRunning && !tab.controller.ReadOnly {
 				tab.updateConfigButton.Enable()
 			} else {
 				tab.updateConfigButton.Disable()
@@ -550,6 +664,8 @@ func (tab *CoreDashboardTab) updateConfigInfo() {
 		}
 	}
 
+	tab.updateSubscriptionsInfo()
+
 	// Обновляем статус кнопок Start/Stop, так как они зависят от наличия конфига
 	tab.updateRunningStatus()
 }
@@ -569,27 +685,43 @@ func (tab *CoreDashboardTab) updateVersionInfoAsync() {
 		// Получаем установленную версию (локальная операция, быстрая)
 		installedVersion, err := tab.controller.GetInstalledCoreVersion()
 
-		// Обновляем UI для установленной версии
+		// sing-box из PATH: лаунчер не может его обновить, кнопку скачивания не показываем
+		if externalPath, external := tab.controller.ExternalSingboxPath(); err == nil && external {
+			fyne.Do(func() {
+				tab.setVersionChecking(false)
+				tab.setBlockVersionState("", false)
+				tab.singbox.StatusLabel.Importance = widget.MediumImportance
+				tab.setSingboxState(T("core.singbox.external", installedVersion, externalPath), "", -1)
+			})
+			return
+		}
+
+		// Обновляем UI для установленной версии; пока идёт сетевой запрос, вместо кнопки крутится спиннер
 		fyne.Do(func() {
 			if err != nil {
 				// Показываем ошибку в статусе
-				tab.singboxStatusLabel.Importance = widget.MediumImportance
-				tab.downloadButton.Importance = widget.HighImportance
-				tab.setSingboxState("❌ sing-box.exe not found", "Download", -1)
+				tab.singbox.StatusLabel.Importance = widget.MediumImportance
+				tab.singbox.DownloadButton.Importance = widget.HighImportance
+				tab.setSingboxState(T("core.singbox.not_found"), T("core.download"), -1)
 			} else {
 				// Показываем версию
-				tab.singboxStatusLabel.Importance = widget.MediumImportance
+				tab.singbox.StatusLabel.Importance = widget.MediumImportance
 				tab.setSingboxState(installedVersion, "", -1)
 			}
+			tab.setVersionChecking(true)
 		})
 
 		// Если бинарник не найден, пытаемся получить последнюю версию для кнопки
 		if err != nil {
 			latest, latestErr := tab.controller.GetLatestCoreVersion()
+			tab.recordLatestVersionCheck(latestErr)
 			fyne.Do(func() {
-				buttonText := "Download"
-				if latestErr == nil && latest != "" {
-					buttonText = fmt.Sprintf("Download v%s", latest)
+				tab.setVersionChecking(false)
+				tab.setBlockVersionState("", false)
+				buttonText := T("core.download")
+				// The fallback version can be downloaded too, it is just not known to be the latest
+				if (latestErr == nil || errors.Is(latestErr, core.ErrUsingFallbackVersion)) && latest != "" {
+					buttonText = T("core.download.version", latest)
 				}
 				tab.setSingboxState("", buttonText, -1)
 			})
@@ -598,24 +730,35 @@ func (tab *CoreDashboardTab) updateVersionInfoAsync() {
 
 		// Получаем последнюю версию (сетевая операция, асинхронная)
 		latest, latestErr := tab.controller.GetLatestCoreVersion()
+		tab.recordLatestVersionCheck(latestErr)
+		blocked := latestErr == nil && tab.controller.IsCoreVersionBlocked(latest)
 
 		// Обновляем UI с результатом
 		fyne.Do(func() {
+			tab.setVersionChecking(false)
 			if latestErr != nil {
 				// Network error - not critical, just don't show update
 				// Log for debugging, but don't show to user
 				tab.setSingboxState("", "", -1)
+				tab.setBlockVersionState("", false)
 				return
 			}
 
 			// Сравниваем версии
-			if latest != "" && compareVersions(installedVersion, latest) < 0 {
-				// Есть обновление
-				tab.downloadButton.Importance = widget.HighImportance
-				tab.setSingboxState("", fmt.Sprintf("Update v%s", latest), -1)
+			if latest != "" && core.CompareVersions(installedVersion, latest) < 0 {
+				if blocked {
+					// Обновление заблокировано пользователем - не показываем кнопку Update
+					tab.setSingboxState("", "", -1)
+				} else {
+					// Есть обновление
+					tab.singbox.DownloadButton.Importance = widget.HighImportance
+					tab.setSingboxState("", T("core.download.update", latest), -1)
+				}
+				tab.setBlockVersionState(latest, blocked)
 			} else {
 				// Версия актуальна
 				tab.setSingboxState("", "", -1)
+				tab.setBlockVersionState("", false)
 			}
 		})
 	}()
@@ -658,7 +801,7 @@ func (tab *CoreDashboardTab) downloadConfigTemplate() {
 			})
 			return
 		}
-		target := filepath.Join(tab.controller.ExecDir, "bin", "config_template.json")
+		target := filepath.Join(tab.controller.ExecDir(), "bin", "config_template.json")
 		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
 			fyne.Do(func() {
 				if tab.templateDownloadButton != nil {
@@ -677,50 +820,20 @@ func (tab *CoreDashboardTab) downloadConfigTemplate() {
 			})
 			return
 		}
+		invalidateTemplateCache(target)
 		fyne.Do(func() {
 			if tab.templateDownloadButton != nil {
 				tab.templateDownloadButton.Hide()
 			}
-			dialog.ShowInformation("Config Template", fmt.Sprintf("Template saved to %s", target), tab.controller.MainWindow)
+			dialog.ShowInformation(T("core.template.title"), T("core.template.saved", target), tab.controller.MainWindow)
 			tab.updateConfigInfo()
 		})
 	}()
 }
 
-// compareVersions сравнивает две версии (формат X.Y.Z)
-// Возвращает: -1 если v1 < v2, 0 если v1 == v2, 1 если v1 > v2
-func compareVersions(v1, v2 string) int {
-	parts1 := strings.Split(v1, ".")
-	parts2 := strings.Split(v2, ".")
-
-	maxLen := len(parts1)
-	if len(parts2) > maxLen {
-		maxLen = len(parts2)
-	}
-
-	for i := 0; i < maxLen; i++ {
-		var num1, num2 int
-		if i < len(parts1) {
-			fmt.Sscanf(parts1[i], "%d", &num1)
-		}
-		if i < len(parts2) {
-			fmt.Sscanf(parts2[i], "%d", &num2)
-		}
-
-		if num1 < num2 {
-			return -1
-		}
-		if num1 > num2 {
-			return 1
-		}
-	}
-
-	return 0
-}
-
 // handleDownload обрабатывает нажатие на кнопку Download
 func (tab *CoreDashboardTab) handleDownload() {
-	if tab.downloadInProgress {
+	if tab.anyDownloadInProgress() {
 		return // Уже идет скачивание
 	}
 
@@ -733,11 +846,13 @@ func (tab *CoreDashboardTab) handleDownload() {
 		// But for download we need version immediately, so do it synchronously in goroutine
 		go func() {
 			latest, err := tab.controller.GetLatestCoreVersion()
+			if errors.Is(err, core.ErrUsingFallbackVersion) {
+				err = nil
+			}
 			fyne.Do(func() {
 				if err != nil {
 					ShowError(tab.controller.MainWindow, fmt.Errorf("failed to get latest version: %w", err))
-					tab.downloadInProgress = false
-					tab.setSingboxState("", "Download", -1)
+					tab.setSingboxState("", T("core.download"), -1)
 					return
 				}
 				// Запускаем скачивание с полученной версией
@@ -753,10 +868,9 @@ func (tab *CoreDashboardTab) handleDownload() {
 
 // startDownloadWithVersion запускает процесс скачивания с указанной версией
 func (tab *CoreDashboardTab) startDownloadWithVersion(targetVersion string) {
-	// Запускаем скачивание в отдельной горутине
-	tab.downloadInProgress = true
-	tab.downloadButton.Disable()
-	tab.setSingboxState("", "", 0.0)
+	// Запускаем скачивание в отдельной горутине; прогресс-бар показывает onDownloadEvent
+	tab.singbox.DownloadButton.Disable()
+	tab.setBlockVersionState("", false)
 
 	// Создаем канал для прогресса
 	progressChan := make(chan core.DownloadProgress, 10)
@@ -772,106 +886,70 @@ func (tab *CoreDashboardTab) startDownloadWithVersion(targetVersion string) {
 	go func() {
 		for progress := range progressChan {
 			fyne.Do(func() {
-				// Обновляем прогресс-бар
-				progressValue := float64(progress.Progress) / 100.0
-				tab.setSingboxState("", "", progressValue)
-
-				if progress.Status == "done" {
-					tab.downloadInProgress = false
-					// Обновляем статусы после успешного скачивания (это уберет ошибки и обновит статус)
-					tab.updateVersionInfo()
-					tab.updateBinaryStatus() // Это вызовет updateRunningStatus() и обновит статус
-					// Обновляем иконку трея (может измениться с красной на черную/зеленую)
-					tab.controller.UpdateUI()
-					ShowInfo(tab.controller.MainWindow, "Download Complete", progress.Message)
-				} else if progress.Status == "error" {
-					tab.downloadInProgress = false
-					tab.setSingboxState("", "Download", -1)
+				switch {
+				case progress.Status == "done":
+					ShowInfo(tab.controller.MainWindow, T("core.download.complete"), progress.Message)
+				case progress.Status == "error":
 					ShowError(tab.controller.MainWindow, progress.Error)
+				case tab.controller.IsDownloadInProgress(core.DownloadResourceCore):
+					// Обновляем прогресс-бар (запоздавшие сообщения после DownloadFinished пропускаем)
+					tab.setSingboxState("", "", float64(progress.Progress)/100.0)
 				}
 			})
 		}
 	}()
 }
 
-// startAutoUpdate запускает автообновление версии (статус управляется через RunningState)
+// startAutoUpdate запускает автообновление версии (статус управляется через RunningState).
+// Горутина останавливается через Destroy.
 func (tab *CoreDashboardTab) startAutoUpdate() {
+	ctx, cancel := context.WithCancel(context.Background())
+	tab.autoUpdateCancel = cancel
+
 	// Запускаем периодическое обновление с умной логикой
+	tab.autoUpdateDone.Add(1)
 	go func() {
+		defer tab.autoUpdateDone.Done()
 		rand.Seed(time.Now().UnixNano()) // Инициализация генератора случайных чисел
 
 		for {
+			// Ждем перед следующим обновлением
 			select {
-			case <-tab.stopAutoUpdate:
+			case <-time.After(tab.nextVersionCheckDelay()):
+				// Обновляем только версию асинхронно (не блокируем UI)
+				// updateVersionInfo теперь полностью асинхронная
+				// lastUpdateSuccess выставляется по результату сетевого запроса
+				tab.updateVersionInfo()
+			case <-ctx.Done():
 				return
-			default:
-				// Ждем перед следующим обновлением
-				var delay time.Duration
-				if tab.lastUpdateSuccess {
-					// Если последнее обновление было успешным - не повторяем автоматически
-					// Ждем очень долго (или можно вообще не повторять)
-					delay = 10 * time.Minute
-				} else {
-					// Если была ошибка - повторяем через случайный интервал 20-35 секунд
-					delay = time.Duration(20+rand.Intn(16)) * time.Second // 20-35 секунд
-				}
-
-				select {
-				case <-time.After(delay):
-					// Обновляем только версию асинхронно (не блокируем UI)
-					// updateVersionInfo теперь полностью асинхронная
-					tab.updateVersionInfo()
-					// Устанавливаем успех после небольшой задержки
-					// (в реальности нужно отслеживать через канал, но для простоты используем задержку)
-					go func() {
-						time.Sleep(2 * time.Second)
-						tab.lastUpdateSuccess = true // Упрощенная логика
-					}()
-				case <-tab.stopAutoUpdate:
-					return
-				}
 			}
 		}
 	}()
 }
 
-// createWintunBlock creates a block for displaying wintun.dll status
-func (tab *CoreDashboardTab) createWintunBlock() fyne.CanvasObject {
-	title := widget.NewLabel("Wintun")
-	title.Importance = widget.MediumImportance
-
-	tab.wintunStatusLabel = widget.NewLabel("Checking...")
-	tab.wintunStatusLabel.Wrapping = fyne.TextWrapOff
-
-	tab.wintunDownloadButton = widget.NewButton("Download", func() {
-		tab.handleWintunDownload()
-	})
-	tab.wintunDownloadButton.Importance = widget.MediumImportance
-	tab.wintunDownloadButton.Disable()
-
-	tab.wintunDownloadProgress = widget.NewProgressBar()
-	tab.wintunDownloadProgress.Hide()
-	tab.wintunDownloadProgress.SetValue(0)
+// recordLatestVersionCheck stores the result of a latest-version check for the auto-update loop.
+// Falling back to FallbackVersion (GitHub unreachable) counts as a failure, so the check is retried soon.
+// Called from the check goroutine.
+func (tab *CoreDashboardTab) recordLatestVersionCheck(err error) {
+	tab.lastUpdateSuccess.Store(err == nil)
+}
 
-	if tab.wintunDownloadPlaceholder == nil {
-		tab.wintunDownloadPlaceholder = canvas.NewRectangle(color.Transparent)
+// nextVersionCheckDelay returns how long the auto-update loop waits before the next check
+func (tab *CoreDashboardTab) nextVersionCheckDelay() time.Duration {
+	if tab.lastUpdateSuccess.Load() {
+		// Если последнее обновление было успешным - не повторяем автоматически
+		// Ждем очень долго (или можно вообще не повторять)
+		return 10 * time.Minute
 	}
-	wintunPlaceholderSize := fyne.NewSize(downloadPlaceholderWidth, tab.wintunDownloadButton.MinSize().Height)
-	tab.wintunDownloadPlaceholder.SetMinSize(wintunPlaceholderSize)
-	tab.wintunDownloadPlaceholder.Hide()
-
-	tab.wintunDownloadContainer = container.NewStack(
-		tab.wintunDownloadPlaceholder,
-		tab.wintunDownloadButton,
-		tab.wintunDownloadProgress,
-	)
+	// Если была ошибка - повторяем через случайный интервал 20-35 секунд
+	return time.Duration(20+rand.Intn(16)) * time.Second // 20-35 секунд
+}
 
-	return container.NewHBox(
-		title,
-		layout.NewSpacer(),
-		tab.wintunStatusLabel,
-		tab.wintunDownloadContainer,
-	)
+// createWintunBlock creates a block for displaying wintun.dll status
+func (tab *CoreDashboardTab) createWintunBlock() fyne.CanvasObject {
+	tab.wintun = NewDownloadableComponent(T("core.wintun.title"), tab.handleWintunDownload)
+	tab.wintun.DownloadsDisabled = tab.controller.ReadOnly
+	return tab.wintun.CreateWidget()
 }
 
 // updateWintunStatus обновляет статус wintun.dll
@@ -880,20 +958,28 @@ func (tab *CoreDashboardTab) updateWintunStatus() {
 		return // wintun нужен только на Windows
 	}
 
-	exists, err := tab.controller.CheckWintunDLL()
+	wintun, err := tab.controller.CheckWintunDLL()
 	if err != nil {
-		tab.wintunStatusLabel.Importance = widget.MediumImportance
-		tab.setWintunState("❌ Error checking wintun.dll", "", -1)
+		tab.wintun.StatusLabel.Importance = widget.MediumImportance
+		tab.setWintunState(T("core.wintun.check_error"), "", -1)
 		return
 	}
 
-	if exists {
-		tab.wintunStatusLabel.Importance = widget.MediumImportance
-		tab.setWintunState("ok", "", -1)
+	if wintun.Found {
+		tab.wintun.StatusLabel.Importance = widget.MediumImportance
+		status := T("core.wintun.ok") + " — " + wintun.Path
+		if wintun.NonStandard {
+			// Installed globally in System32, sing-box loads it from there
+			status = T("core.wintun.found_at", wintun.Path)
+		}
+		if wintun.Version != "" {
+			status += " (v" + wintun.Version + ")"
+		}
+		tab.setWintunState(status, "", -1)
 	} else {
-		tab.wintunStatusLabel.Importance = widget.MediumImportance
-		tab.wintunDownloadButton.Importance = widget.HighImportance
-		tab.setWintunState("❌ wintun.dll not found", "Download wintun.dll", -1)
+		tab.wintun.StatusLabel.Importance = widget.MediumImportance
+		tab.wintun.DownloadButton.Importance = widget.HighImportance
+		tab.setWintunState(T("core.wintun.not_found"), T("core.wintun.download"), -1)
 	}
 
 	// Обновляем статус кнопок Start/Stop, так как они зависят от наличия wintun.dll
@@ -902,13 +988,12 @@ func (tab *CoreDashboardTab) updateWintunStatus() {
 
 // handleWintunDownload обрабатывает нажатие на кнопку Download wintun.dll
 func (tab *CoreDashboardTab) handleWintunDownload() {
-	if tab.wintunDownloadInProgress {
+	if tab.anyDownloadInProgress() {
 		return // Уже идет скачивание
 	}
 
-	tab.wintunDownloadInProgress = true
-	tab.wintunDownloadButton.Disable()
-	tab.setWintunState("", "", 0.0)
+	// Прогресс-бар показывает onDownloadEvent
+	tab.wintun.DownloadButton.Disable()
 
 	go func() {
 		progressChan := make(chan core.DownloadProgress, 10)
@@ -921,17 +1006,13 @@ func (tab *CoreDashboardTab) handleWintunDownload() {
 
 		for progress := range progressChan {
 			fyne.Do(func() {
-				progressValue := float64(progress.Progress) / 100.0
-				tab.setWintunState("", "", progressValue)
-
-				if progress.Status == "done" {
-					tab.wintunDownloadInProgress = false
-					tab.updateWintunStatus() // Обновляет статус и управляет кнопкой
-					ShowInfo(tab.controller.MainWindow, "Download Complete", progress.Message)
-				} else if progress.Status == "error" {
-					tab.wintunDownloadInProgress = false
-					tab.setWintunState("", "Download wintun.dll", -1)
+				switch {
+				case progress.Status == "done":
+					ShowInfo(tab.controller.MainWindow, T("core.download.complete"), progress.Message)
+				case progress.Status == "error":
 					ShowError(tab.controller.MainWindow, progress.Error)
+				case tab.controller.IsDownloadInProgress(core.DownloadResourceWintun):
+					tab.setWintunState("", "", float64(progress.Progress)/100.0)
 				}
 			})
 		}
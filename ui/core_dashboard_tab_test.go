@@ -0,0 +1,60 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"singbox-launcher/core"
+)
+
+// TestVersionCheckResultRace runs the check goroutine (recordLatestVersionCheck) and the
+// auto-update loop (nextVersionCheckDelay) concurrently; run with go test -race.
+func TestVersionCheckResultRace(t *testing.T) {
+	tab := &CoreDashboardTab{}
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				if (i+j)%2 == 0 {
+					tab.recordLatestVersionCheck(nil)
+				} else {
+					tab.recordLatestVersionCheck(core.ErrUsingFallbackVersion)
+				}
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				tab.nextVersionCheckDelay()
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestNextVersionCheckDelay(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		min, max time.Duration
+	}{
+		{"success", nil, 10 * time.Minute, 10 * time.Minute},
+		{"fallback version", core.ErrUsingFallbackVersion, 20 * time.Second, 35 * time.Second},
+		{"wrapped fallback version", fmt.Errorf("check: %w", core.ErrUsingFallbackVersion), 20 * time.Second, 35 * time.Second},
+		{"network error", errors.New("network error: timeout"), 20 * time.Second, 35 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tab := &CoreDashboardTab{}
+			tab.recordLatestVersionCheck(tt.err)
+			if got := tab.nextVersionCheckDelay(); got < tt.min || got > tt.max {
+				t.Errorf("nextVersionCheckDelay() = %v, want between %v and %v", got, tt.min, tt.max)
+			}
+		})
+	}
+}
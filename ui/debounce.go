@@ -0,0 +1,21 @@
+package ui
+
+import (
+	"sync"
+	"time"
+)
+
+// debounce returns a function that calls f once d has passed since its last call.
+// Bursts of calls (e.g. from a progress loop) result in a single call of f.
+func debounce(d time.Duration, f func()) func() {
+	var mu sync.Mutex
+	var timer *time.Timer
+	return func() {
+		mu.Lock()
+		defer mu.Unlock()
+		if timer != nil {
+			timer.Stop()
+		}
+		timer = time.AfterFunc(d, f)
+	}
+}
@@ -0,0 +1,42 @@
+//go:build debug
+
+package ui
+
+import (
+	"context"
+	"log/slog"
+
+	"singbox-launcher/internal/debuglog"
+)
+
+// templateLoaderLogLevel enables template loader logging in debug builds (go build -tags debug)
+const templateLoaderLogLevel = debuglog.LevelTrace
+
+// tplLog logs msg with structured fields (key-value pairs: "file", path, "blockIndex", 2, ...)
+// to templateLoaderLogger
+func tplLog(level debuglog.Level, msg string, args ...interface{}) {
+	if !tplEnabled(level) {
+		return
+	}
+	templateLoaderLogger.Log(context.Background(), slogLevel(level), msg, args...)
+}
+
+// tplEnabled reports whether messages of the given level are logged.
+// Use it to skip building expensive log arguments (e.g. truncateString) when logging is off.
+func tplEnabled(level debuglog.Level) bool {
+	return debuglog.ShouldLog(level, templateLoaderLogLevel)
+}
+
+// slogLevel maps debuglog levels to slog ones; verbose and trace messages are debug messages
+func slogLevel(level debuglog.Level) slog.Level {
+	switch level {
+	case debuglog.LevelError:
+		return slog.LevelError
+	case debuglog.LevelWarn:
+		return slog.LevelWarn
+	case debuglog.LevelInfo:
+		return slog.LevelInfo
+	default:
+		return slog.LevelDebug
+	}
+}
@@ -1,14 +1,21 @@
 package ui
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
 	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 	"github.com/pion/stun"
 
@@ -96,9 +103,8 @@ func CreateDiagnosticsTab(ac *core.AppController) fyne.CanvasObject {
 					log.Printf("diagnosticsTab: STUN check successful, IP: %s", ip)
 					// Создаем кастомный диалог с кнопкой "Copy"
 					resultLabel := widget.NewLabel(fmt.Sprintf("Your External IP: %s\n(determined via [UDP]%s)", ip, stunServer))
-					copyButton := widget.NewButton("Copy IP", func() {
-						ac.MainWindow.Clipboard().SetContent(ip)
-						ShowAutoHideInfo(ac.Application, ac.MainWindow, "Copied", "IP address copied to clipboard.")
+					copyButton := widget.NewButtonWithIcon("Copy IP", theme.ContentCopyIcon(), func() {
+						CopyToClipboard(ip, ac.MainWindow)
 					})
 
 					ShowCustom(ac.MainWindow, "STUN Check Result", "Close", container.NewVBox(resultLabel, copyButton))
@@ -117,7 +123,27 @@ func CreateDiagnosticsTab(ac *core.AppController) fyne.CanvasObject {
 		})
 	}
 
+	systemInfo := newSystemInfoSection(ac)
+	certificates := newCertificatesSection(ac)
+	copyAllButton := widget.NewButtonWithIcon("Copy All", theme.ContentCopyIcon(), func() {
+		sections := []string{systemInfo.text(), certificates.text()}
+		if report := lastConnectivityReport(); report != "" {
+			sections = append(sections, "Connectivity check:\n"+report)
+		}
+		CopyToClipboard(strings.Join(sections, "\n\n"), ac.MainWindow)
+	})
+
 	return container.NewVBox(
+		container.NewHBox(
+			widget.NewButton("Run Connectivity Check", func() { runConnectivityCheck(ac) }),
+			layout.NewSpacer(),
+			copyAllButton,
+		),
+		widget.NewSeparator(),
+		systemInfo.content,
+		widget.NewSeparator(),
+		certificates.content,
+		widget.NewSeparator(),
 		widget.NewLabel("IP Check Services:"),
 		stunButton, // Google STUN [UDP] перенесен в секцию IP Check Services
 		openBrowserButton("2ip.ru", "https://2ip.ru"),
@@ -128,3 +154,131 @@ func CreateDiagnosticsTab(ac *core.AppController) fyne.CanvasObject {
 		openBrowserButton("WhatIsMyIPAddress", "https://whatismyipaddress.com"),
 	)
 }
+
+// diagnosticsSection is a text section of the Diagnostics tab with its own Copy button
+type diagnosticsSection struct {
+	title   string
+	label   *widget.Label
+	content fyne.CanvasObject
+}
+
+// newDiagnosticsSection creates a section titled title showing label, with extra widgets below it
+func newDiagnosticsSection(ac *core.AppController, title string, label *widget.Label, extra ...fyne.CanvasObject) *diagnosticsSection {
+	section := &diagnosticsSection{title: title, label: label}
+	copyButton := widget.NewButtonWithIcon("", theme.ContentCopyIcon(), func() {
+		CopyToClipboard(section.text(), ac.MainWindow)
+	})
+	objects := []fyne.CanvasObject{
+		container.NewHBox(widget.NewLabel(title+":"), layout.NewSpacer(), copyButton),
+		label,
+	}
+	section.content = container.NewVBox(append(objects, extra...)...)
+	return section
+}
+
+// text returns the section as "Title:\n<text>" for the clipboard
+func (s *diagnosticsSection) text() string {
+	return s.title + ":\n" + s.label.Text
+}
+
+// newSystemInfoSection creates the "System Info" section: versions, platform and paths
+func newSystemInfoSection(ac *core.AppController) *diagnosticsSection {
+	lines := []string{
+		"Launcher: " + core.AppVersion,
+		fmt.Sprintf("OS: %s/%s", runtime.GOOS, runtime.GOARCH),
+		"sing-box: " + ac.GetCoreBinaryPath(),
+		"Config: " + ac.ConfigPath(),
+		"Launcher folder: " + ac.ExecDir(),
+	}
+	if id := ac.SharedDeviceID(); id != "" {
+		lines = append(lines, "Device ID: "+id)
+	}
+	label := widget.NewLabel(strings.Join(lines, "\n"))
+	label.Wrapping = fyne.TextWrapWord
+	return newDiagnosticsSection(ac, "System Info", label)
+}
+
+// connectivityReport is the text of the last connectivity check, included in "Copy All"
+var connectivityReport struct {
+	mutex sync.Mutex
+	text  string
+}
+
+// lastConnectivityReport returns the report of the last connectivity check, "" if none was run
+func lastConnectivityReport() string {
+	connectivityReport.mutex.Lock()
+	defer connectivityReport.mutex.Unlock()
+	return connectivityReport.text
+}
+
+// runConnectivityCheck runs AppController.TestConnectivity and shows the checklist with a Copy button,
+// so it can be pasted into a support request
+func runConnectivityCheck(ac *core.AppController) {
+	waitDialog := dialog.NewCustomWithoutButtons("Connectivity Check", widget.NewLabel("Checking, please wait..."), ac.MainWindow)
+	waitDialog.Show()
+
+	go func() {
+		report := ac.TestConnectivity(context.Background())
+		text := report.String()
+		log.Printf("diagnosticsTab: Connectivity check:\n%s", text)
+		connectivityReport.mutex.Lock()
+		connectivityReport.text = text
+		connectivityReport.mutex.Unlock()
+
+		fyne.Do(func() {
+			waitDialog.Hide()
+			resultLabel := widget.NewLabel(text)
+			copyButton := widget.NewButtonWithIcon("Copy", theme.ContentCopyIcon(), func() {
+				CopyToClipboard(text, ac.MainWindow)
+			})
+			ShowCustom(ac.MainWindow, "Connectivity Check Result", "Close", container.NewVBox(resultLabel, copyButton))
+		})
+	}()
+}
+
+// newCertificatesSection creates the "Certificate Management" section listing custom CA files from certs/
+func newCertificatesSection(ac *core.AppController) *diagnosticsSection {
+	filesLabel := widget.NewLabel("")
+	filesLabel.Wrapping = fyne.TextWrapWord
+
+	refresh := func() {
+		files, err := core.ListCustomCACerts(ac.CertsDir())
+		if err != nil {
+			filesLabel.SetText("Error: " + err.Error())
+			return
+		}
+		if len(files) == 0 {
+			filesLabel.SetText("No custom CA certificates. Put PEM files (*.pem, *.crt, *.cer) into the certs folder to trust a corporate CA.")
+			return
+		}
+		lines := make([]string, 0, len(files))
+		for _, file := range files {
+			if file.Err != nil {
+				lines = append(lines, fmt.Sprintf("❌ %s: %v", file.Name, file.Err))
+			} else {
+				lines = append(lines, fmt.Sprintf("✅ %s (%d certificates)", file.Name, file.Certs))
+			}
+		}
+		filesLabel.SetText(strings.Join(lines, "\n"))
+	}
+	refresh()
+
+	refreshButton := widget.NewButton("Refresh", func() {
+		// New HTTP clients use the updated certs/ folder
+		core.ReloadCustomCACerts()
+		refresh()
+	})
+	openButton := widget.NewButton("Open Certs Folder", func() {
+		dir := ac.CertsDir()
+		err := os.MkdirAll(dir, 0755)
+		if err == nil {
+			err = platform.OpenFolder(dir)
+		}
+		if err != nil {
+			log.Printf("diagnosticsTab: Failed to open certs folder: %v", err)
+			ShowError(ac.MainWindow, err)
+		}
+	})
+
+	return newDiagnosticsSection(ac, "Certificate Management", filesLabel, container.NewHBox(refreshButton, openButton))
+}
@@ -0,0 +1,79 @@
+package ui
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// createDiagnosticsContent builds the Diagnostics tab with a "Pop Out" button that moves
+// the content into a separate window
+func (a *App) createDiagnosticsContent() fyne.CanvasObject {
+	a.diagnosticsPopOut = widget.NewButtonWithIcon("Pop Out", theme.ViewFullScreenIcon(), a.popOutDiagnostics)
+	a.diagnosticsView = container.NewBorder(
+		container.NewHBox(layout.NewSpacer(), a.diagnosticsPopOut),
+		nil, nil, nil,
+		CreateDiagnosticsTab(a.core),
+	)
+
+	reattach := widget.NewButton("Reattach", a.reattachDiagnostics)
+	a.diagnosticsPlaceholder = container.NewCenter(container.NewVBox(
+		widget.NewLabel("Diagnostics is in a separate window"),
+		container.NewCenter(reattach),
+	))
+
+	a.diagnosticsSlot = container.NewStack(a.diagnosticsView)
+	return a.diagnosticsSlot
+}
+
+// popOutDiagnostics moves the Diagnostics content into its own window.
+// The window is independent of the main one and stays open when the main window is hidden to tray.
+func (a *App) popOutDiagnostics() {
+	if a.diagnosticsWindow != nil {
+		a.diagnosticsWindow.RequestFocus()
+		return
+	}
+
+	a.diagnosticsSlot.Objects = []fyne.CanvasObject{a.diagnosticsPlaceholder}
+	a.diagnosticsSlot.Refresh()
+	a.diagnosticsPopOut.Hide()
+
+	w := a.core.Application.NewWindow("Diagnostics")
+	w.SetIcon(a.core.AppIconData)
+	w.SetContent(a.diagnosticsView)
+	w.Resize(fyne.NewSize(350, 450))
+	w.CenterOnScreen()
+	restoreWindowGeometry(a.core, w, diagnosticsWindowGeometry)
+	// Closing the window ("X") puts the content back into the tab
+	w.SetCloseIntercept(a.reattachDiagnostics)
+	a.diagnosticsWindow = w
+	w.Show()
+}
+
+// reattachDiagnostics closes the Diagnostics window and shows its content in the tab again
+func (a *App) reattachDiagnostics() {
+	w := a.diagnosticsWindow
+	if w == nil {
+		return
+	}
+	a.diagnosticsWindow = nil
+
+	saveWindowGeometry(a.core, w, diagnosticsWindowGeometry)
+	// Detach the content first, closing the window must not destroy it
+	w.SetContent(widget.NewLabel(""))
+	w.Close()
+	releaseDialogQueue(w)
+
+	a.diagnosticsPopOut.Show()
+	a.diagnosticsSlot.Objects = []fyne.CanvasObject{a.diagnosticsView}
+	a.diagnosticsSlot.Refresh()
+}
+
+// SaveDiagnosticsWindowGeometry stores the geometry of the Diagnostics window if it is open
+func (a *App) SaveDiagnosticsWindowGeometry() {
+	if a.diagnosticsWindow != nil {
+		saveWindowGeometry(a.core, a.diagnosticsWindow, diagnosticsWindowGeometry)
+	}
+}
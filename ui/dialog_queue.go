@@ -0,0 +1,133 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+)
+
+// queuedDialog is a notification waiting in a DialogQueue
+type queuedDialog struct {
+	title   string
+	message string
+	isError bool
+}
+
+// DialogQueue shows error and info dialogs of a window one at a time, so a burst of
+// errors (e.g. during a download) doesn't stack dialogs on top of each other.
+// While more than one notification is pending, the count is shown in the window title.
+// The consumer goroutine and the dialogQueues entry exist only while dialogs are pending
+// or until the window is closed, see releaseDialogQueue.
+type DialogQueue struct {
+	window    fyne.Window
+	baseTitle string
+
+	mutex   sync.Mutex
+	items   []queuedDialog
+	pending int           // queued + currently shown
+	done    chan struct{} // closed by releaseDialogQueue
+}
+
+var (
+	dialogQueues      = make(map[fyne.Window]*DialogQueue)
+	dialogQueuesMutex sync.Mutex // taken before DialogQueue.mutex
+)
+
+// enqueueDialog adds a dialog to the queue of window, starting its consumer if none is running.
+// Safe to call from any goroutine; also installed as dialogs.EnqueueFunc so dialogs shown
+// from core share the queues.
+func enqueueDialog(window fyne.Window, title, message string, isError bool) {
+	dialogQueuesMutex.Lock()
+	q, ok := dialogQueues[window]
+	if !ok {
+		q = &DialogQueue{window: window, baseTitle: window.Title(), done: make(chan struct{})}
+		dialogQueues[window] = q
+		go q.run()
+	}
+	q.mutex.Lock()
+	q.items = append(q.items, queuedDialog{title: title, message: message, isError: isError})
+	q.pending++
+	pending := q.pending
+	q.mutex.Unlock()
+	dialogQueuesMutex.Unlock()
+
+	q.updateTitle(pending)
+}
+
+// releaseDialogQueue drops the queue of window and stops its consumer. Call it from the
+// OnClosed handler of windows other than the main window: a dialog on a closed window is
+// never dismissed, so the consumer would wait for it forever.
+func releaseDialogQueue(window fyne.Window) {
+	dialogQueuesMutex.Lock()
+	defer dialogQueuesMutex.Unlock()
+	if q, ok := dialogQueues[window]; ok {
+		delete(dialogQueues, window)
+		close(q.done)
+	}
+}
+
+// next removes the first queued dialog. When the queue is empty it is dropped from
+// dialogQueues (the next dialog starts a new consumer) and ok is false.
+func (q *DialogQueue) next() (item queuedDialog, ok bool) {
+	dialogQueuesMutex.Lock()
+	defer dialogQueuesMutex.Unlock()
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	select {
+	case <-q.done:
+		return item, false
+	default:
+	}
+	if len(q.items) == 0 {
+		delete(dialogQueues, q.window)
+		return item, false
+	}
+	item = q.items[0]
+	q.items = q.items[1:]
+	return item, true
+}
+
+// run shows queued dialogs one by one, waiting for each to be dismissed
+func (q *DialogQueue) run() {
+	for {
+		item, ok := q.next()
+		if !ok {
+			return
+		}
+
+		closed := make(chan struct{})
+		fyne.Do(func() {
+			var d dialog.Dialog
+			if item.isError {
+				d = dialog.NewError(errors.New(item.message), q.window)
+			} else {
+				d = dialog.NewInformation(item.title, item.message, q.window)
+			}
+			d.SetOnClosed(func() { close(closed) })
+			d.Show()
+		})
+		select {
+		case <-closed:
+		case <-q.done:
+			return
+		}
+
+		q.mutex.Lock()
+		q.pending--
+		pending := q.pending
+		q.mutex.Unlock()
+		q.updateTitle(pending)
+	}
+}
+
+// updateTitle shows "(N pending notifications)" in the window title while more than one is pending
+func (q *DialogQueue) updateTitle(pending int) {
+	title := q.baseTitle
+	if pending > 1 {
+		title = fmt.Sprintf("%s (%d pending notifications)", q.baseTitle, pending)
+	}
+	fyne.Do(func() { q.window.SetTitle(title) })
+}
@@ -9,25 +9,19 @@ import (
 	"fyne.io/fyne/v2/widget"
 )
 
-// ShowError shows an error dialog to the user
+// ShowError shows an error dialog to the user (queued after dialogs already shown)
 func ShowError(window fyne.Window, err error) {
-	fyne.Do(func() {
-		dialog.ShowError(err, window)
-	})
+	enqueueDialog(window, "Error", err.Error(), true)
 }
 
-// ShowErrorText shows an error dialog with a text message
+// ShowErrorText shows an error dialog with a text message (queued after dialogs already shown)
 func ShowErrorText(window fyne.Window, title, message string) {
-	fyne.Do(func() {
-		dialog.ShowError(fmt.Errorf("%s: %s", title, message), window)
-	})
+	enqueueDialog(window, title, fmt.Sprintf("%s: %s", title, message), true)
 }
 
-// ShowInfo shows an information dialog to the user
+// ShowInfo shows an information dialog to the user (queued after dialogs already shown)
 func ShowInfo(window fyne.Window, title, message string) {
-	fyne.Do(func() {
-		dialog.ShowInformation(title, message, window)
-	})
+	enqueueDialog(window, title, message, false)
 }
 
 // ShowCustom shows a custom dialog with custom content
@@ -44,6 +38,12 @@ func ShowConfirm(window fyne.Window, title, message string, onConfirm func(bool)
 	})
 }
 
+// CopyToClipboard puts content into the clipboard of window and shows a short "Copied!" notice
+func CopyToClipboard(content string, window fyne.Window) {
+	window.Clipboard().SetContent(content)
+	ShowAutoHideInfo(fyne.CurrentApp(), window, "Copied!", "Copied to clipboard.")
+}
+
 // ShowErrorBanner shows an error banner (widget.Entry with error styling)
 // This can be used for inline error display in forms
 func ShowErrorBanner(message string) *widget.Entry {
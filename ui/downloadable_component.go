@@ -0,0 +1,117 @@
+package ui
+
+import (
+	"image/color"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+)
+
+// downloadPlaceholderWidth - ширина слота кнопки скачивания, чтобы строки Core не прыгали
+const downloadPlaceholderWidth = 180
+
+// DownloadableComponent is a Core tab row for a file the launcher can download (sing-box, wintun.dll):
+// title, status and a download button that turns into a progress bar while downloading
+type DownloadableComponent struct {
+	Title          string
+	StatusLabel    *widget.Label
+	DownloadButton *widget.Button
+	ProgressBar    *widget.ProgressBar
+	// DownloadsDisabled keeps the download button hidden whatever SetState is given (read-only mode)
+	DownloadsDisabled bool
+
+	alternatives []fyne.CanvasObject // shown in place of the button, see ShowAlternative
+	trailing     []fyne.CanvasObject // shown after the button slot
+	slot         *AlternatingContainer
+}
+
+// NewDownloadableComponent creates the row widgets; onDownload is called by the download button
+func NewDownloadableComponent(title string, onDownload func()) *DownloadableComponent {
+	c := &DownloadableComponent{Title: title}
+
+	c.StatusLabel = widget.NewLabel(T("core.checking"))
+	c.StatusLabel.Wrapping = fyne.TextWrapOff
+
+	c.DownloadButton = widget.NewButton(T("core.download"), onDownload)
+	c.DownloadButton.Importance = widget.MediumImportance
+	c.DownloadButton.Disable()
+
+	c.ProgressBar = widget.NewProgressBar()
+	c.ProgressBar.SetValue(0)
+	return c
+}
+
+// AddAlternative adds an object that can be shown in place of the download button (e.g. a spinner).
+// Must be called before CreateWidget.
+func (c *DownloadableComponent) AddAlternative(o fyne.CanvasObject) {
+	c.alternatives = append(c.alternatives, o)
+}
+
+// AddTrailing adds an object after the download button. Must be called before CreateWidget.
+func (c *DownloadableComponent) AddTrailing(o fyne.CanvasObject) {
+	c.trailing = append(c.trailing, o)
+}
+
+// CreateWidget builds the row: title, spacer, status, button slot and trailing objects
+func (c *DownloadableComponent) CreateWidget() fyne.CanvasObject {
+	title := widget.NewLabel(c.Title)
+	title.Importance = widget.MediumImportance
+
+	// The placeholder is never shown, it only keeps the width of the slot
+	placeholder := canvas.NewRectangle(color.Transparent)
+	placeholder.SetMinSize(fyne.NewSize(downloadPlaceholderWidth, c.DownloadButton.MinSize().Height))
+	slotObjects := append([]fyne.CanvasObject{placeholder, c.DownloadButton, c.ProgressBar}, c.alternatives...)
+	c.slot = NewAlternatingContainer(slotObjects...)
+
+	objects := append([]fyne.CanvasObject{title, layout.NewSpacer(), c.StatusLabel, c.slot}, c.trailing...)
+	return container.NewHBox(objects...)
+}
+
+// SetState updates the row:
+// statusText: текст для статус-лейбла (если "", не менять)
+// buttonText: текст кнопки (если "", скрыть кнопку; иначе показать с этим текстом)
+// progress: значение прогресса (если < 0, скрыть прогресс; иначе показать с этим значением 0.0-1.0, кнопка скрыта)
+// buttonDisabled: показать кнопку отключённой (например, пока идёт другое скачивание)
+func (c *DownloadableComponent) SetState(statusText, buttonText string, progress float64, buttonDisabled bool) {
+	if statusText != "" {
+		c.StatusLabel.SetText(statusText)
+	}
+
+	switch {
+	case progress >= 0:
+		c.ProgressBar.SetValue(progress)
+		c.slot.SetActive(c.ProgressBar)
+	case buttonText != "" && !c.DownloadsDisabled:
+		c.ProgressBar.SetValue(0)
+		c.DownloadButton.SetText(buttonText)
+		if buttonDisabled {
+			c.DownloadButton.Disable()
+		} else {
+			c.DownloadButton.Enable()
+		}
+		c.slot.SetActive(c.DownloadButton)
+	default:
+		// Скрыть кнопку и прогресс; показанную альтернативу убирает ShowAlternative(nil)
+		c.ProgressBar.SetValue(0)
+		if active := c.slot.Active(); active == c.DownloadButton || active == c.ProgressBar {
+			c.slot.SetActive(nil)
+		}
+	}
+}
+
+// ShowAlternative shows o (added with AddAlternative) in place of the button; nil hides it
+// if it is shown
+func (c *DownloadableComponent) ShowAlternative(o fyne.CanvasObject) {
+	if o == nil {
+		for _, alternative := range c.alternatives {
+			if c.slot.Active() == alternative {
+				c.slot.SetActive(nil)
+			}
+		}
+		return
+	}
+	c.slot.SetActive(o)
+}
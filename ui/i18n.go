@@ -0,0 +1,269 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"singbox-launcher/core"
+)
+
+const (
+	defaultLanguage = "en"
+	localeDirName   = "locale"
+)
+
+// languageNames are display names for the built-in languages
+var languageNames = map[string]string{
+	"en":    "English",
+	"zh-CN": "简体中文",
+}
+
+// builtinTranslations are the tables compiled into the launcher.
+// Files in execDir/locale/<lang>.json override or extend them.
+var builtinTranslations = map[string]map[string]string{
+	"en": {
+		"core.exit":                 "Exit",
+		"core.start":                "Start",
+		"core.stop":                 "Stop",
+		"app.readonly":              "🔒 Read-only mode: configuration changes are disabled.",
+		"app.network.title":         "Network Changed",
+		"app.network.changed":       "Network changed (%s → %s). Restart sing-box if connections fail.",
+		"app.network.offline":       "offline",
+		"app.fallback.title":        "Proxy Switched",
+		"app.fallback.switched":     "%s: %s was slow or unreachable, switched to %s.",
+		"core.status.checking":      "Core Status Checking...",
+		"core.status.not_found":     "Core Status ❌ Error: sing-box not found",
+		"core.status.running":       "Core Status ✅ Running",
+		"core.status.external":      "Core Status ✅ Running (Externally managed)",
+		"core.status.stopped":       "Core Status ⏸️ Stopped",
+		"core.status.failed":        "Core Status ❌ Failed to start",
+		"core.status.restart":       " [restart %d/%d]",
+		"core.config.title":         "Config",
+		"core.config.checking":      "Checking config...",
+		"core.config.ok":            "%s ✅ %s",
+		"core.config.not_found":     "%s ❌ not found",
+		"core.config.error":         "Config error: %v",
+		"core.config.update":        "🔄 Update",
+		"core.config.wizard":        "⚙️ Wizard",
+		"core.config.starting":      "Starting...",
+		"core.config.missing":       "⚠️ No config.json found. Create one with the Wizard from your subscription or download the config template.",
+		"core.subs.refreshed":       "%s — Last refreshed: %s",
+		"core.subs.never":           "Never",
+		"time.just_now":             "just now",
+		"time.minute_ago":           "1 minute ago",
+		"time.minutes_ago":          "%d minutes ago",
+		"time.hour_ago":             "1 hour ago",
+		"time.hours_ago":            "%d hours ago",
+		"time.day_ago":              "1 day ago",
+		"time.days_ago":             "%d days ago",
+		"core.bindir.readonly":      "⚠️ %s is not writable, downloads will fail. Choose a writable folder in Settings (folder for downloaded binaries) or start the launcher with --binary-dir.",
+		"core.template.download":    "Download Config Template",
+		"core.template.title":       "Config Template",
+		"core.template.saved":       "Template saved to %s",
+		"core.singbox.title":        "Sing-box",
+		"core.singbox.not_found":    "❌ sing-box.exe not found",
+		"core.singbox.external":     "%s — External: %s (not updated by the launcher)",
+		"core.checking":             "Checking...",
+		"core.download":             "Download",
+		"core.download.version":     "Download v%s",
+		"core.download.update":      "Update v%s",
+		"core.download.complete":    "Download Complete",
+		"core.download.busy":        "Another download is in progress",
+		"core.version.block":        "Block This Version",
+		"core.version.checking":     "Checking…",
+		"core.version.unblock":      "Unblock v%s",
+		"core.wintun.title":         "Wintun",
+		"core.wintun.ok":            "ok",
+		"core.wintun.found_at":      "Found at %s",
+		"core.wintun.check_error":   "❌ Error checking wintun.dll",
+		"core.wintun.not_found":     "❌ wintun.dll not found",
+		"core.wintun.download":      "Download wintun.dll",
+		"settings.language":         "Language:",
+		"settings.language.restart": "Restart the launcher to apply the new language.",
+		"settings.language.title":   "Language",
+	},
+	"zh-CN": {
+		"core.exit":                 "退出",
+		"core.start":                "启动",
+		"core.stop":                 "停止",
+		"app.readonly":              "🔒 只读模式：已禁用配置更改。",
+		"app.network.title":         "网络已变更",
+		"app.network.changed":       "网络已变更（%s → %s）。如果连接失败，请重启 sing-box。",
+		"app.network.offline":       "离线",
+		"app.fallback.title":        "代理已切换",
+		"app.fallback.switched":     "%s：%s 速度慢或不可用，已切换到 %s。",
+		"core.status.checking":      "核心状态 检查中...",
+		"core.status.not_found":     "核心状态 ❌ 错误：未找到 sing-box",
+		"core.status.running":       "核心状态 ✅ 运行中",
+		"core.status.external":      "核心状态 ✅ 运行中（外部管理）",
+		"core.status.stopped":       "核心状态 ⏸️ 已停止",
+		"core.status.failed":        "核心状态 ❌ 启动失败",
+		"core.status.restart":       " [重启 %d/%d]",
+		"core.config.title":         "配置",
+		"core.config.checking":      "正在检查配置...",
+		"core.config.ok":            "%s ✅ %s",
+		"core.config.not_found":     "%s ❌ 未找到",
+		"core.config.error":         "配置错误：%v",
+		"core.config.update":        "🔄 更新",
+		"core.config.wizard":        "⚙️ 向导",
+		"core.config.starting":      "正在启动...",
+		"core.config.missing":       "⚠️ 未找到 config.json。请使用向导根据订阅创建，或下载配置模板。",
+		"core.subs.refreshed":       "%s — 上次刷新：%s",
+		"core.subs.never":           "从未",
+		"time.just_now":             "刚刚",
+		"time.minute_ago":           "1 分钟前",
+		"time.minutes_ago":          "%d 分钟前",
+		"time.hour_ago":             "1 小时前",
+		"time.hours_ago":            "%d 小时前",
+		"time.day_ago":              "1 天前",
+		"time.days_ago":             "%d 天前",
+		"core.bindir.readonly":      "⚠️ %s 不可写，下载将失败。请在设置中选择可写的二进制文件夹，或使用 --binary-dir 启动程序。",
+		"core.template.download":    "下载配置模板",
+		"core.template.title":       "配置模板",
+		"core.template.saved":       "模板已保存到 %s",
+		"core.singbox.title":        "Sing-box",
+		"core.singbox.not_found":    "❌ 未找到 sing-box.exe",
+		"core.singbox.external":     "%s — 外部：%s（启动器不会自动更新）",
+		"core.checking":             "检查中...",
+		"core.download":             "下载",
+		"core.download.version":     "下载 v%s",
+		"core.download.update":      "更新 v%s",
+		"core.download.complete":    "下载完成",
+		"core.download.busy":        "另一个下载正在进行中",
+		"core.version.block":        "屏蔽此版本",
+		"core.version.checking":     "检查中…",
+		"core.version.unblock":      "取消屏蔽 v%s",
+		"core.wintun.title":         "Wintun",
+		"core.wintun.ok":            "正常",
+		"core.wintun.found_at":      "位于 %s",
+		"core.wintun.check_error":   "❌ 检查 wintun.dll 时出错",
+		"core.wintun.not_found":     "❌ 未找到 wintun.dll",
+		"core.wintun.download":      "下载 wintun.dll",
+		"settings.language":         "语言：",
+		"settings.language.restart": "重启启动器以应用新语言。",
+		"settings.language.title":   "语言",
+	},
+}
+
+// Localizer looks up UI strings in translation tables
+type Localizer struct {
+	language string
+	tables   map[string]map[string]string
+}
+
+// localizer is the active localizer used by T
+var localizer = NewLocalizer("", defaultLanguage)
+
+// NewLocalizer creates a localizer with the built-in tables plus tables from
+// execDir/locale/<lang>.json (if execDir is not empty)
+func NewLocalizer(execDir, language string) *Localizer {
+	l := &Localizer{
+		language: language,
+		tables:   make(map[string]map[string]string),
+	}
+	for lang, table := range builtinTranslations {
+		l.tables[lang] = make(map[string]string, len(table))
+		for key, value := range table {
+			l.tables[lang][key] = value
+		}
+	}
+	if execDir != "" {
+		l.loadLocaleDir(filepath.Join(execDir, localeDirName))
+	}
+	return l
+}
+
+// loadLocaleDir merges all <lang>.json files from dir into the tables
+func (l *Localizer) loadLocaleDir(dir string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("Localizer: Failed to read locale directory: %v", err)
+		}
+		return
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		lang := strings.TrimSuffix(entry.Name(), ".json")
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			log.Printf("Localizer: Failed to read %s: %v", entry.Name(), err)
+			continue
+		}
+		var table map[string]string
+		if err := json.Unmarshal(data, &table); err != nil {
+			log.Printf("Localizer: Failed to parse %s: %v", entry.Name(), err)
+			continue
+		}
+		if l.tables[lang] == nil {
+			l.tables[lang] = make(map[string]string, len(table))
+		}
+		for key, value := range table {
+			l.tables[lang][key] = value
+		}
+		log.Printf("Localizer: Loaded %d strings for %q from %s", len(table), lang, entry.Name())
+	}
+}
+
+// T returns the translation of key for the active language, formatted with args.
+// Falls back to English and then to the key itself.
+func (l *Localizer) T(key string, args ...interface{}) string {
+	text, ok := l.tables[l.language][key]
+	if !ok {
+		text, ok = l.tables[defaultLanguage][key]
+	}
+	if !ok {
+		text = key
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(text, args...)
+	}
+	return text
+}
+
+// Languages returns the codes of all available languages, sorted
+func (l *Localizer) Languages() []string {
+	langs := make([]string, 0, len(l.tables))
+	for lang := range l.tables {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// Language returns the active language code
+func (l *Localizer) Language() string {
+	return l.language
+}
+
+// T translates key using the active localizer
+func T(key string, args ...interface{}) string {
+	return localizer.T(key, args...)
+}
+
+// InitLocalizer loads translations and selects the language saved in preferences.json.
+// It is kept there rather than under the "locale" key of the Fyne preferences so that
+// Reset to Defaults and Export State handle it like every other launcher setting.
+// Must be called before the UI is created.
+func InitLocalizer(ac *core.AppController) {
+	language := ac.LoadPreferences().Language
+	if language == "" {
+		language = defaultLanguage
+	}
+	localizer = NewLocalizer(ac.ExecDir(), language)
+}
+
+// languageDisplayName returns a human-readable name for a language code
+func languageDisplayName(lang string) string {
+	if name, ok := languageNames[lang]; ok {
+		return name
+	}
+	return lang
+}
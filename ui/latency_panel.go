@@ -0,0 +1,111 @@
+package ui
+
+import (
+	"fmt"
+	"image/color"
+	"log"
+	"sort"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"singbox-launcher/core"
+)
+
+// latencyColor returns green (<100 ms), yellow (100–500 ms) or red (>500 ms or failed)
+func latencyColor(ms int) color.Color {
+	switch {
+	case ms <= 0 || ms > 500:
+		return theme.Color(theme.ColorNameError)
+	case ms < 100:
+		return theme.Color(theme.ColorNameSuccess)
+	default:
+		return theme.Color(theme.ColorNameWarning)
+	}
+}
+
+// createLatencyPanel creates the collapsible "Proxy Latency" panel listing members of urltest groups
+func createLatencyPanel(ac *core.AppController) fyne.CanvasObject {
+	groups, err := ac.GetOutboundTags("urltest")
+	if err != nil {
+		log.Printf("createLatencyPanel: failed to get urltest groups: %v", err)
+	}
+	if len(groups) == 0 {
+		content := widget.NewLabel("No urltest outbounds in config.json")
+		return NewCollapsibleSection("Proxy Latency", content, false)
+	}
+
+	selectedGroup := groups[0]
+	rows := container.NewVBox()
+	status := widget.NewLabel("")
+
+	showLatency := func(latency map[string]int) {
+		names := make([]string, 0, len(latency))
+		for name := range latency {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		rows.RemoveAll()
+		for _, name := range names {
+			ms := latency[name]
+			text := "n/a"
+			if ms > 0 {
+				text = fmt.Sprintf("%d ms", ms)
+			}
+			value := canvas.NewText(text, latencyColor(ms))
+			value.TextStyle.Bold = true
+			rows.Add(container.NewHBox(widget.NewLabel(name), layout.NewSpacer(), value))
+		}
+		rows.Refresh()
+	}
+
+	// load runs fetch in the background and shows its result
+	load := func(action string, fetch func(group string) (map[string]int, error)) {
+		if !ac.ClashAPIEnabled {
+			ShowErrorText(ac.MainWindow, "Clash API", "API is disabled: config error")
+			return
+		}
+		group := selectedGroup
+		status.SetText(fmt.Sprintf("%s '%s'...", action, group))
+		go func() {
+			latency, err := fetch(group)
+			fyne.Do(func() {
+				if err != nil {
+					status.SetText("Error: " + err.Error())
+					return
+				}
+				showLatency(latency)
+				status.SetText(fmt.Sprintf("%d proxies in '%s'", len(latency), group))
+			})
+		}()
+	}
+
+	groupSelect := widget.NewSelect(groups, nil)
+	groupSelect.SetSelected(selectedGroup)
+	groupSelect.OnChanged = func(value string) {
+		selectedGroup = value
+		rows.RemoveAll()
+		load("Loading", ac.GetURLTestLatency)
+	}
+
+	refreshButton := widget.NewButton("Refresh", func() { load("Loading", ac.GetURLTestLatency) })
+	testButton := widget.NewButton("Test Now", func() { load("Testing", ac.TestGroupLatency) })
+
+	content := container.NewVBox(
+		container.NewHBox(widget.NewLabel("URLTest group:"), groupSelect, refreshButton, testButton),
+		rows,
+		status,
+	)
+	section := NewCollapsibleSection("Proxy Latency", content, false)
+	section.OnToggled = func(expanded bool) {
+		if expanded && len(rows.Objects) == 0 && ac.RunningState.IsRunning() {
+			load("Loading", ac.GetURLTestLatency)
+		}
+	}
+	return section
+}
@@ -0,0 +1,16 @@
+//go:build !debug
+
+package ui
+
+import "singbox-launcher/internal/debuglog"
+
+// templateLoaderLogLevel disables template loader logging in release builds
+const templateLoaderLogLevel = debuglog.LevelOff
+
+// tplLog is a no-op in release builds, so calls (and their messages) are eliminated by the compiler
+func tplLog(level debuglog.Level, msg string, args ...interface{}) {}
+
+// tplEnabled always returns false in release builds, so guarded log blocks are dead code
+func tplEnabled(level debuglog.Level) bool {
+	return false
+}
@@ -0,0 +1,72 @@
+package ui
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"singbox-launcher/core"
+)
+
+// proxyDiffPreviewCount is how many tags of each kind are listed before "Review Changes" is expanded
+const proxyDiffPreviewCount = 5
+
+// proxyDiffColumn creates a column with a colored "<title> (N)" header and the tag list
+func proxyDiffColumn(title string, textColor color.Color, tags []string, limit int) fyne.CanvasObject {
+	header := canvas.NewText(fmt.Sprintf("%s (%d)", title, len(tags)), textColor)
+	header.TextStyle.Bold = true
+
+	shown := tags
+	if limit > 0 && len(shown) > limit {
+		shown = shown[:limit]
+	}
+	text := strings.Join(shown, "\n")
+	if len(shown) < len(tags) {
+		text += fmt.Sprintf("\n... and %d more", len(tags)-len(shown))
+	}
+	if len(tags) == 0 {
+		text = "—"
+	}
+	list := widget.NewLabel(text)
+	list.Wrapping = fyne.TextWrapWord
+	return container.NewVBox(header, list)
+}
+
+// proxyDiffColumns lays out added (green), removed (red) and changed (yellow) proxies side by side
+func proxyDiffColumns(diff core.ProxyListDiff, limit int) fyne.CanvasObject {
+	return container.NewGridWithColumns(3,
+		proxyDiffColumn("Added", theme.Color(theme.ColorNameSuccess), diff.Added, limit),
+		proxyDiffColumn("Removed", theme.Color(theme.ColorNameError), diff.Removed, limit),
+		proxyDiffColumn("Changed", theme.Color(theme.ColorNameWarning), diff.Changed, limit),
+	)
+}
+
+// confirmProxyChanges shows the refreshed subscription changes and blocks until the user applies or cancels them.
+// Must not be called from the UI goroutine.
+func confirmProxyChanges(window fyne.Window, diff core.ProxyListDiff) bool {
+	result := make(chan bool, 1)
+	fyne.Do(func() {
+		fullLists := container.NewVScroll(proxyDiffColumns(diff, 0))
+		fullLists.SetMinSize(fyne.NewSize(520, 240))
+		review := widget.NewAccordion(widget.NewAccordionItem("Review Changes", fullLists))
+
+		content := container.NewVBox(
+			widget.NewLabel("The refreshed subscriptions change the proxy list:"),
+			proxyDiffColumns(diff, proxyDiffPreviewCount),
+			review,
+		)
+		d := dialog.NewCustomConfirm("Subscription Changes", "Apply", "Cancel", content, func(apply bool) {
+			result <- apply
+		}, window)
+		d.Resize(fyne.NewSize(600, 0))
+		d.Show()
+	})
+	return <-result
+}
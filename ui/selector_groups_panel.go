@@ -0,0 +1,105 @@
+package ui
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"singbox-launcher/api"
+	"singbox-launcher/core"
+)
+
+// createSelectorGroupsPanel creates the collapsible "Selector Groups" panel with a member dropdown per selector group
+func createSelectorGroupsPanel(ac *core.AppController) fyne.CanvasObject {
+	rows := container.NewVBox()
+	status := widget.NewLabel("")
+
+	groupRow := func(group api.GroupInfo) fyne.CanvasObject {
+		latencyText := canvas.NewText("", latencyColor(int(group.Delay)))
+		setLatency := func(ms int64) {
+			latencyText.Text = "n/a"
+			if ms > 0 {
+				latencyText.Text = fmt.Sprintf("%d ms", ms)
+			}
+			latencyText.Color = latencyColor(int(ms))
+			latencyText.Refresh()
+		}
+		setLatency(group.Delay)
+
+		memberSelect := widget.NewSelect(group.Members, nil)
+		memberSelect.SetSelected(group.Now)
+		memberSelect.OnChanged = func(proxy string) {
+			status.SetText(fmt.Sprintf("Switching '%s' to %s...", group.Name, proxy))
+			go func() {
+				err := ac.SwitchProxyGroup(group.Name, proxy)
+				fyne.Do(func() {
+					if err != nil {
+						ShowError(ac.MainWindow, err)
+						status.SetText("Switch error: " + err.Error())
+						return
+					}
+					status.SetText(fmt.Sprintf("Switched '%s' to %s", group.Name, proxy))
+					if group.Name == ac.GetSelectedClashGroup() {
+						ac.SetActiveProxyName(proxy)
+						if ac.ProxiesListWidget != nil {
+							ac.ProxiesListWidget.Refresh()
+						}
+						if ac.UpdateTrayMenuFunc != nil {
+							ac.UpdateTrayMenuFunc()
+						}
+					}
+					setLatency(0)
+					go func() {
+						delay, err := api.GetDelay(ac.ClashAPIBaseURL, ac.ClashAPIToken, proxy, ac.ApiLogFile)
+						if err == nil {
+							fyne.Do(func() { setLatency(delay) })
+						}
+					}()
+				})
+			}()
+		}
+
+		nameLabel := widget.NewLabel(group.Name)
+		nameLabel.TextStyle.Bold = true
+		return container.NewBorder(nil, nil, nameLabel, latencyText, memberSelect)
+	}
+
+	reload := func() {
+		if !ac.ClashAPIEnabled {
+			status.SetText("Clash API disabled due to config error")
+			return
+		}
+		status.SetText("Loading selector groups...")
+		go func() {
+			groups, err := api.GetSelectorGroups(ac.ClashAPIBaseURL, ac.ClashAPIToken, ac.ApiLogFile)
+			fyne.Do(func() {
+				if err != nil {
+					status.SetText("Error: " + err.Error())
+					return
+				}
+				rows.RemoveAll()
+				for _, group := range groups {
+					rows.Add(groupRow(group))
+				}
+				rows.Refresh()
+				status.SetText(fmt.Sprintf("%d selector groups", len(groups)))
+			})
+		}()
+	}
+
+	content := container.NewVBox(
+		widget.NewButton("Refresh Groups", func() { reload() }),
+		rows,
+		status,
+	)
+	section := NewCollapsibleSection("Selector Groups", content, false)
+	section.OnToggled = func(expanded bool) {
+		if expanded && ac.RunningState.IsRunning() {
+			reload()
+		}
+	}
+	return section
+}
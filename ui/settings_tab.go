@@ -0,0 +1,417 @@
+package ui
+
+import (
+	"fmt"
+	"image/color"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"singbox-launcher/core"
+	"singbox-launcher/internal/platform"
+)
+
+// CreateSettingsTab creates and returns the content for the "Settings" tab.
+func CreateSettingsTab(ac *core.AppController) fyne.CanvasObject {
+	return container.NewVBox(
+		createThemeBlock(ac),
+		widget.NewSeparator(),
+		createLanguageBlock(ac),
+		widget.NewSeparator(),
+		createRememberTabBlock(ac),
+		widget.NewSeparator(),
+		createJSONSchemaBlock(ac),
+		widget.NewSeparator(),
+		createLocalSubscriptionsBlock(ac),
+		widget.NewSeparator(),
+		createCoreBuildBlock(ac),
+		widget.NewSeparator(),
+		createAutoFallbackBlock(ac),
+		widget.NewSeparator(),
+		createDeviceIDBlock(ac),
+		widget.NewSeparator(),
+		createBinaryDirBlock(ac),
+		widget.NewSeparator(),
+		createWorkDirBlock(ac),
+		widget.NewSeparator(),
+		createEnvironmentBlock(ac),
+	)
+}
+
+// disableIfReadOnly disables widgets that save settings: in read-only mode
+// preferences.json is not written (UpdatePreferences fails with core.ErrReadOnly)
+func disableIfReadOnly(ac *core.AppController, widgets ...fyne.Disableable) {
+	if !ac.ReadOnly {
+		return
+	}
+	for _, w := range widgets {
+		w.Disable()
+	}
+}
+
+// createBinaryDirBlock lets the user choose the folder for downloaded binaries,
+// for installations where the bin folder is read-only
+func createBinaryDirBlock(ac *core.AppController) fyne.CanvasObject {
+	dirEntry := widget.NewEntry()
+	dirEntry.SetPlaceHolder(platform.GetBinDir(ac.ExecDir()))
+	dirEntry.SetText(ac.LoadPreferences().BinaryDir)
+
+	browseButton := widget.NewButton("Browse...", func() {
+		dialog.ShowFolderOpen(func(uri fyne.ListableURI, err error) {
+			if err != nil || uri == nil {
+				return
+			}
+			dirEntry.SetText(uri.Path())
+		}, ac.MainWindow)
+	})
+	saveButton := widget.NewButton("Save", func() {
+		if err := ac.SetBinaryDir(dirEntry.Text); err != nil {
+			ShowError(ac.MainWindow, err)
+			return
+		}
+		ShowAutoHideInfo(ac.Application, ac.MainWindow, "Saved", "Binary folder saved. Will take effect after the launcher restarts.")
+	})
+	disableIfReadOnly(ac, dirEntry, browseButton, saveButton)
+
+	return container.NewVBox(
+		widget.NewLabel("Folder for downloaded sing-box, wintun.dll and rule-sets (empty for the bin folder):"),
+		container.NewBorder(nil, nil, nil, container.NewHBox(browseButton, saveButton), dirEntry),
+	)
+}
+
+// createWorkDirBlock lets the user choose the working directory of sing-box
+// (relative paths in config.json resolve against it)
+func createWorkDirBlock(ac *core.AppController) fyne.CanvasObject {
+	dirEntry := widget.NewEntry()
+	dirEntry.SetPlaceHolder(ac.BinaryDir)
+	dirEntry.SetText(ac.LoadPreferences().SingboxWorkDir)
+
+	browseButton := widget.NewButton("Browse...", func() {
+		dialog.ShowFolderOpen(func(uri fyne.ListableURI, err error) {
+			if err != nil || uri == nil {
+				return
+			}
+			dirEntry.SetText(uri.Path())
+		}, ac.MainWindow)
+	})
+	saveButton := widget.NewButton("Save", func() {
+		if err := ac.SetSingboxWorkDir(dirEntry.Text); err != nil {
+			ShowError(ac.MainWindow, err)
+			return
+		}
+		message := "sing-box working directory saved."
+		if ac.RunningState.IsRunning() {
+			message += " Will take effect after next restart."
+		}
+		ShowAutoHideInfo(ac.Application, ac.MainWindow, "Saved", message)
+	})
+	disableIfReadOnly(ac, dirEntry, browseButton, saveButton)
+
+	return container.NewVBox(
+		widget.NewLabel("sing-box working directory (empty for the binary folder):"),
+		container.NewBorder(nil, nil, nil, container.NewHBox(browseButton, saveButton), dirEntry),
+	)
+}
+
+// envVarRow is a row of the environment variables editor
+type envVarRow struct {
+	name, value *widget.Entry
+	row         fyne.CanvasObject
+}
+
+// createEnvironmentBlock creates the editor of environment variables passed to sing-box
+// (e.g. SING_BOX_LOG_LEVEL=debug or HOME for sandboxed deployments)
+func createEnvironmentBlock(ac *core.AppController) fyne.CanvasObject {
+	rowsBox := container.NewVBox()
+	var rows []*envVarRow
+
+	addRow := func(name, value string) {
+		r := &envVarRow{name: widget.NewEntry(), value: widget.NewEntry()}
+		r.name.SetPlaceHolder("NAME")
+		r.name.SetText(name)
+		r.value.SetPlaceHolder("value")
+		r.value.SetText(value)
+		removeButton := widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+			for i, other := range rows {
+				if other == r {
+					rows = append(rows[:i], rows[i+1:]...)
+					break
+				}
+			}
+			rowsBox.Remove(r.row)
+		})
+		disableIfReadOnly(ac, r.name, r.value, removeButton)
+		r.row = container.NewBorder(nil, nil, nil, removeButton, container.NewGridWithColumns(2, r.name, r.value))
+		rows = append(rows, r)
+		rowsBox.Add(r.row)
+	}
+
+	saved := ac.LoadPreferences().EnvironmentVars
+	names := make([]string, 0, len(saved))
+	for name := range saved {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		addRow(name, saved[name])
+	}
+
+	addButton := widget.NewButtonWithIcon("Add", theme.ContentAddIcon(), func() { addRow("", "") })
+	saveButton := widget.NewButton("Save", func() {
+		vars := make(map[string]string, len(rows))
+		for _, r := range rows {
+			name := strings.TrimSpace(r.name.Text)
+			if name == "" && r.value.Text == "" {
+				continue // empty row
+			}
+			if err := core.ValidateEnvironmentVarName(name); err != nil {
+				ShowError(ac.MainWindow, err)
+				return
+			}
+			vars[name] = r.value.Text
+		}
+		if err := ac.SetEnvironmentVars(vars); err != nil {
+			ShowError(ac.MainWindow, err)
+			return
+		}
+		message := "Environment variables saved."
+		if ac.RunningState.IsRunning() {
+			message += " Will take effect after next restart."
+		}
+		ShowAutoHideInfo(ac.Application, ac.MainWindow, "Saved", message)
+	})
+	disableIfReadOnly(ac, addButton, saveButton)
+
+	return container.NewVBox(
+		widget.NewLabel("sing-box environment variables:"),
+		rowsBox,
+		container.NewHBox(addButton, saveButton),
+	)
+}
+
+// createCoreBuildBlock lets the user choose between QUIC variants of sing-box release archives
+func createCoreBuildBlock(ac *core.AppController) fyne.CanvasObject {
+	check := widget.NewCheck("Prefer sing-box builds without QUIC (if a release offers both)", nil)
+	check.SetChecked(ac.LoadPreferences().CoreWithoutQUIC)
+	check.OnChanged = func(checked bool) {
+		if err := ac.UpdatePreferences(func(p *core.Preferences) { p.CoreWithoutQUIC = checked }); err != nil {
+			log.Printf("settingsTab: Failed to save QUIC build preference: %v", err)
+		}
+	}
+	disableIfReadOnly(ac, check)
+	return check
+}
+
+// createAutoFallbackBlock turns on switching selector groups away from slow proxies (see AppController.SetAutoFallback)
+func createAutoFallbackBlock(ac *core.AppController) fyne.CanvasObject {
+	check := widget.NewCheck(fmt.Sprintf("Switch to the fastest proxy when the selected one is slower than %d ms", ac.FallbackThresholdMs), nil)
+	check.SetChecked(ac.IsAutoFallbackEnabled())
+	check.OnChanged = func(checked bool) {
+		ac.SetAutoFallback(checked)
+		if err := ac.UpdatePreferences(func(p *core.Preferences) { p.AutoFallback = checked }); err != nil {
+			log.Printf("settingsTab: Failed to save auto fallback preference: %v", err)
+		}
+	}
+	disableIfReadOnly(ac, check)
+	return check
+}
+
+// createRememberTabBlock turns off reopening the launcher on the tab it was closed on
+func createRememberTabBlock(ac *core.AppController) fyne.CanvasObject {
+	check := widget.NewCheck("Remember last tab", nil)
+	check.SetChecked(!ac.LoadPreferences().ForgetLastTab)
+	check.OnChanged = func(checked bool) {
+		if err := ac.UpdatePreferences(func(p *core.Preferences) {
+			p.ForgetLastTab = !checked
+			if !checked {
+				p.LastTabIndex = 0
+			}
+		}); err != nil {
+			log.Printf("settingsTab: Failed to save remember last tab preference: %v", err)
+		}
+	}
+	disableIfReadOnly(ac, check)
+	return check
+}
+
+// createDeviceIDBlock lets the user opt in to sending the anonymous device ID (off by default)
+func createDeviceIDBlock(ac *core.AppController) fyne.CanvasObject {
+	check := widget.NewCheck("Share anonymous device ID for diagnostics", nil)
+	check.SetChecked(ac.LoadPreferences().ShareDeviceID)
+	check.OnChanged = func(checked bool) {
+		if err := ac.UpdatePreferences(func(p *core.Preferences) { p.ShareDeviceID = checked }); err != nil {
+			log.Printf("settingsTab: Failed to save device ID preference: %v", err)
+		}
+	}
+	disableIfReadOnly(ac, check)
+	return check
+}
+
+// createLocalSubscriptionsBlock sets the extra folder file:// subscription sources may be read from
+func createLocalSubscriptionsBlock(ac *core.AppController) fyne.CanvasObject {
+	dirEntry := widget.NewEntry()
+	dirEntry.SetPlaceHolder("Launcher folder only")
+	dirEntry.SetText(ac.LoadPreferences().LocalSubscriptionsDir)
+	// Saved shortly after the user stops typing
+	save := debounce(500*time.Millisecond, func() {
+		fyne.Do(func() {
+			dir := strings.TrimSpace(dirEntry.Text)
+			if err := ac.UpdatePreferences(func(p *core.Preferences) { p.LocalSubscriptionsDir = dir }); err != nil {
+				log.Printf("settingsTab: Failed to save local subscriptions folder: %v", err)
+			}
+		})
+	})
+	dirEntry.OnChanged = func(string) { save() }
+
+	browseButton := widget.NewButton("Browse...", func() {
+		dialog.ShowFolderOpen(func(uri fyne.ListableURI, err error) {
+			if err != nil || uri == nil {
+				return
+			}
+			dirEntry.SetText(uri.Path())
+		}, ac.MainWindow)
+	})
+
+	disableIfReadOnly(ac, dirEntry, browseButton)
+
+	return container.NewVBox(
+		widget.NewLabel("Folder for local subscriptions (file:// sources):"),
+		container.NewBorder(nil, nil, nil, browseButton, dirEntry),
+	)
+}
+
+// createJSONSchemaBlock lets the user download the sing-box JSON schema used for field hints in the Config Wizard
+func createJSONSchemaBlock(ac *core.AppController) fyne.CanvasObject {
+	urlEntry := widget.NewEntry()
+	urlEntry.SetPlaceHolder("https://.../sing-box.schema.json")
+	urlEntry.SetText(ac.LoadPreferences().JSONSchemaURL)
+
+	status := widget.NewLabel("Not downloaded, the Config Wizard shows no field hints")
+	if ac.HasJSONSchema() {
+		status.SetText("Schema cached")
+	}
+
+	var downloadButton *widget.Button
+	downloadButton = widget.NewButton("Download", func() {
+		schemaURL := strings.TrimSpace(urlEntry.Text)
+		if err := ac.UpdatePreferences(func(p *core.Preferences) { p.JSONSchemaURL = schemaURL }); err != nil {
+			log.Printf("settingsTab: Failed to save schema URL: %v", err)
+		}
+		downloadButton.Disable()
+		status.SetText("Downloading...")
+		go func() {
+			err := ac.DownloadJSONSchema(schemaURL)
+			fyne.Do(func() {
+				downloadButton.Enable()
+				if err != nil {
+					status.SetText("Download failed")
+					ShowError(ac.MainWindow, err)
+					return
+				}
+				status.SetText("Schema cached, reopen the Config Wizard to use it")
+			})
+		}()
+	})
+
+	disableIfReadOnly(ac, urlEntry, downloadButton)
+
+	return container.NewVBox(
+		widget.NewLabel("sing-box JSON schema (field hints in the Config Wizard):"),
+		container.NewBorder(nil, nil, nil, downloadButton, urlEntry),
+		status,
+	)
+}
+
+// createLanguageBlock creates the UI language selector
+func createLanguageBlock(ac *core.AppController) fyne.CanvasObject {
+	languages := localizer.Languages()
+	options := make([]string, 0, len(languages))
+	codes := make(map[string]string, len(languages))
+	for _, lang := range languages {
+		name := languageDisplayName(lang)
+		options = append(options, name)
+		codes[name] = lang
+	}
+
+	languageSelect := widget.NewSelect(options, nil)
+	languageSelect.SetSelected(languageDisplayName(localizer.Language()))
+	languageSelect.OnChanged = func(selected string) {
+		lang := codes[selected]
+		if err := ac.UpdatePreferences(func(p *core.Preferences) { p.Language = lang }); err != nil {
+			log.Printf("settingsTab: Failed to save language: %v", err)
+			return
+		}
+		ShowInfo(ac.MainWindow, T("settings.language.title"), T("settings.language.restart"))
+	}
+
+	disableIfReadOnly(ac, languageSelect)
+
+	return container.NewHBox(widget.NewLabel(T("settings.language")), languageSelect)
+}
+
+// createThemeBlock creates the theme selector and primary colour picker
+func createThemeBlock(ac *core.AppController) fyne.CanvasObject {
+	themeOptions := []string{"System", "Light", "Dark"}
+	themeNames := map[string]string{"System": themeSystem, "Light": themeLight, "Dark": themeDark}
+
+	prefs := ac.LoadPreferences()
+
+	themeSelect := widget.NewSelect(themeOptions, nil)
+	switch prefs.Theme {
+	case themeLight:
+		themeSelect.SetSelected("Light")
+	case themeDark:
+		themeSelect.SetSelected("Dark")
+	default:
+		themeSelect.SetSelected("System")
+	}
+	// Set after the initial selection so opening the tab doesn't rewrite preferences
+	themeSelect.OnChanged = func(selected string) {
+		name := themeNames[selected]
+		if err := ac.UpdatePreferences(func(p *core.Preferences) { p.Theme = name }); err != nil {
+			log.Printf("settingsTab: Failed to save theme: %v", err)
+		}
+		ApplyTheme(ac)
+	}
+
+	swatch := canvas.NewRectangle(theme.Color(theme.ColorNamePrimary))
+	swatch.SetMinSize(fyne.NewSize(24, 24))
+	if c, err := parseHexColor(prefs.PrimaryColor); err == nil {
+		swatch.FillColor = c
+	}
+
+	setPrimaryColor := func(hex string, c color.Color) {
+		if err := ac.UpdatePreferences(func(p *core.Preferences) { p.PrimaryColor = hex }); err != nil {
+			log.Printf("settingsTab: Failed to save primary colour: %v", err)
+		}
+		ApplyTheme(ac)
+		swatch.FillColor = c
+		swatch.Refresh()
+	}
+
+	pickColorButton := widget.NewButton("Choose...", func() {
+		picker := dialog.NewColorPicker("Primary Colour", "Accent colour for buttons and highlights", func(c color.Color) {
+			setPrimaryColor(formatHexColor(c), c)
+		}, ac.MainWindow)
+		picker.Advanced = true
+		picker.Show()
+	})
+	resetColorButton := widget.NewButton("Reset", func() {
+		setPrimaryColor("", theme.DefaultTheme().Color(theme.ColorNamePrimary, ac.Application.Settings().ThemeVariant()))
+	})
+
+	disableIfReadOnly(ac, themeSelect, pickColorButton, resetColorButton)
+
+	return container.NewVBox(
+		container.NewHBox(widget.NewLabel("Theme:"), themeSelect),
+		container.NewHBox(widget.NewLabel("Primary colour:"), swatch, pickColorButton, resetColorButton),
+	)
+}
@@ -0,0 +1,19 @@
+package ui
+
+import (
+	"log/slog"
+	"os"
+)
+
+// templateLoaderLogger receives the template loader messages in debug builds (see debug_template.go).
+// Messages carry structured fields such as file, section and blockIndex.
+var templateLoaderLogger *slog.Logger
+
+func init() {
+	templateLoaderLogger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+}
+
+// SetTemplateLoaderLogger replaces the template loader logger, e.g. to capture messages in tests
+func SetTemplateLoaderLogger(l *slog.Logger) {
+	templateLoaderLogger = l
+}
@@ -0,0 +1,79 @@
+package ui
+
+import (
+	"fmt"
+	"image/color"
+	"log"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/theme"
+
+	"singbox-launcher/core"
+)
+
+// Theme names stored in preferences.json
+const (
+	themeSystem = "system"
+	themeLight  = "light"
+	themeDark   = "dark"
+)
+
+// CustomTheme wraps the default Fyne theme, optionally forcing the light or dark
+// variant and overriding the primary colour
+type CustomTheme struct {
+	fyne.Theme
+	variant fyne.ThemeVariant
+	forced  bool        // false to follow the system variant
+	primary color.Color // nil for the default primary colour
+}
+
+// NewCustomTheme creates a theme for the given theme name and "#rrggbb" primary colour
+func NewCustomTheme(name, primaryHex string) *CustomTheme {
+	t := &CustomTheme{Theme: theme.DefaultTheme()}
+	switch name {
+	case themeLight:
+		t.variant, t.forced = theme.VariantLight, true
+	case themeDark:
+		t.variant, t.forced = theme.VariantDark, true
+	}
+	if primaryHex != "" {
+		if c, err := parseHexColor(primaryHex); err == nil {
+			t.primary = c
+		} else {
+			log.Printf("NewCustomTheme: Ignoring invalid primary colour %q: %v", primaryHex, err)
+		}
+	}
+	return t
+}
+
+// Color returns the colour for name, applying the forced variant and primary colour override
+func (t *CustomTheme) Color(name fyne.ThemeColorName, variant fyne.ThemeVariant) color.Color {
+	if t.forced {
+		variant = t.variant
+	}
+	if t.primary != nil && name == theme.ColorNamePrimary {
+		return t.primary
+	}
+	return t.Theme.Color(name, variant)
+}
+
+// ApplyTheme applies the theme settings from preferences to the application
+func ApplyTheme(ac *core.AppController) {
+	prefs := ac.LoadPreferences()
+	ac.Application.Settings().SetTheme(NewCustomTheme(prefs.Theme, prefs.PrimaryColor))
+}
+
+// parseHexColor parses a "#rrggbb" colour
+func parseHexColor(s string) (color.Color, error) {
+	var r, g, b uint8
+	if _, err := fmt.Sscanf(s, "#%02x%02x%02x", &r, &g, &b); err != nil {
+		return nil, err
+	}
+	return color.NRGBA{R: r, G: g, B: b, A: 0xff}, nil
+}
+
+// formatHexColor formats a colour as "#rrggbb"
+func formatHexColor(c color.Color) string {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	return fmt.Sprintf("#%02x%02x%02x", n.R, n.G, n.B)
+}
@@ -1,10 +1,17 @@
 package ui
 
 import (
+	"fmt"
 	"log"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/storage"
 	"fyne.io/fyne/v2/widget"
 
 	"singbox-launcher/core"
@@ -14,7 +21,7 @@ import (
 // CreateToolsTab creates and returns the content for the "Tools" tab.
 func CreateToolsTab(ac *core.AppController) fyne.CanvasObject {
 	logsButton := widget.NewButton("Open Logs Folder", func() {
-		logsDir := platform.GetLogsDir(ac.ExecDir)
+		logsDir := platform.GetLogsDir(ac.ExecDir())
 		if err := platform.OpenFolder(logsDir); err != nil {
 			log.Printf("toolsTab: Failed to open logs folder: %v", err)
 			ShowError(ac.MainWindow, err)
@@ -22,7 +29,7 @@ func CreateToolsTab(ac *core.AppController) fyne.CanvasObject {
 	})
 
 	configButton := widget.NewButton("Open Config Folder", func() {
-		binDir := platform.GetBinDir(ac.ExecDir)
+		binDir := platform.GetBinDir(ac.ExecDir())
 		if err := platform.OpenFolder(binDir); err != nil {
 			log.Printf("toolsTab: Failed to open config folder: %v", err)
 			ShowError(ac.MainWindow, err)
@@ -34,7 +41,7 @@ func CreateToolsTab(ac *core.AppController) fyne.CanvasObject {
 			_ = platform.KillProcess(processName)
 			fyne.Do(func() {
 				ShowAutoHideInfo(ac.Application, ac.MainWindow, "Kill", "Sing-Box killed if running.")
-				ac.RunningState.Set(false)
+				ac.RunningState.SetRunning(false)
 			})
 		}()
 	})
@@ -43,12 +50,457 @@ func CreateToolsTab(ac *core.AppController) fyne.CanvasObject {
 		ac.CheckForUpdates()
 	})
 
-	return container.NewVBox(
+	// Scrollable: snapshots and rule-sets lists make the tab taller than the default window
+	return container.NewVScroll(container.NewVBox(
 		logsButton,
 		configButton,
 		killButton,
 		widget.NewSeparator(),
 		checkUpdatesButton,
+		widget.NewSeparator(),
+		createSnapshotsBlock(ac),
+		widget.NewSeparator(),
+		createRuleSetsBlock(ac),
+		widget.NewSeparator(),
+		createBackupBlock(ac),
+		widget.NewSeparator(),
+		createDownloadHistoryBlock(ac),
+		widget.NewSeparator(),
+		createResetBlock(ac),
+	))
+}
+
+// createDownloadHistoryBlock shows the sing-box and wintun.dll downloads recorded by core, newest first
+func createDownloadHistoryBlock(ac *core.AppController) fyne.CanvasObject {
+	table := container.NewGridWithColumns(6)
+
+	reload := func() {
+		table.RemoveAll()
+		for _, title := range []string{"Time", "Resource", "Version", "Result", "Duration", "Size"} {
+			header := widget.NewLabel(title)
+			header.TextStyle.Bold = true
+			table.Add(header)
+		}
+		history := ac.LoadDownloadHistory()
+		for i := len(history) - 1; i >= 0; i-- {
+			entry := history[i]
+			result := "OK"
+			if !entry.Success {
+				result = "Failed"
+			}
+			size := "—"
+			if entry.BytesDownloaded > 0 {
+				size = core.FormatBytesUtil(entry.BytesDownloaded)
+			}
+			table.Add(widget.NewLabel(entry.Timestamp.Format("2006-01-02 15:04")))
+			table.Add(widget.NewLabel(entry.Resource))
+			table.Add(widget.NewLabel(entry.Version))
+			table.Add(widget.NewLabel(result))
+			table.Add(widget.NewLabel(fmt.Sprintf("%.1f s", entry.DurationSeconds)))
+			table.Add(widget.NewLabel(size))
+		}
+		table.Refresh()
+	}
+	reload()
+
+	ac.Events.Subscribe(func(event core.Event) {
+		if _, ok := event.(core.DownloadFinished); ok {
+			fyne.Do(reload)
+		}
+	})
+
+	return container.NewVBox(
+		widget.NewLabel("Download history (sing-box, wintun.dll):"),
+		table,
+	)
+}
+
+// createResetBlock creates the "Reset to Defaults" button: deletes preferences and launcher state
+func createResetBlock(ac *core.AppController) fyne.CanvasObject {
+	resetButton := widget.NewButton("Reset to Defaults...", func() {
+		confirmReset(ac)
+	})
+	resetButton.Importance = widget.DangerImportance
+	if ac.ReadOnly {
+		resetButton.Disable()
+	}
+	return container.NewVBox(
+		widget.NewLabel("Reset the launcher settings (config.json is kept):"),
+		container.NewHBox(resetButton),
+	)
+}
+
+// confirmReset lists what "Reset to Defaults" deletes and keeps, and resets after confirmation
+func confirmReset(ac *core.AppController) {
+	files := ac.ResetFiles()
+	if len(files) == 0 {
+		ShowInfo(ac.MainWindow, "Reset to Defaults", "The launcher already uses the default settings.")
+		return
+	}
+	names := make([]string, len(files))
+	for i, path := range files {
+		names[i] = path
+		if rel, err := filepath.Rel(ac.ExecDir(), path); err == nil {
+			names[i] = rel
+		}
+	}
+
+	message := fmt.Sprintf("The following will be deleted:\n\n• %s\n\n"+
+		"Kept: config.json, config snapshots, sing-box and wintun.dll binaries, rule-sets, logs.\n"+
+		"sing-box is stopped if it is running.",
+		strings.Join(names, "\n• "))
+	ShowConfirm(ac.MainWindow, "Reset to Defaults", message, func(ok bool) {
+		if !ok {
+			return
+		}
+		go func() {
+			err := ac.ResetToDefaults()
+			fyne.Do(func() {
+				if err != nil {
+					log.Printf("toolsTab: Failed to reset to defaults: %v", err)
+					ShowError(ac.MainWindow, err)
+					return
+				}
+				ShowAutoHideInfo(ac.Application, ac.MainWindow, "Reset to Defaults", "Settings were reset to defaults.")
+			})
+		}()
+	})
+}
+
+// createBackupBlock creates the "Backup" section: export/import of the full app state as a ZIP
+func createBackupBlock(ac *core.AppController) fyne.CanvasObject {
+	exportButton := widget.NewButton("Export State...", func() {
+		saveDialog := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+			if err != nil {
+				ShowError(ac.MainWindow, err)
+				return
+			}
+			if writer == nil {
+				return // Cancelled
+			}
+			destPath := writer.URI().Path()
+			writer.Close()
+			go func() {
+				err := ac.ExportFullState(destPath)
+				fyne.Do(func() {
+					if err != nil {
+						log.Printf("toolsTab: Failed to export state: %v", err)
+						ShowError(ac.MainWindow, err)
+						return
+					}
+					ShowInfo(ac.MainWindow, "Export State", "State exported to:\n"+destPath)
+				})
+			}()
+		}, ac.MainWindow)
+		saveDialog.SetFileName(fmt.Sprintf("singbox-launcher-backup-%s.zip", time.Now().Format("2006-01-02")))
+		saveDialog.SetFilter(storage.NewExtensionFileFilter([]string{".zip"}))
+		saveDialog.Show()
+	})
+
+	importButton := widget.NewButton("Import State...", func() {
+		openDialog := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+			if err != nil {
+				ShowError(ac.MainWindow, err)
+				return
+			}
+			if reader == nil {
+				return // Cancelled
+			}
+			srcPath := reader.URI().Path()
+			reader.Close()
+			confirmStateImport(ac, srcPath)
+		}, ac.MainWindow)
+		openDialog.SetFilter(storage.NewExtensionFileFilter([]string{".zip"}))
+		openDialog.Show()
+	})
+	if ac.ReadOnly {
+		importButton.Disable()
+	}
+
+	return container.NewVBox(
+		widget.NewLabel("Backup (config, preferences, selector choices, snapshots):"),
+		container.NewHBox(exportButton, importButton),
+	)
+}
+
+// confirmStateImport shows what a state archive contains and imports it after confirmation
+func confirmStateImport(ac *core.AppController, srcPath string) {
+	manifest, err := ac.ReadStateArchiveManifest(srcPath)
+	if err != nil {
+		log.Printf("toolsTab: Failed to read state archive: %v", err)
+		ShowError(ac.MainWindow, err)
+		return
+	}
+
+	message := fmt.Sprintf("The following files will be replaced:\n\n• %s\n\n"+
+		"sing-box is stopped during the import and restarted if it is running.\n"+
+		"Encrypted subscription credentials only work on the machine they were saved on.",
+		strings.Join(manifest, "\n• "))
+	dialog.ShowConfirm("Import State", message, func(ok bool) {
+		if !ok {
+			return
+		}
+		go func() {
+			err := ac.ImportFullState(srcPath)
+			fyne.Do(func() {
+				if err != nil {
+					log.Printf("toolsTab: Failed to import state: %v", err)
+					ShowError(ac.MainWindow, err)
+					return
+				}
+				ShowInfo(ac.MainWindow, "Import State", fmt.Sprintf("Imported %d file(s). Restart the launcher to apply imported preferences.", len(manifest)))
+			})
+		}()
+	}, ac.MainWindow)
+}
+
+// createSnapshotsBlock creates the "Snapshots" list with Save/Restore/Delete actions
+func createSnapshotsBlock(ac *core.AppController) fyne.CanvasObject {
+	var snapshots []core.Snapshot
+
+	statusLabel := widget.NewLabel("")
+	statusLabel.Wrapping = fyne.TextWrapWord
+
+	var snapshotsList *widget.List
+
+	reloadSnapshots := func() {
+		list, err := ac.ListSnapshots()
+		if err != nil {
+			log.Printf("toolsTab: Failed to list snapshots: %v", err)
+			statusLabel.SetText("Error: " + err.Error())
+			return
+		}
+		snapshots = list
+		if len(snapshots) == 0 {
+			statusLabel.SetText("No snapshots yet.")
+		} else {
+			statusLabel.SetText(fmt.Sprintf("%d snapshot(s)", len(snapshots)))
+		}
+		snapshotsList.Refresh()
+	}
+
+	snapshotsList = widget.NewList(
+		func() int { return len(snapshots) },
+		func() fyne.CanvasObject {
+			return container.NewHBox(
+				widget.NewLabel("Snapshot"),
+				layout.NewSpacer(),
+				widget.NewButton("Restore", nil),
+				widget.NewButton("Delete", nil),
+			)
+		},
+		func(id widget.ListItemID, o fyne.CanvasObject) {
+			if id < 0 || id >= len(snapshots) {
+				return
+			}
+			snapshot := snapshots[id]
+			row := o.(*fyne.Container)
+			label := row.Objects[0].(*widget.Label)
+			restoreButton := row.Objects[2].(*widget.Button)
+			deleteButton := row.Objects[3].(*widget.Button)
+			if ac.ReadOnly {
+				restoreButton.Disable()
+				deleteButton.Disable()
+			}
+
+			title := snapshot.Label
+			if title == "" {
+				title = "(no label)"
+			}
+			label.SetText(fmt.Sprintf("%s — %s", snapshot.CreatedAt.Local().Format("2006-01-02 15:04"), title))
+
+			restoreButton.OnTapped = func() {
+				message := fmt.Sprintf("Restore config.json from snapshot \"%s\"?\n\nSing-box will be restarted if it is running.", title)
+				ShowConfirm(ac.MainWindow, "Restore Snapshot", message, func(ok bool) {
+					if !ok {
+						return
+					}
+					go func() {
+						err := ac.RestoreSnapshot(snapshot.ID)
+						fyne.Do(func() {
+							if err != nil {
+								log.Printf("toolsTab: Failed to restore snapshot: %v", err)
+								ShowError(ac.MainWindow, err)
+								return
+							}
+							ShowAutoHideInfo(ac.Application, ac.MainWindow, "Snapshots", "Snapshot restored.")
+						})
+					}()
+				})
+			}
+			deleteButton.OnTapped = func() {
+				ShowConfirm(ac.MainWindow, "Delete Snapshot", fmt.Sprintf("Delete snapshot \"%s\"?", title), func(ok bool) {
+					if !ok {
+						return
+					}
+					if err := ac.DeleteSnapshot(snapshot.ID); err != nil {
+						log.Printf("toolsTab: Failed to delete snapshot: %v", err)
+						ShowError(ac.MainWindow, err)
+					}
+				})
+			}
+		},
+	)
+
+	saveButton := widget.NewButton("Save Snapshot", func() {
+		labelEntry := widget.NewEntry()
+		labelEntry.SetPlaceHolder("e.g. before switching provider")
+		dialog.ShowForm("Save Snapshot", "Save", "Cancel",
+			[]*widget.FormItem{widget.NewFormItem("Label", labelEntry)},
+			func(ok bool) {
+				if !ok {
+					return
+				}
+				if err := ac.SaveSnapshot(labelEntry.Text); err != nil {
+					log.Printf("toolsTab: Failed to save snapshot: %v", err)
+					ShowError(ac.MainWindow, err)
+				}
+			}, ac.MainWindow)
+	})
+	if ac.ReadOnly {
+		saveButton.Disable()
+	}
+
+	// Refresh the list whenever snapshots change (wizard save, restore, delete)
+	ac.UpdateSnapshotsFunc = func() {
+		fyne.Do(reloadSnapshots)
+	}
+	reloadSnapshots()
+
+	scrollContainer := container.NewVScroll(snapshotsList)
+	scrollContainer.SetMinSize(fyne.NewSize(0, 150))
+
+	return container.NewVBox(
+		container.NewHBox(widget.NewLabel("Snapshots:"), layout.NewSpacer(), saveButton),
+		scrollContainer,
+		statusLabel,
 	)
 }
 
+
+// createRuleSetsBlock creates the "Rule Sets" list of files in bin/rule-sets with download/update actions
+func createRuleSetsBlock(ac *core.AppController) fyne.CanvasObject {
+	rows := container.NewVBox()
+	statusLabel := widget.NewLabel("")
+	statusLabel.Wrapping = fyne.TextWrapWord
+
+	// Progress of running downloads by file name, kept across list refreshes
+	downloading := make(map[string]float64)
+	progressBars := make(map[string]*widget.ProgressBar)
+
+	var reloadRuleSets func()
+
+	startDownload := func(sourceURL, name string) {
+		if _, busy := downloading[name]; busy {
+			return
+		}
+		downloading[name] = 0
+		reloadRuleSets()
+
+		progressChan := make(chan core.DownloadProgress, 10)
+		go func() {
+			err := ac.DownloadRuleSet(sourceURL, filepath.Join(ac.GetRuleSetsDir(), name), progressChan)
+			close(progressChan)
+			fyne.Do(func() {
+				delete(downloading, name)
+				reloadRuleSets()
+				if err != nil {
+					log.Printf("toolsTab: Failed to download rule-set %s: %v", name, err)
+					ShowError(ac.MainWindow, err)
+					return
+				}
+				statusLabel.SetText(fmt.Sprintf("%s downloaded.", name))
+			})
+		}()
+		go func() {
+			for progress := range progressChan {
+				value := float64(progress.Progress) / 100.0
+				fyne.Do(func() {
+					if _, busy := downloading[name]; !busy {
+						return
+					}
+					downloading[name] = value
+					if bar := progressBars[name]; bar != nil {
+						bar.SetValue(value)
+					}
+				})
+			}
+		}()
+	}
+
+	ruleSetRow := func(name, details, sourceURL string) fyne.CanvasObject {
+		label := widget.NewLabel(name)
+		label.TextStyle.Bold = true
+		row := container.NewHBox(label, widget.NewLabel(details), layout.NewSpacer())
+		if value, busy := downloading[name]; busy {
+			bar := widget.NewProgressBar()
+			bar.SetValue(value)
+			progressBars[name] = bar
+			return container.NewVBox(row, bar)
+		}
+		if sourceURL != "" && !ac.ReadOnly {
+			row.Add(widget.NewButton("Update", func() { startDownload(sourceURL, name) }))
+		}
+		return row
+	}
+
+	reloadRuleSets = func() {
+		ruleSets, err := ac.ListRuleSets()
+		if err != nil {
+			log.Printf("toolsTab: Failed to list rule-sets: %v", err)
+			statusLabel.SetText("Error: " + err.Error())
+			return
+		}
+		rows.RemoveAll()
+		progressBars = make(map[string]*widget.ProgressBar)
+		listed := make(map[string]bool)
+		for _, ruleSet := range ruleSets {
+			listed[ruleSet.Name] = true
+			details := fmt.Sprintf("%s, %s", core.FormatBytesUtil(ruleSet.Size), ruleSet.ModTime.Format("2006-01-02"))
+			rows.Add(ruleSetRow(ruleSet.Name, details, ruleSet.SourceURL))
+		}
+		// New files being downloaded for the first time
+		for name := range downloading {
+			if !listed[name] {
+				rows.Add(ruleSetRow(name, "downloading...", ""))
+			}
+		}
+		rows.Refresh()
+		if len(ruleSets) == 0 {
+			statusLabel.SetText(fmt.Sprintf("No rule-sets in %s", ac.GetRuleSetsDir()))
+		} else {
+			statusLabel.SetText(fmt.Sprintf("%d rule-set(s)", len(ruleSets)))
+		}
+	}
+
+	addButton := widget.NewButton("Add Rule Set", func() {
+		urlEntry := widget.NewEntry()
+		urlEntry.SetPlaceHolder("https://.../geosite-example.srs")
+		dialog.ShowForm("Add Rule Set", "Download", "Cancel",
+			[]*widget.FormItem{widget.NewFormItem("URL", urlEntry)},
+			func(ok bool) {
+				if !ok {
+					return
+				}
+				sourceURL := strings.TrimSpace(urlEntry.Text)
+				name, err := core.RuleSetFileName(sourceURL)
+				if err != nil {
+					ShowError(ac.MainWindow, err)
+					return
+				}
+				startDownload(sourceURL, name)
+			}, ac.MainWindow)
+	})
+	refreshButton := widget.NewButton("Refresh", func() { reloadRuleSets() })
+	if ac.ReadOnly {
+		addButton.Hide()
+	}
+
+	reloadRuleSets()
+
+	return container.NewVBox(
+		container.NewHBox(widget.NewLabel("Rule Sets:"), layout.NewSpacer(), refreshButton, addButton),
+		rows,
+		statusLabel,
+	)
+}
@@ -0,0 +1,178 @@
+package ui
+
+import (
+	"log"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/driver"
+
+	"singbox-launcher/core"
+	"singbox-launcher/internal/platform"
+)
+
+const (
+	// geometrySaveDelay - a resize or move is saved once the window stays put for this long
+	geometrySaveDelay = 500 * time.Millisecond
+	// windowMovePollInterval - how often WatchWindowGeometry checks the window position
+	windowMovePollInterval = 2 * time.Second
+)
+
+// geometryField selects where in preferences.json the geometry of a window is stored
+type geometryField func(p *core.Preferences) **core.WindowGeometry
+
+func mainWindowGeometry(p *core.Preferences) **core.WindowGeometry {
+	return &p.Window
+}
+
+func diagnosticsWindowGeometry(p *core.Preferences) **core.WindowGeometry {
+	return &p.DiagnosticsWindow
+}
+
+// SaveWindowGeometry stores the size and position of the main window w in preferences.json
+func SaveWindowGeometry(ac *core.AppController, w fyne.Window) {
+	saveWindowGeometry(ac, w, mainWindowGeometry)
+}
+
+// RestoreWindowGeometry resizes the main window w to the saved size and moves it to the saved
+// position once the window is shown. The position is skipped if it is not on a connected screen.
+// Returns false if there is no saved geometry.
+func RestoreWindowGeometry(ac *core.AppController, w fyne.Window) bool {
+	return restoreWindowGeometry(ac, w, mainWindowGeometry)
+}
+
+// WatchWindowGeometry saves the geometry of the main window w shortly after it is resized or moved,
+// so it survives a crash or a forced exit. Call after the content of w is set.
+// Fyne reports neither resizes nor moves of a window: resizes are seen by the layout of the
+// content, the position is polled where the platform can read it (platform.WindowPositionSupported).
+func WatchWindowGeometry(ac *core.AppController, w fyne.Window) {
+	save := debounce(geometrySaveDelay, func() {
+		fyne.Do(func() { SaveWindowGeometry(ac, w) })
+	})
+	w.SetContent(container.New(&geometryWatchLayout{onResize: save}, w.Content()))
+
+	if !platform.WindowPositionSupported {
+		return
+	}
+	ac.GoBackground(func() {
+		ticker := time.NewTicker(windowMovePollInterval)
+		defer ticker.Stop()
+		// Only touched on the main thread, inside fyne.Do
+		var lastX, lastY int
+		var known bool
+		for {
+			select {
+			case <-ac.ShuttingDown():
+				return
+			case <-ticker.C:
+			}
+			// Not DoAndWait: GracefulExit waits for background tasks on the main thread
+			fyne.Do(func() {
+				x, y, ok := platform.GetWindowPosition(nativeWindowHandle(w))
+				if !ok {
+					return
+				}
+				if known && (x != lastX || y != lastY) {
+					save()
+				}
+				lastX, lastY, known = x, y, true
+			})
+		}
+	})
+}
+
+// geometryWatchLayout stacks the objects like container.NewStack and calls onResize when the size changes
+type geometryWatchLayout struct {
+	onResize func()
+	size     fyne.Size
+}
+
+func (l *geometryWatchLayout) Layout(objects []fyne.CanvasObject, size fyne.Size) {
+	for _, o := range objects {
+		o.Move(fyne.NewPos(0, 0))
+		o.Resize(size)
+	}
+	if size != l.size {
+		known := l.size != fyne.Size{}
+		l.size = size
+		// The first layout is the initial size, not a change by the user
+		if known {
+			l.onResize()
+		}
+	}
+}
+
+func (l *geometryWatchLayout) MinSize(objects []fyne.CanvasObject) fyne.Size {
+	min := fyne.NewSize(0, 0)
+	for _, o := range objects {
+		min = min.Max(o.MinSize())
+	}
+	return min
+}
+
+// saveWindowGeometry stores the size and position of w in the given preferences field
+func saveWindowGeometry(ac *core.AppController, w fyne.Window, field geometryField) {
+	if ac.ReadOnly {
+		return // preferences.json is not written in read-only mode
+	}
+	size := w.Canvas().Size()
+	if size.Width <= 0 || size.Height <= 0 {
+		return
+	}
+	x, y, hasPos := platform.GetWindowPosition(nativeWindowHandle(w))
+
+	err := ac.UpdatePreferences(func(p *core.Preferences) {
+		geometry := core.WindowGeometry{Width: size.Width, Height: size.Height}
+		if hasPos {
+			geometry.X, geometry.Y, geometry.HasPos = x, y, true
+		} else if saved := *field(p); saved != nil {
+			// Window is already closed or the platform can't report it, keep the last known position
+			geometry.X, geometry.Y, geometry.HasPos = saved.X, saved.Y, saved.HasPos
+		}
+		*field(p) = &geometry
+	})
+	if err != nil {
+		log.Printf("SaveWindowGeometry: %v", err)
+	}
+}
+
+// restoreWindowGeometry applies the geometry stored in the given preferences field to w
+func restoreWindowGeometry(ac *core.AppController, w fyne.Window, field geometryField) bool {
+	prefs := ac.LoadPreferences()
+	geometry := *field(&prefs)
+	if geometry == nil || geometry.Width <= 0 || geometry.Height <= 0 {
+		return false
+	}
+	w.Resize(fyne.NewSize(geometry.Width, geometry.Height))
+	if !geometry.HasPos {
+		return false
+	}
+
+	// The native window only exists after Show, so move it a bit later
+	go func() {
+		time.Sleep(300 * time.Millisecond)
+		fyne.Do(func() {
+			if !platform.SetWindowPosition(nativeWindowHandle(w), geometry.X, geometry.Y) {
+				log.Printf("RestoreWindowGeometry: Saved position (%d, %d) is not on a connected screen, centering", geometry.X, geometry.Y)
+				w.CenterOnScreen()
+			}
+		})
+	}()
+	return true
+}
+
+// nativeWindowHandle returns the native handle of w, or 0 if not available
+func nativeWindowHandle(w fyne.Window) uintptr {
+	native, ok := w.(driver.NativeWindow)
+	if !ok {
+		return 0
+	}
+	var handle uintptr
+	native.RunNative(func(context any) {
+		if ctx, ok := context.(driver.WindowsWindowContext); ok {
+			handle = ctx.HWND
+		}
+	})
+	return handle
+}
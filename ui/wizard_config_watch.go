@@ -0,0 +1,81 @@
+package ui
+
+import (
+	"fmt"
+	"log"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+
+	"singbox-launcher/core"
+)
+
+// createConfigChangedBanner creates the banner shown when config.json changes on disk while the
+// wizard is open (e.g. edited in another editor). The listener is removed when the window closes.
+func (state *WizardState) createConfigChangedBanner() fyne.CanvasObject {
+	var banner *fyne.Container
+
+	restartButton := widget.NewButton("Restart to apply changes", func() {
+		go core.RestartSingBoxProcess(state.Controller)
+		banner.Hide()
+	})
+	reloadButton := widget.NewButton("Reload", func() {
+		if _, err := loadConfigFromFile(state); err != nil {
+			log.Printf("ConfigWizard: Failed to reload config: %v", err)
+			dialog.ShowError(fmt.Errorf("Failed to reload config: %w", err), state.Window)
+			return
+		}
+		state.updateTemplatePreview()
+		banner.Hide()
+	})
+	reloadButton.Importance = widget.HighImportance
+	dismissButton := widget.NewButton("Dismiss", func() { banner.Hide() })
+
+	label := widget.NewLabel("config.json changed on disk. Reload?")
+	label.Importance = widget.WarningImportance
+	banner = container.NewVBox(
+		container.NewHBox(label, layout.NewSpacer(), reloadButton, restartButton, dismissButton),
+		widget.NewSeparator(),
+	)
+	banner.Hide()
+
+	unsubscribe := state.Controller.Events.Subscribe(func(event core.Event) {
+		if event != core.ConfigChanged {
+			return
+		}
+		fyne.Do(func() {
+			// Restarting only makes sense if the core runs with the old config
+			if state.Controller.RunningState.IsRunning() {
+				restartButton.Show()
+			} else {
+				restartButton.Hide()
+			}
+			banner.Show()
+		})
+	})
+	state.Window.SetOnClosed(func() {
+		unsubscribe()
+		releaseDialogQueue(state.Window)
+	})
+	return banner
+}
+
+// createTemplateWarningsBanner creates the yellow banner listing the ValidationWarnings of
+// config_template.json (e.g. a route.final that is not an outbound); empty if there are none
+func (state *WizardState) createTemplateWarningsBanner() fyne.CanvasObject {
+	banner := container.NewVBox()
+	if state.TemplateData == nil || len(state.TemplateData.Warnings) == 0 {
+		return banner
+	}
+	for _, warning := range state.TemplateData.Warnings {
+		label := widget.NewLabel("⚠️ " + warning.Message)
+		label.Importance = widget.WarningImportance
+		label.Wrapping = fyne.TextWrapWord
+		banner.Add(label)
+	}
+	banner.Add(widget.NewSeparator())
+	return banner
+}
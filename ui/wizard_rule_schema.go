@@ -0,0 +1,183 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+	"github.com/muhammadmuzzammil1998/jsonc"
+)
+
+// Field types supported by @schema
+const (
+	schemaTypeString = "string"
+	schemaTypeBool   = "bool"
+	schemaTypeEnum   = "enum"
+)
+
+// FieldSpec describes one field of a selectable rule that can be edited in the wizard (see @schema):
+//
+//	@schema {"domain_suffix": {"type": "string", "description": "Comma-separated domains"},
+//	         "invert": {"type": "bool", "default": "false"},
+//	         "network": {"type": "enum", "enum": ["tcp", "udp"], "default": "tcp"}}
+type FieldSpec struct {
+	Type        string   `json:"type"` // string, bool or enum
+	Description string   `json:"description,omitempty"`
+	Default     string   `json:"default,omitempty"`
+	Enum        []string `json:"enum,omitempty"` // choices of an enum field
+}
+
+// parseRuleSchema parses the JSON object of a @schema directive
+func parseRuleSchema(text string) (map[string]FieldSpec, error) {
+	var schema map[string]FieldSpec
+	if err := json.Unmarshal(jsonc.ToJSON([]byte(text)), &schema); err != nil {
+		return nil, fmt.Errorf("invalid @schema: %w", err)
+	}
+	for name, spec := range schema {
+		switch spec.Type {
+		case "":
+			spec.Type = schemaTypeString
+			schema[name] = spec
+		case schemaTypeString, schemaTypeBool:
+		case schemaTypeEnum:
+			if len(spec.Enum) == 0 {
+				return nil, fmt.Errorf("invalid @schema: enum field %q has no choices", name)
+			}
+		default:
+			return nil, fmt.Errorf("invalid @schema: field %q has unknown type %q", name, spec.Type)
+		}
+	}
+	return schema, nil
+}
+
+// schemaFieldNames returns the field names of a schema in a stable order
+func schemaFieldNames(schema map[string]FieldSpec) []string {
+	names := make([]string, 0, len(schema))
+	for name := range schema {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// defaultSchemaValues returns the initial form values of a rule: the @schema default,
+// or the value the rule already has in the template
+func defaultSchemaValues(rule TemplateSelectableRule) map[string]string {
+	values := make(map[string]string, len(rule.Schema))
+	for name, spec := range rule.Schema {
+		value := spec.Default
+		if value == "" {
+			value = formatSchemaValue(rule.Raw[name])
+		}
+		values[name] = value
+	}
+	return values
+}
+
+// formatSchemaValue shows a rule value in a form field; lists are comma-separated
+func formatSchemaValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		if v {
+			return "true"
+		}
+		return "false"
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, formatSchemaValue(item))
+		}
+		return strings.Join(items, ", ")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// applySchemaValues writes the form values into a cloned rule. Fields that are lists in the
+// template stay lists (the value is split by commas); empty string fields are removed.
+func applySchemaValues(rule map[string]interface{}, schema map[string]FieldSpec, values map[string]string) {
+	for name, spec := range schema {
+		value, ok := values[name]
+		if !ok {
+			continue
+		}
+		if spec.Type == schemaTypeBool {
+			rule[name] = value == "true"
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if value == "" {
+			delete(rule, name)
+			continue
+		}
+		if _, isList := rule[name].([]interface{}); isList {
+			var items []interface{}
+			for _, item := range strings.Split(value, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					items = append(items, item)
+				}
+			}
+			rule[name] = items
+			continue
+		}
+		rule[name] = value
+	}
+}
+
+// createRuleSchemaForm creates the form editing the @schema fields of a rule
+func createRuleSchemaForm(state *WizardState, ruleState *SelectableRuleState) *widget.Form {
+	if ruleState.FieldValues == nil {
+		ruleState.FieldValues = defaultSchemaValues(ruleState.Rule)
+	}
+	set := func(name, value string) {
+		if ruleState.FieldValues[name] == value {
+			return
+		}
+		ruleState.FieldValues[name] = value
+		state.updateTemplatePreview()
+	}
+
+	form := widget.NewForm()
+	for _, name := range schemaFieldNames(ruleState.Rule.Schema) {
+		name := name
+		spec := ruleState.Rule.Schema[name]
+		value := ruleState.FieldValues[name]
+
+		var field fyne.CanvasObject
+		switch spec.Type {
+		case schemaTypeBool:
+			check := widget.NewCheck("", func(checked bool) {
+				set(name, formatSchemaValue(checked))
+			})
+			check.SetChecked(value == "true")
+			field = check
+		case schemaTypeEnum:
+			sel := widget.NewSelect(spec.Enum, func(selected string) {
+				set(name, selected)
+			})
+			if containsString(spec.Enum, value) {
+				sel.SetSelected(value)
+			}
+			field = sel
+		default:
+			entry := widget.NewEntry()
+			entry.SetText(value)
+			entry.OnChanged = func(text string) {
+				set(name, text)
+			}
+			field = entry
+		}
+
+		item := widget.NewFormItem(name, field)
+		item.HintText = spec.Description
+		form.AppendItem(item)
+	}
+	return form
+}
@@ -0,0 +1,167 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"singbox-launcher/core"
+)
+
+// firstProxySource returns the first proxy source from the ParserConfig entry, or nil
+func (state *WizardState) firstProxySource() *core.ProxySource {
+	if state.ParserConfigEntry == nil {
+		return nil
+	}
+	var parserConfig core.ParserConfig
+	if err := json.Unmarshal([]byte(strings.TrimSpace(state.ParserConfigEntry.Text)), &parserConfig); err != nil {
+		return nil
+	}
+	if len(parserConfig.ParserConfig.Proxies) == 0 {
+		return nil
+	}
+	return &parserConfig.ParserConfig.Proxies[0]
+}
+
+// sourceFetchOptions returns decrypted request options of the first proxy source
+func (state *WizardState) sourceFetchOptions() (core.FetchOptions, error) {
+	source := state.firstProxySource()
+	if source == nil {
+		return core.FetchOptions{}, nil
+	}
+	return source.FetchOptions()
+}
+
+// updateFirstProxySource applies fn to the first proxy source in the ParserConfig entry
+func (state *WizardState) updateFirstProxySource(fn func(source *core.ProxySource) error) error {
+	var parserConfig core.ParserConfig
+	if err := json.Unmarshal([]byte(strings.TrimSpace(state.ParserConfigEntry.Text)), &parserConfig); err != nil {
+		return fmt.Errorf("ParserConfig is not valid JSON: %w", err)
+	}
+	if len(parserConfig.ParserConfig.Proxies) == 0 {
+		parserConfig.ParserConfig.Proxies = []core.ProxySource{
+			{Source: strings.TrimSpace(state.VLESSURLEntry.Text)},
+		}
+	}
+	if err := fn(&parserConfig.ParserConfig.Proxies[0]); err != nil {
+		return err
+	}
+	serialized, err := serializeParserConfig(&parserConfig)
+	if err != nil {
+		return err
+	}
+	state.parserConfigUpdating = true
+	state.ParserConfigEntry.SetText(serialized)
+	state.parserConfigUpdating = false
+	state.ParserConfig = &parserConfig
+	state.previewNeedsParse = true
+	return nil
+}
+
+// updateAuthStatus shows masked authentication settings of the first proxy source
+func (state *WizardState) updateAuthStatus() {
+	if state.AuthStatusLabel == nil {
+		return
+	}
+	source := state.firstProxySource()
+	if source == nil || (source.Authorization == "" && len(source.ExtraHeaders) == 0) {
+		state.AuthStatusLabel.SetText("Authentication: none")
+		return
+	}
+	parts := make([]string, 0, len(source.ExtraHeaders)+1)
+	if source.Authorization != "" {
+		parts = append(parts, "Authorization: "+core.MaskSecret(source.Authorization))
+	}
+	for _, name := range sortedHeaderNames(source.ExtraHeaders) {
+		parts = append(parts, name+": "+core.MaskSecret(source.ExtraHeaders[name]))
+	}
+	state.AuthStatusLabel.SetText("Authentication: " + strings.Join(parts, ", "))
+}
+
+// showSourceAuthDialog opens a dialog to edit the Authorization and extra headers of the first proxy source
+func showSourceAuthDialog(state *WizardState) {
+	opts, err := state.sourceFetchOptions()
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("Failed to read saved authentication: %w\n\nEnter the values again to replace them.", err), state.Window)
+		opts = core.FetchOptions{}
+	}
+
+	authEntry := widget.NewPasswordEntry()
+	authEntry.SetPlaceHolder("Bearer <token>")
+	authEntry.SetText(opts.Authorization)
+
+	headersEntry := widget.NewMultiLineEntry()
+	headersEntry.SetPlaceHolder("Cookie: session=...\nX-Custom-Header: value")
+	headersEntry.Wrapping = fyne.TextWrapOff
+	headersEntry.SetMinRowsVisible(4)
+	headersEntry.SetText(formatHeaderLines(opts.Headers))
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("Authorization", authEntry),
+		widget.NewFormItem("Extra headers", headersEntry),
+	}
+	form := dialog.NewForm("Subscription Authentication", "Save", "Cancel", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		headers, err := parseHeaderLines(headersEntry.Text)
+		if err != nil {
+			dialog.ShowError(err, state.Window)
+			return
+		}
+		err = state.updateFirstProxySource(func(source *core.ProxySource) error {
+			return source.SetAuth(authEntry.Text, headers)
+		})
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("Failed to save authentication: %w", err), state.Window)
+			return
+		}
+		state.updateAuthStatus()
+	}, state.Window)
+	form.Resize(fyne.NewSize(520, 320))
+	form.Show()
+}
+
+// parseHeaderLines parses "Name: value" lines into a header map
+func parseHeaderLines(text string) (map[string]string, error) {
+	headers := make(map[string]string)
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		name, value, found := strings.Cut(line, ":")
+		name = strings.TrimSpace(name)
+		if !found || name == "" {
+			return nil, fmt.Errorf("header line %d must be in \"Name: value\" format", i+1)
+		}
+		headers[name] = strings.TrimSpace(value)
+	}
+	if len(headers) == 0 {
+		return nil, nil
+	}
+	return headers, nil
+}
+
+// formatHeaderLines formats a header map as "Name: value" lines
+func formatHeaderLines(headers map[string]string) string {
+	lines := make([]string, 0, len(headers))
+	for _, name := range sortedHeaderNames(headers) {
+		lines = append(lines, name+": "+headers[name])
+	}
+	return strings.Join(lines, "\n")
+}
+
+func sortedHeaderNames(headers map[string]string) []string {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
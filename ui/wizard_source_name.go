@@ -0,0 +1,79 @@
+package ui
+
+import (
+	"log"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"singbox-launcher/core"
+)
+
+// createSourceNameRow creates the row editing the name of the first proxy source and
+// whether its proxy tags are prefixed with that name
+func createSourceNameRow(state *WizardState) fyne.CanvasObject {
+	state.SourceNameEntry = widget.NewEntry()
+	state.SourceNameEntry.Wrapping = fyne.TextWrapOff
+	state.SourceNameEntry.OnChanged = func(value string) {
+		state.applySourceName(func(source *core.ProxySource) {
+			source.Name = strings.TrimSpace(value)
+		})
+	}
+
+	state.PrefixTagsCheck = widget.NewCheck("Prefix proxy tags with the name", func(checked bool) {
+		state.applySourceName(func(source *core.ProxySource) {
+			source.PrefixTags = checked
+		})
+	})
+
+	return container.NewBorder(nil, nil, widget.NewLabel("Name:"), state.PrefixTagsCheck, state.SourceNameEntry)
+}
+
+// applySourceName changes the name settings of the first proxy source in the ParserConfig entry.
+// Nothing is rewritten if they don't change (e.g. when syncSourceNameFields sets the fields).
+func (state *WizardState) applySourceName(fn func(source *core.ProxySource)) {
+	current := state.firstProxySource()
+	if current == nil {
+		return
+	}
+	updated := *current
+	fn(&updated)
+	if updated.Name == current.Name && updated.PrefixTags == current.PrefixTags {
+		return
+	}
+	err := state.updateFirstProxySource(func(source *core.ProxySource) error {
+		fn(source)
+		return nil
+	})
+	if err != nil {
+		log.Printf("ConfigWizard: Failed to update subscription name: %v", err)
+	}
+}
+
+// syncSourceNameFields shows the name of the first proxy source. When the name is empty,
+// the placeholder shows the name generated from the URL.
+func (state *WizardState) syncSourceNameFields() {
+	if state.SourceNameEntry == nil || state.PrefixTagsCheck == nil {
+		return
+	}
+	source := state.firstProxySource()
+	if source == nil {
+		source = &core.ProxySource{}
+		if state.VLESSURLEntry != nil {
+			source.Source = state.VLESSURLEntry.Text
+		}
+	}
+	placeholder := core.ProxySourceNameFromURL(source.Source)
+	if placeholder == "" {
+		placeholder = "subscription.example.com"
+	}
+	state.SourceNameEntry.SetPlaceHolder(placeholder)
+	if state.SourceNameEntry.Text != source.Name {
+		state.SourceNameEntry.SetText(source.Name)
+	}
+	if state.PrefixTagsCheck.Checked != source.PrefixTags {
+		state.PrefixTagsCheck.SetChecked(source.PrefixTags)
+	}
+}